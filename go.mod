@@ -3,11 +3,17 @@ module github.com/submariner-io/cloud-prepare
 go 1.13
 
 require (
+	github.com/Azure/azure-sdk-for-go/sdk/azcore v1.0.0
+	github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork v1.0.0
+	github.com/IBM/go-sdk-core/v5 v5.9.5
+	github.com/IBM/vpc-go-sdk v0.20.0
 	github.com/aws/aws-sdk-go-v2 v1.13.0
 	github.com/aws/aws-sdk-go-v2/config v1.13.1
 	github.com/aws/aws-sdk-go-v2/credentials v1.8.0
 	github.com/aws/aws-sdk-go-v2/service/ec2 v1.29.0
+	github.com/aws/aws-sdk-go-v2/service/servicequotas v1.11.0
 	github.com/aws/smithy-go v1.10.0
+	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/golang/mock v1.6.0
 	github.com/gophercloud/gophercloud v0.24.0
 	github.com/onsi/ginkgo v1.16.5
@@ -18,4 +24,5 @@ require (
 	k8s.io/api v0.19.16
 	k8s.io/apimachinery v0.19.16
 	k8s.io/client-go v0.19.16
+	sigs.k8s.io/yaml v1.2.0
 )
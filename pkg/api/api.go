@@ -17,6 +17,8 @@ limitations under the License.
 */
 package api
 
+import "context"
+
 // Reporter is responsible for reporting back on the progress of the cloud preparation.
 type Reporter interface {
 	// Started will report that an operation started on the cloud.
@@ -25,14 +27,22 @@ type Reporter interface {
 	// Succeeded will report that the last operation on the cloud has succeeded.
 	Succeeded(message string, args ...interface{})
 
-	// Failed will report that the last operation on the cloud has failed.
+	// Failed will report that the last operation on the cloud has failed. It is always accompanied by an error returned
+	// from the operation.
 	Failed(errs ...error)
+
+	// Warning will report a problem with the last operation on the cloud which didn't prevent it from completing.
+	Warning(message string, args ...interface{})
 }
 
 // PortSpec is a specification of port+protocol to open.
 type PortSpec struct {
 	Port     uint16
 	Protocol string
+
+	// SourceCIDRs optionally restricts the sources allowed to reach a public port, overriding the
+	// GatewayDeployInput's PublicSourceCIDRs. It's ignored for the internal ports.
+	SourceCIDRs []string
 }
 
 type PrepareForSubmarinerInput struct {
@@ -45,17 +55,42 @@ type Cloud interface {
 	// PrepareForSubmariner will prepare the cloud for Submariner to operate on.
 	PrepareForSubmariner(input PrepareForSubmarinerInput, reporter Reporter) error
 
-	// // CreateVpcPeering Creates a VPC Peering to the target cloud
+	// PrepareForSubmarinerWithContext is PrepareForSubmariner, bound to the given context.
+	PrepareForSubmarinerWithContext(ctx context.Context, input PrepareForSubmarinerInput, reporter Reporter) error
+
+	// CreateVpcPeering Creates a VPC Peering to the target cloud
 	CreateVpcPeering(target Cloud, reporter Reporter) error
 
+	// CreateVpcPeeringWithContext is CreateVpcPeering, bound to the given context.
+	CreateVpcPeeringWithContext(ctx context.Context, target Cloud, reporter Reporter) error
+
+	// CleanupVpcPeering Removes the VPC Peering with the target cloud and the related Routes.
+	CleanupVpcPeering(target Cloud, reporter Reporter) error
+
+	// CleanupVpcPeeringWithContext is CleanupVpcPeering, bound to the given context.
+	CleanupVpcPeeringWithContext(ctx context.Context, target Cloud, reporter Reporter) error
+
 	// CleanupAfterSubmariner will clean up the cloud after Submariner is removed.
 	CleanupAfterSubmariner(reporter Reporter) error
+
+	// CleanupAfterSubmarinerWithContext is CleanupAfterSubmariner, bound to the given context.
+	CleanupAfterSubmarinerWithContext(ctx context.Context, reporter Reporter) error
+
+	// Plan returns the changes PrepareForSubmariner would make to the cloud, without applying them.
+	Plan(ctx context.Context, input PrepareForSubmarinerInput) ([]Action, error)
+
+	// Inspect returns what has been set up on the cloud for Submariner.
+	Inspect(ctx context.Context) (*CloudStatus, error)
 }
 
 type GatewayDeployInput struct {
 	// List of ports to open externally so that Submariner can reach and be reached by other Submariners.
 	PublicPorts []PortSpec
 
+	// PublicSourceCIDRs restricts the sources allowed to reach the public ports which don't specify their own
+	// SourceCIDRs. The public ports are open to any IPv4 address if empty.
+	PublicSourceCIDRs []string
+
 	// Amount of gateways that are being deployed.
 	//
 	// 0 = Deploy gateways per the default deployer policy (Default if not specified)
@@ -69,6 +104,26 @@ type GatewayDeployer interface {
 	// Deploy dedicated gateways as requested.
 	Deploy(input GatewayDeployInput, reporter Reporter) error
 
+	// DeployWithContext is Deploy, bound to the given context.
+	DeployWithContext(ctx context.Context, input GatewayDeployInput, reporter Reporter) error
+
 	// Cleanup any dedicated gateways that were previously deployed.
 	Cleanup(reporter Reporter) error
+
+	// CleanupWithContext is Cleanup, bound to the given context.
+	CleanupWithContext(ctx context.Context, reporter Reporter) error
+
+	// Plan returns the changes Deploy would make to the cloud, without applying them.
+	Plan(ctx context.Context, input GatewayDeployInput) ([]Action, error)
+
+	// Inspect returns the gateways that have been deployed on the cloud.
+	Inspect(ctx context.Context) (*GatewayStatus, error)
+}
+
+// PublicPortsUpdater is implemented by the gateway deployers which can update the rules opening the public ports in
+// place, adding and removing source CIDRs, without redeploying the gateways.
+type PublicPortsUpdater interface {
+	// UpdatePublicPorts opens the public ports of the given input to their source CIDRs, and revokes the access from the
+	// sources which are no longer allowed. The number of gateways of the input is ignored.
+	UpdatePublicPorts(ctx context.Context, input GatewayDeployInput, reporter Reporter) error
 }
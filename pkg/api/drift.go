@@ -0,0 +1,161 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Drift is a difference between the state a cloud is expected to be in and its actual state, e.g. because a security
+// group or firewall rule was edited by hand.
+type Drift struct {
+	// Resource is the name or ID of the resource which drifted.
+	Resource string
+
+	// Message describes the difference.
+	Message string
+
+	// Repair is the change which brings the resource back to its expected state.
+	Repair Action
+}
+
+func (d Drift) String() string {
+	return d.Message
+}
+
+var driftMessages = map[ActionKind]string{
+	ActionCreateSecurityGroup: "security group %s is missing",
+	ActionAuthorizeIngress:    "security group %s is missing an ingress rule",
+	ActionAttachSecurityGroup: "server %s isn't attached to its security group",
+	ActionTagSubnet:           "subnet %s isn't tagged for gateways",
+	ActionInsertFirewallRule:  "firewall rule %s is missing",
+	ActionUpdateFirewallRule:  "firewall rule %s doesn't allow the expected ports",
+	ActionTagInstance:         "instance %s isn't tagged as a gateway",
+	ActionConfigurePublicIP:   "instance %s has no public IP",
+	ActionDeployMachineSet:    "gateway machine set %s is missing",
+	ActionLabelNode:           "node %s isn't labeled as a gateway",
+	ActionUnlabelNode:         "node %s is a surplus gateway",
+	ActionDeleteMachineSet:    "gateway machine set %s is surplus",
+	ActionUntagSubnet:         "subnet %s hosts a surplus gateway",
+	ActionUntagInstance:       "instance %s is a surplus gateway",
+	ActionDeletePublicIP:      "instance %s is a surplus gateway with a public IP",
+	ActionDetachSecurityGroup: "server %s is a surplus gateway attached to the gateway security group",
+	ActionCreateNodePool:      "gateway node pool %s is missing",
+	ActionUpdateNodePool:      "gateway node pool %s doesn't span the expected zones",
+}
+
+// NewDrift returns the Drift which the given planned Action would repair.
+func NewDrift(repair Action) Drift {
+	format, ok := driftMessages[repair.Kind]
+	if !ok {
+		format = "%s needs " + string(repair.Kind)
+	}
+
+	message := fmt.Sprintf(format, repair.Resource)
+	if len(repair.Parameters) > 0 {
+		message = fmt.Sprintf("%s (%s)", message, repair.formatParameters())
+	}
+
+	return Drift{
+		Resource: repair.Resource,
+		Message:  message,
+		Repair:   repair,
+	}
+}
+
+func newDrifts(actions []Action) []Drift {
+	drifts := make([]Drift, 0, len(actions))
+	for i := range actions {
+		drifts = append(drifts, NewDrift(actions[i]))
+	}
+
+	return drifts
+}
+
+// VerifyCloud compares the state the cloud is expected to be in once prepared with the given input, with its actual
+// state, and returns the differences. It doesn't change anything in the cloud.
+func VerifyCloud(ctx context.Context, cloud Cloud, input PrepareForSubmarinerInput) ([]Drift, error) {
+	actions, err := cloud.Plan(ctx, input)
+	if err != nil {
+		return nil, errors.Wrap(err, "error verifying the cloud")
+	}
+
+	return newDrifts(actions), nil
+}
+
+// VerifyGateways compares the gateways expected to be deployed with the given input, with the actual gateways, and
+// returns the differences. It doesn't change anything in the cloud.
+func VerifyGateways(ctx context.Context, gwDeployer GatewayDeployer, input GatewayDeployInput) ([]Drift, error) {
+	actions, err := gwDeployer.Plan(ctx, input)
+	if err != nil {
+		return nil, errors.Wrap(err, "error verifying the gateways")
+	}
+
+	return newDrifts(actions), nil
+}
+
+// ReconcileCloud reports each difference between the expected and the actual state of the cloud, and repairs them by
+// preparing the cloud again with the given input. It returns the differences which were found.
+func ReconcileCloud(ctx context.Context, cloud Cloud, input PrepareForSubmarinerInput, reporter Reporter) ([]Drift, error) {
+	drifts, err := verify(reporter, func() ([]Drift, error) {
+		return VerifyCloud(ctx, cloud, input)
+	})
+	if err != nil || len(drifts) == 0 {
+		return drifts, err
+	}
+
+	return drifts, errors.Wrap(cloud.PrepareForSubmarinerWithContext(ctx, input, reporter), "error repairing the cloud")
+}
+
+// ReconcileGateways reports each difference between the expected and the actual gateways, and repairs them by
+// deploying the gateways again with the given input. It returns the differences which were found.
+func ReconcileGateways(ctx context.Context, gwDeployer GatewayDeployer, input GatewayDeployInput, reporter Reporter) ([]Drift, error) {
+	drifts, err := verify(reporter, func() ([]Drift, error) {
+		return VerifyGateways(ctx, gwDeployer, input)
+	})
+	if err != nil || len(drifts) == 0 {
+		return drifts, err
+	}
+
+	return drifts, errors.Wrap(gwDeployer.DeployWithContext(ctx, input, reporter), "error repairing the gateways")
+}
+
+func verify(reporter Reporter, verifyFn func() ([]Drift, error)) ([]Drift, error) {
+	reporter.Started("Verifying the cloud against its expected state")
+
+	drifts, err := verifyFn()
+	if err != nil {
+		reporter.Failed(err)
+		return nil, err
+	}
+
+	for _, drift := range drifts {
+		reporter.Warning("%s", drift.Message)
+	}
+
+	if len(drifts) == 0 {
+		reporter.Succeeded("The cloud is in its expected state")
+	} else {
+		reporter.Succeeded("Found %d differences from the expected state", len(drifts))
+	}
+
+	return drifts, nil
+}
@@ -0,0 +1,115 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+var _ = Describe("ReconcileCloud", func() {
+	var (
+		cloud    *driftedCloud
+		reporter *recordingReporter
+		drifts   []api.Drift
+		retError error
+	)
+
+	BeforeEach(func() {
+		cloud = &driftedCloud{}
+		reporter = &recordingReporter{}
+	})
+
+	JustBeforeEach(func() {
+		drifts, retError = api.ReconcileCloud(context.TODO(), cloud, api.PrepareForSubmarinerInput{}, reporter)
+	})
+
+	When("the cloud has drifted", func() {
+		BeforeEach(func() {
+			cloud.actions = []api.Action{{
+				Kind:       api.ActionAuthorizeIngress,
+				Resource:   "worker-sg",
+				Parameters: map[string]string{"port": "4800", "protocol": "udp"},
+			}}
+		})
+
+		It("should report the drift and repair it", func() {
+			Expect(retError).To(Succeed())
+			Expect(drifts).To(Equal([]api.Drift{{
+				Resource: "worker-sg",
+				Message:  "security group worker-sg is missing an ingress rule (port=4800, protocol=udp)",
+				Repair:   cloud.actions[0],
+			}}))
+			Expect(reporter.messages).To(ContainElement(
+				"warning: security group worker-sg is missing an ingress rule (port=4800, protocol=udp)"))
+			Expect(cloud.prepared).To(BeTrue())
+		})
+
+		Context("and the repair fails", func() {
+			BeforeEach(func() {
+				cloud.prepareErr = errors.New("fake prepare error")
+			})
+
+			It("should return an error", func() {
+				Expect(retError).To(MatchError(ContainSubstring(cloud.prepareErr.Error())))
+				Expect(drifts).To(HaveLen(1))
+			})
+		})
+	})
+
+	When("the cloud hasn't drifted", func() {
+		It("should not repair it", func() {
+			Expect(retError).To(Succeed())
+			Expect(drifts).To(BeEmpty())
+			Expect(cloud.prepared).To(BeFalse())
+		})
+	})
+
+	When("verification fails", func() {
+		BeforeEach(func() {
+			cloud.planErr = errors.New("fake plan error")
+		})
+
+		It("should report and return an error", func() {
+			Expect(retError).To(HaveOccurred())
+			Expect(reporter.messages).To(ContainElement(ContainSubstring("failed: ")))
+			Expect(cloud.prepared).To(BeFalse())
+		})
+	})
+})
+
+type driftedCloud struct {
+	api.Cloud
+	actions    []api.Action
+	planErr    error
+	prepareErr error
+	prepared   bool
+}
+
+func (c *driftedCloud) Plan(_ context.Context, _ api.PrepareForSubmarinerInput) ([]api.Action, error) {
+	return c.actions, c.planErr
+}
+
+func (c *driftedCloud) PrepareForSubmarinerWithContext(_ context.Context, _ api.PrepareForSubmarinerInput, _ api.Reporter) error {
+	c.prepared = true
+	return c.prepareErr
+}
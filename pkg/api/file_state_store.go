@@ -0,0 +1,79 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type fileStateStore struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewFileStateStore returns a StateStore persisting the State as JSON in the given local file.
+func NewFileStateStore(path string) StateStore {
+	return &fileStateStore{path: path}
+}
+
+func (f *fileStateStore) Load(_ context.Context) (*State, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state := &State{Resources: []Resource{}}
+
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading the state file %q", f.path)
+	}
+
+	err = json.Unmarshal(data, state)
+
+	return state, errors.Wrapf(err, "error parsing the state file %q", f.path)
+}
+
+func (f *fileStateStore) Save(_ context.Context, state *State) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error serializing the state")
+	}
+
+	// Write to a temporary file first so that an interrupted save doesn't lose the existing record.
+	tmpPath := filepath.Join(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp")
+
+	err = ioutil.WriteFile(tmpPath, data, 0o600)
+	if err != nil {
+		return errors.Wrapf(err, "error writing the state file %q", tmpPath)
+	}
+
+	return errors.Wrapf(os.Rename(tmpPath, f.path), "error replacing the state file %q", f.path)
+}
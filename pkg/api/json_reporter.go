@@ -0,0 +1,125 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type jsonReporter struct {
+	mutex     sync.Mutex
+	encoder   *json.Encoder
+	provider  string
+	nextID    int
+	steps     []string
+	startTime map[string]time.Time
+}
+
+// NewJSONReporter returns a StructuredReporter writing each Event as a line of JSON to the given writer. Steps reported
+// without an ID are given one, and events are attributed to the given cloud provider unless they name their own.
+func NewJSONReporter(out io.Writer, provider string) StructuredReporter {
+	return &jsonReporter{
+		encoder:   json.NewEncoder(out),
+		provider:  provider,
+		startTime: map[string]time.Time{},
+	}
+}
+
+func (r *jsonReporter) Started(message string, args ...interface{}) {
+	r.Report(Event{Type: EventStarted, Message: fmt.Sprintf(message, args...)})
+}
+
+func (r *jsonReporter) Succeeded(message string, args ...interface{}) {
+	r.Report(Event{Type: EventSucceeded, Message: fmt.Sprintf(message, args...)})
+}
+
+func (r *jsonReporter) Failed(errs ...error) {
+	r.Report(Event{Type: EventFailed, Message: failureMessage(errs)})
+}
+
+func (r *jsonReporter) Warning(message string, args ...interface{}) {
+	r.Report(Event{Type: EventWarning, Message: fmt.Sprintf(message, args...)})
+}
+
+func (r *jsonReporter) Report(event Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	if event.Severity == "" {
+		event.Severity = defaultSeverity(event.Type)
+	}
+
+	if event.Provider == "" {
+		event.Provider = r.provider
+	}
+
+	switch event.Type {
+	case EventStarted:
+		if event.ID == "" {
+			r.nextID++
+			event.ID = "step-" + strconv.Itoa(r.nextID)
+		}
+
+		r.steps = append(r.steps, event.ID)
+		r.startTime[event.ID] = event.Time
+	case EventSucceeded, EventFailed:
+		event.ID = r.endStep(event.ID)
+
+		if startTime, ok := r.startTime[event.ID]; ok && event.Duration == 0 {
+			event.Duration = event.Time.Sub(startTime)
+		}
+
+		delete(r.startTime, event.ID)
+	case EventWarning:
+		if event.ID == "" && len(r.steps) > 0 {
+			event.ID = r.steps[len(r.steps)-1]
+		}
+	}
+
+	// There's nowhere to report a failure to write a report.
+	_ = r.encoder.Encode(&event)
+}
+
+// endStep removes the given step, or the step started last if none is given, from the running steps.
+func (r *jsonReporter) endStep(id string) string {
+	if id == "" {
+		if len(r.steps) == 0 {
+			return ""
+		}
+
+		id = r.steps[len(r.steps)-1]
+	}
+
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		if r.steps[i] == id {
+			r.steps = append(r.steps[:i], r.steps[i+1:]...)
+			break
+		}
+	}
+
+	return id
+}
@@ -0,0 +1,176 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+var _ = Describe("JSON Reporter", func() {
+	var (
+		out      *bytes.Buffer
+		reporter api.StructuredReporter
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		reporter = api.NewJSONReporter(out, "aws")
+	})
+
+	events := func() []api.Event {
+		events := []api.Event{}
+
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			event := api.Event{}
+			Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+			events = append(events, event)
+		}
+
+		return events
+	}
+
+	When("plain messages are reported", func() {
+		BeforeEach(func() {
+			reporter.Started("Creating %s", "group")
+			reporter.Started("Adding rule")
+			reporter.Failed(errors.New("fake error"))
+			reporter.Succeeded("Created %s", "group")
+		})
+
+		It("should write an event per line and correlate the steps", func() {
+			events := events()
+			Expect(events).To(HaveLen(4))
+
+			Expect(events[0].Type).To(Equal(api.EventStarted))
+			Expect(events[0].Message).To(Equal("Creating group"))
+			Expect(events[0].Provider).To(Equal("aws"))
+			Expect(events[0].Severity).To(Equal(api.SeverityInfo))
+
+			Expect(events[2].Type).To(Equal(api.EventFailed))
+			Expect(events[2].ID).To(Equal(events[1].ID))
+			Expect(events[2].Message).To(Equal("fake error"))
+			Expect(events[2].Severity).To(Equal(api.SeverityError))
+
+			Expect(events[3].ID).To(Equal(events[0].ID))
+			Expect(events[3].ID).ToNot(Equal(events[1].ID))
+			Expect(events[3].Duration).ToNot(BeZero())
+		})
+	})
+
+	When("events are reported", func() {
+		BeforeEach(func() {
+			step := api.Step{ID: "gateway-sg", ResourceType: "SecurityGroup", ResourceID: "sg-1"}
+			reporter.Report(api.Event{Step: step, Type: api.EventStarted, Message: "Creating the gateway group"})
+			reporter.Warning("The group already exists")
+			reporter.Report(api.Event{Step: step, Type: api.EventSucceeded})
+		})
+
+		It("should keep their step details", func() {
+			events := events()
+			Expect(events).To(HaveLen(3))
+
+			Expect(events[0].Step).To(Equal(api.Step{ID: "gateway-sg", Provider: "aws", ResourceType: "SecurityGroup", ResourceID: "sg-1"}))
+			Expect(events[1].ID).To(Equal("gateway-sg"))
+			Expect(events[1].Severity).To(Equal(api.SeverityWarning))
+			Expect(events[2].ID).To(Equal("gateway-sg"))
+		})
+	})
+})
+
+var _ = Describe("NewStructuredReporter", func() {
+	It("should return StructuredReporters as is", func() {
+		reporter := api.NewJSONReporter(&bytes.Buffer{}, "")
+		Expect(api.NewStructuredReporter(reporter)).To(BeIdenticalTo(reporter))
+	})
+
+	It("should report events to plain Reporters", func() {
+		reporter := &recordingReporter{}
+		structured := api.NewStructuredReporter(reporter)
+
+		structured.Report(api.Event{Type: api.EventStarted, Message: "Creating the gateway group"})
+		structured.Report(api.Event{Type: api.EventWarning, Message: "The group already exists"})
+		structured.Report(api.Event{Type: api.EventFailed, Message: "fake error"})
+
+		Expect(reporter.messages).To(Equal([]string{
+			"started: Creating the gateway group", "warning: The group already exists", "failed: fake error",
+		}))
+	})
+})
+
+var _ = Describe("Resource steps", func() {
+	step := api.ResourceStep("gcp", api.ResourceFirewallRule, "internal-rule")
+
+	It("should identify the step by its resource", func() {
+		Expect(step).To(Equal(api.Step{
+			ID: "gcp/FirewallRule/internal-rule", Provider: "gcp", ResourceType: "FirewallRule", ResourceID: "internal-rule",
+		}))
+	})
+
+	It("should be reported as events to StructuredReporters", func() {
+		out := &bytes.Buffer{}
+		reporter := api.NewJSONReporter(out, "")
+
+		api.StepStarted(reporter, step, "Opening %s", "ports")
+		api.StepFailed(reporter, step, errors.New("fake error"))
+
+		event := api.Event{}
+		Expect(json.Unmarshal([]byte(strings.Split(out.String(), "\n")[1]), &event)).To(Succeed())
+		Expect(event.Step).To(Equal(step))
+		Expect(event.Type).To(Equal(api.EventFailed))
+		Expect(event.Message).To(Equal("fake error"))
+	})
+
+	It("should be reported as plain messages to other Reporters", func() {
+		reporter := &recordingReporter{}
+
+		api.StepStarted(reporter, step, "Opening %s", "ports")
+		api.StepSucceeded(reporter, step, "Opened %s", "ports")
+
+		Expect(reporter.messages).To(Equal([]string{"started: Opening ports", "succeeded: Opened ports"}))
+	})
+})
+
+type recordingReporter struct {
+	messages []string
+}
+
+func (r *recordingReporter) Started(message string, args ...interface{}) {
+	r.messages = append(r.messages, "started: "+fmt.Sprintf(message, args...))
+}
+
+func (r *recordingReporter) Succeeded(message string, args ...interface{}) {
+	r.messages = append(r.messages, "succeeded: "+fmt.Sprintf(message, args...))
+}
+
+func (r *recordingReporter) Warning(message string, args ...interface{}) {
+	r.messages = append(r.messages, "warning: "+fmt.Sprintf(message, args...))
+}
+
+func (r *recordingReporter) Failed(errs ...error) {
+	for _, err := range errs {
+		r.messages = append(r.messages, "failed: "+err.Error())
+	}
+}
@@ -40,3 +40,7 @@ func (r *loggingReporter) Succeeded(message string, args ...interface{}) {
 func (r *loggingReporter) Failed(errs ...error) {
 	fmt.Println(errors.NewAggregate(errs).Error())
 }
+
+func (r *loggingReporter) Warning(message string, args ...interface{}) {
+	fmt.Println("Warning: " + fmt.Sprintf(message, args...))
+}
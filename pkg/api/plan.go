@@ -0,0 +1,82 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ActionKind identifies the kind of change a planned Action would make.
+type ActionKind string
+
+const (
+	ActionCreateSecurityGroup ActionKind = "CreateSecurityGroup"
+	ActionAuthorizeIngress    ActionKind = "AuthorizeIngress"
+	ActionRevokeIngress       ActionKind = "RevokeIngress"
+	ActionAttachSecurityGroup ActionKind = "AttachSecurityGroup"
+	ActionTagSubnet           ActionKind = "TagSubnet"
+	ActionInsertFirewallRule  ActionKind = "InsertFirewallRule"
+	ActionUpdateFirewallRule  ActionKind = "UpdateFirewallRule"
+	ActionDeleteFirewallRule  ActionKind = "DeleteFirewallRule"
+	ActionTagInstance         ActionKind = "TagInstance"
+	ActionConfigurePublicIP   ActionKind = "ConfigurePublicIP"
+	ActionDeployMachineSet    ActionKind = "DeployMachineSet"
+	ActionLabelNode           ActionKind = "LabelNode"
+	ActionUnlabelNode         ActionKind = "UnlabelNode"
+	ActionDeleteMachineSet    ActionKind = "DeleteMachineSet"
+	ActionUntagSubnet         ActionKind = "UntagSubnet"
+	ActionUntagInstance       ActionKind = "UntagInstance"
+	ActionDeletePublicIP      ActionKind = "DeletePublicIP"
+	ActionDetachSecurityGroup ActionKind = "DetachSecurityGroup"
+	ActionCreateNodePool      ActionKind = "CreateNodePool"
+	ActionUpdateNodePool      ActionKind = "UpdateNodePool"
+)
+
+// Action is a change that an operation intends to make to the cloud, as returned by a Plan.
+type Action struct {
+	// Kind of change.
+	Kind ActionKind
+
+	// Resource is the name or ID of the resource the change applies to.
+	Resource string
+
+	// Parameters details the change, e.g. the port and protocol of an ingress rule.
+	Parameters map[string]string
+}
+
+func (a Action) String() string {
+	return fmt.Sprintf("%s %s [%s]", a.Kind, a.Resource, a.formatParameters())
+}
+
+func (a Action) formatParameters() string {
+	keys := make([]string, 0, len(a.Parameters))
+	for key := range a.Parameters {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		params = append(params, fmt.Sprintf("%s=%s", key, a.Parameters[key]))
+	}
+
+	return strings.Join(params, ", ")
+}
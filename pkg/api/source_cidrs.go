@@ -0,0 +1,94 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import "sort"
+
+// AnyIPv4CIDR is the source of the public ports which aren't restricted to specific CIDRs.
+const AnyIPv4CIDR = "0.0.0.0/0"
+
+// SourceCIDRs returns the sorted CIDRs allowed to reach the given public port: its own SourceCIDRs, or else the
+// PublicSourceCIDRs of the input, or else any IPv4 address.
+func (i *GatewayDeployInput) SourceCIDRs(port PortSpec) []string {
+	cidrs := port.SourceCIDRs
+	if len(cidrs) == 0 {
+		cidrs = i.PublicSourceCIDRs
+	}
+
+	if len(cidrs) == 0 {
+		return []string{AnyIPv4CIDR}
+	}
+
+	return uniqueSorted(cidrs)
+}
+
+// RestrictsSources returns whether the public ports of the input are restricted to specific source CIDRs.
+func (i *GatewayDeployInput) RestrictsSources() bool {
+	for _, port := range i.PublicPorts {
+		sources := i.SourceCIDRs(port)
+		if len(sources) != 1 || sources[0] != AnyIPv4CIDR {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DiffSourceCIDRs returns the CIDRs which must be added to the existing ones, and those which must be removed from
+// them, to obtain the desired ones.
+func DiffSourceCIDRs(existing, desired []string) (added, removed []string) {
+	existingSet := map[string]bool{}
+	for _, cidr := range existing {
+		existingSet[cidr] = true
+	}
+
+	desiredSet := map[string]bool{}
+	for _, cidr := range desired {
+		desiredSet[cidr] = true
+	}
+
+	for _, cidr := range uniqueSorted(desired) {
+		if !existingSet[cidr] {
+			added = append(added, cidr)
+		}
+	}
+
+	for _, cidr := range uniqueSorted(existing) {
+		if !desiredSet[cidr] {
+			removed = append(removed, cidr)
+		}
+	}
+
+	return added, removed
+}
+
+func uniqueSorted(values []string) []string {
+	set := map[string]bool{}
+	unique := []string{}
+
+	for _, value := range values {
+		if !set[value] {
+			set[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	sort.Strings(unique)
+
+	return unique
+}
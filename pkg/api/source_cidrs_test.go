@@ -0,0 +1,73 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+var _ = Describe("Source CIDRs", func() {
+	var input *api.GatewayDeployInput
+
+	BeforeEach(func() {
+		input = &api.GatewayDeployInput{
+			PublicPorts: []api.PortSpec{
+				{Port: 4500, Protocol: "udp"},
+				{Port: 4490, Protocol: "udp", SourceCIDRs: []string{"10.2.0.0/16", "10.1.0.0/16", "10.2.0.0/16"}},
+			},
+		}
+	})
+
+	It("should default to any IPv4 address", func() {
+		Expect(input.SourceCIDRs(input.PublicPorts[0])).To(Equal([]string{api.AnyIPv4CIDR}))
+	})
+
+	It("should use the port's own sorted CIDRs", func() {
+		Expect(input.SourceCIDRs(input.PublicPorts[1])).To(Equal([]string{"10.1.0.0/16", "10.2.0.0/16"}))
+		Expect(input.RestrictsSources()).To(BeTrue())
+	})
+
+	When("the input restricts all the public ports", func() {
+		BeforeEach(func() {
+			input.PublicSourceCIDRs = []string{"192.168.0.0/24"}
+		})
+
+		It("should use the input's CIDRs for the ports without their own", func() {
+			Expect(input.SourceCIDRs(input.PublicPorts[0])).To(Equal([]string{"192.168.0.0/24"}))
+			Expect(input.SourceCIDRs(input.PublicPorts[1])).To(Equal([]string{"10.1.0.0/16", "10.2.0.0/16"}))
+		})
+	})
+
+	When("no port is restricted", func() {
+		BeforeEach(func() {
+			input.PublicPorts = input.PublicPorts[:1]
+		})
+
+		It("should not restrict the sources", func() {
+			Expect(input.RestrictsSources()).To(BeFalse())
+		})
+	})
+
+	It("should compute the CIDRs to add and remove", func() {
+		added, removed := api.DiffSourceCIDRs([]string{"10.0.0.0/8", "0.0.0.0/0"}, []string{"10.0.0.0/8", "10.1.0.0/16"})
+		Expect(added).To(Equal([]string{"10.1.0.0/16"}))
+		Expect(removed).To(Equal([]string{"0.0.0.0/0"}))
+	})
+})
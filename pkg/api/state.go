@@ -0,0 +1,149 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ResourceType identifies the kind of a resource provisioned in a cloud.
+type ResourceType string
+
+const (
+	ResourceSecurityGroup           ResourceType = "SecurityGroup"
+	ResourceIngressRule             ResourceType = "IngressRule"
+	ResourceSecurityGroupAttachment ResourceType = "SecurityGroupAttachment"
+	ResourceSubnetTag               ResourceType = "SubnetTag"
+	ResourceFirewallRule            ResourceType = "FirewallRule"
+	ResourceGatewayInstance         ResourceType = "GatewayInstance"
+	ResourceMachineSet              ResourceType = "MachineSet"
+	ResourceNodeLabel               ResourceType = "NodeLabel"
+	ResourceFloatingIP              ResourceType = "FloatingIP"
+	ResourceNodePool                ResourceType = "NodePool"
+)
+
+// Resource is a record of a resource provisioned in a cloud.
+type Resource struct {
+	// Type of the resource.
+	Type ResourceType `json:"type"`
+
+	// ID uniquely identifies the resource among the resources of its type, e.g. the AWS security group ID or the node name.
+	ID string `json:"id"`
+
+	// Parameters holds what's needed to remove the resource, e.g. the port and protocol of an ingress rule.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// State is the record of the resources provisioned in a cloud.
+type State struct {
+	Resources []Resource `json:"resources"`
+}
+
+// Add records the given resource, replacing any existing record of the same resource.
+func (s *State) Add(resource Resource) {
+	s.Remove(resource.Type, resource.ID)
+	s.Resources = append(s.Resources, resource)
+}
+
+// Remove removes the record of the given resource.
+func (s *State) Remove(resourceType ResourceType, id string) {
+	for i := range s.Resources {
+		if s.Resources[i].Type == resourceType && s.Resources[i].ID == id {
+			s.Resources = append(s.Resources[:i], s.Resources[i+1:]...)
+			return
+		}
+	}
+}
+
+// Find returns the records of the given type of resource.
+func (s *State) Find(resourceType ResourceType) []Resource {
+	found := []Resource{}
+
+	for i := range s.Resources {
+		if s.Resources[i].Type == resourceType {
+			found = append(found, s.Resources[i])
+		}
+	}
+
+	return found
+}
+
+// StateStore persists the record of the resources provisioned in a cloud, so that they can be cleaned up exactly.
+type StateStore interface {
+	// Load returns the persisted State, which is empty if nothing was persisted yet.
+	Load(ctx context.Context) (*State, error)
+
+	// Save persists the given State.
+	Save(ctx context.Context, state *State) error
+}
+
+// RecordResources adds the given resources to the State persisted in the store. A nil store records nothing.
+func RecordResources(ctx context.Context, store StateStore, resources ...Resource) error {
+	return updateState(ctx, store, func(state *State) {
+		for i := range resources {
+			state.Add(resources[i])
+		}
+	})
+}
+
+// ForgetResources removes the given resources from the State persisted in the store. A nil store records nothing.
+func ForgetResources(ctx context.Context, store StateStore, resources ...Resource) error {
+	return updateState(ctx, store, func(state *State) {
+		for i := range resources {
+			state.Remove(resources[i].Type, resources[i].ID)
+		}
+	})
+}
+
+// RecordedResources returns the records of the given type of resource persisted in the store. Without a store, or without
+// any record of that type, an empty list is returned and callers should fall back to discovering the resources.
+func RecordedResources(ctx context.Context, store StateStore, resourceType ResourceType) ([]Resource, error) {
+	if store == nil {
+		return []Resource{}, nil
+	}
+
+	state, err := store.Load(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading the provisioned resources")
+	}
+
+	return state.Find(resourceType), nil
+}
+
+func updateState(ctx context.Context, store StateStore, update func(state *State)) error {
+	if store == nil {
+		return nil
+	}
+
+	state, err := store.Load(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error loading the provisioned resources")
+	}
+
+	update(state)
+
+	return errors.Wrap(store.Save(ctx, state), "error saving the provisioned resources")
+}
+
+// StateRecorder is implemented by the Clouds and GatewayDeployers which can record the resources they provision in a StateStore.
+// Without a store, they discover the resources to clean up from their names, tags and labels.
+type StateRecorder interface {
+	SetStateStore(store StateStore)
+}
@@ -0,0 +1,124 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+var _ = Describe("State", func() {
+	var state *api.State
+
+	BeforeEach(func() {
+		state = &api.State{}
+		state.Add(api.Resource{Type: api.ResourceSecurityGroup, ID: "sg-1"})
+		state.Add(api.Resource{Type: api.ResourceNodeLabel, ID: "node-1"})
+	})
+
+	It("should find the resources of a type", func() {
+		Expect(state.Find(api.ResourceSecurityGroup)).To(Equal([]api.Resource{{Type: api.ResourceSecurityGroup, ID: "sg-1"}}))
+		Expect(state.Find(api.ResourceMachineSet)).To(BeEmpty())
+	})
+
+	It("should replace an existing record of the same resource", func() {
+		state.Add(api.Resource{Type: api.ResourceSecurityGroup, ID: "sg-1", Parameters: map[string]string{"name": "gw"}})
+		Expect(state.Find(api.ResourceSecurityGroup)).To(Equal([]api.Resource{
+			{Type: api.ResourceSecurityGroup, ID: "sg-1", Parameters: map[string]string{"name": "gw"}},
+		}))
+	})
+
+	It("should remove a record", func() {
+		state.Remove(api.ResourceNodeLabel, "node-1")
+		Expect(state.Find(api.ResourceNodeLabel)).To(BeEmpty())
+		Expect(state.Resources).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("File StateStore", func() {
+	var (
+		dir   string
+		store api.StateStore
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "state")
+		Expect(err).To(Succeed())
+
+		store = api.NewFileStateStore(filepath.Join(dir, "state.json"))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	When("nothing was saved", func() {
+		It("should load an empty state", func() {
+			state, err := store.Load(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(state.Resources).To(BeEmpty())
+		})
+	})
+
+	It("should persist the recorded resources", func() {
+		ruleResource := api.Resource{Type: api.ResourceIngressRule, ID: "rule-1", Parameters: map[string]string{"port": "4500"}}
+		labelResource := api.Resource{Type: api.ResourceNodeLabel, ID: "node-1"}
+
+		Expect(api.RecordResources(context.TODO(), store, ruleResource, labelResource)).To(Succeed())
+
+		recorded, err := api.RecordedResources(context.TODO(), api.NewFileStateStore(filepath.Join(dir, "state.json")),
+			api.ResourceIngressRule)
+		Expect(err).To(Succeed())
+		Expect(recorded).To(Equal([]api.Resource{ruleResource}))
+
+		Expect(api.ForgetResources(context.TODO(), store, ruleResource)).To(Succeed())
+
+		state, err := store.Load(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(state.Resources).To(Equal([]api.Resource{labelResource}))
+	})
+
+	When("the state file is corrupt", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "state.json"), []byte("{"), 0o600)).To(Succeed())
+		})
+
+		It("should return an error", func() {
+			_, err := api.RecordedResources(context.TODO(), store, api.ResourceNodeLabel)
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Without a StateStore", func() {
+	It("should record nothing", func() {
+		Expect(api.RecordResources(context.TODO(), nil, api.Resource{Type: api.ResourceNodeLabel, ID: "node-1"})).To(Succeed())
+
+		recorded, err := api.RecordedResources(context.TODO(), nil, api.ResourceNodeLabel)
+		Expect(err).To(Succeed())
+		Expect(recorded).To(BeEmpty())
+	})
+})
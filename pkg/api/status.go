@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+// CloudStatus describes what has been set up on a cloud for Submariner, as returned by Cloud.Inspect.
+type CloudStatus struct {
+	// InternalPorts are the ports open inside the cluster for communication between Submariner services.
+	InternalPorts []PortSpec
+
+	// Peerings are the network peerings with other clouds.
+	Peerings []PeeringStatus
+}
+
+// PeeringStatus describes a network peering between two clouds.
+type PeeringStatus struct {
+	// ID of the peering resource, e.g. the AWS VPC peering connection.
+	ID string
+
+	// Peer is the VPC, network or cluster the cloud is peered with.
+	Peer string
+
+	// State of the peering, as reported by the cloud.
+	State string
+}
+
+// GatewayStatus describes the gateways deployed on a cloud for Submariner, as returned by GatewayDeployer.Inspect.
+type GatewayStatus struct {
+	// PublicPorts are the ports open externally so that Submariner can reach and be reached by other Submariners.
+	PublicPorts []PortSpec
+
+	// GatewaySubnets are the subnets tagged to host gateways.
+	GatewaySubnets []string
+
+	// GatewayNodes are the nodes carrying the gateway label.
+	GatewayNodes []string
+
+	// GatewayMachineSets are the machine sets deployed for dedicated gateways.
+	GatewayMachineSets []string
+}
@@ -0,0 +1,169 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/errors"
+)
+
+// EventType identifies what happened to a step.
+type EventType string
+
+const (
+	EventStarted   EventType = "started"
+	EventSucceeded EventType = "succeeded"
+	EventFailed    EventType = "failed"
+	EventWarning   EventType = "warning"
+)
+
+// Severity of an Event.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Step identifies an operation performed on the cloud.
+type Step struct {
+	// ID identifies the step, and is shared by all the events reported for it.
+	ID string `json:"stepID,omitempty"`
+
+	// Provider is the cloud provider the step is performed on, e.g. "aws".
+	Provider string `json:"provider,omitempty"`
+
+	// ResourceType is the type of resource the step operates on, e.g. "SecurityGroup".
+	ResourceType string `json:"resourceType,omitempty"`
+
+	// ResourceID is the name or ID of the resource the step operates on.
+	ResourceID string `json:"resourceID,omitempty"`
+}
+
+// Event is a machine-readable report on the progress of a step.
+type Event struct {
+	Step
+
+	Type     EventType `json:"type"`
+	Severity Severity  `json:"severity"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+
+	// Duration of the step, set on the events ending it.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// StructuredReporter is a Reporter which can also report Events. Events with an empty step ID apply to the step
+// started last, and events with no severity get the default severity of their type.
+type StructuredReporter interface {
+	Reporter
+
+	// Report will report the given event.
+	Report(event Event)
+}
+
+type reporterAdapter struct {
+	Reporter
+}
+
+// NewStructuredReporter returns the given Reporter as a StructuredReporter; Reporters which don't support Events
+// get the Events reported as plain messages.
+func NewStructuredReporter(reporter Reporter) StructuredReporter {
+	if structured, ok := reporter.(StructuredReporter); ok {
+		return structured
+	}
+
+	return &reporterAdapter{Reporter: reporter}
+}
+
+func (r *reporterAdapter) Report(event Event) {
+	switch event.Type {
+	case EventStarted:
+		r.Started("%s", event.Message)
+	case EventSucceeded:
+		r.Succeeded("%s", event.Message)
+	case EventFailed:
+		r.Failed(fmt.Errorf("%s", event.Message))
+	case EventWarning:
+		r.Warning("%s", event.Message)
+	}
+}
+
+// ResourceStep returns the step operating on the given resource with the given cloud provider. Its ID is derived from
+// the resource, so that the events ending the step are matched to the event starting it.
+func ResourceStep(provider string, resourceType ResourceType, resourceID string) Step {
+	return Step{
+		ID:           fmt.Sprintf("%s/%s/%s", provider, resourceType, resourceID),
+		Provider:     provider,
+		ResourceType: string(resourceType),
+		ResourceID:   resourceID,
+	}
+}
+
+// StepStarted reports the start of the given step, as an Event if the reporter supports them.
+func StepStarted(reporter Reporter, step Step, message string, args ...interface{}) {
+	if structured, ok := reporter.(StructuredReporter); ok {
+		structured.Report(Event{Step: step, Type: EventStarted, Message: fmt.Sprintf(message, args...)})
+		return
+	}
+
+	reporter.Started(message, args...)
+}
+
+// StepSucceeded reports the success of the given step, as an Event if the reporter supports them.
+func StepSucceeded(reporter Reporter, step Step, message string, args ...interface{}) {
+	if structured, ok := reporter.(StructuredReporter); ok {
+		structured.Report(Event{Step: step, Type: EventSucceeded, Message: fmt.Sprintf(message, args...)})
+		return
+	}
+
+	reporter.Succeeded(message, args...)
+}
+
+// StepFailed reports the failure of the given step, as an Event if the reporter supports them.
+func StepFailed(reporter Reporter, step Step, errs ...error) {
+	if structured, ok := reporter.(StructuredReporter); ok {
+		structured.Report(Event{Step: step, Type: EventFailed, Message: failureMessage(errs)})
+		return
+	}
+
+	reporter.Failed(errs...)
+}
+
+func defaultSeverity(eventType EventType) Severity {
+	switch eventType {
+	case EventFailed:
+		return SeverityError
+	case EventWarning:
+		return SeverityWarning
+	case EventStarted, EventSucceeded:
+	}
+
+	return SeverityInfo
+}
+
+func failureMessage(errs []error) string {
+	if err := errors.NewAggregate(errs); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
@@ -29,6 +29,7 @@ import (
 )
 
 const (
+	providerName                  = "aws"
 	messageRetrieveVPCID          = "Retrieving VPC ID"
 	messageRetrievedVPCID         = "Retrieved VPC ID %s"
 	messageValidatePrerequisites  = "Validating pre-requisites"
@@ -36,9 +37,10 @@ const (
 )
 
 type awsCloud struct {
-	client  awsClient.Interface
-	infraID string
-	region  string
+	client     awsClient.Interface
+	infraID    string
+	region     string
+	stateStore api.StateStore
 }
 
 // NewCloud creates a new api.Cloud instance which can prepare AWS for Submariner to be deployed on it.
@@ -63,12 +65,17 @@ func NewCloudFromConfig(cfg *aws.Config, infraID, region string) api.Cloud {
 // NewCloudFromSettings creates a new api.Cloud instance using the given credentials file and profile
 // which can prepare AWS for Submariner to be deployed on it.
 func NewCloudFromSettings(credentialsFile, profile, infraID, region string) (api.Cloud, error) {
+	return NewCloudFromSettingsWithContext(context.Background(), credentialsFile, profile, infraID, region)
+}
+
+// NewCloudFromSettingsWithContext is NewCloudFromSettings, loading the AWS configuration with the given context.
+func NewCloudFromSettingsWithContext(ctx context.Context, credentialsFile, profile, infraID, region string) (api.Cloud, error) {
 	options := []func(*config.LoadOptions) error{config.WithRegion(region), config.WithSharedConfigProfile(profile)}
 	if credentialsFile != DefaultCredentialsFile() {
 		options = append(options, config.WithSharedCredentialsFiles([]string{credentialsFile}))
 	}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(), options...)
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
 	if err != nil {
 		return nil, errors.Wrap(err, "error loading default config")
 	}
@@ -87,9 +94,14 @@ func DefaultProfile() string {
 }
 
 func (ac *awsCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
+	return ac.PrepareForSubmarinerWithContext(context.Background(), input, reporter)
+}
+
+func (ac *awsCloud) PrepareForSubmarinerWithContext(ctx context.Context, input api.PrepareForSubmarinerInput,
+	reporter api.Reporter) error {
 	reporter.Started(messageRetrieveVPCID)
 
-	vpcID, err := ac.getVpcID()
+	vpcID, err := ac.getVpcID(ctx)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -99,7 +111,7 @@ func (ac *awsCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, re
 
 	reporter.Started(messageValidatePrerequisites)
 
-	err = ac.validatePreparePrerequisites(vpcID)
+	err = ac.validatePreparePrerequisites(ctx, vpcID)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -110,7 +122,7 @@ func (ac *awsCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, re
 	for _, port := range input.InternalPorts {
 		reporter.Started("Opening port %v protocol %s for intra-cluster communications", port.Port, port.Protocol)
 
-		err = ac.allowPortInCluster(vpcID, port.Port, port.Protocol)
+		err = ac.allowPortInCluster(ctx, vpcID, port.Port, port.Protocol)
 		if err != nil {
 			reporter.Failed(err)
 			return err
@@ -122,14 +134,18 @@ func (ac *awsCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, re
 	return nil
 }
 
-func (ac *awsCloud) validatePreparePrerequisites(vpcID string) error {
-	return ac.validateCreateSecGroupRule(vpcID)
+func (ac *awsCloud) validatePreparePrerequisites(ctx context.Context, vpcID string) error {
+	return ac.validateCreateSecGroupRule(ctx, vpcID)
 }
 
 func (ac *awsCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
+	return ac.CleanupAfterSubmarinerWithContext(context.Background(), reporter)
+}
+
+func (ac *awsCloud) CleanupAfterSubmarinerWithContext(ctx context.Context, reporter api.Reporter) error {
 	reporter.Started(messageRetrieveVPCID)
 
-	vpcID, err := ac.getVpcID()
+	vpcID, err := ac.getVpcID(ctx)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -139,7 +155,7 @@ func (ac *awsCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
 
 	reporter.Started(messageValidatePrerequisites)
 
-	err = ac.validateCleanupPrerequisites(vpcID)
+	err = ac.validateCleanupPrerequisites(ctx, vpcID)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -149,7 +165,7 @@ func (ac *awsCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
 
 	reporter.Started("Revoking intra-cluster communication permissions")
 
-	err = ac.revokePortsInCluster(vpcID)
+	err = ac.revokePortsInCluster(ctx, vpcID)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -160,15 +176,41 @@ func (ac *awsCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
 	return nil
 }
 
-func (ac *awsCloud) validateCleanupPrerequisites(vpcID string) error {
-	return ac.validateDeleteSecGroupRule(vpcID)
+func (ac *awsCloud) validateCleanupPrerequisites(ctx context.Context, vpcID string) error {
+	return ac.validateDeleteSecGroupRule(ctx, vpcID)
 }
 
+// CreateVpcPeering Creates a VPC Peering to the target cloud. Only the same
+// Cloud Provider is supported.
 func (ac *awsCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) error {
-	return errors.New("AWS CreateVpcPeering not implemented")
+	return ac.CreateVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (ac *awsCloud) CreateVpcPeeringWithContext(ctx context.Context, target api.Cloud, reporter api.Reporter) error {
+	awsTarget, ok := target.(*awsCloud)
+	if !ok {
+		err := errors.New("only AWS clients are supported")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	return ac.createVpcPeering(ctx, awsTarget, reporter)
 }
 
 // CleanupVpcPeering Removes the VPC Peering with the target cloud and the related Routes.
 func (ac *awsCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) error {
-	return errors.New("AWS CleanupVpcPeering not implemented")
+	return ac.CleanupVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (ac *awsCloud) CleanupVpcPeeringWithContext(ctx context.Context, target api.Cloud, reporter api.Reporter) error {
+	awsTarget, ok := target.(*awsCloud)
+	if !ok {
+		err := errors.New("only AWS clients are supported")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	return ac.cleanupVpcPeering(ctx, awsTarget, reporter)
 }
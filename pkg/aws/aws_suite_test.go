@@ -0,0 +1,106 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/aws/client/fake"
+)
+
+const (
+	infraID       = "test-infraID"
+	targetInfraID = "test-target-infraID"
+	region        = "test-region"
+	vpcID         = "test-vpc"
+	targetVpcID   = "test-target-vpc"
+	workerGroupID = "test-worker-group"
+)
+
+func TestAWS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AWS Suite")
+}
+
+type fakeAWSClientBase struct {
+	awsClient *fake.MockInterface
+	mockCtrl  *gomock.Controller
+}
+
+func (f *fakeAWSClientBase) beforeEach() {
+	f.mockCtrl = gomock.NewController(GinkgoT())
+	f.awsClient = fake.NewMockInterface(f.mockCtrl)
+}
+
+func (f *fakeAWSClientBase) afterEach() {
+	f.mockCtrl.Finish()
+}
+
+func (f *fakeAWSClientBase) expectDescribeVpcs(vpcID, cidr, ownerID string) {
+	f.awsClient.EXPECT().DescribeVpcs(gomock.Any(), gomock.Any()).Return(&ec2.DescribeVpcsOutput{
+		Vpcs: []types.Vpc{
+			{
+				VpcId:     aws.String(vpcID),
+				CidrBlock: aws.String(cidr),
+				OwnerId:   aws.String(ownerID),
+			},
+		},
+	}, nil).AnyTimes()
+}
+
+func (f *fakeAWSClientBase) expectDescribeSecurityGroups(groupID string, permissions ...types.IpPermission) {
+	f.awsClient.EXPECT().DescribeSecurityGroups(gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []types.SecurityGroup{
+			{
+				GroupId:       aws.String(groupID),
+				IpPermissions: permissions,
+			},
+		},
+	}, nil).AnyTimes()
+}
+
+func (f *fakeAWSClientBase) expectDescribeRouteTables(routeTables ...types.RouteTable) {
+	f.awsClient.EXPECT().DescribeRouteTables(gomock.Any(), gomock.Any()).Return(&ec2.DescribeRouteTablesOutput{
+		RouteTables: routeTables,
+	}, nil).AnyTimes()
+}
+
+func isDryRun(dryRun *bool) bool {
+	return dryRun != nil && *dryRun
+}
+
+func dryRunError() error {
+	return &smithy.GenericAPIError{Code: "DryRunOperation"}
+}
+
+func filterValue(filters []types.Filter, name string) string {
+	for _, filter := range filters {
+		if *filter.Name == name && len(filter.Values) > 0 {
+			return filter.Values[0]
+		}
+	}
+
+	return ""
+}
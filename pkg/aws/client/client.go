@@ -27,20 +27,32 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
 )
 
 //go:generate mockgen -source=./client.go -destination=./fake/client.go -package=fake
 
 // Interface wraps an actual AWS SDK ec2 client to allow for easier testing.
 type Interface interface {
+	AcceptVpcPeeringConnection(ctx context.Context, params *ec2.AcceptVpcPeeringConnectionInput,
+		optFns ...func(*ec2.Options)) (*ec2.AcceptVpcPeeringConnectionOutput, error)
+	AllocateAddress(ctx context.Context, params *ec2.AllocateAddressInput,
+		optFns ...func(*ec2.Options)) (*ec2.AllocateAddressOutput, error)
+	AssociateAddress(ctx context.Context, params *ec2.AssociateAddressInput,
+		optFns ...func(*ec2.Options)) (*ec2.AssociateAddressOutput, error)
 	AuthorizeSecurityGroupIngress(ctx context.Context, params *ec2.AuthorizeSecurityGroupIngressInput,
 		optFns ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error)
 
+	CreateRoute(ctx context.Context, params *ec2.CreateRouteInput, optFns ...func(*ec2.Options)) (*ec2.CreateRouteOutput, error)
 	CreateSecurityGroup(ctx context.Context, params *ec2.CreateSecurityGroupInput,
 		optFns ...func(*ec2.Options)) (*ec2.CreateSecurityGroupOutput, error)
 	CreateTags(ctx context.Context, params *ec2.CreateTagsInput,
 		optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+	CreateVpcPeeringConnection(ctx context.Context, params *ec2.CreateVpcPeeringConnectionInput,
+		optFns ...func(*ec2.Options)) (*ec2.CreateVpcPeeringConnectionOutput, error)
 
+	DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput,
+		optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
 	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput,
 		optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
 	DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput,
@@ -51,11 +63,27 @@ type Interface interface {
 		optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
 	DescribeInstanceTypeOfferings(ctx context.Context, params *ec2.DescribeInstanceTypeOfferingsInput,
 		optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypeOfferingsOutput, error)
+	DescribeInstanceTypes(ctx context.Context, params *ec2.DescribeInstanceTypesInput,
+		optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error)
+	DescribeRouteTables(ctx context.Context, params *ec2.DescribeRouteTablesInput,
+		optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error)
+	DescribeVpcPeeringConnections(ctx context.Context, params *ec2.DescribeVpcPeeringConnectionsInput,
+		optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error)
 
+	DeleteRoute(ctx context.Context, params *ec2.DeleteRouteInput, optFns ...func(*ec2.Options)) (*ec2.DeleteRouteOutput, error)
 	DeleteSecurityGroup(ctx context.Context, params *ec2.DeleteSecurityGroupInput,
 		optFns ...func(*ec2.Options)) (*ec2.DeleteSecurityGroupOutput, error)
 	DeleteTags(ctx context.Context, params *ec2.DeleteTagsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error)
+	DeleteVpcPeeringConnection(ctx context.Context, params *ec2.DeleteVpcPeeringConnectionInput,
+		optFns ...func(*ec2.Options)) (*ec2.DeleteVpcPeeringConnectionOutput, error)
+	DisassociateAddress(ctx context.Context, params *ec2.DisassociateAddressInput,
+		optFns ...func(*ec2.Options)) (*ec2.DisassociateAddressOutput, error)
 
+	ModifyNetworkInterfaceAttribute(ctx context.Context, params *ec2.ModifyNetworkInterfaceAttributeInput,
+		optFns ...func(*ec2.Options)) (*ec2.ModifyNetworkInterfaceAttributeOutput, error)
+
+	ReleaseAddress(ctx context.Context, params *ec2.ReleaseAddressInput,
+		optFns ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error)
 	RevokeSecurityGroupIngress(ctx context.Context, params *ec2.RevokeSecurityGroupIngressInput,
 		optFns ...func(*ec2.Options)) (*ec2.RevokeSecurityGroupIngressOutput, error)
 }
@@ -119,8 +147,114 @@ func (ac *awsClient) DescribeInstanceTypeOfferings(ctx context.Context, input *e
 	return ac.ec2Client.DescribeInstanceTypeOfferings(ctx, input, optFns...)
 }
 
+func (ac *awsClient) DescribeInstanceTypes(ctx context.Context, input *ec2.DescribeInstanceTypesInput,
+	optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	return ac.ec2Client.DescribeInstanceTypes(ctx, input, optFns...)
+}
+
+func (ac *awsClient) AllocateAddress(ctx context.Context, input *ec2.AllocateAddressInput,
+	optFns ...func(*ec2.Options)) (*ec2.AllocateAddressOutput, error) {
+	return ac.ec2Client.AllocateAddress(ctx, input, optFns...)
+}
+
+func (ac *awsClient) AssociateAddress(ctx context.Context, input *ec2.AssociateAddressInput,
+	optFns ...func(*ec2.Options)) (*ec2.AssociateAddressOutput, error) {
+	return ac.ec2Client.AssociateAddress(ctx, input, optFns...)
+}
+
+func (ac *awsClient) DescribeAddresses(ctx context.Context, input *ec2.DescribeAddressesInput,
+	optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	return ac.ec2Client.DescribeAddresses(ctx, input, optFns...)
+}
+
+func (ac *awsClient) DisassociateAddress(ctx context.Context, input *ec2.DisassociateAddressInput,
+	optFns ...func(*ec2.Options)) (*ec2.DisassociateAddressOutput, error) {
+	return ac.ec2Client.DisassociateAddress(ctx, input, optFns...)
+}
+
+func (ac *awsClient) ReleaseAddress(ctx context.Context, input *ec2.ReleaseAddressInput,
+	optFns ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error) {
+	return ac.ec2Client.ReleaseAddress(ctx, input, optFns...)
+}
+
+func (ac *awsClient) AcceptVpcPeeringConnection(ctx context.Context, input *ec2.AcceptVpcPeeringConnectionInput,
+	optFns ...func(*ec2.Options)) (*ec2.AcceptVpcPeeringConnectionOutput, error) {
+	return ac.ec2Client.AcceptVpcPeeringConnection(ctx, input, optFns...)
+}
+
+func (ac *awsClient) CreateRoute(ctx context.Context, input *ec2.CreateRouteInput,
+	optFns ...func(*ec2.Options)) (*ec2.CreateRouteOutput, error) {
+	return ac.ec2Client.CreateRoute(ctx, input, optFns...)
+}
+
+func (ac *awsClient) CreateVpcPeeringConnection(ctx context.Context, input *ec2.CreateVpcPeeringConnectionInput,
+	optFns ...func(*ec2.Options)) (*ec2.CreateVpcPeeringConnectionOutput, error) {
+	return ac.ec2Client.CreateVpcPeeringConnection(ctx, input, optFns...)
+}
+
+func (ac *awsClient) DescribeRouteTables(ctx context.Context, input *ec2.DescribeRouteTablesInput,
+	optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+	return ac.ec2Client.DescribeRouteTables(ctx, input, optFns...)
+}
+
+func (ac *awsClient) DescribeVpcPeeringConnections(ctx context.Context, input *ec2.DescribeVpcPeeringConnectionsInput,
+	optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+	return ac.ec2Client.DescribeVpcPeeringConnections(ctx, input, optFns...)
+}
+
+func (ac *awsClient) DeleteRoute(ctx context.Context, input *ec2.DeleteRouteInput,
+	optFns ...func(*ec2.Options)) (*ec2.DeleteRouteOutput, error) {
+	return ac.ec2Client.DeleteRoute(ctx, input, optFns...)
+}
+
+func (ac *awsClient) DeleteVpcPeeringConnection(ctx context.Context, input *ec2.DeleteVpcPeeringConnectionInput,
+	optFns ...func(*ec2.Options)) (*ec2.DeleteVpcPeeringConnectionOutput, error) {
+	return ac.ec2Client.DeleteVpcPeeringConnection(ctx, input, optFns...)
+}
+
+func (ac *awsClient) ModifyNetworkInterfaceAttribute(ctx context.Context, input *ec2.ModifyNetworkInterfaceAttributeInput,
+	optFns ...func(*ec2.Options)) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+	return ac.ec2Client.ModifyNetworkInterfaceAttribute(ctx, input, optFns...)
+}
+
 func New(accessKeyID, secretAccessKey, region string) (Interface, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
+	return NewWithContext(context.Background(), accessKeyID, secretAccessKey, region)
+}
+
+// NewWithContext is New, loading the AWS configuration with the given context.
+func NewWithContext(ctx context.Context, accessKeyID, secretAccessKey, region string) (Interface, error) {
+	cfg, err := loadConfig(ctx, accessKeyID, secretAccessKey, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &awsClient{
+		ec2Client: *ec2.NewFromConfig(cfg),
+	}, nil
+}
+
+// QuotaInterface wraps an actual AWS SDK service quotas client to allow for easier testing.
+type QuotaInterface interface {
+	GetServiceQuota(ctx context.Context, params *servicequotas.GetServiceQuotaInput,
+		optFns ...func(*servicequotas.Options)) (*servicequotas.GetServiceQuotaOutput, error)
+}
+
+func NewQuotaClient(accessKeyID, secretAccessKey, region string) (QuotaInterface, error) {
+	return NewQuotaClientWithContext(context.Background(), accessKeyID, secretAccessKey, region)
+}
+
+// NewQuotaClientWithContext is NewQuotaClient, loading the AWS configuration with the given context.
+func NewQuotaClientWithContext(ctx context.Context, accessKeyID, secretAccessKey, region string) (QuotaInterface, error) {
+	cfg, err := loadConfig(ctx, accessKeyID, secretAccessKey, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return servicequotas.NewFromConfig(cfg), nil
+}
+
+func loadConfig(ctx context.Context, accessKeyID, secretAccessKey, region string) (aws.Config, error) {
+	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithRegion(region),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")),
 		config.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(
@@ -135,10 +269,8 @@ func New(accessKeyID, secretAccessKey, region string) (Interface, error) {
 				}, nil
 			})))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+		return aws.Config{}, fmt.Errorf("failed to load AWS configuration: %w", err)
 	}
 
-	return &awsClient{
-		ec2Client: *ec2.NewFromConfig(cfg),
-	}, nil
+	return cfg, nil
 }
@@ -25,10 +25,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
-func ec2Filter(name, value string) types.Filter {
+func ec2Filter(name string, values ...string) types.Filter {
 	return types.Filter{
 		Name:   aws.String(name),
-		Values: []string{value},
+		Values: values,
 	}
 }
 
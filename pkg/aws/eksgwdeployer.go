@@ -0,0 +1,578 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type eksGatewayDeployer struct {
+	aws       *awsCloud
+	k8sClient k8s.Interface
+}
+
+// NewEksGatewayDeployer returns a GatewayDeployer capable of deploying gateways on EKS and other non-OpenShift
+// Kubernetes clusters, where the cloud's infraID is the cluster name. Existing worker nodes with a public IP are labeled
+// as gateways, their subnets are tagged and the gateway security group is attached to their network interfaces.
+// If the supplied cloud is not an awsCloud, an error is returned.
+func NewEksGatewayDeployer(cloud api.Cloud, k8sClient k8s.Interface) (api.GatewayDeployer, error) {
+	aws, ok := cloud.(*awsCloud)
+	if !ok {
+		return nil, errors.New("the cloud must be AWS")
+	}
+
+	return &eksGatewayDeployer{
+		aws:       aws,
+		k8sClient: k8sClient,
+	}, nil
+}
+
+// SetStateStore sets the store recording the resources provisioned on AWS. It's shared with the cloud the deployer
+// was created for.
+func (d *eksGatewayDeployer) SetStateStore(store api.StateStore) {
+	d.aws.stateStore = store
+}
+
+// getClusterSecurityGroup returns the security group shared by the nodes of the cluster: the cluster security group
+// created by EKS or, on self-managed clusters, a security group tagged for the cluster.
+func (ac *awsCloud) getClusterSecurityGroup(ctx context.Context) (types.SecurityGroup, error) {
+	filters := [][]types.Filter{
+		{ec2Filter("tag:aws:eks:cluster-name", ac.infraID)},
+		{ec2Filter(ac.withAWSInfo("tag:kubernetes.io/cluster/{infraID}"), "owned", "shared")},
+	}
+
+	gatewayGroupName := ac.withAWSInfo("{infraID}-submariner-gw-sg")
+
+	for i := range filters {
+		result, err := ac.client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{Filters: filters[i]})
+		if err != nil {
+			return types.SecurityGroup{}, errors.Wrap(err, "error describing AWS security groups")
+		}
+
+		for j := range result.SecurityGroups {
+			if aws.ToString(result.SecurityGroups[j].GroupName) != gatewayGroupName {
+				return result.SecurityGroups[j], nil
+			}
+		}
+	}
+
+	return types.SecurityGroup{}, newNotFoundError("security group of cluster %s", ac.infraID)
+}
+
+func (d *eksGatewayDeployer) getVpcID(ctx context.Context, reporter api.Reporter) (string, error) {
+	reporter.Started(messageRetrieveVPCID)
+
+	clusterGroup, err := d.aws.getClusterSecurityGroup(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return "", err
+	}
+
+	vpcID := aws.ToString(clusterGroup.VpcId)
+
+	reporter.Succeeded(messageRetrievedVPCID, vpcID)
+
+	return vpcID, nil
+}
+
+func (d *eksGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *eksGatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	vpcID, err := d.getVpcID(ctx, reporter)
+	if err != nil {
+		return err
+	}
+
+	reporter.Started(messageValidatePrerequisites)
+
+	err = d.aws.validateCreateSecGroup(ctx, vpcID)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded(messageValidatedPrerequisites)
+
+	gatewaySGStep := d.aws.gatewaySGStep()
+
+	api.StepStarted(reporter, gatewaySGStep, "Creating Submariner gateway security group")
+
+	gatewaySG, err := d.aws.createGatewaySG(ctx, vpcID, &input)
+	if err != nil {
+		api.StepFailed(reporter, gatewaySGStep, err)
+		return err
+	}
+
+	gatewayGroupID, err := d.aws.getSecurityGroupID(ctx, vpcID, gatewaySG)
+	if err != nil {
+		api.StepFailed(reporter, gatewaySGStep, err)
+		return err
+	}
+
+	api.StepSucceeded(reporter, gatewaySGStep, "Created Submariner gateway security group %s", gatewaySG)
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "error listing the gateway nodes")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	instances, err := d.describeNodeInstances(ctx, gwNodes.Items)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	// The default policy keeps whatever gateways already exist.
+	if input.Gateways > 0 && len(gwNodes.Items) > input.Gateways {
+		surplus := k8s.SurplusGatewayNodes(gwNodes, input.Gateways)
+
+		err = d.removeGateways(ctx, surplus, instances, *gatewayGroupID, reporter)
+		if err != nil {
+			return err
+		}
+
+		gwNodes.Items = withoutNodes(gwNodes.Items, surplus)
+	}
+
+	// Reconfigure the existing gateways, whose subnet tag or security group may have been removed.
+	for i := range gwNodes.Items {
+		err = d.configureGateway(ctx, &gwNodes.Items[i], instances[gwNodes.Items[i].Name], *gatewayGroupID, reporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	if input.Gateways <= len(gwNodes.Items) {
+		return nil
+	}
+
+	candidates, instances, err := d.gatewayCandidates(ctx, input.Gateways-len(gwNodes.Items))
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	for i := range candidates {
+		node := &candidates[i]
+
+		reporter.Started("Labeling node %q as a Submariner gateway", node.Name)
+
+		err = d.k8sClient.AddGWLabelOnNodeWithContext(ctx, node.Name)
+		if err == nil {
+			err = api.RecordResources(ctx, d.aws.stateStore, nodeLabelResource(node.Name))
+		}
+
+		if err != nil {
+			err = errors.Wrapf(err, "error labeling node %q as a Submariner gateway", node.Name)
+			reporter.Failed(err)
+
+			return err
+		}
+
+		reporter.Succeeded("Labeled node %q as a Submariner gateway", node.Name)
+
+		err = d.configureGateway(ctx, node, instances[node.Name], *gatewayGroupID, reporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdatePublicPorts updates the source CIDRs of the public ports in the gateway security group, without reconfiguring
+// the gateway nodes.
+func (d *eksGatewayDeployer) UpdatePublicPorts(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	vpcID, err := d.getVpcID(ctx, reporter)
+	if err != nil {
+		return err
+	}
+
+	return d.aws.updatePublicPorts(ctx, vpcID, &input, reporter)
+}
+
+// gatewayCandidates returns the given number of worker nodes, not yet gateways, whose instances have a public IP, along
+// with their instances. An error is returned if there aren't enough such nodes.
+func (d *eksGatewayDeployer) gatewayCandidates(ctx context.Context, count int) ([]v1.Node, map[string]*types.Instance, error) {
+	nonGWNodes, err := d.k8sClient.ListNodesWithLabelWithContext(ctx, "!"+k8s.SubmarinerGatewayLabel)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error listing the non-gateway nodes")
+	}
+
+	instances, err := d.describeNodeInstances(ctx, nonGWNodes.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates := []v1.Node{}
+
+	for i := range nonGWNodes.Items {
+		node := &nonGWNodes.Items[i]
+
+		if isControlPlaneNode(node) || instances[node.Name] == nil || instances[node.Name].PublicIpAddress == nil {
+			continue
+		}
+
+		candidates = append(candidates, *node)
+		if len(candidates) == count {
+			return candidates, instances, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("there are an insufficient number of worker nodes with a public IP (%d) to deploy %d more gateways",
+		len(candidates), count)
+}
+
+// configureGateway tags the subnet of the gateway node and attaches the gateway security group to its network
+// interfaces.
+func (d *eksGatewayDeployer) configureGateway(ctx context.Context, node *v1.Node, instance *types.Instance, gatewayGroupID string,
+	reporter api.Reporter) error {
+	reporter.Started("Configuring gateway node %q", node.Name)
+
+	if instance == nil {
+		err := fmt.Errorf("no AWS instance found for gateway node %q", node.Name)
+		reporter.Failed(err)
+
+		return err
+	}
+
+	err := d.aws.tagPublicSubnet(ctx, instance.SubnetId)
+	if err == nil {
+		err = api.RecordResources(ctx, d.aws.stateStore, instanceSubnetTagResource(instance))
+	}
+
+	if err == nil {
+		err = d.setGatewayGroup(ctx, instance, gatewayGroupID, true)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Configured gateway node %q in subnet %s", node.Name, aws.ToString(instance.SubnetId))
+
+	return nil
+}
+
+// removeGateways detaches the gateway security group from the given gateway nodes and unlabels them. Their subnets stay
+// tagged, as they may host other gateways, until clean up.
+func (d *eksGatewayDeployer) removeGateways(ctx context.Context, nodes []*v1.Node, instances map[string]*types.Instance,
+	gatewayGroupID string, reporter api.Reporter) error {
+	for _, node := range nodes {
+		reporter.Started("Removing the Submariner gateway configuration from node %q", node.Name)
+
+		var err error
+
+		if instance := instances[node.Name]; instance != nil && gatewayGroupID != "" {
+			err = d.setGatewayGroup(ctx, instance, gatewayGroupID, false)
+		}
+
+		if err == nil {
+			err = d.k8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, node)
+			if apierrors.IsNotFound(err) {
+				err = nil
+			}
+
+			err = errors.Wrapf(err, "error removing the gateway label from node %q", node.Name)
+		}
+
+		if err == nil {
+			err = api.ForgetResources(ctx, d.aws.stateStore, nodeLabelResource(node.Name))
+		}
+
+		if err != nil {
+			reporter.Failed(err)
+			return err
+		}
+
+		reporter.Succeeded("Removed the Submariner gateway configuration from node %q", node.Name)
+	}
+
+	return nil
+}
+
+// setGatewayGroup attaches the gateway security group to, or detaches it from, all the network interfaces of the instance.
+func (d *eksGatewayDeployer) setGatewayGroup(ctx context.Context, instance *types.Instance, gatewayGroupID string, attach bool) error {
+	for i := range instance.NetworkInterfaces {
+		networkInterface := &instance.NetworkInterfaces[i]
+
+		groups, attached := otherGroups(networkInterface.Groups, gatewayGroupID)
+		if attached == attach {
+			continue
+		}
+
+		if attach {
+			groups = append(groups, gatewayGroupID)
+		}
+
+		_, err := d.aws.client.ModifyNetworkInterfaceAttribute(ctx, &ec2.ModifyNetworkInterfaceAttributeInput{
+			NetworkInterfaceId: networkInterface.NetworkInterfaceId,
+			Groups:             groups,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error modifying the security groups of AWS network interface %s",
+				aws.ToString(networkInterface.NetworkInterfaceId))
+		}
+	}
+
+	return nil
+}
+
+// otherGroups returns the IDs of the groups other than the given one, and whether the given one is among the groups.
+func otherGroups(groups []types.GroupIdentifier, groupID string) ([]string, bool) {
+	others := []string{}
+	found := false
+
+	for i := range groups {
+		if aws.ToString(groups[i].GroupId) == groupID {
+			found = true
+			continue
+		}
+
+		others = append(others, aws.ToString(groups[i].GroupId))
+	}
+
+	return others, found
+}
+
+// describeNodeInstances returns the instances of the given nodes, by node name. Nodes are mapped to their instance
+// through their provider ID, e.g. "aws:///us-east-1a/i-0123456789abcdef0".
+func (d *eksGatewayDeployer) describeNodeInstances(ctx context.Context, nodes []v1.Node) (map[string]*types.Instance, error) {
+	nodeNames := map[string]string{}
+	instanceIDs := []string{}
+
+	for i := range nodes {
+		providerID := nodes[i].Spec.ProviderID
+		if !strings.HasPrefix(providerID, "aws://") {
+			continue
+		}
+
+		instanceID := providerID[strings.LastIndex(providerID, "/")+1:]
+		nodeNames[instanceID] = nodes[i].Name
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+
+	instances := map[string]*types.Instance{}
+	if len(instanceIDs) == 0 {
+		return instances, nil
+	}
+
+	result, err := d.aws.client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{ec2Filter("instance-id", instanceIDs...)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error describing AWS node instances")
+	}
+
+	for i := range result.Reservations {
+		for j := range result.Reservations[i].Instances {
+			instance := &result.Reservations[i].Instances[j]
+			if nodeName, found := nodeNames[aws.ToString(instance.InstanceId)]; found {
+				instances[nodeName] = instance
+			}
+		}
+	}
+
+	return instances, nil
+}
+
+// getGatewaySubnets returns the subnets of the VPC tagged for gateways; unlike on OpenShift, they aren't necessarily
+// owned by the cluster.
+func (ac *awsCloud) getGatewaySubnets(ctx context.Context, vpcID string) ([]types.Subnet, error) {
+	result, err := ac.client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		Filters: []types.Filter{ec2Filter("vpc-id", vpcID), ec2FilterByTag(tagSubmarinerGateway)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error describing AWS subnets")
+	}
+
+	return result.Subnets, nil
+}
+
+func (d *eksGatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *eksGatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
+	vpcID, err := d.getVpcID(ctx, reporter)
+	if err != nil {
+		return err
+	}
+
+	gatewayGroupID, err := d.aws.getSecurityGroupID(ctx, vpcID, d.aws.withAWSInfo("{infraID}-submariner-gw-sg"))
+	if err != nil && !isNotFoundError(err) {
+		reporter.Failed(err)
+		return err
+	}
+
+	nodes, instances, err := d.gatewayNodesToCleanup(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	err = d.removeGateways(ctx, nodes, instances, aws.ToString(gatewayGroupID), reporter)
+	if err != nil {
+		return err
+	}
+
+	subnets, err := d.aws.recordedGatewaySubnets(ctx)
+	if err == nil && len(subnets) == 0 {
+		subnets, err = d.aws.getGatewaySubnets(ctx, vpcID)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	for i := range subnets {
+		reporter.Started("Untagging subnet %s from supporting Submariner", aws.ToString(subnets[i].SubnetId))
+
+		err = d.aws.untagPublicSubnet(ctx, subnets[i].SubnetId)
+		if err == nil {
+			err = api.ForgetResources(ctx, d.aws.stateStore, subnetTagResource(&subnets[i]))
+		}
+
+		if err != nil {
+			reporter.Failed(err)
+			return err
+		}
+
+		reporter.Succeeded("Untagged subnet %s from supporting Submariner", aws.ToString(subnets[i].SubnetId))
+	}
+
+	gatewaySGStep := d.aws.gatewaySGStep()
+
+	api.StepStarted(reporter, gatewaySGStep, "Deleting Submariner gateway security group")
+
+	err = d.aws.deleteGatewaySG(ctx, vpcID)
+	if err != nil {
+		api.StepFailed(reporter, gatewaySGStep, err)
+		return err
+	}
+
+	api.StepSucceeded(reporter, gatewaySGStep, "Deleted Submariner gateway security group")
+
+	return nil
+}
+
+// gatewayNodesToCleanup returns the nodes recorded as labeled or, if none were recorded, the nodes currently labeled as
+// gateways, with their instances.
+func (d *eksGatewayDeployer) gatewayNodesToCleanup(ctx context.Context) ([]*v1.Node, map[string]*types.Instance, error) {
+	recorded, err := api.RecordedResources(ctx, d.aws.stateStore, api.ResourceNodeLabel)
+	if err != nil {
+		return nil, nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	instances, err := d.describeNodeInstances(ctx, gwNodes.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labeled := map[string]*v1.Node{}
+	for i := range gwNodes.Items {
+		labeled[gwNodes.Items[i].Name] = &gwNodes.Items[i]
+	}
+
+	nodes := []*v1.Node{}
+
+	if len(recorded) == 0 {
+		for i := range gwNodes.Items {
+			nodes = append(nodes, &gwNodes.Items[i])
+		}
+
+		return nodes, instances, nil
+	}
+
+	for i := range recorded {
+		node, found := labeled[recorded[i].ID]
+		if !found {
+			// The node may have been deleted; its record is still forgotten.
+			node = &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: recorded[i].ID}}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, instances, nil
+}
+
+func nodeLabelResource(nodeName string) api.Resource {
+	return api.Resource{Type: api.ResourceNodeLabel, ID: nodeName}
+}
+
+func instanceSubnetTagResource(instance *types.Instance) api.Resource {
+	subnet := types.Subnet{SubnetId: instance.SubnetId}
+	if instance.Placement != nil {
+		subnet.AvailabilityZone = instance.Placement.AvailabilityZone
+	}
+
+	return subnetTagResource(&subnet)
+}
+
+func withoutNodes(nodes []v1.Node, removed []*v1.Node) []v1.Node {
+	removedNames := map[string]bool{}
+	for _, node := range removed {
+		removedNames[node.Name] = true
+	}
+
+	kept := []v1.Node{}
+
+	for i := range nodes {
+		if !removedNames[nodes[i].Name] {
+			kept = append(kept, nodes[i])
+		}
+	}
+
+	return kept
+}
+
+func isControlPlaneNode(node *v1.Node) bool {
+	for _, label := range []string{"node-role.kubernetes.io/master", "node-role.kubernetes.io/control-plane"} {
+		if _, found := node.Labels[label]; found {
+			return true
+		}
+	}
+
+	return false
+}
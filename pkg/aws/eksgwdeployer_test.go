@@ -0,0 +1,411 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	cloudprepareaws "github.com/submariner-io/cloud-prepare/pkg/aws"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	clusterGroupID   = "test-cluster-group"
+	gatewayGroupName = infraID + "-submariner-gw-sg"
+)
+
+var _ = Describe("EKS GatewayDeployer", func() {
+	t := newEksDeployerTestDriver()
+
+	When("one gateway is requested", func() {
+		BeforeEach(func() {
+			t.addNode("node-1", "subnet-private", false, time.Hour, false)
+			t.addNode("node-2", "subnet-public", true, time.Hour, false)
+			t.numGateways = 1
+		})
+
+		It("should label a node with a public IP, tag its subnet and attach the gateway security group", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.gatewayNodes()).To(Equal([]string{"node-2"}))
+			Expect(t.taggedSubnets).To(Equal(map[string]bool{"subnet-public": true}))
+			Expect(t.interfaceGroups("node-2")).To(ConsistOf(clusterGroupID, gatewayGroupID))
+			Expect(t.interfaceGroups("node-1")).To(Equal([]string{clusterGroupID}))
+		})
+
+		It("should plan the changes without making them", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), t.input())
+			Expect(err).To(Succeed())
+			Expect(actions).To(Equal([]api.Action{
+				{Kind: api.ActionCreateSecurityGroup, Resource: gatewayGroupName, Parameters: map[string]string{"vpc-id": vpcID}},
+				{
+					Kind: api.ActionAuthorizeIngress, Resource: gatewayGroupName,
+					Parameters: map[string]string{"protocol": "udp", "port": "4500", "source-cidr": "0.0.0.0/0"},
+				},
+				{Kind: api.ActionLabelNode, Resource: "node-2"},
+				{
+					Kind: api.ActionTagSubnet, Resource: "subnet-public",
+					Parameters: map[string]string{"tags": "submariner.io/gateway,kubernetes.io/role/internal-elb"},
+				},
+				{
+					Kind: api.ActionAttachSecurityGroup, Resource: "node-2",
+					Parameters: map[string]string{"security-group": gatewayGroupName},
+				},
+			}))
+			Expect(t.gatewayNodes()).To(BeEmpty())
+			Expect(t.taggedSubnets).To(BeEmpty())
+		})
+
+		Context("and no node has a public IP", func() {
+			BeforeEach(func() {
+				t.nodes = t.nodes[:1]
+			})
+
+			It("should return an error", func() {
+				Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).ToNot(Succeed())
+			})
+		})
+	})
+
+	When("the requested number of gateways is decreased", func() {
+		BeforeEach(func() {
+			t.addNode("node-1", "subnet-a", true, 3*time.Hour, true)
+			t.addNode("node-2", "subnet-b", true, time.Hour, true)
+			t.numGateways = 1
+		})
+
+		It("should unlabel the newest gateway and detach the gateway security group from it", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.gatewayNodes()).To(Equal([]string{"node-1"}))
+			Expect(t.interfaceGroups("node-1")).To(ConsistOf(clusterGroupID, gatewayGroupID))
+			Expect(t.interfaceGroups("node-2")).To(Equal([]string{clusterGroupID}))
+		})
+	})
+
+	Context("on Inspect", func() {
+		BeforeEach(func() {
+			t.addNode("node-1", "subnet-a", true, time.Hour, false)
+			t.numGateways = 1
+		})
+
+		It("should return the gateway ports, subnets and nodes", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+
+			status, err := t.gwDeployer.Inspect(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(status.GatewaySubnets).To(Equal([]string{"subnet-a"}))
+			Expect(status.GatewayNodes).To(Equal([]string{"node-1"}))
+			Expect(status.GatewayMachineSets).To(BeEmpty())
+		})
+	})
+
+	Context("on Cleanup", func() {
+		BeforeEach(func() {
+			t.addNode("node-1", "subnet-a", true, time.Hour, false)
+			t.numGateways = 1
+		})
+
+		It("should remove the gateway configuration and delete the gateway security group", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+			Expect(t.gatewayNodes()).To(BeEmpty())
+			Expect(t.taggedSubnets).To(BeEmpty())
+			Expect(t.interfaceGroups("node-1")).To(Equal([]string{clusterGroupID}))
+			Expect(t.gatewayGroupExists).To(BeFalse())
+		})
+
+		Context("with a state store", func() {
+			var (
+				dir   string
+				store api.StateStore
+			)
+
+			BeforeEach(func() {
+				var err error
+
+				dir, err = ioutil.TempDir("", "state")
+				Expect(err).To(Succeed())
+
+				store = api.NewFileStateStore(filepath.Join(dir, "state.json"))
+			})
+
+			JustBeforeEach(func() {
+				t.gwDeployer.(api.StateRecorder).SetStateStore(store)
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			})
+
+			It("should record the gateway security group and its public rules, and delete the recorded group", func() {
+				Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+
+				recorded, err := api.RecordedResources(context.TODO(), store, api.ResourceSecurityGroup)
+				Expect(err).To(Succeed())
+				Expect(recorded).To(Equal([]api.Resource{{
+					Type:       api.ResourceSecurityGroup,
+					ID:         gatewayGroupID,
+					Parameters: map[string]string{"name": gatewayGroupName, "vpc-id": vpcID},
+				}}))
+
+				recorded, err = api.RecordedResources(context.TODO(), store, api.ResourceIngressRule)
+				Expect(err).To(Succeed())
+				Expect(recorded).To(HaveLen(1))
+				Expect(recorded[0].Parameters).To(HaveKeyWithValue("source-cidr", api.AnyIPv4CIDR))
+
+				// The group can't be found by its name anymore, only the record knows it.
+				t.gatewayGroupExists = false
+
+				Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+				Expect(t.deletedGroups).To(Equal([]string{gatewayGroupID}))
+
+				for _, resourceType := range []api.ResourceType{api.ResourceSecurityGroup, api.ResourceIngressRule} {
+					recorded, err = api.RecordedResources(context.TODO(), store, resourceType)
+					Expect(err).To(Succeed())
+					Expect(recorded).To(BeEmpty())
+				}
+			})
+		})
+	})
+})
+
+type eksDeployerTestDriver struct {
+	fakeAWSClientBase
+	numGateways        int
+	kubeClient         *kubeFake.Clientset
+	nodes              []*corev1.Node
+	instances          []types.Instance
+	gatewayGroupExists bool
+	deletedGroups      []string
+	taggedSubnets      map[string]bool
+	gwDeployer         api.GatewayDeployer
+}
+
+func newEksDeployerTestDriver() *eksDeployerTestDriver {
+	t := &eksDeployerTestDriver{}
+
+	BeforeEach(func() {
+		t.beforeEach()
+
+		t.numGateways = 0
+		t.nodes = nil
+		t.instances = nil
+		t.gatewayGroupExists = false
+		t.deletedGroups = nil
+		t.taggedSubnets = map[string]bool{}
+		t.kubeClient = kubeFake.NewSimpleClientset()
+	})
+
+	JustBeforeEach(func() {
+		for _, node := range t.nodes {
+			_, err := t.kubeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		}
+
+		t.expectSecurityGroups()
+		t.expectSubnets()
+
+		t.awsClient.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+				return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: t.instances}}}, nil
+			}).AnyTimes()
+
+		t.awsClient.EXPECT().ModifyNetworkInterfaceAttribute(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.ModifyNetworkInterfaceAttributeInput, _ ...func(*ec2.Options),
+			) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+				for i := range t.instances {
+					networkInterface := &t.instances[i].NetworkInterfaces[0]
+					if *networkInterface.NetworkInterfaceId == *input.NetworkInterfaceId {
+						networkInterface.Groups = nil
+						for _, groupID := range input.Groups {
+							networkInterface.Groups = append(networkInterface.Groups, types.GroupIdentifier{GroupId: aws.String(groupID)})
+						}
+					}
+				}
+
+				return &ec2.ModifyNetworkInterfaceAttributeOutput{}, nil
+			}).AnyTimes()
+
+		var err error
+
+		t.gwDeployer, err = cloudprepareaws.NewEksGatewayDeployer(cloudprepareaws.NewCloud(t.awsClient, infraID, region),
+			k8s.NewInterface(t.kubeClient))
+		Expect(err).To(Succeed())
+	})
+
+	AfterEach(func() {
+		t.afterEach()
+	})
+
+	return t
+}
+
+func (t *eksDeployerTestDriver) expectSecurityGroups() {
+	t.awsClient.EXPECT().DescribeSecurityGroups(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeSecurityGroupsInput, _ ...func(*ec2.Options),
+		) (*ec2.DescribeSecurityGroupsOutput, error) {
+			if filterValue(input.Filters, "tag:aws:eks:cluster-name") == infraID {
+				return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{{
+					GroupId:   aws.String(clusterGroupID),
+					GroupName: aws.String("eks-cluster-sg"),
+					VpcId:     aws.String(vpcID),
+				}}}, nil
+			}
+
+			if filterValue(input.Filters, "tag:Name") == gatewayGroupName && t.gatewayGroupExists {
+				return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{{
+					GroupId:   aws.String(gatewayGroupID),
+					GroupName: aws.String(gatewayGroupName),
+					VpcId:     aws.String(vpcID),
+				}}}, nil
+			}
+
+			return &ec2.DescribeSecurityGroupsOutput{}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().CreateSecurityGroup(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateSecurityGroupInput, _ ...func(*ec2.Options)) (*ec2.CreateSecurityGroupOutput, error) {
+			if isDryRun(input.DryRun) {
+				return nil, dryRunError()
+			}
+
+			t.gatewayGroupExists = true
+
+			return &ec2.CreateSecurityGroupOutput{GroupId: aws.String(gatewayGroupID)}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().AuthorizeSecurityGroupIngress(gomock.Any(), gomock.Any()).Return(
+		&ec2.AuthorizeSecurityGroupIngressOutput{}, nil).AnyTimes()
+
+	t.awsClient.EXPECT().DeleteSecurityGroup(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DeleteSecurityGroupInput, _ ...func(*ec2.Options)) (*ec2.DeleteSecurityGroupOutput, error) {
+			t.gatewayGroupExists = false
+			t.deletedGroups = append(t.deletedGroups, aws.ToString(input.GroupId))
+
+			return &ec2.DeleteSecurityGroupOutput{}, nil
+		}).AnyTimes()
+}
+
+func (t *eksDeployerTestDriver) expectSubnets() {
+	t.awsClient.EXPECT().DescribeSubnets(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *ec2.DescribeSubnetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+			subnets := []types.Subnet{}
+			for subnetID := range t.taggedSubnets {
+				subnets = append(subnets, types.Subnet{SubnetId: aws.String(subnetID)})
+			}
+
+			return &ec2.DescribeSubnetsOutput{Subnets: subnets}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().CreateTags(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+			for _, subnetID := range input.Resources {
+				t.taggedSubnets[subnetID] = true
+			}
+
+			return &ec2.CreateTagsOutput{}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().DeleteTags(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+			for _, subnetID := range input.Resources {
+				delete(t.taggedSubnets, subnetID)
+			}
+
+			return &ec2.DeleteTagsOutput{}, nil
+		}).AnyTimes()
+}
+
+// addNode adds a node and its instance, which has a single network interface attached to the cluster security group,
+// or also to the gateway security group if the node is a gateway.
+func (t *eksDeployerTestDriver) addNode(name, subnetID string, public bool, age time.Duration, gateway bool) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Labels:            map[string]string{"topology.kubernetes.io/zone": "zone-" + subnetID},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Spec: corev1.NodeSpec{ProviderID: "aws:///zone-" + subnetID + "/i-" + name},
+	}
+
+	groups := []types.GroupIdentifier{{GroupId: aws.String(clusterGroupID)}}
+
+	if gateway {
+		node.Labels["submariner.io/gateway"] = "true"
+		groups = append(groups, types.GroupIdentifier{GroupId: aws.String(gatewayGroupID)})
+		t.gatewayGroupExists = true
+	}
+
+	instance := types.Instance{
+		InstanceId: aws.String("i-" + name),
+		SubnetId:   aws.String(subnetID),
+		NetworkInterfaces: []types.InstanceNetworkInterface{{
+			NetworkInterfaceId: aws.String("eni-" + name),
+			Groups:             groups,
+		}},
+	}
+
+	if public {
+		instance.PublicIpAddress = aws.String("1.2.3.4")
+	}
+
+	t.nodes = append(t.nodes, node)
+	t.instances = append(t.instances, instance)
+}
+
+func (t *eksDeployerTestDriver) gatewayNodes() []string {
+	nodes, err := k8s.NewInterface(t.kubeClient).ListGatewayNodesWithContext(context.TODO())
+	Expect(err).To(Succeed())
+
+	return k8s.NodeNames(nodes)
+}
+
+func (t *eksDeployerTestDriver) interfaceGroups(nodeName string) []string {
+	for i := range t.instances {
+		if *t.instances[i].InstanceId == "i-"+nodeName {
+			groups := []string{}
+			for _, group := range t.instances[i].NetworkInterfaces[0].Groups {
+				groups = append(groups, *group.GroupId)
+			}
+
+			return groups
+		}
+	}
+
+	return nil
+}
+
+func (t *eksDeployerTestDriver) input() api.GatewayDeployInput {
+	return api.GatewayDeployInput{
+		Gateways:    t.numGateways,
+		PublicPorts: []api.PortSpec{{Port: 4500, Protocol: "udp"}},
+	}
+}
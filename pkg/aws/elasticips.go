@@ -0,0 +1,210 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultElasticIPTimeout is how long a gateway instance is waited for when ElasticIPConfig doesn't specify a timeout.
+	DefaultElasticIPTimeout = 10 * time.Minute
+
+	gatewaySubnetTagKey  = "submariner.io/gateway-subnet"
+	instancePollInterval = 10 * time.Second
+)
+
+// ElasticIPConfig requests Elastic IPs for the gateways, so that their public addresses survive their replacement.
+type ElasticIPConfig struct {
+	// Timeout bounds the wait for a gateway instance to be running, so that its Elastic IP can be associated with it.
+	// Zero uses DefaultElasticIPTimeout.
+	Timeout time.Duration
+}
+
+// ElasticIPRequester is implemented by the gateway deployers which can assign Elastic IPs to the gateways.
+type ElasticIPRequester interface {
+	// SetElasticIPs requests an Elastic IP for each gateway; nil reverts to auto-assigned public IPs.
+	SetElasticIPs(config *ElasticIPConfig)
+}
+
+// SetElasticIPs requests an Elastic IP for each gateway. The Elastic IP of a subnet is reused by the gateways replacing
+// its gateway.
+func (d *ocpGatewayDeployer) SetElasticIPs(config *ElasticIPConfig) {
+	d.elasticIPs = config
+}
+
+// findGatewayElasticIP returns the Elastic IP allocated for the gateways of the given subnet, or nil if there's none.
+func (ac *awsCloud) findGatewayElasticIP(ctx context.Context, subnetID string) (*types.Address, error) {
+	output, err := ac.client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+		Filters: []types.Filter{
+			ac.filterByCurrentCluster(),
+			ec2Filter("tag:"+gatewaySubnetTagKey, subnetID),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error describing the Elastic IPs of subnet %q", subnetID)
+	}
+
+	if len(output.Addresses) == 0 {
+		return nil, nil
+	}
+
+	return &output.Addresses[0], nil
+}
+
+// allocateGatewayElasticIP returns the Elastic IP of the given subnet, allocating it if needed.
+func (ac *awsCloud) allocateGatewayElasticIP(ctx context.Context, subnet *types.Subnet) (*types.Address, error) {
+	subnetID := aws.ToString(subnet.SubnetId)
+
+	address, err := ac.findGatewayElasticIP(ctx, subnetID)
+	if err != nil || address != nil {
+		return address, err
+	}
+
+	output, err := ac.client.AllocateAddress(ctx, &ec2.AllocateAddressInput{
+		Domain: types.DomainTypeVpc,
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeElasticIp,
+				Tags: []types.Tag{
+					ec2Tag("Name", ac.withAWSInfo("{infraID}-submariner-gw-")+aws.ToString(subnet.AvailabilityZone)),
+					ec2Tag(ac.withAWSInfo("kubernetes.io/cluster/{infraID}"), "owned"),
+					ec2Tag(gatewaySubnetTagKey, subnetID),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error allocating an Elastic IP for subnet %q", subnetID)
+	}
+
+	address = &types.Address{AllocationId: output.AllocationId, PublicIp: output.PublicIp}
+
+	return address, api.RecordResources(ctx, ac.stateStore, elasticIPResource(address, subnetID))
+}
+
+// associateGatewayElasticIP associates the Elastic IP of the given subnet with its running gateway instance, once there
+// is one, and returns the Elastic IP.
+func (d *ocpGatewayDeployer) associateGatewayElasticIP(ctx context.Context, vpcID string, subnet *types.Subnet) (string, error) {
+	address, err := d.aws.allocateGatewayElasticIP(ctx, subnet)
+	if err != nil {
+		return "", err
+	}
+
+	instanceID, err := d.waitForGatewayInstance(ctx, vpcID, aws.ToString(subnet.SubnetId))
+	if err != nil {
+		return "", err
+	}
+
+	if aws.ToString(address.InstanceId) == instanceID {
+		return aws.ToString(address.PublicIp), nil
+	}
+
+	_, err = d.aws.client.AssociateAddress(ctx, &ec2.AssociateAddressInput{
+		AllocationId:       address.AllocationId,
+		InstanceId:         aws.String(instanceID),
+		AllowReassociation: aws.Bool(true),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "error associating Elastic IP %q with instance %q", aws.ToString(address.PublicIp),
+			instanceID)
+	}
+
+	return aws.ToString(address.PublicIp), nil
+}
+
+// waitForGatewayInstance waits for a running gateway instance in the given subnet, and returns the ID of the newest one.
+func (d *ocpGatewayDeployer) waitForGatewayInstance(ctx context.Context, vpcID, subnetID string) (string, error) {
+	timeout := d.elasticIPs.Timeout
+	if timeout == 0 {
+		timeout = DefaultElasticIPTimeout
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var instanceID string
+
+	err := wait.PollImmediateUntil(instancePollInterval, func() (bool, error) {
+		result, err := d.aws.client.DescribeInstances(waitCtx, &ec2.DescribeInstancesInput{
+			Filters: []types.Filter{
+				ec2Filter("vpc-id", vpcID),
+				ec2Filter("subnet-id", subnetID),
+				ec2Filter("tag:submariner.io", "gateway"),
+				ec2Filter("instance-state-name", "running"),
+				d.aws.filterByCurrentCluster(),
+			},
+		})
+		if err != nil {
+			return false, errors.Wrap(err, "error describing AWS gateway instances")
+		}
+
+		var launchTime time.Time
+
+		for i := range result.Reservations {
+			for j := range result.Reservations[i].Instances {
+				instance := &result.Reservations[i].Instances[j]
+				if instanceID == "" || aws.ToTime(instance.LaunchTime).After(launchTime) {
+					instanceID = aws.ToString(instance.InstanceId)
+					launchTime = aws.ToTime(instance.LaunchTime)
+				}
+			}
+		}
+
+		return instanceID != "", nil
+	}, waitCtx.Done())
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return "", fmt.Errorf("timed out after %v waiting for a running gateway instance in subnet %q", timeout, subnetID)
+	}
+
+	return instanceID, err
+}
+
+// releaseGatewayElasticIP releases the Elastic IP of the given subnet, if any.
+func (ac *awsCloud) releaseGatewayElasticIP(ctx context.Context, subnet *types.Subnet) error {
+	subnetID := aws.ToString(subnet.SubnetId)
+
+	address, err := ac.findGatewayElasticIP(ctx, subnetID)
+	if err != nil || address == nil {
+		return err
+	}
+
+	if address.AssociationId != nil {
+		_, err = ac.client.DisassociateAddress(ctx, &ec2.DisassociateAddressInput{AssociationId: address.AssociationId})
+		if err != nil && !isAWSError(err, "InvalidAssociationID.NotFound") {
+			return errors.Wrapf(err, "error disassociating Elastic IP %q", aws.ToString(address.PublicIp))
+		}
+	}
+
+	_, err = ac.client.ReleaseAddress(ctx, &ec2.ReleaseAddressInput{AllocationId: address.AllocationId})
+	if err != nil && !isAWSError(err, "InvalidAllocationID.NotFound") {
+		return errors.Wrapf(err, "error releasing Elastic IP %q", aws.ToString(address.PublicIp))
+	}
+
+	return api.ForgetResources(ctx, ac.stateStore, elasticIPResource(address, subnetID))
+}
@@ -0,0 +1,194 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+)
+
+// Inspect returns the internal ports open in the worker security group and the live VPC peerings of the cluster's VPC.
+func (ac *awsCloud) Inspect(ctx context.Context) (*api.CloudStatus, error) {
+	vpcID, err := ac.getVpcID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workerGroup, err := ac.getSecurityGroup(ctx, vpcID, "{infraID}-worker-sg")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &api.CloudStatus{InternalPorts: []api.PortSpec{}, Peerings: []api.PeeringStatus{}}
+
+	for i := range workerGroup.IpPermissions {
+		for _, groupPair := range workerGroup.IpPermissions[i].UserIdGroupPairs {
+			if groupPair.Description != nil && strings.Contains(*groupPair.Description, internalTraffic) {
+				status.InternalPorts = append(status.InternalPorts, permissionPort(&workerGroup.IpPermissions[i]))
+				break
+			}
+		}
+	}
+
+	for _, filterName := range []string{"requester-vpc-info.vpc-id", "accepter-vpc-info.vpc-id"} {
+		result, err := ac.client.DescribeVpcPeeringConnections(ctx, &ec2.DescribeVpcPeeringConnectionsInput{
+			Filters: []types.Filter{
+				ec2Filter(filterName, vpcID),
+				ec2Filter("status-code",
+					string(types.VpcPeeringConnectionStateReasonCodeInitiatingRequest),
+					string(types.VpcPeeringConnectionStateReasonCodePendingAcceptance),
+					string(types.VpcPeeringConnectionStateReasonCodeProvisioning),
+					string(types.VpcPeeringConnectionStateReasonCodeActive)),
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error describing AWS VPC peerings")
+		}
+
+		for i := range result.VpcPeeringConnections {
+			status.Peerings = append(status.Peerings, peeringStatus(&result.VpcPeeringConnections[i], vpcID))
+		}
+	}
+
+	return status, nil
+}
+
+// Inspect returns the ports open in the gateway security group, the tagged subnets, the gateway instances and their machine sets.
+func (d *ocpGatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	vpcID, err := d.aws.getVpcID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &api.GatewayStatus{
+		PublicPorts:    []api.PortSpec{},
+		GatewaySubnets: []string{},
+		GatewayNodes:   []string{},
+	}
+
+	gatewayGroup, err := d.aws.getSecurityGroup(ctx, vpcID, d.aws.withAWSInfo("{infraID}-submariner-gw-sg"))
+	if err != nil && !isNotFoundError(err) {
+		return nil, err
+	}
+
+	for i := range gatewayGroup.IpPermissions {
+		status.PublicPorts = append(status.PublicPorts, permissionPort(&gatewayGroup.IpPermissions[i]))
+	}
+
+	subnets, err := d.aws.getTaggedPublicSubnets(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subnets {
+		status.GatewaySubnets = append(status.GatewaySubnets, aws.ToString(subnets[i].SubnetId))
+	}
+
+	instances, err := d.describeGatewayInstances(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range instances {
+		status.GatewayNodes = append(status.GatewayNodes, aws.ToString(instances[i].PrivateDnsName))
+	}
+
+	status.GatewayMachineSets, err = ocp.ListGatewayMachineSets(ctx, d.msDeployer, d.aws.infraID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	return status, nil
+}
+
+func permissionPort(permission *types.IpPermission) api.PortSpec {
+	return api.PortSpec{
+		Port:     uint16(aws.ToInt32(permission.FromPort)),
+		Protocol: aws.ToString(permission.IpProtocol),
+	}
+}
+
+func peeringStatus(peering *types.VpcPeeringConnection, vpcID string) api.PeeringStatus {
+	status := api.PeeringStatus{ID: aws.ToString(peering.VpcPeeringConnectionId)}
+
+	peer := peering.AccepterVpcInfo
+	if peer != nil && aws.ToString(peer.VpcId) == vpcID {
+		peer = peering.RequesterVpcInfo
+	}
+
+	if peer != nil {
+		status.Peer = aws.ToString(peer.VpcId)
+	}
+
+	if peering.Status != nil {
+		status.State = string(peering.Status.Code)
+	}
+
+	return status
+}
+
+// Inspect returns the ports open in the gateway security group, the tagged subnets and the gateway nodes.
+func (d *eksGatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	clusterGroup, err := d.aws.getClusterSecurityGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vpcID := aws.ToString(clusterGroup.VpcId)
+
+	status := &api.GatewayStatus{
+		PublicPorts:        []api.PortSpec{},
+		GatewaySubnets:     []string{},
+		GatewayMachineSets: []string{},
+	}
+
+	gatewayGroup, err := d.aws.getSecurityGroup(ctx, vpcID, d.aws.withAWSInfo("{infraID}-submariner-gw-sg"))
+	if err != nil && !isNotFoundError(err) {
+		return nil, err
+	}
+
+	for i := range gatewayGroup.IpPermissions {
+		status.PublicPorts = append(status.PublicPorts, permissionPort(&gatewayGroup.IpPermissions[i]))
+	}
+
+	subnets, err := d.aws.getGatewaySubnets(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subnets {
+		status.GatewaySubnets = append(status.GatewaySubnets, aws.ToString(subnets[i].SubnetId))
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	status.GatewayNodes = k8s.NodeNames(gwNodes)
+
+	return status, nil
+}
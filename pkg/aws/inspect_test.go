@@ -0,0 +1,86 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws_test
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	cloudprepareaws "github.com/submariner-io/cloud-prepare/pkg/aws"
+)
+
+var _ = Describe("AWS Inspect", func() {
+	var client fakeAWSClientBase
+
+	BeforeEach(func() {
+		client.beforeEach()
+
+		client.expectDescribeVpcs(vpcID, sourceCIDR, accountID)
+		client.expectDescribeSecurityGroups(workerGroupID,
+			types.IpPermission{
+				IpProtocol: aws.String("udp"),
+				FromPort:   aws.Int32(4800),
+				ToPort:     aws.Int32(4800),
+				UserIdGroupPairs: []types.UserIdGroupPair{{
+					GroupId:     aws.String(workerGroupID),
+					Description: aws.String("Internal Submariner traffic between the workers"),
+				}},
+			},
+			types.IpPermission{
+				IpProtocol:       aws.String("tcp"),
+				FromPort:         aws.Int32(22),
+				ToPort:           aws.Int32(22),
+				UserIdGroupPairs: []types.UserIdGroupPair{{GroupId: aws.String(workerGroupID)}},
+			})
+
+		client.awsClient.EXPECT().DescribeVpcPeeringConnections(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.DescribeVpcPeeringConnectionsInput, _ ...func(*ec2.Options),
+			) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+				if filterValue(input.Filters, "requester-vpc-info.vpc-id") != vpcID {
+					return &ec2.DescribeVpcPeeringConnectionsOutput{}, nil
+				}
+
+				return &ec2.DescribeVpcPeeringConnectionsOutput{VpcPeeringConnections: []types.VpcPeeringConnection{
+					newPeering(types.VpcPeeringConnectionStateReasonCodeActive),
+				}}, nil
+			}).Times(2)
+	})
+
+	AfterEach(func() {
+		client.afterEach()
+	})
+
+	It("should return the internal ports and the VPC peerings", func() {
+		status, err := cloudprepareaws.NewCloud(client.awsClient, infraID, region).Inspect(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(status).To(Equal(&api.CloudStatus{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "udp"}},
+			Peerings: []api.PeeringStatus{{
+				ID:    peeringID,
+				Peer:  targetVpcID,
+				State: string(types.VpcPeeringConnectionStateReasonCodeActive),
+			}},
+		}))
+	})
+})
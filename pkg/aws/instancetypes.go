@@ -0,0 +1,280 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/pkg/errors"
+	awsClient "github.com/submariner-io/cloud-prepare/pkg/aws/client"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// standardVCPUQuotaCode is the code of the "Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances" quota.
+	standardVCPUQuotaCode = "L-1216C47A"
+	ec2ServiceCode        = "ec2"
+)
+
+var (
+	preferredInstances = []string{"c5d.large", "m5n.large"}
+
+	standardInstanceFamilies = map[string]bool{
+		"a": true, "c": true, "d": true, "h": true, "i": true, "m": true, "r": true, "t": true, "z": true,
+	}
+)
+
+// InstanceTypePreferences configures how the instance type of each gateway is chosen.
+type InstanceTypePreferences struct {
+	// InstanceTypes lists the acceptable instance types, most preferred first. Each gateway uses the first one offered
+	// in its availability zone. If empty, a built-in list is used.
+	InstanceTypes []string
+
+	// Quotas, if set, is used to skip the instance types which would exceed the account's vCPU quota for On-Demand
+	// Standard instances.
+	Quotas awsClient.QuotaInterface
+}
+
+// instanceTypeSelector chooses the instance type of each gateway deployed by a single Deploy or Plan call.
+type instanceTypeSelector struct {
+	aws         *awsCloud
+	preferences InstanceTypePreferences
+	offerings   map[string]map[string]bool
+	existing    map[string]string
+	vCPUs       map[string]int32
+	// availableVCPUs is the remaining vCPU quota, or nil if it isn't known yet.
+	availableVCPUs *int32
+}
+
+func (d *ocpGatewayDeployer) newInstanceTypeSelector(ctx context.Context) (*instanceTypeSelector, error) {
+	machineSets, err := ocp.GatewayMachineSets(ctx, d.msDeployer, d.aws.infraID)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	existing := map[string]string{}
+
+	for i := range machineSets {
+		instanceType, _, _ := unstructured.NestedString(machineSets[i].Object,
+			"spec", "template", "spec", "providerSpec", "value", "instanceType")
+		if instanceType != "" {
+			existing[machineSets[i].GetName()] = instanceType
+		}
+	}
+
+	return &instanceTypeSelector{
+		aws:         d.aws,
+		preferences: d.instanceTypes,
+		offerings:   map[string]map[string]bool{},
+		existing:    existing,
+	}, nil
+}
+
+// offeredInstanceTypes returns the preferred instance types offered in the given availability zone.
+func (s *instanceTypeSelector) offeredInstanceTypes(ctx context.Context, zone string) (map[string]bool, error) {
+	if offered, found := s.offerings[zone]; found {
+		return offered, nil
+	}
+
+	output, err := s.aws.client.DescribeInstanceTypeOfferings(ctx, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: types.LocationTypeAvailabilityZone,
+		Filters: []types.Filter{
+			ec2Filter("location", zone),
+			ec2Filter("instance-type", s.preferences.InstanceTypes...),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error describing the instance types offered in %q", zone)
+	}
+
+	offered := map[string]bool{}
+	for i := range output.InstanceTypeOfferings {
+		offered[string(output.InstanceTypeOfferings[i].InstanceType)] = true
+	}
+
+	s.offerings[zone] = offered
+
+	return offered, nil
+}
+
+// supportedSubnets returns the subnets whose availability zone offers at least one of the preferred instance types.
+func (s *instanceTypeSelector) supportedSubnets(ctx context.Context, subnets []types.Subnet) ([]types.Subnet, error) {
+	return filterSubnets(subnets, func(subnet *types.Subnet) (bool, error) {
+		offered, err := s.offeredInstanceTypes(ctx, aws.ToString(subnet.AvailabilityZone))
+		return len(offered) > 0, err
+	})
+}
+
+// selectInstanceType returns the instance type to use for the given gateway machine set in the given subnet. An
+// existing machine set keeps its instance type; otherwise the first preferred type offered in the subnet's availability
+// zone, and fitting in the remaining vCPU quota, is used.
+func (s *instanceTypeSelector) selectInstanceType(ctx context.Context, machineSetName string, subnet *types.Subnet) (string, error) {
+	if instanceType, found := s.existing[machineSetName]; found {
+		return instanceType, nil
+	}
+
+	zone := aws.ToString(subnet.AvailabilityZone)
+
+	offered, err := s.offeredInstanceTypes(ctx, zone)
+	if err != nil {
+		return "", err
+	}
+
+	for _, instanceType := range s.preferences.InstanceTypes {
+		if !offered[instanceType] {
+			continue
+		}
+
+		fits, err := s.reserveVCPUs(ctx, instanceType)
+		if err != nil {
+			return "", err
+		}
+
+		if fits {
+			return instanceType, nil
+		}
+	}
+
+	return "", fmt.Errorf("none of the instance types %v is available in %q within the account's vCPU quota",
+		s.preferences.InstanceTypes, zone)
+}
+
+// reserveVCPUs checks whether an instance of the given type fits in the remaining vCPU quota, and if so, deducts its
+// vCPUs from it. Instance types outside the standard families, and all types if quotas aren't checked, always fit.
+func (s *instanceTypeSelector) reserveVCPUs(ctx context.Context, instanceType string) (bool, error) {
+	if s.preferences.Quotas == nil || !isStandardInstanceType(instanceType) {
+		return true, nil
+	}
+
+	if s.availableVCPUs == nil {
+		err := s.loadVCPUQuota(ctx)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	vCPUs := s.vCPUs[instanceType]
+	if vCPUs > *s.availableVCPUs {
+		return false, nil
+	}
+
+	*s.availableVCPUs -= vCPUs
+
+	return true, nil
+}
+
+// loadVCPUQuota retrieves the vCPU counts of the preferred instance types, and the vCPU quota for standard instances
+// left over by the pending and running instances of the account.
+func (s *instanceTypeSelector) loadVCPUQuota(ctx context.Context) error {
+	typesOutput, err := s.aws.client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: toInstanceTypes(s.preferences.InstanceTypes),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error describing AWS instance types")
+	}
+
+	s.vCPUs = map[string]int32{}
+
+	for i := range typesOutput.InstanceTypes {
+		if typesOutput.InstanceTypes[i].VCpuInfo != nil {
+			s.vCPUs[string(typesOutput.InstanceTypes[i].InstanceType)] =
+				aws.ToInt32(typesOutput.InstanceTypes[i].VCpuInfo.DefaultVCpus)
+		}
+	}
+
+	quotaOutput, err := s.preferences.Quotas.GetServiceQuota(ctx, &servicequotas.GetServiceQuotaInput{
+		ServiceCode: aws.String(ec2ServiceCode),
+		QuotaCode:   aws.String(standardVCPUQuotaCode),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error retrieving the AWS vCPU quota")
+	}
+
+	var quota int32
+	if quotaOutput.Quota != nil {
+		quota = int32(aws.ToFloat64(quotaOutput.Quota.Value))
+	}
+
+	used, err := s.usedStandardVCPUs(ctx)
+	if err != nil {
+		return err
+	}
+
+	available := quota - used
+	s.availableVCPUs = &available
+
+	return nil
+}
+
+// usedStandardVCPUs returns the number of vCPUs used by the account's pending and running standard instances.
+func (s *instanceTypeSelector) usedStandardVCPUs(ctx context.Context) (int32, error) {
+	var used int32
+
+	input := &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{ec2Filter("instance-state-name", "pending", "running")},
+	}
+
+	for {
+		output, err := s.aws.client.DescribeInstances(ctx, input)
+		if err != nil {
+			return 0, errors.Wrap(err, "error describing AWS instances")
+		}
+
+		for i := range output.Reservations {
+			for j := range output.Reservations[i].Instances {
+				instance := &output.Reservations[i].Instances[j]
+				if isStandardInstanceType(string(instance.InstanceType)) && instance.CpuOptions != nil {
+					used += aws.ToInt32(instance.CpuOptions.CoreCount) * aws.ToInt32(instance.CpuOptions.ThreadsPerCore)
+				}
+			}
+		}
+
+		if aws.ToString(output.NextToken) == "" {
+			return used, nil
+		}
+
+		input.NextToken = output.NextToken
+	}
+}
+
+// isStandardInstanceType returns whether the given instance type belongs to one of the families counted by the
+// standard instances vCPU quota.
+func isStandardInstanceType(instanceType string) bool {
+	i := strings.IndexAny(instanceType, "0123456789")
+	if i < 0 {
+		return false
+	}
+
+	return standardInstanceFamilies[instanceType[:i]]
+}
+
+func toInstanceTypes(names []string) []types.InstanceType {
+	instanceTypes := make([]types.InstanceType, len(names))
+	for i, name := range names {
+		instanceTypes[i] = types.InstanceType(name)
+	}
+
+	return instanceTypes
+}
@@ -18,48 +18,77 @@ limitations under the License.
 package aws
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"text/template"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/pkg/errors"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
 	"github.com/submariner-io/cloud-prepare/pkg/ocp"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
 type ocpGatewayDeployer struct {
-	aws          *awsCloud
-	msDeployer   ocp.MachineSetDeployer
-	instanceType string
+	ocp.MachineSetTemplate
+	aws           *awsCloud
+	msDeployer    ocp.MachineSetDeployer
+	instanceTypes InstanceTypePreferences
+	spot          *ocp.SpotConfig
+	elasticIPs    *ElasticIPConfig
 }
 
-var preferredInstances = []string{"c5d.large", "m5n.large"}
-
 // NewOcpGatewayDeployer returns a GatewayDeployer capable deploying gateways using OCP.
 // If the supplied cloud is not an awsCloud, an error is returned.
 func NewOcpGatewayDeployer(cloud api.Cloud, msDeployer ocp.MachineSetDeployer, instanceType string) (api.GatewayDeployer, error) {
+	preferences := InstanceTypePreferences{}
+	if instanceType != "" {
+		preferences.InstanceTypes = []string{instanceType}
+	}
+
+	return NewOcpGatewayDeployerWithPreferences(cloud, msDeployer, preferences)
+}
+
+// NewOcpGatewayDeployerWithPreferences returns a GatewayDeployer capable deploying gateways using OCP, choosing the
+// instance type of each gateway from the given preferences.
+// If the supplied cloud is not an awsCloud, an error is returned.
+func NewOcpGatewayDeployerWithPreferences(cloud api.Cloud, msDeployer ocp.MachineSetDeployer, preferences InstanceTypePreferences,
+) (api.GatewayDeployer, error) {
 	aws, ok := cloud.(*awsCloud)
 	if !ok {
 		return nil, errors.New("the cloud must be AWS")
 	}
 
+	if len(preferences.InstanceTypes) == 0 {
+		preferences.InstanceTypes = preferredInstances
+	}
+
 	return &ocpGatewayDeployer{
-		aws:          aws,
-		msDeployer:   msDeployer,
-		instanceType: instanceType,
+		MachineSetTemplate: ocp.NewMachineSetTemplate(machineSetYAML),
+		aws:                aws,
+		msDeployer:         msDeployer,
+		instanceTypes:      preferences,
 	}, nil
 }
 
+// SetSpotInstances requests gateways on spot instances, falling back to on-demand instances if spot capacity can't be
+// obtained in time.
+func (d *ocpGatewayDeployer) SetSpotInstances(config *ocp.SpotConfig) {
+	d.spot = config
+}
+
 func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *ocpGatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
 	reporter.Started(messageRetrieveVPCID)
 
-	vpcID, err := d.aws.getVpcID()
+	vpcID, err := d.aws.getVpcID(ctx)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -69,13 +98,19 @@ func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.R
 
 	reporter.Started(messageValidatePrerequisites)
 
-	publicSubnets, err := d.aws.findPublicSubnets(vpcID, d.aws.filterByName("{infraID}-public-{region}*"))
+	publicSubnets, err := d.aws.findPublicSubnets(ctx, vpcID, d.aws.filterByName("{infraID}-public-{region}*"))
 	if err != nil {
 		reporter.Failed(err)
 		return err
 	}
 
-	err = d.validateDeployPrerequisites(vpcID, input, publicSubnets)
+	selector, err := d.newInstanceTypeSelector(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	err = d.validateDeployPrerequisites(ctx, vpcID, input, publicSubnets, selector)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -83,40 +118,48 @@ func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.R
 
 	reporter.Succeeded(messageValidatedPrerequisites)
 
-	reporter.Started("Creating Submariner gateway security group")
+	gatewaySGStep := d.aws.gatewaySGStep()
+
+	api.StepStarted(reporter, gatewaySGStep, "Creating Submariner gateway security group")
 
-	gatewaySG, err := d.aws.createGatewaySG(vpcID, input.PublicPorts)
+	gatewaySG, err := d.aws.createGatewaySG(ctx, vpcID, &input)
 	if err != nil {
-		reporter.Failed(err)
+		api.StepFailed(reporter, gatewaySGStep, err)
 		return err
 	}
 
-	reporter.Succeeded("Created Submariner gateway security group %s", gatewaySG)
+	api.StepSucceeded(reporter, gatewaySGStep, "Created Submariner gateway security group %s", gatewaySG)
 
-	subnets, err := d.aws.getSubnetsSupportingInstanceType(publicSubnets, d.instanceType)
+	subnets, err := selector.supportedSubnets(ctx, publicSubnets)
 	if err != nil {
+		reporter.Failed(err)
 		return err
 	}
 
-	taggedSubnets, _ := filterSubnets(subnets, func(subnet *types.Subnet) (bool, error) {
-		return subnetTagged(subnet), nil
-	})
-	untaggedSubnets, _ := filterSubnets(subnets, func(subnet *types.Subnet) (bool, error) {
-		return !subnetTagged(subnet), nil
-	})
+	taggedSubnets, subnetsToTag := selectGatewaySubnets(subnets, input.Gateways)
 
-	for i := range untaggedSubnets {
-		subnet := &untaggedSubnets[i]
+	if input.Gateways > 0 && len(taggedSubnets) > input.Gateways {
+		var surplusSubnets []types.Subnet
 
-		if input.Gateways > 0 && len(taggedSubnets) == input.Gateways {
-			break
+		taggedSubnets, surplusSubnets, err = d.selectSurplusSubnets(ctx, vpcID, taggedSubnets, input.Gateways)
+		if err != nil {
+			reporter.Failed(err)
+			return err
 		}
 
+		err = d.removeGateways(ctx, surplusSubnets, reporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range subnetsToTag {
+		subnet := &subnetsToTag[i]
 		subnetName := extractName(subnet.Tags)
 
 		reporter.Started("Adjusting public subnet %s to support Submariner", subnetName)
 
-		err = d.aws.tagPublicSubnet(subnet.SubnetId)
+		err = d.aws.tagPublicSubnet(ctx, subnet.SubnetId)
 		if err != nil {
 			reporter.Failed(err)
 			return err
@@ -131,54 +174,231 @@ func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.R
 		subnet := &taggedSubnets[i]
 		subnetName := extractName(subnet.Tags)
 
-		reporter.Started("Deploying gateway node for public subnet %s", subnetName)
+		machineSetStep := d.machineSetStep(subnet)
+
+		api.StepStarted(reporter, machineSetStep, "Deploying gateway node for public subnet %s", subnetName)
 
-		err = d.deployGateway(vpcID, gatewaySG, subnet)
+		instanceType, spot, err := d.deployGateway(ctx, vpcID, gatewaySG, subnet, selector)
+		if err != nil {
+			api.StepFailed(reporter, machineSetStep, err)
+			return err
+		}
+
+		api.StepSucceeded(reporter, machineSetStep, "Deployed gateway node for public subnet %s with %s instance type %s", subnetName,
+			ocp.InstanceKind(spot), instanceType)
+
+		if d.elasticIPs == nil {
+			continue
+		}
+
+		reporter.Started("Associating an Elastic IP with the gateway node for public subnet %s", subnetName)
+
+		publicIP, err := d.associateGatewayElasticIP(ctx, vpcID, subnet)
 		if err != nil {
 			reporter.Failed(err)
 			return err
 		}
 
-		reporter.Succeeded("Deployed gateway node for public subnet %s", subnetName)
+		reporter.Succeeded("Associated Elastic IP %s with the gateway node for public subnet %s", publicIP, subnetName)
 	}
 
 	return nil
 }
 
-func (d *ocpGatewayDeployer) validateDeployPrerequisites(vpcID string, input api.GatewayDeployInput,
-	publicSubnets []types.Subnet) error {
-	var errs []error
-	var subnets []types.Subnet
+// selectGatewaySubnets returns the subnets already tagged for gateways, and the untagged subnets which need to be
+// tagged to reach the requested number of gateways.
+func selectGatewaySubnets(subnets []types.Subnet, gateways int) ([]types.Subnet, []types.Subnet) {
+	taggedSubnets, _ := filterSubnets(subnets, func(subnet *types.Subnet) (bool, error) {
+		return subnetTagged(subnet), nil
+	})
+	untaggedSubnets, _ := filterSubnets(subnets, func(subnet *types.Subnet) (bool, error) {
+		return !subnetTagged(subnet), nil
+	})
 
-	errs = appendIfError(errs, d.aws.validateCreateSecGroup(vpcID))
-	errs = appendIfError(errs, d.aws.validateCreateSecGroupRule(vpcID))
-	err := d.aws.validateDescribeInstanceTypeOfferings()
-	errs = appendIfError(errs, err)
+	subnetsToTag := []types.Subnet{}
+
+	for i := range untaggedSubnets {
+		if gateways > 0 && len(taggedSubnets)+len(subnetsToTag) == gateways {
+			break
+		}
+
+		subnetsToTag = append(subnetsToTag, untaggedSubnets[i])
+	}
+
+	return taggedSubnets, subnetsToTag
+}
+
+// UpdatePublicPorts updates the source CIDRs of the public ports in the gateway security group, without redeploying the
+// gateways.
+func (d *ocpGatewayDeployer) UpdatePublicPorts(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	reporter.Started(messageRetrieveVPCID)
 
+	vpcID, err := d.aws.getVpcID(ctx)
 	if err != nil {
-		return utilerrors.NewAggregate(errs)
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded(messageRetrievedVPCID, vpcID)
+
+	return d.aws.updatePublicPorts(ctx, vpcID, &input, reporter)
+}
+
+// selectSurplusSubnets splits the tagged subnets into those whose gateways are kept and those whose gateways are
+// removed, so that only the desired number of gateways remain. The oldest gateways are kept.
+func (d *ocpGatewayDeployer) selectSurplusSubnets(ctx context.Context, vpcID string, taggedSubnets []types.Subnet,
+	gateways int) ([]types.Subnet, []types.Subnet, error) {
+	launchTimes, err := d.gatewayLaunchTimes(ctx, vpcID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates := make([]k8s.Gateway, len(taggedSubnets))
+
+	for i := range taggedSubnets {
+		subnetID := aws.ToString(taggedSubnets[i].SubnetId)
+
+		// A tagged subnet without a gateway instance yet is considered to have the newest gateway.
+		launchTime, found := launchTimes[subnetID]
+		if !found {
+			launchTime = time.Now()
+		}
+
+		candidates[i] = k8s.Gateway{
+			Name:              subnetID,
+			Zone:              aws.ToString(taggedSubnets[i].AvailabilityZone),
+			CreationTimestamp: launchTime,
+		}
+	}
+
+	surplus := map[string]bool{}
+	for _, gateway := range k8s.SurplusGateways(candidates, gateways) {
+		surplus[gateway.Name] = true
+	}
+
+	keptSubnets, _ := filterSubnets(taggedSubnets, func(subnet *types.Subnet) (bool, error) {
+		return !surplus[aws.ToString(subnet.SubnetId)], nil
+	})
+	surplusSubnets, _ := filterSubnets(taggedSubnets, func(subnet *types.Subnet) (bool, error) {
+		return surplus[aws.ToString(subnet.SubnetId)], nil
+	})
+
+	return keptSubnets, surplusSubnets, nil
+}
+
+// describeGatewayInstances returns the pending and running gateway instances of the cluster.
+func (d *ocpGatewayDeployer) describeGatewayInstances(ctx context.Context, vpcID string) ([]types.Instance, error) {
+	result, err := d.aws.client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			ec2Filter("vpc-id", vpcID),
+			ec2Filter("tag:submariner.io", "gateway"),
+			ec2Filter("instance-state-name", "pending", "running"),
+			d.aws.filterByCurrentCluster(),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error describing AWS gateway instances")
+	}
+
+	instances := []types.Instance{}
+	for i := range result.Reservations {
+		instances = append(instances, result.Reservations[i].Instances...)
+	}
+
+	return instances, nil
+}
+
+// gatewayLaunchTimes returns the launch time of the oldest running gateway instance in each subnet.
+func (d *ocpGatewayDeployer) gatewayLaunchTimes(ctx context.Context, vpcID string) (map[string]time.Time, error) {
+	instances, err := d.describeGatewayInstances(ctx, vpcID)
+	if err != nil {
+		return nil, err
 	}
 
-	// If instanceType is not specified, auto-select the most suitable one.
-	if d.instanceType == "" {
-		for _, instanceType := range preferredInstances {
-			subnets, err = d.aws.getSubnetsSupportingInstanceType(publicSubnets, instanceType)
-			if err != nil {
-				return err
-			}
+	launchTimes := map[string]time.Time{}
+
+	for i := range instances {
+		subnetID := aws.ToString(instances[i].SubnetId)
+
+		if instances[i].LaunchTime == nil {
+			continue
+		}
+
+		if launchTime, found := launchTimes[subnetID]; !found || instances[i].LaunchTime.Before(launchTime) {
+			launchTimes[subnetID] = *instances[i].LaunchTime
+		}
+	}
+
+	return launchTimes, nil
+}
+
+func (d *ocpGatewayDeployer) removeGateways(ctx context.Context, subnets []types.Subnet, reporter api.Reporter) error {
+	for i := range subnets {
+		subnet := &subnets[i]
+		subnetName := extractName(subnet.Tags)
+
+		machineSetStep := d.machineSetStep(subnet)
+
+		api.StepStarted(reporter, machineSetStep, "Removing gateway node for public subnet %s", subnetName)
+
+		err := d.deleteGateway(ctx, subnet)
+		if err != nil {
+			api.StepFailed(reporter, machineSetStep, err)
+			return err
+		}
+
+		err = d.aws.releaseGatewayElasticIP(ctx, subnet)
+		if err != nil {
+			api.StepFailed(reporter, machineSetStep, err)
+			return err
+		}
+
+		api.StepSucceeded(reporter, machineSetStep, "Removed gateway node for public subnet %s", subnetName)
 
-			if len(subnets) != 0 {
-				d.instanceType = instanceType
-				break
-			}
+		reporter.Started("Untagging public subnet %s from supporting Submariner", subnetName)
+
+		err = d.aws.untagPublicSubnet(ctx, subnet.SubnetId)
+		if err != nil {
+			reporter.Failed(err)
+			return err
 		}
-	} else {
-		subnets, err = d.aws.getSubnetsSupportingInstanceType(publicSubnets, d.instanceType)
+
+		reporter.Succeeded("Untagged public subnet %s from supporting Submariner", subnetName)
+
+		machineSet, err := d.initMachineSet("", "", "", subnet)
 		if err != nil {
+			reporter.Failed(err)
+			return err
+		}
+
+		err = api.ForgetResources(ctx, d.aws.stateStore, subnetTagResource(subnet), machineSetResource(machineSet))
+		if err != nil {
+			reporter.Failed(err)
 			return err
 		}
 	}
 
+	return nil
+}
+
+func (d *ocpGatewayDeployer) validateDeployPrerequisites(ctx context.Context, vpcID string, input api.GatewayDeployInput,
+	publicSubnets []types.Subnet, selector *instanceTypeSelector) error {
+	var errs []error
+
+	errs = appendIfError(errs, d.aws.validateCreateSecGroup(ctx, vpcID))
+	errs = appendIfError(errs, d.aws.validateCreateSecGroupRule(ctx, vpcID))
+	err := d.aws.validateDescribeInstanceTypeOfferings(ctx)
+	errs = appendIfError(errs, err)
+
+	if err != nil {
+		return utilerrors.NewAggregate(errs)
+	}
+
+	subnets, err := selector.supportedSubnets(ctx, publicSubnets)
+	if err != nil {
+		return err
+	}
+
 	subnetsCount := len(subnets)
 	if subnetsCount == 0 {
 		errs = append(errs, errors.New("found no public subnets to deploy Submariner gateway(s)"))
@@ -189,7 +409,7 @@ func (d *ocpGatewayDeployer) validateDeployPrerequisites(vpcID string, input api
 	}
 
 	if len(subnets) > 0 {
-		errs = appendIfError(errs, d.aws.validateCreateTag(*subnets[0].SubnetId))
+		errs = appendIfError(errs, d.aws.validateCreateTag(ctx, *subnets[0].SubnetId))
 	}
 
 	return utilerrors.NewAggregate(errs)
@@ -205,8 +425,8 @@ type machineSetConfig struct {
 	PublicSubnet  string
 }
 
-func (d *ocpGatewayDeployer) findAMIID(vpcID string) (string, error) {
-	result, err := d.aws.client.DescribeInstances(context.TODO(), &ec2.DescribeInstancesInput{
+func (d *ocpGatewayDeployer) findAMIID(ctx context.Context, vpcID string) (string, error) {
+	result, err := d.aws.client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
 		Filters: []types.Filter{
 			ec2Filter("vpc-id", vpcID),
 			d.aws.filterByName("{infraID}-worker*"),
@@ -232,70 +452,84 @@ func (d *ocpGatewayDeployer) findAMIID(vpcID string) (string, error) {
 	return *result.Reservations[0].Instances[0].ImageId, nil
 }
 
-func (d *ocpGatewayDeployer) loadGatewayYAML(gatewaySecurityGroup, amiID string, publicSubnet *types.Subnet) ([]byte, error) {
-	var buf bytes.Buffer
-
-	// TODO: Not working properly, but we should revisit this as it makes more sense
-	// tpl, err := template.ParseFiles("pkg/aws/gw-machineset.yaml.template")
-	tpl, err := template.New("").Parse(machineSetYAML)
-	if err != nil {
-		return nil, errors.Wrap(err, "error parsing machine set YAML")
-	}
+// machineSetStep returns the step operating on the gateway machine set of the given subnet.
+func (d *ocpGatewayDeployer) machineSetStep(subnet *types.Subnet) api.Step {
+	return api.ResourceStep(providerName, api.ResourceMachineSet,
+		fmt.Sprintf("%s-submariner-gw-%s", d.aws.infraID, aws.ToString(subnet.AvailabilityZone)))
+}
 
+func (d *ocpGatewayDeployer) initMachineSet(gwSecurityGroup, amiID, instanceType string, publicSubnet *types.Subnet,
+) (*unstructured.Unstructured, error) {
 	tplVars := machineSetConfig{
 		AZ:            *publicSubnet.AvailabilityZone,
 		AMIId:         amiID,
 		InfraID:       d.aws.infraID,
-		InstanceType:  d.instanceType,
+		InstanceType:  instanceType,
 		Region:        d.aws.region,
-		SecurityGroup: gatewaySecurityGroup,
+		SecurityGroup: gwSecurityGroup,
 		PublicSubnet:  extractName(publicSubnet.Tags),
 	}
 
-	err = tpl.Execute(&buf, tplVars)
+	return d.RenderMachineSet(tplVars)
+}
+
+// deployGateway deploys the gateway machine set of the given subnet, and returns the instance type it uses and whether
+// its instances are spot instances.
+func (d *ocpGatewayDeployer) deployGateway(ctx context.Context, vpcID, gatewaySecurityGroup string, publicSubnet *types.Subnet,
+	selector *instanceTypeSelector) (string, bool, error) {
+	amiID, err := d.findAMIID(ctx, vpcID)
 	if err != nil {
-		return nil, errors.Wrap(err, "error executing the template")
+		return "", false, err
 	}
 
-	return buf.Bytes(), nil
-}
-
-func (d *ocpGatewayDeployer) initMachineSet(gwSecurityGroup, amiID string, publicSubnet *types.Subnet) (*unstructured.Unstructured, error) {
-	gatewayYAML, err := d.loadGatewayYAML(gwSecurityGroup, amiID, publicSubnet)
+	machineSet, instanceType, err := d.initGatewayMachineSet(ctx, gatewaySecurityGroup, amiID, publicSubnet, selector)
 	if err != nil {
-		return nil, err
+		return "", false, err
 	}
 
-	unstructDecoder := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+	spotMachineSet := machineSet.DeepCopy()
 
-	machineSet := &unstructured.Unstructured{}
+	err = unstructured.SetNestedMap(spotMachineSet.Object, map[string]interface{}{},
+		"spec", "template", "spec", "providerSpec", "value", "spotMarketOptions")
+	if err != nil {
+		return "", false, errors.Wrapf(err, "error requesting spot instances in machine set %q", machineSet.GetName())
+	}
 
-	_, _, err = unstructDecoder.Decode(gatewayYAML, nil, machineSet)
+	spot, err := ocp.DeploySpotMachineSet(ctx, d.msDeployer, d.spot, spotMachineSet, machineSet)
 	if err != nil {
-		return nil, errors.Wrap(err, "error converting YAML to machine set")
+		return "", false, errors.Wrapf(err, "error deploying machine set %q", machineSet.GetName())
 	}
 
-	return machineSet, nil
+	return instanceType, spot, api.RecordResources(ctx, d.aws.stateStore, subnetTagResource(publicSubnet),
+		machineSetResource(machineSet))
 }
 
-func (d *ocpGatewayDeployer) deployGateway(vpcID, gatewaySecurityGroup string, publicSubnet *types.Subnet) error {
-	amiID, err := d.findAMIID(vpcID)
+// initGatewayMachineSet returns the gateway machine set of the given subnet, using the instance type chosen for it.
+func (d *ocpGatewayDeployer) initGatewayMachineSet(ctx context.Context, gatewaySecurityGroup, amiID string, publicSubnet *types.Subnet,
+	selector *instanceTypeSelector) (*unstructured.Unstructured, string, error) {
+	machineSet, err := d.initMachineSet("", "", "", publicSubnet)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
-	machineSet, err := d.initMachineSet(gatewaySecurityGroup, amiID, publicSubnet)
+	instanceType, err := selector.selectInstanceType(ctx, machineSet.GetName(), publicSubnet)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
-	return errors.Wrapf(d.msDeployer.Deploy(machineSet), "error deploying machine set %q", machineSet.GetName())
+	machineSet, err = d.initMachineSet(gatewaySecurityGroup, amiID, instanceType, publicSubnet)
+
+	return machineSet, instanceType, err
 }
 
 func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *ocpGatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
 	reporter.Started(messageRetrieveVPCID)
 
-	vpcID, err := d.aws.getVpcID()
+	vpcID, err := d.aws.getVpcID(ctx)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -305,7 +539,7 @@ func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
 
 	reporter.Started(messageValidatePrerequisites)
 
-	err = d.validateCleanupPrerequisites(vpcID)
+	err = d.validateCleanupPrerequisites(ctx, vpcID)
 	if err != nil {
 		reporter.Failed(err)
 		return err
@@ -313,71 +547,58 @@ func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
 
 	reporter.Succeeded(messageValidatedPrerequisites)
 
-	subnets, err := d.aws.getTaggedPublicSubnets(vpcID)
+	subnets, err := d.aws.recordedGatewaySubnets(ctx)
+	if err == nil && len(subnets) == 0 {
+		subnets, err = d.aws.getTaggedPublicSubnets(ctx, vpcID)
+	}
+
 	if err != nil {
+		reporter.Failed(err)
 		return err
 	}
 
-	for i := range subnets {
-		subnet := &subnets[i]
-		subnetName := extractName(subnet.Tags)
-
-		reporter.Started("Removing gateway node for public subnet %s", subnetName)
-
-		err = d.deleteGateway(subnet)
-		if err != nil {
-			reporter.Failed(err)
-			return err
-		}
-
-		reporter.Succeeded("Removed gateway node for public subnet %s", subnetName)
-
-		reporter.Started("Untagging public subnet %s from supporting Submariner", subnetName)
-
-		err = d.aws.untagPublicSubnet(subnet.SubnetId)
-		if err != nil {
-			reporter.Failed(err)
-			return err
-		}
-
-		reporter.Succeeded("Untagged public subnet %s from supporting Submariner", subnetName)
+	err = d.removeGateways(ctx, subnets, reporter)
+	if err != nil {
+		return err
 	}
 
-	reporter.Started("Deleting Submariner gateway security group")
+	gatewaySGStep := d.aws.gatewaySGStep()
+
+	api.StepStarted(reporter, gatewaySGStep, "Deleting Submariner gateway security group")
 
-	err = d.aws.deleteGatewaySG(vpcID)
+	err = d.aws.deleteGatewaySG(ctx, vpcID)
 	if err != nil {
-		reporter.Failed(err)
+		api.StepFailed(reporter, gatewaySGStep, err)
 		return err
 	}
 
-	reporter.Succeeded("Deleted Submariner gateway security group")
+	api.StepSucceeded(reporter, gatewaySGStep, "Deleted Submariner gateway security group")
 
 	return nil
 }
 
-func (d *ocpGatewayDeployer) validateCleanupPrerequisites(vpcID string) error {
+func (d *ocpGatewayDeployer) validateCleanupPrerequisites(ctx context.Context, vpcID string) error {
 	var errs []error
 
-	errs = appendIfError(errs, d.aws.validateDeleteSecGroup(vpcID))
+	errs = appendIfError(errs, d.aws.validateDeleteSecGroup(ctx, vpcID))
 
-	subnets, err := d.aws.getTaggedPublicSubnets(vpcID)
+	subnets, err := d.aws.getTaggedPublicSubnets(ctx, vpcID)
 	if err != nil {
 		return err
 	}
 
 	if len(subnets) > 0 {
-		errs = appendIfError(errs, d.aws.validateRemoveTag(subnets[0].SubnetId))
+		errs = appendIfError(errs, d.aws.validateRemoveTag(ctx, subnets[0].SubnetId))
 	}
 
 	return utilerrors.NewAggregate(errs)
 }
 
-func (d *ocpGatewayDeployer) deleteGateway(publicSubnet *types.Subnet) error {
-	machineSet, err := d.initMachineSet("", "", publicSubnet)
+func (d *ocpGatewayDeployer) deleteGateway(ctx context.Context, publicSubnet *types.Subnet) error {
+	machineSet, err := d.initMachineSet("", "", "", publicSubnet)
 	if err != nil {
 		return err
 	}
 
-	return errors.Wrapf(d.msDeployer.Delete(machineSet), "error deleting machine set %q", machineSet.GetName())
+	return errors.Wrapf(d.msDeployer.DeleteWithContext(ctx, machineSet), "error deleting machine set %q", machineSet.GetName())
 }
@@ -0,0 +1,653 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	quotatypes "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	cloudprepareaws "github.com/submariner-io/cloud-prepare/pkg/aws"
+	"github.com/submariner-io/cloud-prepare/pkg/aws/client/fake"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	ocpFake "github.com/submariner-io/cloud-prepare/pkg/ocp/fake"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	gatewayGroupID = "test-gateway-group"
+	instanceType   = "c5d.large"
+)
+
+var _ = Describe("OCP GatewayDeployer", func() {
+	t := newGatewayDeployerTestDriver()
+
+	When("the requested number of gateways is decreased", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", 3*time.Hour)
+			t.addGateway("subnet-b", "zone-b", time.Hour)
+			t.addGateway("subnet-c", "zone-c", 2*time.Hour)
+			t.numGateways = 2
+		})
+
+		It("should remove the newest gateway and untag its subnet", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.deletedMachineSets).To(Equal([]string{infraID + "-submariner-gw-zone-b"}))
+			Expect(t.untaggedSubnets).To(Equal([]string{"subnet-b"}))
+			Expect(t.deployedMachineSets).To(ConsistOf(infraID+"-submariner-gw-zone-a", infraID+"-submariner-gw-zone-c"))
+		})
+
+		Context("and the removed gateway has an Elastic IP", func() {
+			BeforeEach(func() {
+				t.addElasticIP("subnet-b", "i-subnet-b")
+			})
+
+			It("should release the Elastic IP", func() {
+				Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+				Expect(t.releasedElasticIPs).To(Equal([]string{"eipalloc-subnet-b"}))
+			})
+		})
+
+		It("should plan the removal of the newest gateway", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), t.input())
+			Expect(err).To(Succeed())
+			Expect(actions).To(ContainElement(api.Action{
+				Kind:       api.ActionUntagSubnet,
+				Resource:   "subnet-b",
+				Parameters: map[string]string{"name": "subnet-b"},
+			}))
+			Expect(t.deletedMachineSets).To(BeEmpty())
+			Expect(t.untaggedSubnets).To(BeEmpty())
+		})
+	})
+
+	When("the preferred instance type isn't offered in a gateway's availability zone", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.addGateway("subnet-b", "zone-b", time.Hour)
+			t.preferences = &cloudprepareaws.InstanceTypePreferences{InstanceTypes: []string{"c5d.large", "m5n.large"}}
+			t.offerings = map[string][]string{"zone-a": {"c5d.large", "m5n.large"}, "zone-b": {"m5n.large"}}
+		})
+
+		It("should use the next preferred instance type offered in that zone", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.deployedInstanceTypes).To(Equal(map[string]string{
+				infraID + "-submariner-gw-zone-a": "c5d.large",
+				infraID + "-submariner-gw-zone-b": "m5n.large",
+			}))
+		})
+
+		It("should plan the instance type offered in each zone", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), t.input())
+			Expect(err).To(Succeed())
+
+			instanceTypes := map[string]string{}
+			for _, action := range actions {
+				if action.Kind == api.ActionDeployMachineSet {
+					instanceTypes[action.Parameters["availability-zone"]] = action.Parameters["instance-type"]
+				}
+			}
+
+			Expect(instanceTypes).To(Equal(map[string]string{"zone-a": "c5d.large", "zone-b": "m5n.large"}))
+		})
+	})
+
+	When("the vCPU quota doesn't allow the preferred instance type for all gateways", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.addGateway("subnet-b", "zone-b", time.Hour)
+			t.preferences = &cloudprepareaws.InstanceTypePreferences{InstanceTypes: []string{"c5d.large", "t3.medium"}}
+			t.offerings = map[string][]string{"zone-a": {"c5d.large", "t3.medium"}, "zone-b": {"c5d.large", "t3.medium"}}
+			t.vCPUQuota = 3
+		})
+
+		It("should fall back to the next preferred instance type", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.deployedInstanceTypes).To(Equal(map[string]string{
+				infraID + "-submariner-gw-zone-a": "c5d.large",
+				infraID + "-submariner-gw-zone-b": "t3.medium",
+			}))
+		})
+
+		Context("and no preferred instance type fits", func() {
+			BeforeEach(func() {
+				t.vCPUQuota = 2
+			})
+
+			It("should return an error", func() {
+				Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).ToNot(Succeed())
+			})
+		})
+	})
+
+	When("a gateway machine set already exists", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.machineSets = []string{infraID + "-submariner-gw-zone-a"}
+			t.machineSetInstanceType = "m5n.large"
+		})
+
+		It("should keep its instance type", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.deployedInstanceTypes).To(Equal(map[string]string{infraID + "-submariner-gw-zone-a": "m5n.large"}))
+		})
+	})
+
+	When("the gateways are already deployed", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.addGateway("subnet-b", "zone-b", time.Hour)
+			t.machineSets = []string{infraID + "-submariner-gw-zone-a", infraID + "-submariner-gw-zone-b"}
+			t.gatewayPermissions = []types.IpPermission{{
+				IpProtocol: aws.String("udp"),
+				FromPort:   aws.Int32(4500),
+				ToPort:     aws.Int32(4500),
+				IpRanges:   []types.IpRange{{CidrIp: aws.String(api.AnyIPv4CIDR)}},
+			}}
+		})
+
+		It("should plan nothing", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), t.input())
+			Expect(err).To(Succeed())
+			Expect(actions).To(BeEmpty())
+		})
+
+		It("should report no drift", func() {
+			drift, err := api.VerifyGateways(context.TODO(), t.gwDeployer, t.input())
+			Expect(err).To(Succeed())
+			Expect(drift).To(BeEmpty())
+		})
+	})
+
+	When("spot instances are requested", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.spotInstances = true
+		})
+
+		It("should deploy the machine set with spot market options and an on-demand fallback", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.spotMachineSets).To(HaveLen(1))
+
+			_, found, _ := unstructured.NestedMap(t.spotMachineSets[0].Object,
+				"spec", "template", "spec", "providerSpec", "value", "spotMarketOptions")
+			Expect(found).To(BeTrue())
+
+			_, found, _ = unstructured.NestedMap(t.onDemandMachineSets[0].Object,
+				"spec", "template", "spec", "providerSpec", "value", "spotMarketOptions")
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	When("Elastic IPs are requested", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.elasticIPs = true
+		})
+
+		It("should allocate an Elastic IP and associate it with the gateway instance", func() {
+			Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+			Expect(t.allocatedElasticIPs).To(Equal([]string{"subnet-a"}))
+			Expect(t.associatedElasticIPs).To(Equal(map[string]string{"eipalloc-subnet-a": "i-subnet-a"}))
+		})
+
+		It("should plan the Elastic IP", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), t.input())
+			Expect(err).To(Succeed())
+			Expect(actions).To(ContainElement(api.Action{
+				Kind:       api.ActionConfigurePublicIP,
+				Resource:   infraID + "-submariner-gw-zone-a",
+				Parameters: map[string]string{"subnet-id": "subnet-a", "elastic-ip": ""},
+			}))
+			Expect(t.allocatedElasticIPs).To(BeEmpty())
+		})
+
+		Context("and the subnet already has an Elastic IP from a replaced gateway", func() {
+			BeforeEach(func() {
+				t.addElasticIP("subnet-a", "i-replaced")
+			})
+
+			It("should reuse it for the new gateway instance", func() {
+				Expect(t.gwDeployer.Deploy(t.input(), api.NewLoggingReporter())).To(Succeed())
+				Expect(t.allocatedElasticIPs).To(BeEmpty())
+				Expect(t.associatedElasticIPs).To(Equal(map[string]string{"eipalloc-subnet-a": "i-subnet-a"}))
+			})
+		})
+	})
+
+	When("the public ports are restricted to source CIDRs", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.gatewayPermissions = []types.IpPermission{{
+				IpProtocol: aws.String("udp"),
+				FromPort:   aws.Int32(4500),
+				ToPort:     aws.Int32(4500),
+				IpRanges:   []types.IpRange{{CidrIp: aws.String(api.AnyIPv4CIDR)}, {CidrIp: aws.String("10.1.0.0/16")}},
+			}}
+			t.sourceCIDRs = []string{"10.1.0.0/16", "10.2.0.0/16"}
+		})
+
+		It("should update the ranges of the existing rule in place", func() {
+			Expect(t.gwDeployer.(api.PublicPortsUpdater).UpdatePublicPorts(context.TODO(), t.input(),
+				api.NewLoggingReporter())).To(Succeed())
+			Expect(t.authorizedIngress).To(HaveLen(1))
+			Expect(t.authorizedIngress[0].IpRanges).To(HaveLen(1))
+			Expect(*t.authorizedIngress[0].IpRanges[0].CidrIp).To(Equal("10.2.0.0/16"))
+			Expect(t.revokedIngress).To(HaveLen(1))
+			Expect(t.revokedIngress[0].IpRanges).To(HaveLen(1))
+			Expect(*t.revokedIngress[0].IpRanges[0].CidrIp).To(Equal(api.AnyIPv4CIDR))
+			Expect(t.deployedMachineSets).To(BeEmpty())
+		})
+
+		It("should plan the ranges to authorize and revoke", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), t.input())
+			Expect(err).To(Succeed())
+			Expect(actions).To(ContainElements(api.Action{
+				Kind:       api.ActionAuthorizeIngress,
+				Resource:   gatewayGroupID,
+				Parameters: map[string]string{"protocol": "udp", "port": "4500", "source-cidr": "10.2.0.0/16"},
+			}, api.Action{
+				Kind:       api.ActionRevokeIngress,
+				Resource:   gatewayGroupID,
+				Parameters: map[string]string{"protocol": "udp", "port": "4500", "source-cidr": api.AnyIPv4CIDR},
+			}))
+		})
+	})
+
+	Context("on Inspect", func() {
+		BeforeEach(func() {
+			t.addGateway("subnet-a", "zone-a", time.Hour)
+			t.gatewayPermissions = []types.IpPermission{{
+				IpProtocol: aws.String("udp"),
+				FromPort:   aws.Int32(4500),
+				ToPort:     aws.Int32(4500),
+				IpRanges:   []types.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+			}}
+			t.machineSets = []string{infraID + "-submariner-gw-zone-a", infraID + "-worker-zone-a"}
+		})
+
+		It("should return the gateway ports, subnets, nodes and machine sets", func() {
+			status, err := t.gwDeployer.Inspect(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(status).To(Equal(&api.GatewayStatus{
+				PublicPorts:        []api.PortSpec{{Port: 4500, Protocol: "udp"}},
+				GatewaySubnets:     []string{"subnet-a"},
+				GatewayNodes:       []string{"ip-subnet-a.ec2.internal"},
+				GatewayMachineSets: []string{infraID + "-submariner-gw-zone-a"},
+			}))
+		})
+	})
+})
+
+type gatewayDeployerTestDriver struct {
+	fakeAWSClientBase
+	numGateways            int
+	gatewayPermissions     []types.IpPermission
+	sourceCIDRs            []string
+	authorizedIngress      []types.IpPermission
+	revokedIngress         []types.IpPermission
+	machineSets            []string
+	machineSetInstanceType string
+	subnets                []types.Subnet
+	instances              []types.Instance
+	preferences            *cloudprepareaws.InstanceTypePreferences
+	offerings              map[string][]string
+	vCPUQuota              float64
+	spotInstances          bool
+	elasticIPs             bool
+	addresses              []types.Address
+	allocatedElasticIPs    []string
+	associatedElasticIPs   map[string]string
+	releasedElasticIPs     []string
+	spotMachineSets        []*unstructured.Unstructured
+	onDemandMachineSets    []*unstructured.Unstructured
+	msDeployer             *ocpFake.MockMachineSetDeployer
+	deployedMachineSets    []string
+	deployedInstanceTypes  map[string]string
+	deletedMachineSets     []string
+	untaggedSubnets        []string
+	gwDeployer             api.GatewayDeployer
+}
+
+func newGatewayDeployerTestDriver() *gatewayDeployerTestDriver {
+	t := &gatewayDeployerTestDriver{}
+
+	BeforeEach(func() {
+		t.beforeEach()
+
+		t.numGateways = 0
+		t.subnets = nil
+		t.instances = nil
+		t.gatewayPermissions = nil
+		t.sourceCIDRs = nil
+		t.authorizedIngress = nil
+		t.revokedIngress = nil
+		t.machineSets = nil
+		t.machineSetInstanceType = ""
+		t.preferences = nil
+		t.offerings = nil
+		t.vCPUQuota = 0
+		t.spotInstances = false
+		t.elasticIPs = false
+		t.addresses = nil
+		t.allocatedElasticIPs = []string{}
+		t.associatedElasticIPs = map[string]string{}
+		t.releasedElasticIPs = []string{}
+		t.spotMachineSets = nil
+		t.onDemandMachineSets = nil
+		t.deployedMachineSets = []string{}
+		t.deployedInstanceTypes = map[string]string{}
+		t.deletedMachineSets = []string{}
+		t.untaggedSubnets = []string{}
+		t.msDeployer = ocpFake.NewMockMachineSetDeployer(t.mockCtrl)
+	})
+
+	JustBeforeEach(func() {
+		t.expectDescribeVpcs(vpcID, sourceCIDR, accountID)
+		t.expectDescribeSecurityGroups(gatewayGroupID, t.gatewayPermissions...)
+		t.expectDryRuns()
+
+		t.awsClient.EXPECT().DescribeSubnets(gomock.Any(), gomock.Any()).Return(&ec2.DescribeSubnetsOutput{
+			Subnets: t.subnets,
+		}, nil).AnyTimes()
+
+		t.awsClient.EXPECT().DescribeInstanceTypeOfferings(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.DescribeInstanceTypeOfferingsInput, _ ...func(*ec2.Options),
+			) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+				offered := []string{instanceType}
+				if t.offerings != nil {
+					offered = t.offerings[filterValue(input.Filters, "location")]
+				}
+
+				output := &ec2.DescribeInstanceTypeOfferingsOutput{}
+				for _, offeredType := range offered {
+					output.InstanceTypeOfferings = append(output.InstanceTypeOfferings,
+						types.InstanceTypeOffering{InstanceType: types.InstanceType(offeredType)})
+				}
+
+				return output, nil
+			}).AnyTimes()
+
+		t.awsClient.EXPECT().DescribeInstanceTypes(gomock.Any(), gomock.Any()).Return(&ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []types.InstanceTypeInfo{
+				{InstanceType: "c5d.large", VCpuInfo: &types.VCpuInfo{DefaultVCpus: aws.Int32(2)}},
+				{InstanceType: "t3.medium", VCpuInfo: &types.VCpuInfo{DefaultVCpus: aws.Int32(1)}},
+			},
+		}, nil).AnyTimes()
+
+		t.awsClient.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+				if filterValue(input.Filters, "tag:submariner.io") == "gateway" {
+					subnetID := filterValue(input.Filters, "subnet-id")
+					instances := []types.Instance{}
+
+					for i := range t.instances {
+						if subnetID == "" || aws.ToString(t.instances[i].SubnetId) == subnetID {
+							instances = append(instances, t.instances[i])
+						}
+					}
+
+					return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: instances}}}, nil
+				}
+
+				return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{
+					Instances: []types.Instance{{ImageId: aws.String("test-ami")}},
+				}}}, nil
+			}).AnyTimes()
+
+		t.expectElasticIPs()
+
+		t.awsClient.EXPECT().DeleteTags(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+				t.untaggedSubnets = append(t.untaggedSubnets, input.Resources...)
+				return &ec2.DeleteTagsOutput{}, nil
+			}).AnyTimes()
+
+		t.msDeployer.EXPECT().DeployWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, machineSet *unstructured.Unstructured) error {
+				t.deployedMachineSets = append(t.deployedMachineSets, machineSet.GetName())
+				t.deployedInstanceTypes[machineSet.GetName()], _, _ = unstructured.NestedString(machineSet.Object,
+					"spec", "template", "spec", "providerSpec", "value", "instanceType")
+
+				return nil
+			}).AnyTimes()
+
+		t.msDeployer.EXPECT().DeleteWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, machineSet *unstructured.Unstructured) error {
+				t.deletedMachineSets = append(t.deletedMachineSets, machineSet.GetName())
+				return nil
+			}).AnyTimes()
+
+		t.msDeployer.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+				machineSets := []unstructured.Unstructured{}
+
+				for _, name := range t.machineSets {
+					machineSet := unstructured.Unstructured{}
+					machineSet.SetName(name)
+
+					if t.machineSetInstanceType != "" {
+						Expect(unstructured.SetNestedField(machineSet.Object, t.machineSetInstanceType,
+							"spec", "template", "spec", "providerSpec", "value", "instanceType")).To(Succeed())
+					}
+
+					machineSets = append(machineSets, machineSet)
+				}
+
+				return machineSets, nil
+			}).AnyTimes()
+
+		var err error
+
+		cloud := cloudprepareaws.NewCloud(t.awsClient, infraID, region)
+
+		if t.elasticIPs {
+			t.gwDeployer, err = cloudprepareaws.NewOcpGatewayDeployer(cloud, t.msDeployer, instanceType)
+			Expect(err).To(Succeed())
+
+			t.gwDeployer.(cloudprepareaws.ElasticIPRequester).SetElasticIPs(&cloudprepareaws.ElasticIPConfig{})
+
+			return
+		}
+
+		if t.spotInstances {
+			spotDeployer := ocpFake.NewMockSpotMachineSetDeployer(t.mockCtrl)
+			spotDeployer.EXPECT().DeploySpot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, spot, onDemand *unstructured.Unstructured, _ time.Duration) (bool, error) {
+					t.spotMachineSets = append(t.spotMachineSets, spot)
+					t.onDemandMachineSets = append(t.onDemandMachineSets, onDemand)
+
+					return true, nil
+				}).AnyTimes()
+
+			t.gwDeployer, err = cloudprepareaws.NewOcpGatewayDeployer(cloud, &spotMachineSetDeployer{
+				MockMachineSetDeployer:     t.msDeployer,
+				MockSpotMachineSetDeployer: spotDeployer,
+			}, instanceType)
+			Expect(err).To(Succeed())
+
+			t.gwDeployer.(ocp.SpotInstanceRequester).SetSpotInstances(&ocp.SpotConfig{})
+
+			return
+		}
+
+		if t.preferences == nil {
+			t.gwDeployer, err = cloudprepareaws.NewOcpGatewayDeployer(cloud, t.msDeployer, instanceType)
+			Expect(err).To(Succeed())
+
+			return
+		}
+
+		if t.vCPUQuota > 0 {
+			quotas := fake.NewMockQuotaInterface(t.mockCtrl)
+			quotas.EXPECT().GetServiceQuota(gomock.Any(), gomock.Any()).Return(&servicequotas.GetServiceQuotaOutput{
+				Quota: &quotatypes.ServiceQuota{Value: aws.Float64(t.vCPUQuota)},
+			}, nil).AnyTimes()
+			t.preferences.Quotas = quotas
+		}
+
+		t.gwDeployer, err = cloudprepareaws.NewOcpGatewayDeployerWithPreferences(cloud, t.msDeployer, *t.preferences)
+		Expect(err).To(Succeed())
+	})
+
+	AfterEach(func() {
+		t.afterEach()
+	})
+
+	return t
+}
+
+func (t *gatewayDeployerTestDriver) expectDryRuns() {
+	t.awsClient.EXPECT().CreateSecurityGroup(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateSecurityGroupInput, _ ...func(*ec2.Options)) (*ec2.CreateSecurityGroupOutput, error) {
+			Expect(isDryRun(input.DryRun)).To(BeTrue())
+			return nil, dryRunError()
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().AuthorizeSecurityGroupIngress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.AuthorizeSecurityGroupIngressInput, _ ...func(*ec2.Options),
+		) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+			if isDryRun(input.DryRun) {
+				return nil, dryRunError()
+			}
+
+			t.authorizedIngress = append(t.authorizedIngress, input.IpPermissions...)
+
+			return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().RevokeSecurityGroupIngress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.RevokeSecurityGroupIngressInput, _ ...func(*ec2.Options),
+		) (*ec2.RevokeSecurityGroupIngressOutput, error) {
+			t.revokedIngress = append(t.revokedIngress, input.IpPermissions...)
+			return &ec2.RevokeSecurityGroupIngressOutput{}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().CreateTags(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+			Expect(isDryRun(input.DryRun)).To(BeTrue())
+			return nil, dryRunError()
+		}).AnyTimes()
+}
+
+func (t *gatewayDeployerTestDriver) expectElasticIPs() {
+	t.awsClient.EXPECT().DescribeAddresses(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeAddressesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+			output := &ec2.DescribeAddressesOutput{}
+
+			for i := range t.addresses {
+				if hasTagValue(t.addresses[i].Tags, "submariner.io/gateway-subnet",
+					filterValue(input.Filters, "tag:submariner.io/gateway-subnet")) {
+					output.Addresses = append(output.Addresses, t.addresses[i])
+				}
+			}
+
+			return output, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().AllocateAddress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.AllocateAddressInput, _ ...func(*ec2.Options)) (*ec2.AllocateAddressOutput, error) {
+			subnetID := ""
+
+			for _, tag := range input.TagSpecifications[0].Tags {
+				if aws.ToString(tag.Key) == "submariner.io/gateway-subnet" {
+					subnetID = aws.ToString(tag.Value)
+				}
+			}
+
+			t.allocatedElasticIPs = append(t.allocatedElasticIPs, subnetID)
+
+			return &ec2.AllocateAddressOutput{
+				AllocationId: aws.String("eipalloc-" + subnetID),
+				PublicIp:     aws.String("203.0.113.1"),
+			}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().AssociateAddress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.AssociateAddressInput, _ ...func(*ec2.Options)) (*ec2.AssociateAddressOutput, error) {
+			t.associatedElasticIPs[aws.ToString(input.AllocationId)] = aws.ToString(input.InstanceId)
+			return &ec2.AssociateAddressOutput{}, nil
+		}).AnyTimes()
+
+	t.awsClient.EXPECT().DisassociateAddress(gomock.Any(), gomock.Any()).Return(&ec2.DisassociateAddressOutput{}, nil).AnyTimes()
+
+	t.awsClient.EXPECT().ReleaseAddress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.ReleaseAddressInput, _ ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error) {
+			t.releasedElasticIPs = append(t.releasedElasticIPs, aws.ToString(input.AllocationId))
+			return &ec2.ReleaseAddressOutput{}, nil
+		}).AnyTimes()
+}
+
+func (t *gatewayDeployerTestDriver) addElasticIP(subnetID, instanceID string) {
+	t.addresses = append(t.addresses, types.Address{
+		AllocationId:  aws.String("eipalloc-" + subnetID),
+		AssociationId: aws.String("eipassoc-" + subnetID),
+		InstanceId:    aws.String(instanceID),
+		PublicIp:      aws.String("203.0.113.2"),
+		Tags:          []types.Tag{{Key: aws.String("submariner.io/gateway-subnet"), Value: aws.String(subnetID)}},
+	})
+}
+
+func hasTagValue(tags []types.Tag, key, value string) bool {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key && aws.ToString(tag.Value) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *gatewayDeployerTestDriver) addGateway(subnetID, zone string, age time.Duration) {
+	t.subnets = append(t.subnets, types.Subnet{
+		SubnetId:         aws.String(subnetID),
+		AvailabilityZone: aws.String(zone),
+		Tags: []types.Tag{
+			{Key: aws.String("Name"), Value: aws.String(subnetID)},
+			{Key: aws.String("submariner.io/gateway"), Value: aws.String("")},
+		},
+	})
+
+	t.instances = append(t.instances, types.Instance{
+		InstanceId:     aws.String("i-" + subnetID),
+		SubnetId:       aws.String(subnetID),
+		PrivateDnsName: aws.String("ip-" + subnetID + ".ec2.internal"),
+		LaunchTime:     aws.Time(time.Now().Add(-age)),
+	})
+}
+
+func (t *gatewayDeployerTestDriver) input() api.GatewayDeployInput {
+	return api.GatewayDeployInput{
+		Gateways:          t.numGateways,
+		PublicPorts:       []api.PortSpec{{Port: 4500, Protocol: "udp"}},
+		PublicSourceCIDRs: t.sourceCIDRs,
+	}
+}
+
+type spotMachineSetDeployer struct {
+	*ocpFake.MockMachineSetDeployer
+	*ocpFake.MockSpotMachineSetDeployer
+}
@@ -0,0 +1,441 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+)
+
+// Plan returns the ingress rules PrepareForSubmariner would add between the worker and master security groups.
+func (ac *awsCloud) Plan(ctx context.Context, input api.PrepareForSubmarinerInput) ([]api.Action, error) {
+	vpcID, err := ac.getVpcID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ac.validatePreparePrerequisites(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+
+	workerGroup, err := ac.getSecurityGroup(ctx, vpcID, "{infraID}-worker-sg")
+	if err != nil {
+		return nil, err
+	}
+
+	masterGroup, err := ac.getSecurityGroup(ctx, vpcID, "{infraID}-master-sg")
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []api.Action{}
+
+	for _, port := range input.InternalPorts {
+		actions = planIngressFromGroup(actions, &workerGroup, &workerGroup, port)
+		actions = planIngressFromGroup(actions, &masterGroup, &workerGroup, port)
+		actions = planIngressFromGroup(actions, &workerGroup, &masterGroup, port)
+	}
+
+	return actions, nil
+}
+
+// Plan returns the security group, subnet tags and missing machine sets Deploy would create, and the gateways it would
+// remove.
+func (d *ocpGatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	vpcID, err := d.aws.getVpcID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	publicSubnets, err := d.aws.findPublicSubnets(ctx, vpcID, d.aws.filterByName("{infraID}-public-{region}*"))
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := d.newInstanceTypeSelector(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.validateDeployPrerequisites(ctx, vpcID, input, publicSubnets, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	groupName := d.aws.withAWSInfo("{infraID}-submariner-gw-sg")
+
+	actions, err := d.aws.planGatewaySG(ctx, vpcID, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	subnets, err := selector.supportedSubnets(ctx, publicSubnets)
+	if err != nil {
+		return nil, err
+	}
+
+	taggedSubnets, subnetsToTag := selectGatewaySubnets(subnets, input.Gateways)
+
+	if input.Gateways > 0 && len(taggedSubnets) > input.Gateways {
+		var surplusSubnets []types.Subnet
+
+		taggedSubnets, surplusSubnets, err = d.selectSurplusSubnets(ctx, vpcID, taggedSubnets, input.Gateways)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range surplusSubnets {
+			machineSet, err := d.initMachineSet("", "", "", &surplusSubnets[i])
+			if err != nil {
+				return nil, err
+			}
+
+			actions = append(actions,
+				api.Action{
+					Kind:       api.ActionDeleteMachineSet,
+					Resource:   machineSet.GetName(),
+					Parameters: map[string]string{"namespace": machineSet.GetNamespace()},
+				},
+				api.Action{
+					Kind:       api.ActionUntagSubnet,
+					Resource:   aws.ToString(surplusSubnets[i].SubnetId),
+					Parameters: map[string]string{"name": extractName(surplusSubnets[i].Tags)},
+				})
+
+			address, err := d.aws.findGatewayElasticIP(ctx, aws.ToString(surplusSubnets[i].SubnetId))
+			if err != nil {
+				return nil, err
+			}
+
+			if address != nil {
+				actions = append(actions, api.Action{
+					Kind:       api.ActionDeletePublicIP,
+					Resource:   aws.ToString(address.PublicIp),
+					Parameters: map[string]string{"subnet-id": aws.ToString(surplusSubnets[i].SubnetId)},
+				})
+			}
+		}
+	}
+
+	for i := range subnetsToTag {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionTagSubnet,
+			Resource: *subnetsToTag[i].SubnetId,
+			Parameters: map[string]string{
+				"name": extractName(subnetsToTag[i].Tags),
+				"tags": *tagSubmarinerGateway.Key + "," + *tagInternalELB.Key,
+			},
+		})
+	}
+
+	taggedSubnets = append(taggedSubnets, subnetsToTag...)
+	if len(taggedSubnets) == 0 {
+		return actions, nil
+	}
+
+	amiID, err := d.findAMIID(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+
+	deployedMachineSets, err := d.deployedMachineSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range taggedSubnets {
+		machineSet, instanceType, err := d.initGatewayMachineSet(ctx, groupName, amiID, &taggedSubnets[i], selector)
+		if err != nil {
+			return nil, err
+		}
+
+		if !deployedMachineSets[machineSet.GetName()] {
+			actions = append(actions, api.Action{
+				Kind:     api.ActionDeployMachineSet,
+				Resource: machineSet.GetName(),
+				Parameters: map[string]string{
+					"namespace":         machineSet.GetNamespace(),
+					"availability-zone": aws.ToString(taggedSubnets[i].AvailabilityZone),
+					"instance-type":     instanceType,
+					"image":             amiID,
+				},
+			})
+		}
+
+		if d.elasticIPs == nil {
+			continue
+		}
+
+		address, err := d.aws.findGatewayElasticIP(ctx, aws.ToString(taggedSubnets[i].SubnetId))
+		if err != nil {
+			return nil, err
+		}
+
+		if address == nil {
+			address = &types.Address{}
+		}
+
+		if address.InstanceId == nil {
+			actions = append(actions, api.Action{
+				Kind:     api.ActionConfigurePublicIP,
+				Resource: machineSet.GetName(),
+				Parameters: map[string]string{
+					"subnet-id":  aws.ToString(taggedSubnets[i].SubnetId),
+					"elastic-ip": aws.ToString(address.PublicIp),
+				},
+			})
+		}
+	}
+
+	return actions, nil
+}
+
+// deployedMachineSets returns the names of the gateway machine sets which are already deployed.
+func (d *ocpGatewayDeployer) deployedMachineSets(ctx context.Context) (map[string]bool, error) {
+	machineSets, err := ocp.GatewayMachineSets(ctx, d.msDeployer, d.aws.infraID)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	deployed := map[string]bool{}
+	for i := range machineSets {
+		deployed[machineSets[i].GetName()] = true
+	}
+
+	return deployed, nil
+}
+
+// planGatewaySG returns the actions needed to create the gateway security group if it's missing, to open the public
+// ports it lacks to their source CIDRs, and to revoke the access from the CIDRs which are no longer allowed.
+func (ac *awsCloud) planGatewaySG(ctx context.Context, vpcID string, input *api.GatewayDeployInput) ([]api.Action, error) {
+	actions := []api.Action{}
+	groupName := ac.withAWSInfo("{infraID}-submariner-gw-sg")
+
+	gatewayGroup, err := ac.getSecurityGroup(ctx, vpcID, groupName)
+	if isNotFoundError(err) {
+		actions = append(actions, api.Action{
+			Kind:       api.ActionCreateSecurityGroup,
+			Resource:   groupName,
+			Parameters: map[string]string{"vpc-id": vpcID},
+		})
+		gatewayGroup = types.SecurityGroup{GroupId: aws.String(groupName)}
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, port := range input.PublicPorts {
+		added, removed := api.DiffSourceCIDRs(publicSGRuleCIDRs(&gatewayGroup, port), input.SourceCIDRs(port))
+
+		for _, cidr := range added {
+			actions = append(actions, api.Action{
+				Kind:       api.ActionAuthorizeIngress,
+				Resource:   *gatewayGroup.GroupId,
+				Parameters: portParameters(port, "source-cidr", cidr),
+			})
+		}
+
+		for _, cidr := range removed {
+			actions = append(actions, api.Action{
+				Kind:       api.ActionRevokeIngress,
+				Resource:   *gatewayGroup.GroupId,
+				Parameters: portParameters(port, "source-cidr", cidr),
+			})
+		}
+	}
+
+	return actions, nil
+}
+
+func planIngressFromGroup(actions []api.Action, group, source *types.SecurityGroup, port api.PortSpec) []api.Action {
+	if hasIngress(group, port, func(permission *types.IpPermission) bool {
+		for _, pair := range permission.UserIdGroupPairs {
+			if aws.ToString(pair.GroupId) == aws.ToString(source.GroupId) {
+				return true
+			}
+		}
+
+		return false
+	}) {
+		return actions
+	}
+
+	return append(actions, api.Action{
+		Kind:       api.ActionAuthorizeIngress,
+		Resource:   aws.ToString(group.GroupId),
+		Parameters: portParameters(port, "source-group", aws.ToString(source.GroupId)),
+	})
+}
+
+// hasIngress checks whether the group already allows the given port from the source matched by fromSource.
+func hasIngress(group *types.SecurityGroup, port api.PortSpec, fromSource func(*types.IpPermission) bool) bool {
+	for i := range group.IpPermissions {
+		permission := &group.IpPermissions[i]
+
+		if aws.ToString(permission.IpProtocol) != port.Protocol && aws.ToString(permission.IpProtocol) != "-1" {
+			continue
+		}
+
+		if permission.FromPort != nil && permission.ToPort != nil &&
+			(*permission.FromPort > int32(port.Port) || *permission.ToPort < int32(port.Port)) {
+			continue
+		}
+
+		if fromSource(permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func portParameters(port api.PortSpec, sourceKey, source string) map[string]string {
+	return map[string]string{
+		"protocol": port.Protocol,
+		"port":     strconv.Itoa(int(port.Port)),
+		sourceKey:  source,
+	}
+}
+
+// Plan returns the security group Deploy would create, the nodes it would label or unlabel, and the subnet tags and
+// security group attachments it would add or remove.
+func (d *eksGatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	clusterGroup, err := d.aws.getClusterSecurityGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vpcID := aws.ToString(clusterGroup.VpcId)
+	groupName := d.aws.withAWSInfo("{infraID}-submariner-gw-sg")
+
+	actions, err := d.aws.planGatewaySG(ctx, vpcID, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	gatewayGroupID, err := d.aws.getSecurityGroupID(ctx, vpcID, groupName)
+	if err != nil && !isNotFoundError(err) {
+		return nil, err
+	}
+
+	subnets, err := d.aws.getGatewaySubnets(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+
+	taggedSubnets := map[string]bool{}
+	for i := range subnets {
+		taggedSubnets[aws.ToString(subnets[i].SubnetId)] = true
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	instances, err := d.describeNodeInstances(ctx, gwNodes.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Gateways > 0 && len(gwNodes.Items) > input.Gateways {
+		surplus := k8s.SurplusGatewayNodes(gwNodes, input.Gateways)
+
+		for _, node := range surplus {
+			if instance := instances[node.Name]; instance != nil && instanceAttachedTo(instance, aws.ToString(gatewayGroupID)) {
+				actions = append(actions, api.Action{
+					Kind:       api.ActionDetachSecurityGroup,
+					Resource:   node.Name,
+					Parameters: map[string]string{"security-group": groupName},
+				})
+			}
+
+			actions = append(actions, api.Action{Kind: api.ActionUnlabelNode, Resource: node.Name})
+		}
+
+		gwNodes.Items = withoutNodes(gwNodes.Items, surplus)
+	}
+
+	for i := range gwNodes.Items {
+		actions = planGatewayConfiguration(actions, gwNodes.Items[i].Name, instances[gwNodes.Items[i].Name],
+			aws.ToString(gatewayGroupID), groupName, taggedSubnets)
+	}
+
+	if input.Gateways <= len(gwNodes.Items) {
+		return actions, nil
+	}
+
+	candidates, instances, err := d.gatewayCandidates(ctx, input.Gateways-len(gwNodes.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		actions = append(actions, api.Action{Kind: api.ActionLabelNode, Resource: candidates[i].Name})
+		actions = planGatewayConfiguration(actions, candidates[i].Name, instances[candidates[i].Name], aws.ToString(gatewayGroupID),
+			groupName, taggedSubnets)
+	}
+
+	return actions, nil
+}
+
+// planGatewayConfiguration returns the subnet tag and security group attachment the gateway node lacks.
+func planGatewayConfiguration(actions []api.Action, nodeName string, instance *types.Instance, gatewayGroupID, groupName string,
+	taggedSubnets map[string]bool) []api.Action {
+	if instance == nil {
+		return actions
+	}
+
+	subnetID := aws.ToString(instance.SubnetId)
+	if !taggedSubnets[subnetID] {
+		actions = append(actions, api.Action{
+			Kind:       api.ActionTagSubnet,
+			Resource:   subnetID,
+			Parameters: map[string]string{"tags": *tagSubmarinerGateway.Key + "," + *tagInternalELB.Key},
+		})
+		taggedSubnets[subnetID] = true
+	}
+
+	if !instanceAttachedTo(instance, gatewayGroupID) {
+		actions = append(actions, api.Action{
+			Kind:       api.ActionAttachSecurityGroup,
+			Resource:   nodeName,
+			Parameters: map[string]string{"security-group": groupName},
+		})
+	}
+
+	return actions
+}
+
+// instanceAttachedTo checks whether all the network interfaces of the instance are attached to the given group.
+func instanceAttachedTo(instance *types.Instance, groupID string) bool {
+	for i := range instance.NetworkInterfaces {
+		if _, attached := otherGroups(instance.NetworkInterfaces[i].Groups, groupID); !attached {
+			return false
+		}
+	}
+
+	return true
+}
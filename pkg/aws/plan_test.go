@@ -0,0 +1,118 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	cloudprepareaws "github.com/submariner-io/cloud-prepare/pkg/aws"
+)
+
+const masterGroupID = "test-master-group"
+
+var _ = Describe("AWS Plan", func() {
+	var (
+		client     fakeAWSClientBase
+		workerRule []types.IpPermission
+		actions    []api.Action
+		retError   error
+	)
+
+	BeforeEach(func() {
+		client.beforeEach()
+
+		workerRule = []types.IpPermission{{
+			IpProtocol:       aws.String("udp"),
+			FromPort:         aws.Int32(4800),
+			ToPort:           aws.Int32(4800),
+			UserIdGroupPairs: []types.UserIdGroupPair{{GroupId: aws.String(workerGroupID)}},
+		}}
+
+		client.expectDescribeVpcs(vpcID, sourceCIDR, accountID)
+		client.awsClient.EXPECT().DescribeSecurityGroups(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.DescribeSecurityGroupsInput, _ ...func(*ec2.Options),
+			) (*ec2.DescribeSecurityGroupsOutput, error) {
+				if filterValue(input.Filters, "tag:Name") == infraID+"-master-sg" {
+					return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{
+						{GroupId: aws.String(masterGroupID)},
+					}}, nil
+				}
+
+				return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{
+					{GroupId: aws.String(workerGroupID), IpPermissions: workerRule},
+				}}, nil
+			}).AnyTimes()
+		client.awsClient.EXPECT().AuthorizeSecurityGroupIngress(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.AuthorizeSecurityGroupIngressInput, _ ...func(*ec2.Options),
+			) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+				if !isDryRun(input.DryRun) {
+					return nil, errors.New("Plan must not modify security groups")
+				}
+
+				return nil, dryRunError()
+			}).AnyTimes()
+	})
+
+	JustBeforeEach(func() {
+		cloud := cloudprepareaws.NewCloud(client.awsClient, infraID, region)
+		actions, retError = cloud.Plan(context.TODO(), api.PrepareForSubmarinerInput{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "udp"}},
+		})
+	})
+
+	AfterEach(func() {
+		client.afterEach()
+	})
+
+	It("should only return the missing ingress rules", func() {
+		Expect(retError).To(Succeed())
+		Expect(actions).To(Equal([]api.Action{
+			{
+				Kind:       api.ActionAuthorizeIngress,
+				Resource:   masterGroupID,
+				Parameters: map[string]string{"protocol": "udp", "port": "4800", "source-group": workerGroupID},
+			},
+			{
+				Kind:       api.ActionAuthorizeIngress,
+				Resource:   workerGroupID,
+				Parameters: map[string]string{"protocol": "udp", "port": "4800", "source-group": masterGroupID},
+			},
+		}))
+	})
+
+	When("the worker group already allows ingress from the master group", func() {
+		BeforeEach(func() {
+			workerRule[0].UserIdGroupPairs = append(workerRule[0].UserIdGroupPairs,
+				types.UserIdGroupPair{GroupId: aws.String(masterGroupID)})
+		})
+
+		It("should only return the master ingress rule", func() {
+			Expect(retError).To(Succeed())
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].Resource).To(Equal(masterGroupID))
+		})
+	})
+})
@@ -34,8 +34,8 @@ import (
 
 const internalTraffic = "Internal Submariner traffic"
 
-func (ac *awsCloud) getSecurityGroupID(vpcID, name string) (*string, error) {
-	group, err := ac.getSecurityGroup(vpcID, name)
+func (ac *awsCloud) getSecurityGroupID(ctx context.Context, vpcID, name string) (*string, error) {
+	group, err := ac.getSecurityGroup(ctx, vpcID, name)
 	if err != nil {
 		return nil, err
 	}
@@ -43,14 +43,14 @@ func (ac *awsCloud) getSecurityGroupID(vpcID, name string) (*string, error) {
 	return group.GroupId, nil
 }
 
-func (ac *awsCloud) getSecurityGroup(vpcID, name string) (types.SecurityGroup, error) {
+func (ac *awsCloud) getSecurityGroup(ctx context.Context, vpcID, name string) (types.SecurityGroup, error) {
 	filters := []types.Filter{
 		ec2Filter("vpc-id", vpcID),
 		ac.filterByName(name),
 		ac.filterByCurrentCluster(),
 	}
 
-	result, err := ac.client.DescribeSecurityGroups(context.TODO(), &ec2.DescribeSecurityGroupsInput{
+	result, err := ac.client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
 		Filters: filters,
 	})
 	if err != nil {
@@ -64,13 +64,13 @@ func (ac *awsCloud) getSecurityGroup(vpcID, name string) (types.SecurityGroup, e
 	return result.SecurityGroups[0], nil
 }
 
-func (ac *awsCloud) authorizeSecurityGroupIngress(groupID *string, ipPermissions []types.IpPermission) error {
+func (ac *awsCloud) authorizeSecurityGroupIngress(ctx context.Context, groupID *string, ipPermissions []types.IpPermission) error {
 	input := &ec2.AuthorizeSecurityGroupIngressInput{
 		GroupId:       groupID,
 		IpPermissions: ipPermissions,
 	}
 
-	_, err := ac.client.AuthorizeSecurityGroupIngress(context.TODO(), input)
+	_, err := ac.client.AuthorizeSecurityGroupIngress(ctx, input)
 	if isAWSError(err, "InvalidPermission.Duplicate") {
 		return nil
 	}
@@ -78,7 +78,7 @@ func (ac *awsCloud) authorizeSecurityGroupIngress(groupID *string, ipPermissions
 	return errors.Wrap(err, "error authorizing AWS security groups ingress")
 }
 
-func (ac *awsCloud) createClusterSGRule(srcGroup, destGroup *string, port uint16, protocol, description string) error {
+func (ac *awsCloud) createClusterSGRule(ctx context.Context, srcGroup, destGroup *string, port uint16, protocol, description string) error {
 	ipPermissions := []types.IpPermission{
 		{
 			FromPort:   aws.Int32(int32(port)),
@@ -93,61 +93,133 @@ func (ac *awsCloud) createClusterSGRule(srcGroup, destGroup *string, port uint16
 		},
 	}
 
-	return ac.authorizeSecurityGroupIngress(destGroup, ipPermissions)
+	err := ac.authorizeSecurityGroupIngress(ctx, destGroup, ipPermissions)
+	if err != nil {
+		return err
+	}
+
+	return api.RecordResources(ctx, ac.stateStore, ingressRuleResource(srcGroup, destGroup, port, protocol))
 }
 
-func (ac *awsCloud) allowPortInCluster(vpcID string, port uint16, protocol string) error {
-	workerGroupID, err := ac.getSecurityGroupID(vpcID, "{infraID}-worker-sg")
+func (ac *awsCloud) allowPortInCluster(ctx context.Context, vpcID string, port uint16, protocol string) error {
+	workerGroupID, err := ac.getSecurityGroupID(ctx, vpcID, "{infraID}-worker-sg")
 	if err != nil {
 		return err
 	}
 
-	masterGroupID, err := ac.getSecurityGroupID(vpcID, "{infraID}-master-sg")
+	masterGroupID, err := ac.getSecurityGroupID(ctx, vpcID, "{infraID}-master-sg")
 	if err != nil {
 		return err
 	}
 
-	err = ac.createClusterSGRule(workerGroupID, workerGroupID, port, protocol, fmt.Sprintf("%s between the workers", internalTraffic))
+	err = ac.createClusterSGRule(ctx, workerGroupID, workerGroupID, port, protocol, fmt.Sprintf("%s between the workers", internalTraffic))
 	if err != nil {
 		return err
 	}
 
-	err = ac.createClusterSGRule(workerGroupID, masterGroupID, port, protocol, fmt.Sprintf("%s from worker to master nodes", internalTraffic))
+	err = ac.createClusterSGRule(ctx, workerGroupID, masterGroupID, port, protocol,
+		fmt.Sprintf("%s from worker to master nodes", internalTraffic))
 	if err != nil {
 		return err
 	}
 
-	return ac.createClusterSGRule(masterGroupID, workerGroupID, port, protocol, fmt.Sprintf("%s from master to worker nodes", internalTraffic))
+	return ac.createClusterSGRule(ctx, masterGroupID, workerGroupID, port, protocol,
+		fmt.Sprintf("%s from master to worker nodes", internalTraffic))
 }
 
-func (ac *awsCloud) createPublicSGRule(groupID *string, port uint16, protocol, description string) error {
-	ipPermissions := []types.IpPermission{
+// publicSGPermissions returns the permission opening the given public port to the given CIDRs.
+func publicSGPermissions(port api.PortSpec, cidrs []string) []types.IpPermission {
+	ipRanges := []types.IpRange{}
+	for _, cidr := range cidrs {
+		ipRanges = append(ipRanges, types.IpRange{
+			CidrIp:      aws.String(cidr),
+			Description: aws.String("Public Submariner traffic"),
+		})
+	}
+
+	return []types.IpPermission{
 		{
-			FromPort:   aws.Int32(int32(port)),
-			ToPort:     aws.Int32(int32(port)),
-			IpProtocol: aws.String(protocol),
-			IpRanges: []types.IpRange{
-				{
-					CidrIp:      aws.String("0.0.0.0/0"),
-					Description: aws.String(description),
-				},
-			},
+			FromPort:   aws.Int32(int32(port.Port)),
+			ToPort:     aws.Int32(int32(port.Port)),
+			IpProtocol: aws.String(port.Protocol),
+			IpRanges:   ipRanges,
 		},
 	}
+}
+
+// publicSGRuleCIDRs returns the CIDRs the group opens exactly the given port to.
+func publicSGRuleCIDRs(group *types.SecurityGroup, port api.PortSpec) []string {
+	cidrs := []string{}
+
+	for i := range group.IpPermissions {
+		permission := &group.IpPermissions[i]
+
+		if aws.ToString(permission.IpProtocol) != port.Protocol || aws.ToInt32(permission.FromPort) != int32(port.Port) ||
+			aws.ToInt32(permission.ToPort) != int32(port.Port) {
+			continue
+		}
+
+		for _, ipRange := range permission.IpRanges {
+			cidrs = append(cidrs, aws.ToString(ipRange.CidrIp))
+		}
+	}
+
+	return cidrs
+}
+
+// updatePublicSGRules opens the public ports of the gateway security group to their source CIDRs, then revokes the
+// access from the CIDRs which are no longer allowed.
+func (ac *awsCloud) updatePublicSGRules(ctx context.Context, group *types.SecurityGroup, input *api.GatewayDeployInput) error {
+	for _, port := range input.PublicPorts {
+		added, removed := api.DiffSourceCIDRs(publicSGRuleCIDRs(group, port), input.SourceCIDRs(port))
+
+		if len(added) > 0 {
+			err := ac.authorizeSecurityGroupIngress(ctx, group.GroupId, publicSGPermissions(port, added))
+			if err != nil {
+				return err
+			}
+
+			err = api.RecordResources(ctx, ac.stateStore, publicIngressRuleResources(group.GroupId, port, added)...)
+			if err != nil {
+				return err // nolint:wrapcheck // Already wrapped.
+			}
+		}
+
+		if len(removed) > 0 {
+			_, err := ac.client.RevokeSecurityGroupIngress(ctx, &ec2.RevokeSecurityGroupIngressInput{
+				GroupId:       group.GroupId,
+				IpPermissions: publicSGPermissions(port, removed),
+			})
+			if err != nil {
+				return errors.Wrapf(err, "error revoking the access to port %d/%s from %s", port.Port, port.Protocol,
+					strings.Join(removed, ", "))
+			}
+
+			err = api.ForgetResources(ctx, ac.stateStore, publicIngressRuleResources(group.GroupId, port, removed)...)
+			if err != nil {
+				return err // nolint:wrapcheck // Already wrapped.
+			}
+		}
+	}
 
-	return ac.authorizeSecurityGroupIngress(groupID, ipPermissions)
+	return nil
 }
 
-func (ac *awsCloud) createGatewaySG(vpcID string, ports []api.PortSpec) (string, error) {
+// gatewaySGStep returns the step operating on the gateway security group.
+func (ac *awsCloud) gatewaySGStep() api.Step {
+	return api.ResourceStep(providerName, api.ResourceSecurityGroup, ac.withAWSInfo("{infraID}-submariner-gw-sg"))
+}
+
+func (ac *awsCloud) createGatewaySG(ctx context.Context, vpcID string, input *api.GatewayDeployInput) (string, error) {
 	groupName := ac.withAWSInfo("{infraID}-submariner-gw-sg")
 
-	gatewayGroupID, err := ac.getSecurityGroupID(vpcID, groupName)
+	gatewayGroup, err := ac.getSecurityGroup(ctx, vpcID, groupName)
 	if err != nil {
 		if !isNotFoundError(err) {
 			return "", err
 		}
 
-		input := &ec2.CreateSecurityGroupInput{
+		createInput := &ec2.CreateSecurityGroupInput{
 			GroupName:   &groupName,
 			Description: aws.String("Submariner Gateway"),
 			VpcId:       &vpcID,
@@ -162,40 +234,72 @@ func (ac *awsCloud) createGatewaySG(vpcID string, ports []api.PortSpec) (string,
 			},
 		}
 
-		result, err := ac.client.CreateSecurityGroup(context.TODO(), input)
+		result, err := ac.client.CreateSecurityGroup(ctx, createInput)
 		if err != nil {
 			return "", errors.Wrap(err, "error creating AWS security group")
 		}
 
-		gatewayGroupID = result.GroupId
+		gatewayGroup = types.SecurityGroup{GroupId: result.GroupId}
 	}
 
-	for _, port := range ports {
-		err = ac.createPublicSGRule(gatewayGroupID, port.Port, port.Protocol, "Public Submariner traffic")
-		if err != nil {
-			return "", err
-		}
+	err = api.RecordResources(ctx, ac.stateStore, securityGroupResource(gatewayGroup.GroupId, groupName, vpcID))
+	if err != nil {
+		return "", err // nolint:wrapcheck // Already wrapped.
+	}
+
+	err = ac.updatePublicSGRules(ctx, &gatewayGroup, input)
+	if err != nil {
+		return "", err
 	}
 
 	return groupName, nil
 }
 
+// updatePublicPorts updates the source CIDRs of the public ports of the existing gateway security group.
+func (ac *awsCloud) updatePublicPorts(ctx context.Context, vpcID string, input *api.GatewayDeployInput, reporter api.Reporter) error {
+	groupName := ac.withAWSInfo("{infraID}-submariner-gw-sg")
+	gatewaySGStep := ac.gatewaySGStep()
+
+	api.StepStarted(reporter, gatewaySGStep, "Updating the source CIDRs of the public ports in security group %s", groupName)
+
+	gatewayGroup, err := ac.getSecurityGroup(ctx, vpcID, groupName)
+	if err == nil {
+		err = ac.updatePublicSGRules(ctx, &gatewayGroup, input)
+	}
+
+	if err != nil {
+		api.StepFailed(reporter, gatewaySGStep, err)
+		return err
+	}
+
+	api.StepSucceeded(reporter, gatewaySGStep, "Updated the source CIDRs of the public ports in security group %s", groupName)
+
+	return nil
+}
+
 func gatewayDeletionRetriable(err error) bool {
 	return isAWSError(err, "DependencyViolation")
 }
 
-func (ac *awsCloud) deleteGatewaySG(vpcID string) error {
+func (ac *awsCloud) deleteGatewaySG(ctx context.Context, vpcID string) error {
 	groupName := ac.withAWSInfo("{infraID}-submariner-gw-sg")
 
-	gatewayGroupID, err := ac.getSecurityGroupID(vpcID, groupName)
+	gatewayGroupID, err := ac.recordedSecurityGroupID(ctx, vpcID, groupName)
 	if err != nil {
-		if isNotFoundError(err) {
-			return nil
-		}
-
 		return err
 	}
 
+	if gatewayGroupID == nil {
+		gatewayGroupID, err = ac.getSecurityGroupID(ctx, vpcID, groupName)
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
 	backoff := wait.Backoff{
 		Steps:    30,
 		Duration: 500 * time.Millisecond,
@@ -204,40 +308,49 @@ func (ac *awsCloud) deleteGatewaySG(vpcID string) error {
 	}
 
 	err = retry.OnError(backoff, gatewayDeletionRetriable, func() error {
-		_, err = ac.client.DeleteSecurityGroup(context.TODO(), &ec2.DeleteSecurityGroupInput{
+		_, err = ac.client.DeleteSecurityGroup(ctx, &ec2.DeleteSecurityGroupInput{
 			GroupId: gatewayGroupID,
 		})
 
 		return err // nolint:wrapcheck // Let the caller wrap it.
 	})
 
-	if isAWSError(err, "InvalidPermission.NotFound") {
-		return nil
+	if err != nil && !isAWSError(err, "InvalidPermission.NotFound") && !isAWSError(err, "InvalidGroup.NotFound") {
+		return errors.Wrap(err, "error deleting AWS security group")
 	}
 
-	return errors.Wrap(err, "error deleting AWS security group")
+	return ac.forgetSecurityGroup(ctx, gatewayGroupID, groupName, vpcID)
 }
 
-func (ac *awsCloud) revokePortsInCluster(vpcID string) error {
-	workerGroup, err := ac.getSecurityGroup(vpcID, "{infraID}-worker-sg")
+func (ac *awsCloud) revokePortsInCluster(ctx context.Context, vpcID string) error {
+	recorded, err := ac.recordedClusterRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(recorded) > 0 {
+		return ac.revokeRecordedRules(ctx, recorded)
+	}
+
+	workerGroup, err := ac.getSecurityGroup(ctx, vpcID, "{infraID}-worker-sg")
 	if err != nil {
 		return err
 	}
 
-	masterGroup, err := ac.getSecurityGroup(vpcID, "{infraID}-master-sg")
+	masterGroup, err := ac.getSecurityGroup(ctx, vpcID, "{infraID}-master-sg")
 	if err != nil {
 		return err
 	}
 
-	err = ac.revokePortsFromGroup(&workerGroup)
+	err = ac.revokePortsFromGroup(ctx, &workerGroup)
 	if err != nil {
 		return err
 	}
 
-	return ac.revokePortsFromGroup(&masterGroup)
+	return ac.revokePortsFromGroup(ctx, &masterGroup)
 }
 
-func (ac *awsCloud) revokePortsFromGroup(group *types.SecurityGroup) error {
+func (ac *awsCloud) revokePortsFromGroup(ctx context.Context, group *types.SecurityGroup) error {
 	var permissionsToRevoke []types.IpPermission
 
 	for _, permission := range group.IpPermissions {
@@ -258,7 +371,7 @@ func (ac *awsCloud) revokePortsFromGroup(group *types.SecurityGroup) error {
 		IpPermissions: permissionsToRevoke,
 	}
 
-	_, err := ac.client.RevokeSecurityGroupIngress(context.TODO(), input)
+	_, err := ac.client.RevokeSecurityGroupIngress(ctx, input)
 
 	return errors.Wrap(err, "error revoking AWS security group ingress")
 }
@@ -0,0 +1,219 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SetStateStore sets the store recording the resources provisioned on AWS, which are then cleaned up exactly.
+func (ac *awsCloud) SetStateStore(store api.StateStore) {
+	ac.stateStore = store
+}
+
+// SetStateStore sets the store recording the resources provisioned on AWS. It's shared with the cloud the deployer
+// was created for.
+func (d *ocpGatewayDeployer) SetStateStore(store api.StateStore) {
+	d.aws.stateStore = store
+}
+
+func ingressRuleResource(srcGroup, destGroup *string, port uint16, protocol string) api.Resource {
+	return api.Resource{
+		Type: api.ResourceIngressRule,
+		ID:   fmt.Sprintf("%s/%s/%d/%s", aws.ToString(destGroup), aws.ToString(srcGroup), port, protocol),
+		Parameters: map[string]string{
+			"group-id":     aws.ToString(destGroup),
+			"source-group": aws.ToString(srcGroup),
+			"port":         strconv.Itoa(int(port)),
+			"protocol":     protocol,
+		},
+	}
+}
+
+func publicIngressRuleResources(groupID *string, port api.PortSpec, cidrs []string) []api.Resource {
+	resources := []api.Resource{}
+
+	for _, cidr := range cidrs {
+		resources = append(resources, api.Resource{
+			Type: api.ResourceIngressRule,
+			ID:   fmt.Sprintf("%s/%s/%d/%s", aws.ToString(groupID), cidr, port.Port, port.Protocol),
+			Parameters: map[string]string{
+				"group-id":    aws.ToString(groupID),
+				"source-cidr": cidr,
+				"port":        strconv.Itoa(int(port.Port)),
+				"protocol":    port.Protocol,
+			},
+		})
+	}
+
+	return resources
+}
+
+func securityGroupResource(groupID *string, name, vpcID string) api.Resource {
+	return api.Resource{
+		Type: api.ResourceSecurityGroup,
+		ID:   aws.ToString(groupID),
+		Parameters: map[string]string{
+			"name":   name,
+			"vpc-id": vpcID,
+		},
+	}
+}
+
+func subnetTagResource(subnet *types.Subnet) api.Resource {
+	return api.Resource{
+		Type: api.ResourceSubnetTag,
+		ID:   aws.ToString(subnet.SubnetId),
+		Parameters: map[string]string{
+			"name": extractName(subnet.Tags),
+			"zone": aws.ToString(subnet.AvailabilityZone),
+		},
+	}
+}
+
+func machineSetResource(machineSet *unstructured.Unstructured) api.Resource {
+	return api.Resource{
+		Type:       api.ResourceMachineSet,
+		ID:         machineSet.GetName(),
+		Parameters: map[string]string{"namespace": machineSet.GetNamespace()},
+	}
+}
+
+func elasticIPResource(address *types.Address, subnetID string) api.Resource {
+	return api.Resource{
+		Type: api.ResourceFloatingIP,
+		ID:   aws.ToString(address.AllocationId),
+		Parameters: map[string]string{
+			"address":   aws.ToString(address.PublicIp),
+			"subnet-id": subnetID,
+		},
+	}
+}
+
+// revokeRecordedRules revokes exactly the recorded ingress rules.
+func (ac *awsCloud) revokeRecordedRules(ctx context.Context, recorded []api.Resource) error {
+	for i := range recorded {
+		port, err := strconv.ParseUint(recorded[i].Parameters["port"], 10, 16)
+		if err != nil {
+			return errors.Wrapf(err, "invalid port in the record of ingress rule %q", recorded[i].ID)
+		}
+
+		_, err = ac.client.RevokeSecurityGroupIngress(ctx, &ec2.RevokeSecurityGroupIngressInput{
+			GroupId: aws.String(recorded[i].Parameters["group-id"]),
+			IpPermissions: []types.IpPermission{{
+				FromPort:         aws.Int32(int32(port)),
+				ToPort:           aws.Int32(int32(port)),
+				IpProtocol:       aws.String(recorded[i].Parameters["protocol"]),
+				UserIdGroupPairs: []types.UserIdGroupPair{{GroupId: aws.String(recorded[i].Parameters["source-group"])}},
+			}},
+		})
+		if err != nil && !isAWSError(err, "InvalidPermission.NotFound") {
+			return errors.Wrapf(err, "error revoking AWS security group ingress %q", recorded[i].ID)
+		}
+
+		err = api.ForgetResources(ctx, ac.stateStore, recorded[i])
+		if err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	return nil
+}
+
+// recordedClusterRules returns the recorded ingress rules between the cluster's security groups, leaving out the
+// rules opening the public ports of the gateway security group.
+func (ac *awsCloud) recordedClusterRules(ctx context.Context) ([]api.Resource, error) {
+	recorded, err := api.RecordedResources(ctx, ac.stateStore, api.ResourceIngressRule)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	clusterRules := []api.Resource{}
+
+	for i := range recorded {
+		if recorded[i].Parameters["source-group"] != "" {
+			clusterRules = append(clusterRules, recorded[i])
+		}
+	}
+
+	return clusterRules, nil
+}
+
+// recordedSecurityGroupID returns the ID of the security group recorded with the given name in the VPC, or nil if
+// there's none.
+func (ac *awsCloud) recordedSecurityGroupID(ctx context.Context, vpcID, name string) (*string, error) {
+	recorded, err := api.RecordedResources(ctx, ac.stateStore, api.ResourceSecurityGroup)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	for i := range recorded {
+		if recorded[i].Parameters["name"] == name && recorded[i].Parameters["vpc-id"] == vpcID {
+			return aws.String(recorded[i].ID), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// forgetSecurityGroup forgets the recorded security group along with the ingress rules recorded in it.
+func (ac *awsCloud) forgetSecurityGroup(ctx context.Context, groupID *string, name, vpcID string) error {
+	recorded, err := api.RecordedResources(ctx, ac.stateStore, api.ResourceIngressRule)
+	if err != nil {
+		return err // nolint:wrapcheck // Already wrapped.
+	}
+
+	forgotten := []api.Resource{securityGroupResource(groupID, name, vpcID)}
+
+	for i := range recorded {
+		if recorded[i].Parameters["group-id"] == aws.ToString(groupID) {
+			forgotten = append(forgotten, recorded[i])
+		}
+	}
+
+	return api.ForgetResources(ctx, ac.stateStore, forgotten...)
+}
+
+// recordedGatewaySubnets returns the subnets recorded as tagged for gateways.
+func (ac *awsCloud) recordedGatewaySubnets(ctx context.Context) ([]types.Subnet, error) {
+	recorded, err := api.RecordedResources(ctx, ac.stateStore, api.ResourceSubnetTag)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	subnets := make([]types.Subnet, 0, len(recorded))
+
+	for i := range recorded {
+		subnets = append(subnets, types.Subnet{
+			SubnetId:         aws.String(recorded[i].ID),
+			AvailabilityZone: aws.String(recorded[i].Parameters["zone"]),
+			Tags:             []types.Tag{ec2Tag("Name", recorded[i].Parameters["name"])},
+		})
+	}
+
+	return subnets, nil
+}
@@ -0,0 +1,141 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	cloudprepareaws "github.com/submariner-io/cloud-prepare/pkg/aws"
+)
+
+var _ = Describe("AWS Cloud with a StateStore", func() {
+	var (
+		client  fakeAWSClientBase
+		dir     string
+		store   api.StateStore
+		cloud   api.Cloud
+		revoked []*ec2.RevokeSecurityGroupIngressInput
+	)
+
+	BeforeEach(func() {
+		client.beforeEach()
+
+		var err error
+
+		dir, err = ioutil.TempDir("", "state")
+		Expect(err).To(Succeed())
+
+		store = api.NewFileStateStore(filepath.Join(dir, "state.json"))
+		revoked = nil
+
+		client.expectDescribeVpcs(vpcID, sourceCIDR, accountID)
+		client.expectDescribeSecurityGroups(workerGroupID)
+
+		client.awsClient.EXPECT().AuthorizeSecurityGroupIngress(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.AuthorizeSecurityGroupIngressInput, _ ...func(*ec2.Options),
+			) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+				if isDryRun(input.DryRun) {
+					return nil, dryRunError()
+				}
+
+				return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+			}).AnyTimes()
+
+		client.awsClient.EXPECT().RevokeSecurityGroupIngress(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.RevokeSecurityGroupIngressInput, _ ...func(*ec2.Options),
+			) (*ec2.RevokeSecurityGroupIngressOutput, error) {
+				if isDryRun(input.DryRun) {
+					return nil, dryRunError()
+				}
+
+				revoked = append(revoked, input)
+
+				return &ec2.RevokeSecurityGroupIngressOutput{}, nil
+			}).AnyTimes()
+
+		cloud = cloudprepareaws.NewCloud(client.awsClient, infraID, region)
+		cloud.(api.StateRecorder).SetStateStore(store)
+	})
+
+	AfterEach(func() {
+		client.afterEach()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should record the ingress rules it authorizes and revoke exactly those on clean up", func() {
+		Expect(cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "udp"}},
+		}, api.NewLoggingReporter())).To(Succeed())
+
+		recorded, err := api.RecordedResources(context.TODO(), store, api.ResourceIngressRule)
+		Expect(err).To(Succeed())
+		Expect(recorded).To(HaveLen(1))
+
+		Expect(cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+
+		Expect(revoked).To(HaveLen(1))
+		Expect(revoked[0].GroupId).To(Equal(aws.String(workerGroupID)))
+		Expect(revoked[0].IpPermissions).To(Equal([]types.IpPermission{{
+			FromPort:         aws.Int32(4800),
+			ToPort:           aws.Int32(4800),
+			IpProtocol:       aws.String("udp"),
+			UserIdGroupPairs: []types.UserIdGroupPair{{GroupId: aws.String(workerGroupID)}},
+		}}))
+
+		recorded, err = api.RecordedResources(context.TODO(), store, api.ResourceIngressRule)
+		Expect(err).To(Succeed())
+		Expect(recorded).To(BeEmpty())
+	})
+
+	It("should leave the recorded public rules of the gateway security group alone on clean up", func() {
+		publicRule := api.Resource{
+			Type: api.ResourceIngressRule,
+			ID:   gatewayGroupID + "/0.0.0.0/0/4500/udp",
+			Parameters: map[string]string{
+				"group-id":    gatewayGroupID,
+				"source-cidr": api.AnyIPv4CIDR,
+				"port":        "4500",
+				"protocol":    "udp",
+			},
+		}
+
+		Expect(api.RecordResources(context.TODO(), store, publicRule)).To(Succeed())
+
+		Expect(cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "udp"}},
+		}, api.NewLoggingReporter())).To(Succeed())
+		Expect(cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+
+		Expect(revoked).To(HaveLen(1))
+		Expect(revoked[0].GroupId).To(Equal(aws.String(workerGroupID)))
+
+		recorded, err := api.RecordedResources(context.TODO(), store, api.ResourceIngressRule)
+		Expect(err).To(Succeed())
+		Expect(recorded).To(Equal([]api.Resource{publicRule}))
+	})
+})
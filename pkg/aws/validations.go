@@ -39,7 +39,7 @@ func determinePermissionError(err error, operation string) error {
 	return errors.Wrapf(err, "error while checking permissions for %s", operation)
 }
 
-func (ac *awsCloud) validateCreateSecGroup(vpcID string) error {
+func (ac *awsCloud) validateCreateSecGroup(ctx context.Context, vpcID string) error {
 	input := &ec2.CreateSecurityGroupInput{
 		DryRun:      aws.Bool(true),
 		GroupName:   aws.String(permissionsTest),
@@ -47,13 +47,13 @@ func (ac *awsCloud) validateCreateSecGroup(vpcID string) error {
 		VpcId:       aws.String(vpcID),
 	}
 
-	_, err := ac.client.CreateSecurityGroup(context.TODO(), input)
+	_, err := ac.client.CreateSecurityGroup(ctx, input)
 
 	return determinePermissionError(err, "create security group")
 }
 
-func (ac *awsCloud) validateCreateSecGroupRule(vpcID string) error {
-	workerGroupID, err := ac.getSecurityGroupID(vpcID, "{infraID}-worker-sg")
+func (ac *awsCloud) validateCreateSecGroupRule(ctx context.Context, vpcID string) error {
+	workerGroupID, err := ac.getSecurityGroupID(ctx, vpcID, "{infraID}-worker-sg")
 	if err != nil {
 		return err
 	}
@@ -63,13 +63,13 @@ func (ac *awsCloud) validateCreateSecGroupRule(vpcID string) error {
 		GroupId: workerGroupID,
 	}
 
-	_, err = ac.client.AuthorizeSecurityGroupIngress(context.TODO(), input)
+	_, err = ac.client.AuthorizeSecurityGroupIngress(ctx, input)
 
 	return determinePermissionError(err, "authorize security group ingress")
 }
 
-func (ac *awsCloud) validateCreateTag(subnetID string) error {
-	_, err := ac.client.CreateTags(context.TODO(), &ec2.CreateTagsInput{
+func (ac *awsCloud) validateCreateTag(ctx context.Context, subnetID string) error {
+	_, err := ac.client.CreateTags(ctx, &ec2.CreateTagsInput{
 		DryRun:    aws.Bool(true),
 		Resources: []string{subnetID},
 		Tags: []types.Tag{
@@ -80,16 +80,22 @@ func (ac *awsCloud) validateCreateTag(subnetID string) error {
 	return determinePermissionError(err, "create tags on subnets")
 }
 
-func (ac *awsCloud) validateDescribeInstanceTypeOfferings() error {
-	_, err := ac.client.DescribeInstanceTypeOfferings(context.TODO(), &ec2.DescribeInstanceTypeOfferingsInput{
+func (ac *awsCloud) validateDescribeInstanceTypeOfferings(ctx context.Context) error {
+	_, err := ac.client.DescribeInstanceTypeOfferings(ctx, &ec2.DescribeInstanceTypeOfferingsInput{
 		DryRun: aws.Bool(true),
 	})
 
 	return determinePermissionError(err, "describe instance type offerings")
 }
 
-func (ac *awsCloud) validateDeleteSecGroup(vpcID string) error {
-	workerGroupID, err := ac.getSecurityGroupID(vpcID, "{infraID}-worker-sg")
+func (ac *awsCloud) validateCreateVpcPeering(ctx context.Context, input *ec2.CreateVpcPeeringConnectionInput) error {
+	_, err := ac.client.CreateVpcPeeringConnection(ctx, input)
+
+	return determinePermissionError(err, "create VPC peering")
+}
+
+func (ac *awsCloud) validateDeleteSecGroup(ctx context.Context, vpcID string) error {
+	workerGroupID, err := ac.getSecurityGroupID(ctx, vpcID, "{infraID}-worker-sg")
 	if err != nil {
 		return err
 	}
@@ -99,13 +105,13 @@ func (ac *awsCloud) validateDeleteSecGroup(vpcID string) error {
 		GroupId: workerGroupID,
 	}
 
-	_, err = ac.client.DeleteSecurityGroup(context.TODO(), input)
+	_, err = ac.client.DeleteSecurityGroup(ctx, input)
 
 	return determinePermissionError(err, "delete security group")
 }
 
-func (ac *awsCloud) validateDeleteSecGroupRule(vpcID string) error {
-	workerGroupID, err := ac.getSecurityGroupID(vpcID, "{infraID}-worker-sg")
+func (ac *awsCloud) validateDeleteSecGroupRule(ctx context.Context, vpcID string) error {
+	workerGroupID, err := ac.getSecurityGroupID(ctx, vpcID, "{infraID}-worker-sg")
 	if err != nil {
 		return err
 	}
@@ -115,13 +121,13 @@ func (ac *awsCloud) validateDeleteSecGroupRule(vpcID string) error {
 		GroupId: workerGroupID,
 	}
 
-	_, err = ac.client.RevokeSecurityGroupIngress(context.TODO(), input)
+	_, err = ac.client.RevokeSecurityGroupIngress(ctx, input)
 
 	return determinePermissionError(err, "revoke security group ingress")
 }
 
-func (ac *awsCloud) validateRemoveTag(subnetID *string) error {
-	_, err := ac.client.DeleteTags(context.TODO(), &ec2.DeleteTagsInput{
+func (ac *awsCloud) validateRemoveTag(ctx context.Context, subnetID *string) error {
+	_, err := ac.client.DeleteTags(ctx, &ec2.DeleteTagsInput{
 		DryRun:    aws.Bool(true),
 		Resources: []string{*subnetID},
 		Tags: []types.Tag{
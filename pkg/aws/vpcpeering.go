@@ -0,0 +1,554 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const peeringTraffic = "Submariner peering traffic"
+
+var (
+	peeringPollInterval = 5 * time.Second
+	peeringWaitTimeout  = 5 * time.Minute
+)
+
+// peeringPorts are opened between peered VPCs so that the gateways can reach each other over their private IPs.
+var peeringPorts = []api.PortSpec{
+	{Port: 500, Protocol: "udp"},
+	{Port: 4490, Protocol: "udp"},
+	{Port: 4500, Protocol: "udp"},
+	// ESP & AH protocols are used for private-ip to private-ip gateway communications
+	{Port: 0, Protocol: "50"},
+	{Port: 0, Protocol: "51"},
+}
+
+func (ac *awsCloud) createVpcPeering(ctx context.Context, target *awsCloud, reporter api.Reporter) error {
+	reporter.Started("Retrieving the VPCs to peer")
+
+	sourceVpc, err := ac.getVpc(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	targetVpc, err := target.getVpc(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Retrieved VPCs %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+
+	reporter.Started(messageValidatePrerequisites)
+
+	err = ac.validatePeeringPrerequisites(ctx, target, sourceVpc, targetVpc)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded(messageValidatedPrerequisites)
+
+	reporter.Started("Requesting VPC peering between %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+
+	peering, err := ac.requestVpcPeering(ctx, target, sourceVpc, targetVpc)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	peeringID := peering.VpcPeeringConnectionId
+
+	reporter.Succeeded("Requested VPC peering %s", *peeringID)
+
+	reporter.Started("Waiting for VPC peering %s to be ready for acceptance", *peeringID)
+
+	peering, err = target.waitForVpcPeering(ctx, peeringID, types.VpcPeeringConnectionStateReasonCodePendingAcceptance,
+		types.VpcPeeringConnectionStateReasonCodeProvisioning, types.VpcPeeringConnectionStateReasonCodeActive)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("VPC peering %s is ready for acceptance", *peeringID)
+
+	if peering.Status.Code == types.VpcPeeringConnectionStateReasonCodePendingAcceptance {
+		reporter.Started("Accepting VPC peering %s", *peeringID)
+
+		err = target.acceptVpcPeering(ctx, peeringID)
+		if err != nil {
+			reporter.Failed(err)
+			return err
+		}
+
+		reporter.Succeeded("Accepted VPC peering %s", *peeringID)
+	}
+
+	reporter.Started("Waiting for VPC peering %s to become active", *peeringID)
+
+	_, err = ac.waitForVpcPeering(ctx, peeringID, types.VpcPeeringConnectionStateReasonCodeActive)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("VPC peering %s is active", *peeringID)
+
+	reporter.Started("Adding routes through VPC peering %s", *peeringID)
+
+	err = ac.createRoutesForPeering(ctx, *sourceVpc.VpcId, *targetVpc.CidrBlock, peeringID)
+	if err == nil {
+		err = target.createRoutesForPeering(ctx, *targetVpc.VpcId, *sourceVpc.CidrBlock, peeringID)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Added routes through VPC peering %s", *peeringID)
+
+	reporter.Started("Opening Submariner ports between %s and %s", *sourceVpc.CidrBlock, *targetVpc.CidrBlock)
+
+	err = ac.allowPeeringTraffic(ctx, *sourceVpc.VpcId, *targetVpc.CidrBlock)
+	if err == nil {
+		err = target.allowPeeringTraffic(ctx, *targetVpc.VpcId, *sourceVpc.CidrBlock)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Opened Submariner ports between %s and %s", *sourceVpc.CidrBlock, *targetVpc.CidrBlock)
+
+	return nil
+}
+
+func (ac *awsCloud) cleanupVpcPeering(ctx context.Context, target *awsCloud, reporter api.Reporter) error {
+	reporter.Started("Retrieving the peered VPCs")
+
+	sourceVpc, err := ac.getVpc(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	targetVpc, err := target.getVpc(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Retrieved VPCs %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+
+	reporter.Started("Revoking Submariner ports between %s and %s", *sourceVpc.CidrBlock, *targetVpc.CidrBlock)
+
+	err = ac.revokePeeringTraffic(ctx, *sourceVpc.VpcId, *targetVpc.CidrBlock)
+	if err == nil {
+		err = target.revokePeeringTraffic(ctx, *targetVpc.VpcId, *sourceVpc.CidrBlock)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Revoked Submariner ports between %s and %s", *sourceVpc.CidrBlock, *targetVpc.CidrBlock)
+
+	reporter.Started("Removing the routes between %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+
+	err = ac.deleteRoutesForPeering(ctx, *sourceVpc.VpcId, *targetVpc.CidrBlock)
+	if err == nil {
+		err = target.deleteRoutesForPeering(ctx, *targetVpc.VpcId, *sourceVpc.CidrBlock)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Removed the routes between %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+
+	reporter.Started("Deleting VPC peering between %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+
+	peerings, err := ac.findVpcPeerings(ctx, *sourceVpc.VpcId, *targetVpc.VpcId)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	for i := range peerings {
+		err = ac.deleteVpcPeering(ctx, peerings[i].VpcPeeringConnectionId)
+		if err != nil {
+			reporter.Failed(err)
+			return err
+		}
+	}
+
+	reporter.Succeeded("Deleted VPC peering between %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+
+	return nil
+}
+
+func (ac *awsCloud) validatePeeringPrerequisites(ctx context.Context, target *awsCloud, sourceVpc, targetVpc *types.Vpc) error {
+	overlap, err := cidrsOverlap(*sourceVpc.CidrBlock, *targetVpc.CidrBlock)
+	if err != nil {
+		return err
+	}
+
+	if overlap {
+		return fmt.Errorf("the CIDR %s of VPC %s overlaps with the CIDR %s of VPC %s",
+			*sourceVpc.CidrBlock, *sourceVpc.VpcId, *targetVpc.CidrBlock, *targetVpc.VpcId)
+	}
+
+	input := ac.newVpcPeeringInput(target, sourceVpc, targetVpc)
+	input.DryRun = aws.Bool(true)
+
+	return ac.validateCreateVpcPeering(ctx, input)
+}
+
+func cidrsOverlap(cidr1, cidr2 string) (bool, error) {
+	_, net1, err := net.ParseCIDR(cidr1)
+	if err != nil {
+		return false, errors.Wrapf(err, "error parsing CIDR %q", cidr1)
+	}
+
+	_, net2, err := net.ParseCIDR(cidr2)
+	if err != nil {
+		return false, errors.Wrapf(err, "error parsing CIDR %q", cidr2)
+	}
+
+	return net1.Contains(net2.IP) || net2.Contains(net1.IP), nil
+}
+
+func (ac *awsCloud) requestVpcPeering(ctx context.Context, target *awsCloud, sourceVpc, targetVpc *types.Vpc,
+) (*types.VpcPeeringConnection, error) {
+	peering, err := ac.getVpcPeering(ctx, *sourceVpc.VpcId, *targetVpc.VpcId)
+	if err == nil || !isNotFoundError(err) {
+		return peering, err
+	}
+
+	input := ac.newVpcPeeringInput(target, sourceVpc, targetVpc)
+
+	result, err := ac.client.CreateVpcPeeringConnection(ctx, input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating VPC peering between %s and %s", *sourceVpc.VpcId, *targetVpc.VpcId)
+	}
+
+	return result.VpcPeeringConnection, nil
+}
+
+// newVpcPeeringInput creates the request to peer the source VPC with the target VPC, which may be in another
+// region or owned by another account.
+func (ac *awsCloud) newVpcPeeringInput(target *awsCloud, sourceVpc, targetVpc *types.Vpc) *ec2.CreateVpcPeeringConnectionInput {
+	peeringName := fmt.Sprintf("%s-%s-peering", ac.infraID, target.infraID)
+
+	input := &ec2.CreateVpcPeeringConnectionInput{
+		VpcId:     sourceVpc.VpcId,
+		PeerVpcId: targetVpc.VpcId,
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeVpcPeeringConnection,
+				Tags: []types.Tag{
+					ec2Tag("Name", peeringName),
+					ec2Tag(ac.withAWSInfo("kubernetes.io/cluster/{infraID}"), "owned"),
+				},
+			},
+		},
+	}
+
+	if target.region != ac.region {
+		input.PeerRegion = aws.String(target.region)
+	}
+
+	if targetVpc.OwnerId != nil && (sourceVpc.OwnerId == nil || *targetVpc.OwnerId != *sourceVpc.OwnerId) {
+		input.PeerOwnerId = targetVpc.OwnerId
+	}
+
+	return input
+}
+
+func (ac *awsCloud) acceptVpcPeering(ctx context.Context, peeringID *string) error {
+	_, err := ac.client.AcceptVpcPeeringConnection(ctx, &ec2.AcceptVpcPeeringConnectionInput{
+		VpcPeeringConnectionId: peeringID,
+	})
+
+	return errors.Wrapf(err, "error accepting VPC peering %s", *peeringID)
+}
+
+func (ac *awsCloud) deleteVpcPeering(ctx context.Context, peeringID *string) error {
+	_, err := ac.client.DeleteVpcPeeringConnection(ctx, &ec2.DeleteVpcPeeringConnectionInput{
+		VpcPeeringConnectionId: peeringID,
+	})
+	if isAWSError(err, "InvalidVpcPeeringConnectionID.NotFound") {
+		return nil
+	}
+
+	return errors.Wrapf(err, "error deleting VPC peering %s", *peeringID)
+}
+
+// waitForVpcPeering waits until the VPC peering, as seen from this cloud, reaches one of the given states.
+func (ac *awsCloud) waitForVpcPeering(ctx context.Context, peeringID *string, states ...types.VpcPeeringConnectionStateReasonCode,
+) (*types.VpcPeeringConnection, error) {
+	var peering *types.VpcPeeringConnection
+
+	ctx, cancel := context.WithTimeout(ctx, peeringWaitTimeout)
+	defer cancel()
+
+	err := wait.PollImmediateUntil(peeringPollInterval, func() (bool, error) {
+		result, err := ac.client.DescribeVpcPeeringConnections(ctx, &ec2.DescribeVpcPeeringConnectionsInput{
+			VpcPeeringConnectionIds: []string{*peeringID},
+		})
+
+		// A peering requested from another region or account may not be visible yet.
+		if isAWSError(err, "InvalidVpcPeeringConnectionID.NotFound") {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, errors.Wrap(err, "error describing AWS VPC peerings")
+		}
+
+		if len(result.VpcPeeringConnections) == 0 || result.VpcPeeringConnections[0].Status == nil {
+			return false, nil
+		}
+
+		status := result.VpcPeeringConnections[0].Status
+
+		switch status.Code { // nolint:exhaustive // The remaining states are transient.
+		case types.VpcPeeringConnectionStateReasonCodeFailed, types.VpcPeeringConnectionStateReasonCodeRejected,
+			types.VpcPeeringConnectionStateReasonCodeExpired, types.VpcPeeringConnectionStateReasonCodeDeleted:
+			return false, fmt.Errorf("VPC peering %s is %s: %s", *peeringID, status.Code, aws.ToString(status.Message))
+		}
+
+		for _, state := range states {
+			if status.Code == state {
+				peering = &result.VpcPeeringConnections[0]
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}, ctx.Done())
+
+	return peering, errors.Wrapf(err, "error waiting for VPC peering %s", *peeringID)
+}
+
+// getVpcPeering returns the live VPC peering requested from the source VPC to the target VPC.
+func (ac *awsCloud) getVpcPeering(ctx context.Context, sourceVpcID, targetVpcID string) (*types.VpcPeeringConnection, error) {
+	result, err := ac.client.DescribeVpcPeeringConnections(ctx, &ec2.DescribeVpcPeeringConnectionsInput{
+		Filters: []types.Filter{
+			ec2Filter("requester-vpc-info.vpc-id", sourceVpcID),
+			ec2Filter("accepter-vpc-info.vpc-id", targetVpcID),
+			{
+				Name: aws.String("status-code"),
+				Values: []string{
+					string(types.VpcPeeringConnectionStateReasonCodeInitiatingRequest),
+					string(types.VpcPeeringConnectionStateReasonCodePendingAcceptance),
+					string(types.VpcPeeringConnectionStateReasonCodeProvisioning),
+					string(types.VpcPeeringConnectionStateReasonCodeActive),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error describing AWS VPC peerings")
+	}
+
+	if len(result.VpcPeeringConnections) == 0 {
+		return nil, newNotFoundError("VPC peering between %s and %s", sourceVpcID, targetVpcID)
+	}
+
+	return &result.VpcPeeringConnections[0], nil
+}
+
+// findVpcPeerings returns the live VPC peerings between the given VPCs, regardless of which one requested them.
+func (ac *awsCloud) findVpcPeerings(ctx context.Context, vpcID1, vpcID2 string) ([]types.VpcPeeringConnection, error) {
+	peerings := []types.VpcPeeringConnection{}
+
+	for _, vpcIDs := range [][]string{{vpcID1, vpcID2}, {vpcID2, vpcID1}} {
+		peering, err := ac.getVpcPeering(ctx, vpcIDs[0], vpcIDs[1])
+		if isNotFoundError(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		peerings = append(peerings, *peering)
+	}
+
+	return peerings, nil
+}
+
+func (ac *awsCloud) getRouteTables(ctx context.Context, vpcID string) ([]types.RouteTable, error) {
+	result, err := ac.client.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{
+		Filters: []types.Filter{
+			ec2Filter("vpc-id", vpcID),
+			ac.filterByCurrentCluster(),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error describing AWS route tables")
+	}
+
+	return result.RouteTables, nil
+}
+
+func (ac *awsCloud) createRoutesForPeering(ctx context.Context, vpcID, peerCIDR string, peeringID *string) error {
+	routeTables, err := ac.getRouteTables(ctx, vpcID)
+	if err != nil {
+		return err
+	}
+
+	for i := range routeTables {
+		if hasPeeringRoute(&routeTables[i], peerCIDR, peeringID) {
+			continue
+		}
+
+		_, err = ac.client.CreateRoute(ctx, &ec2.CreateRouteInput{
+			RouteTableId:           routeTables[i].RouteTableId,
+			DestinationCidrBlock:   aws.String(peerCIDR),
+			VpcPeeringConnectionId: peeringID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error creating route to %s in route table %s", peerCIDR, *routeTables[i].RouteTableId)
+		}
+	}
+
+	return nil
+}
+
+func (ac *awsCloud) deleteRoutesForPeering(ctx context.Context, vpcID, peerCIDR string) error {
+	routeTables, err := ac.getRouteTables(ctx, vpcID)
+	if err != nil {
+		return err
+	}
+
+	for i := range routeTables {
+		if !hasPeeringRoute(&routeTables[i], peerCIDR, nil) {
+			continue
+		}
+
+		_, err = ac.client.DeleteRoute(ctx, &ec2.DeleteRouteInput{
+			RouteTableId:         routeTables[i].RouteTableId,
+			DestinationCidrBlock: aws.String(peerCIDR),
+		})
+		if err != nil && !isAWSError(err, "InvalidRoute.NotFound") {
+			return errors.Wrapf(err, "error deleting route to %s from route table %s", peerCIDR, *routeTables[i].RouteTableId)
+		}
+	}
+
+	return nil
+}
+
+// hasPeeringRoute checks if the route table routes the given CIDR through a VPC peering,
+// and through the given one if specified.
+func hasPeeringRoute(routeTable *types.RouteTable, cidr string, peeringID *string) bool {
+	for i := range routeTable.Routes {
+		route := &routeTable.Routes[i]
+		if route.DestinationCidrBlock == nil || *route.DestinationCidrBlock != cidr || route.VpcPeeringConnectionId == nil {
+			continue
+		}
+
+		if peeringID == nil || *route.VpcPeeringConnectionId == *peeringID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ac *awsCloud) allowPeeringTraffic(ctx context.Context, vpcID, peerCIDR string) error {
+	workerGroupID, err := ac.getSecurityGroupID(ctx, vpcID, "{infraID}-worker-sg")
+	if err != nil {
+		return err
+	}
+
+	for _, port := range peeringPorts {
+		err = ac.authorizeSecurityGroupIngress(ctx, workerGroupID, []types.IpPermission{
+			{
+				FromPort:   aws.Int32(int32(port.Port)),
+				ToPort:     aws.Int32(int32(port.Port)),
+				IpProtocol: aws.String(port.Protocol),
+				IpRanges: []types.IpRange{
+					{
+						CidrIp:      aws.String(peerCIDR),
+						Description: aws.String(fmt.Sprintf("%s from %s", peeringTraffic, peerCIDR)),
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ac *awsCloud) revokePeeringTraffic(ctx context.Context, vpcID, peerCIDR string) error {
+	workerGroup, err := ac.getSecurityGroup(ctx, vpcID, "{infraID}-worker-sg")
+	if err != nil {
+		return err
+	}
+
+	var permissionsToRevoke []types.IpPermission
+
+	for i := range workerGroup.IpPermissions {
+		permission := workerGroup.IpPermissions[i]
+
+		for _, ipRange := range permission.IpRanges {
+			if ipRange.CidrIp != nil && *ipRange.CidrIp == peerCIDR &&
+				ipRange.Description != nil && strings.Contains(*ipRange.Description, peeringTraffic) {
+				permission.IpRanges = []types.IpRange{ipRange}
+				permission.UserIdGroupPairs = nil
+				permission.Ipv6Ranges = nil
+				permission.PrefixListIds = nil
+				permissionsToRevoke = append(permissionsToRevoke, permission)
+
+				break
+			}
+		}
+	}
+
+	if len(permissionsToRevoke) == 0 {
+		return nil
+	}
+
+	_, err = ac.client.RevokeSecurityGroupIngress(ctx, &ec2.RevokeSecurityGroupIngressInput{
+		GroupId:       workerGroup.GroupId,
+		IpPermissions: permissionsToRevoke,
+	})
+
+	return errors.Wrap(err, "error revoking AWS security group ingress")
+}
@@ -0,0 +1,447 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	cloudprepareaws "github.com/submariner-io/cloud-prepare/pkg/aws"
+)
+
+const (
+	sourceCIDR     = "10.0.0.0/16"
+	targetCIDR     = "10.1.0.0/16"
+	peeringID      = "test-peering"
+	routeTableID   = "test-route-table"
+	targetGroupID  = "test-target-worker-group"
+	accountID      = "test-account"
+	targetAccount  = "test-target-account"
+	targetRegionID = "test-target-region"
+)
+
+var _ = Describe("AWS Peering", func() {
+	Context("CreateVpcPeering", testCreateVpcPeering)
+	Context("CleanupVpcPeering", testCleanupVpcPeering)
+})
+
+func testCreateVpcPeering() {
+	t := newPeeringTestDriver()
+
+	var retError error
+
+	JustBeforeEach(func() {
+		retError = t.source.cloud.CreateVpcPeering(t.targetCloud, api.NewLoggingReporter())
+	})
+
+	When("there is no existing VPC peering", func() {
+		var request *ec2.CreateVpcPeeringConnectionInput
+
+		BeforeEach(func() {
+			request = nil
+			t.expectCreatePeering(&request)
+			t.expectAcceptPeering()
+		})
+
+		It("should create and accept the peering, add the routes and open the ports", func() {
+			Expect(retError).To(Succeed())
+			Expect(request.PeerRegion).To(BeNil())
+			Expect(request.PeerOwnerId).To(BeNil())
+			Expect(t.peerings[0].Status.Code).To(Equal(types.VpcPeeringConnectionStateReasonCodeActive))
+			Expect(t.source.routes).To(Equal(map[string]string{routeTableID: targetCIDR}))
+			Expect(t.target.routes).To(Equal(map[string]string{routeTableID: sourceCIDR}))
+			t.source.assertPeeringIngress(targetCIDR)
+			t.target.assertPeeringIngress(sourceCIDR)
+		})
+
+		Context("and the target cloud is in another region", func() {
+			BeforeEach(func() {
+				t.targetRegion = targetRegionID
+			})
+
+			It("should request the peering in the target region", func() {
+				Expect(retError).To(Succeed())
+				Expect(request.PeerRegion).To(Equal(aws.String(targetRegionID)))
+				Expect(request.PeerOwnerId).To(BeNil())
+			})
+		})
+
+		Context("and the target cloud is in another account", func() {
+			BeforeEach(func() {
+				t.targetOwner = targetAccount
+			})
+
+			It("should request the peering from the target account", func() {
+				Expect(retError).To(Succeed())
+				Expect(request.PeerRegion).To(BeNil())
+				Expect(request.PeerOwnerId).To(Equal(aws.String(targetAccount)))
+			})
+		})
+	})
+
+	When("the VPC peering is already active", func() {
+		BeforeEach(func() {
+			t.peerings = []types.VpcPeeringConnection{newPeering(types.VpcPeeringConnectionStateReasonCodeActive)}
+			t.source.routeTables[0].Routes = []types.Route{newPeeringRoute(targetCIDR)}
+			t.target.routeTables[0].Routes = []types.Route{newPeeringRoute(sourceCIDR)}
+			t.expectDryRunCreatePeering()
+		})
+
+		It("should not recreate the peering nor the routes", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.source.routes).To(BeEmpty())
+			Expect(t.target.routes).To(BeEmpty())
+		})
+	})
+
+	When("the VPC peering is rejected", func() {
+		BeforeEach(func() {
+			t.peerings = []types.VpcPeeringConnection{newPeering(types.VpcPeeringConnectionStateReasonCodeRejected)}
+			t.expectDryRunCreatePeering()
+			t.expectCreatePeering(nil)
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+			Expect(t.source.routes).To(BeEmpty())
+		})
+	})
+
+	When("the VPC CIDRs overlap", func() {
+		BeforeEach(func() {
+			t.targetCIDR = "10.0.1.0/24"
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+
+	When("the peering creation fails", func() {
+		BeforeEach(func() {
+			t.source.awsClient.EXPECT().CreateVpcPeeringConnection(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, input *ec2.CreateVpcPeeringConnectionInput, _ ...func(*ec2.Options),
+				) (*ec2.CreateVpcPeeringConnectionOutput, error) {
+					if isDryRun(input.DryRun) {
+						return nil, dryRunError()
+					}
+
+					return nil, errors.New("fake create error")
+				}).Times(2)
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+
+	When("called with a non-AWS Cloud", func() {
+		BeforeEach(func() {
+			t.targetCloud = &invalidCloud{}
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testCleanupVpcPeering() {
+	t := newPeeringTestDriver()
+
+	var retError error
+
+	JustBeforeEach(func() {
+		retError = t.source.cloud.CleanupVpcPeering(t.targetCloud, api.NewLoggingReporter())
+	})
+
+	When("the VPCs are peered", func() {
+		var deleted []string
+
+		BeforeEach(func() {
+			deleted = []string{}
+
+			t.peerings = []types.VpcPeeringConnection{newPeering(types.VpcPeeringConnectionStateReasonCodeActive)}
+			t.source.routeTables[0].Routes = []types.Route{newPeeringRoute(targetCIDR)}
+			t.target.routeTables[0].Routes = []types.Route{newPeeringRoute(sourceCIDR)}
+			t.source.workerPermissions = newPeeringPermissions(targetCIDR)
+			t.target.workerPermissions = newPeeringPermissions(sourceCIDR)
+
+			t.source.awsClient.EXPECT().DeleteVpcPeeringConnection(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, input *ec2.DeleteVpcPeeringConnectionInput, _ ...func(*ec2.Options),
+				) (*ec2.DeleteVpcPeeringConnectionOutput, error) {
+					deleted = append(deleted, *input.VpcPeeringConnectionId)
+					return &ec2.DeleteVpcPeeringConnectionOutput{}, nil
+				})
+			t.source.expectRevokeIngress(workerGroupID, targetCIDR)
+			t.target.expectRevokeIngress(targetGroupID, sourceCIDR)
+			t.source.expectDeleteRoute(targetCIDR)
+			t.target.expectDeleteRoute(sourceCIDR)
+		})
+
+		It("should remove the peering, the routes and the ports", func() {
+			Expect(retError).To(Succeed())
+			Expect(deleted).To(Equal([]string{peeringID}))
+		})
+	})
+
+	When("the VPCs are not peered", func() {
+		It("should succeed without changes", func() {
+			Expect(retError).To(Succeed())
+		})
+	})
+
+	When("called with a non-AWS Cloud", func() {
+		BeforeEach(func() {
+			t.targetCloud = &invalidCloud{}
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+type peeringCloudDriver struct {
+	fakeAWSClientBase
+	cloud             api.Cloud
+	routeTables       []types.RouteTable
+	routes            map[string]string
+	ingress           []types.IpPermission
+	workerPermissions []types.IpPermission
+}
+
+// peeringTestDriver simulates two clouds, each with its own client, sharing the view of the VPC peerings.
+type peeringTestDriver struct {
+	source       peeringCloudDriver
+	target       peeringCloudDriver
+	targetCloud  api.Cloud
+	targetCIDR   string
+	targetRegion string
+	targetOwner  string
+	peerings     []types.VpcPeeringConnection
+}
+
+func newPeeringTestDriver() *peeringTestDriver {
+	t := &peeringTestDriver{}
+
+	BeforeEach(func() {
+		t.targetCIDR = targetCIDR
+		t.targetRegion = region
+		t.targetOwner = accountID
+		t.targetCloud = nil
+		t.peerings = nil
+
+		for _, d := range []*peeringCloudDriver{&t.source, &t.target} {
+			d.beforeEach()
+			d.routes = map[string]string{}
+			d.ingress = nil
+			d.workerPermissions = nil
+			d.routeTables = []types.RouteTable{{RouteTableId: aws.String(routeTableID)}}
+		}
+	})
+
+	JustBeforeEach(func() {
+		t.source.cloud = cloudprepareaws.NewCloud(t.source.awsClient, infraID, region)
+		t.target.cloud = cloudprepareaws.NewCloud(t.target.awsClient, targetInfraID, t.targetRegion)
+
+		if t.targetCloud == nil {
+			t.targetCloud = t.target.cloud
+		}
+
+		t.source.expectDescribeVpcs(vpcID, sourceCIDR, accountID)
+		t.target.expectDescribeVpcs(targetVpcID, t.targetCIDR, t.targetOwner)
+
+		t.source.justBeforeEach(workerGroupID)
+		t.target.justBeforeEach(targetGroupID)
+
+		t.expectDescribePeerings(&t.source)
+		t.expectDescribePeerings(&t.target)
+	})
+
+	AfterEach(func() {
+		t.source.afterEach()
+		t.target.afterEach()
+	})
+
+	return t
+}
+
+func (d *peeringCloudDriver) justBeforeEach(groupID string) {
+	d.expectDescribeSecurityGroups(groupID, d.workerPermissions...)
+	d.expectDescribeRouteTables(d.routeTables...)
+
+	d.awsClient.EXPECT().CreateRoute(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateRouteInput, _ ...func(*ec2.Options)) (*ec2.CreateRouteOutput, error) {
+			Expect(*input.VpcPeeringConnectionId).To(Equal(peeringID))
+			d.routes[*input.RouteTableId] = *input.DestinationCidrBlock
+
+			return &ec2.CreateRouteOutput{}, nil
+		}).AnyTimes()
+
+	d.awsClient.EXPECT().AuthorizeSecurityGroupIngress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.AuthorizeSecurityGroupIngressInput, _ ...func(*ec2.Options),
+		) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+			d.ingress = append(d.ingress, input.IpPermissions...)
+			return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+		}).AnyTimes()
+}
+
+func (t *peeringTestDriver) expectDescribePeerings(d *peeringCloudDriver) {
+	d.awsClient.EXPECT().DescribeVpcPeeringConnections(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeVpcPeeringConnectionsInput, _ ...func(*ec2.Options),
+		) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+			output := &ec2.DescribeVpcPeeringConnectionsOutput{}
+
+			for i := range t.peerings {
+				if len(input.VpcPeeringConnectionIds) > 0 {
+					if *t.peerings[i].VpcPeeringConnectionId == input.VpcPeeringConnectionIds[0] {
+						output.VpcPeeringConnections = append(output.VpcPeeringConnections, t.peerings[i])
+					}
+
+					continue
+				}
+
+				if *t.peerings[i].RequesterVpcInfo.VpcId == filterValue(input.Filters, "requester-vpc-info.vpc-id") &&
+					t.peerings[i].Status.Code != types.VpcPeeringConnectionStateReasonCodeRejected {
+					output.VpcPeeringConnections = append(output.VpcPeeringConnections, t.peerings[i])
+				}
+			}
+
+			return output, nil
+		}).AnyTimes()
+}
+
+// nolint:gocritic // Error: "consider `request' to be of non-pointer type"
+func (t *peeringTestDriver) expectCreatePeering(request **ec2.CreateVpcPeeringConnectionInput) {
+	t.source.awsClient.EXPECT().CreateVpcPeeringConnection(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateVpcPeeringConnectionInput, _ ...func(*ec2.Options),
+		) (*ec2.CreateVpcPeeringConnectionOutput, error) {
+			Expect(*input.VpcId).To(Equal(vpcID))
+			Expect(*input.PeerVpcId).To(Equal(targetVpcID))
+
+			if isDryRun(input.DryRun) {
+				return nil, dryRunError()
+			}
+
+			if request != nil {
+				*request = input
+			}
+
+			if len(t.peerings) == 0 {
+				t.peerings = []types.VpcPeeringConnection{newPeering(types.VpcPeeringConnectionStateReasonCodePendingAcceptance)}
+			}
+
+			return &ec2.CreateVpcPeeringConnectionOutput{VpcPeeringConnection: &t.peerings[0]}, nil
+		}).MinTimes(1).MaxTimes(2)
+}
+
+func (t *peeringTestDriver) expectAcceptPeering() {
+	t.target.awsClient.EXPECT().AcceptVpcPeeringConnection(gomock.Any(), &ec2.AcceptVpcPeeringConnectionInput{
+		VpcPeeringConnectionId: aws.String(peeringID),
+	}).DoAndReturn(func(_ context.Context, _ *ec2.AcceptVpcPeeringConnectionInput, _ ...func(*ec2.Options),
+	) (*ec2.AcceptVpcPeeringConnectionOutput, error) {
+		t.peerings[0].Status.Code = types.VpcPeeringConnectionStateReasonCodeActive
+		return &ec2.AcceptVpcPeeringConnectionOutput{}, nil
+	})
+}
+
+func (t *peeringTestDriver) expectDryRunCreatePeering() {
+	t.source.awsClient.EXPECT().CreateVpcPeeringConnection(gomock.Any(), gomock.Any()).Return(nil, dryRunError())
+}
+
+func (d *peeringCloudDriver) assertPeeringIngress(cidr string) {
+	Expect(d.ingress).ToNot(BeEmpty())
+
+	for _, permission := range d.ingress {
+		Expect(permission.IpRanges).To(HaveLen(1))
+		Expect(*permission.IpRanges[0].CidrIp).To(Equal(cidr))
+	}
+}
+
+func (d *peeringCloudDriver) expectRevokeIngress(groupID, cidr string) {
+	d.awsClient.EXPECT().RevokeSecurityGroupIngress(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ec2.RevokeSecurityGroupIngressInput, _ ...func(*ec2.Options),
+		) (*ec2.RevokeSecurityGroupIngressOutput, error) {
+			Expect(*input.GroupId).To(Equal(groupID))
+			Expect(input.IpPermissions).To(HaveLen(1))
+			Expect(*input.IpPermissions[0].IpRanges[0].CidrIp).To(Equal(cidr))
+
+			return &ec2.RevokeSecurityGroupIngressOutput{}, nil
+		})
+}
+
+func (d *peeringCloudDriver) expectDeleteRoute(cidr string) {
+	d.awsClient.EXPECT().DeleteRoute(gomock.Any(), &ec2.DeleteRouteInput{
+		RouteTableId:         aws.String(routeTableID),
+		DestinationCidrBlock: aws.String(cidr),
+	}).Return(&ec2.DeleteRouteOutput{}, nil)
+}
+
+func newPeering(status types.VpcPeeringConnectionStateReasonCode) types.VpcPeeringConnection {
+	return types.VpcPeeringConnection{
+		VpcPeeringConnectionId: aws.String(peeringID),
+		RequesterVpcInfo:       &types.VpcPeeringConnectionVpcInfo{VpcId: aws.String(vpcID)},
+		AccepterVpcInfo:        &types.VpcPeeringConnectionVpcInfo{VpcId: aws.String(targetVpcID)},
+		Status:                 &types.VpcPeeringConnectionStateReason{Code: status},
+	}
+}
+
+func newPeeringRoute(cidr string) types.Route {
+	return types.Route{
+		DestinationCidrBlock:   aws.String(cidr),
+		VpcPeeringConnectionId: aws.String(peeringID),
+	}
+}
+
+func newPeeringPermissions(cidr string) []types.IpPermission {
+	return []types.IpPermission{
+		{
+			FromPort:   aws.Int32(4500),
+			ToPort:     aws.Int32(4500),
+			IpProtocol: aws.String("udp"),
+			IpRanges: []types.IpRange{
+				{
+					CidrIp:      aws.String(cidr),
+					Description: aws.String("Submariner peering traffic from " + cidr),
+				},
+			},
+		},
+		{
+			FromPort:   aws.Int32(80),
+			ToPort:     aws.Int32(80),
+			IpProtocol: aws.String("tcp"),
+			IpRanges: []types.IpRange{
+				{
+					CidrIp: aws.String(cidr),
+				},
+			},
+		},
+	}
+}
+
+type invalidCloud struct {
+	api.Cloud
+}
@@ -25,21 +25,30 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (ac *awsCloud) getVpcID() (string, error) {
+func (ac *awsCloud) getVpcID(ctx context.Context) (string, error) {
+	vpc, err := ac.getVpc(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return *vpc.VpcId, nil
+}
+
+func (ac *awsCloud) getVpc(ctx context.Context) (*types.Vpc, error) {
 	vpcName := ac.withAWSInfo("{infraID}-vpc")
 	filters := []types.Filter{
 		ac.filterByName(vpcName),
 		ac.filterByCurrentCluster(),
 	}
 
-	result, err := ac.client.DescribeVpcs(context.TODO(), &ec2.DescribeVpcsInput{Filters: filters})
+	result, err := ac.client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{Filters: filters})
 	if err != nil {
-		return "", errors.Wrap(err, "error describing AWS VPCs")
+		return nil, errors.Wrap(err, "error describing AWS VPCs")
 	}
 
 	if len(result.Vpcs) == 0 {
-		return "", newNotFoundError("VPC %s", vpcName)
+		return nil, newNotFoundError("VPC %s", vpcName)
 	}
 
-	return *result.Vpcs[0].VpcId, nil
+	return &result.Vpcs[0], nil
 }
@@ -0,0 +1,107 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+const providerName = "azure"
+
+type azureCloud struct {
+	CloudInfo
+}
+
+// NewCloud creates a new api.Cloud instance which can prepare Azure for Submariner to be deployed on it.
+func NewCloud(info CloudInfo) api.Cloud {
+	return &azureCloud{CloudInfo: info}
+}
+
+// PrepareForSubmariner prepares submariner cluster environment on Azure.
+func (az *azureCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
+	return az.PrepareForSubmarinerWithContext(context.Background(), input, reporter)
+}
+
+func (az *azureCloud) PrepareForSubmarinerWithContext(ctx context.Context, input api.PrepareForSubmarinerInput,
+	reporter api.Reporter) error {
+	reporter.Started("Opening internal ports %q for intra-cluster communications on Azure", formatPorts(input.InternalPorts))
+
+	// Internal traffic stays within the cluster's virtual network.
+	rules, err := newSecurityRules(generateRulePrefix(az.InfraID, internalPortsRuleName), "VirtualNetwork", input.InternalPorts)
+	if err == nil {
+		err = az.openPorts(ctx, rules...)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Opened internal ports %q in network security group %q on Azure",
+		formatPorts(input.InternalPorts), securityGroupName(az.InfraID))
+
+	return nil
+}
+
+// CleanupAfterSubmariner clean up submariner cluster environment on Azure.
+func (az *azureCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
+	return az.CleanupAfterSubmarinerWithContext(context.Background(), reporter)
+}
+
+func (az *azureCloud) CleanupAfterSubmarinerWithContext(ctx context.Context, reporter api.Reporter) error {
+	return az.deleteSecurityRules(ctx, generateRulePrefix(az.InfraID, internalPortsRuleName), reporter)
+}
+
+// CreateVpcPeering isn't supported on Azure yet.
+func (az *azureCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return az.CreateVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (az *azureCloud) CreateVpcPeeringWithContext(_ context.Context, _ api.Cloud, reporter api.Reporter) error {
+	err := errors.New("VNet peering isn't supported on Azure")
+	reporter.Failed(err)
+
+	return err
+}
+
+// CleanupVpcPeering isn't supported on Azure yet.
+func (az *azureCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return az.CleanupVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (az *azureCloud) CleanupVpcPeeringWithContext(_ context.Context, _ api.Cloud, reporter api.Reporter) error {
+	err := errors.New("VNet peering isn't supported on Azure")
+	reporter.Failed(err)
+
+	return err
+}
+
+func formatPorts(ports []api.PortSpec) string {
+	portStrs := []string{}
+	for _, port := range ports {
+		portStrs = append(portStrs, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+	}
+
+	return strings.Join(portStrs, ", ")
+}
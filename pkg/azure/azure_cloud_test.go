@@ -0,0 +1,188 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/azure"
+)
+
+const (
+	internalUDPRuleName = infraID + "-submariner-internal-udp"
+	internalTCPRuleName = infraID + "-submariner-internal-tcp"
+)
+
+var _ = Describe("Cloud", func() {
+	Describe("PrepareForSubmariner", testPrepareForSubmariner)
+	Describe("CleanupAfterSubmariner", testCleanupAfterSubmariner)
+	Describe("Plan", testPlan)
+	Describe("Inspect", testInspect)
+})
+
+var virtualNetwork = "VirtualNetwork"
+
+var internalPorts = []api.PortSpec{
+	{Port: 4800, Protocol: "udp"},
+	{Port: 8080, Protocol: "tcp"},
+	{Port: 4490, Protocol: "UDP"},
+}
+
+func testPrepareForSubmariner() {
+	t := newCloudTestDriver()
+
+	var retError error
+
+	JustBeforeEach(func() {
+		retError = t.cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{InternalPorts: internalPorts}, api.NewLoggingReporter())
+	})
+
+	When("the security rules don't exist", func() {
+		BeforeEach(func() {
+			t.addRule("installer-rule", "Tcp", 2000, "6443")
+		})
+
+		It("should create a rule per protocol with free priorities", func() {
+			Expect(retError).To(Succeed())
+
+			Expect(t.rules).To(HaveKey(internalTCPRuleName))
+			tcpRule := t.rules[internalTCPRuleName]
+			Expect(*tcpRule.Properties.Protocol).To(Equal(armnetwork.SecurityRuleProtocolTCP))
+			Expect(*tcpRule.Properties.Direction).To(Equal(armnetwork.SecurityRuleDirectionInbound))
+			Expect(*tcpRule.Properties.SourceAddressPrefix).To(Equal("VirtualNetwork"))
+			Expect(*tcpRule.Properties.Priority).To(Equal(int32(2001)))
+			Expect(rulePorts(tcpRule)).To(Equal([]string{"8080"}))
+
+			Expect(t.rules).To(HaveKey(internalUDPRuleName))
+			udpRule := t.rules[internalUDPRuleName]
+			Expect(*udpRule.Properties.Priority).To(Equal(int32(2002)))
+			Expect(rulePorts(udpRule)).To(Equal([]string{"4490", "4800"}))
+		})
+	})
+
+	When("a security rule already exists", func() {
+		BeforeEach(func() {
+			t.addRule(internalUDPRuleName, "Udp", 2010, "4800")
+		})
+
+		It("should update it and keep its priority", func() {
+			Expect(retError).To(Succeed())
+			Expect(*t.rules[internalUDPRuleName].Properties.Priority).To(Equal(int32(2010)))
+			Expect(rulePorts(t.rules[internalUDPRuleName])).To(Equal([]string{"4490", "4800"}))
+		})
+	})
+
+	When("retrieval of the security group fails", func() {
+		BeforeEach(func() {
+			t.getError = errors.New("fake Get error")
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testCleanupAfterSubmariner() {
+	t := newCloudTestDriver()
+
+	BeforeEach(func() {
+		t.addRule(internalUDPRuleName, "Udp", 2000, "4800")
+		t.addRule(infraID+"-submariner-public-udp", "Udp", 2001, "4500")
+	})
+
+	It("should delete the internal security rules", func() {
+		Expect(t.cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+		Expect(t.rules).ToNot(HaveKey(internalUDPRuleName))
+		Expect(t.rules).To(HaveKey(infraID + "-submariner-public-udp"))
+	})
+}
+
+func testPlan() {
+	t := newCloudTestDriver()
+
+	var (
+		actions  []api.Action
+		retError error
+	)
+
+	BeforeEach(func() {
+		t.addRule(internalTCPRuleName, "Tcp", 2000, "8080")
+		t.rules[internalTCPRuleName].Properties.SourceAddressPrefix = &virtualNetwork
+		t.addRule(internalUDPRuleName, "Udp", 2001, "4800")
+	})
+
+	JustBeforeEach(func() {
+		actions, retError = t.cloud.Plan(context.TODO(), api.PrepareForSubmarinerInput{InternalPorts: internalPorts})
+	})
+
+	It("should only return the rules which don't allow the expected ports", func() {
+		Expect(retError).To(Succeed())
+		Expect(actions).To(HaveLen(1))
+		Expect(actions[0].Kind).To(Equal(api.ActionUpdateFirewallRule))
+		Expect(actions[0].Resource).To(Equal(internalUDPRuleName))
+		Expect(t.rules[internalUDPRuleName].Properties.DestinationPortRanges).To(HaveLen(1))
+	})
+}
+
+func testInspect() {
+	t := newCloudTestDriver()
+
+	BeforeEach(func() {
+		t.addRule(internalUDPRuleName, "Udp", 2000, "4800")
+		t.addRule("installer-rule", "Tcp", 2001, "6443")
+	})
+
+	It("should return the ports of the internal security rules", func() {
+		status, err := t.cloud.Inspect(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(status).To(Equal(&api.CloudStatus{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "udp"}},
+			Peerings:      []api.PeeringStatus{},
+		}))
+	})
+}
+
+type cloudTestDriver struct {
+	fakeAzureClientBase
+	cloud api.Cloud
+}
+
+func newCloudTestDriver() *cloudTestDriver {
+	t := &cloudTestDriver{}
+
+	BeforeEach(func() {
+		t.beforeEach()
+
+		t.cloud = azure.NewCloud(azure.CloudInfo{
+			InfraID: infraID,
+			Region:  region,
+			Client:  t.azureClient,
+		})
+	})
+
+	AfterEach(t.afterEach)
+
+	return t
+}
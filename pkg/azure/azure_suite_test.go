@@ -0,0 +1,128 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/azure/client/fake"
+)
+
+const (
+	infraID           = "test-infraID"
+	region            = "eastus"
+	instanceType      = "test-instance-type"
+	securityGroupName = infraID + "-nsg"
+)
+
+func TestAzure(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Azure Suite")
+}
+
+// fakeAzureClientBase fakes the cluster's network security group in memory.
+type fakeAzureClientBase struct {
+	azureClient *fake.MockInterface
+	mockCtrl    *gomock.Controller
+	rules       map[string]*armnetwork.SecurityRule
+	getError    error
+}
+
+func (f *fakeAzureClientBase) beforeEach() {
+	f.mockCtrl = gomock.NewController(GinkgoT())
+	f.azureClient = fake.NewMockInterface(f.mockCtrl)
+	f.rules = map[string]*armnetwork.SecurityRule{}
+	f.getError = nil
+
+	f.azureClient.EXPECT().GetSecurityGroup(gomock.Any(), securityGroupName).DoAndReturn(
+		func(_ context.Context, _ string) (*armnetwork.SecurityGroup, error) {
+			if f.getError != nil {
+				return nil, f.getError
+			}
+
+			names := make([]string, 0, len(f.rules))
+			for name := range f.rules {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			group := &armnetwork.SecurityGroup{Properties: &armnetwork.SecurityGroupPropertiesFormat{}}
+			for _, name := range names {
+				group.Properties.SecurityRules = append(group.Properties.SecurityRules, f.rules[name])
+			}
+
+			return group, nil
+		}).AnyTimes()
+
+	f.azureClient.EXPECT().CreateOrUpdateSecurityRule(gomock.Any(), securityGroupName, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, ruleName string, rule *armnetwork.SecurityRule) error {
+			Expect(*rule.Name).To(Equal(ruleName))
+			f.rules[ruleName] = rule
+
+			return nil
+		}).AnyTimes()
+
+	f.azureClient.EXPECT().DeleteSecurityRule(gomock.Any(), securityGroupName, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, ruleName string) error {
+			delete(f.rules, ruleName)
+			return nil
+		}).AnyTimes()
+}
+
+func (f *fakeAzureClientBase) afterEach() {
+	f.mockCtrl.Finish()
+}
+
+func (f *fakeAzureClientBase) addRule(name, protocol string, priority int32, ports ...string) {
+	f.rules[name] = newRule(name, protocol, priority, ports...)
+}
+
+func newRule(name, protocol string, priority int32, ports ...string) *armnetwork.SecurityRule {
+	ruleProtocol := armnetwork.SecurityRuleProtocol(protocol)
+	portRanges := make([]*string, len(ports))
+
+	for i := range ports {
+		portRanges[i] = &ports[i]
+	}
+
+	return &armnetwork.SecurityRule{
+		Name: &name,
+		Properties: &armnetwork.SecurityRulePropertiesFormat{
+			Protocol:              &ruleProtocol,
+			Priority:              &priority,
+			DestinationPortRanges: portRanges,
+		},
+	}
+}
+
+func rulePorts(rule *armnetwork.SecurityRule) []string {
+	ports := []string{}
+	for _, port := range rule.Properties.DestinationPortRanges {
+		ports = append(ports, *port)
+	}
+
+	return ports
+}
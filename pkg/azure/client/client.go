@@ -0,0 +1,104 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nolint:wrapcheck // The functions are wrappers so let the caller wrap errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+)
+
+//go:generate mockgen -source=./client.go -destination=./fake/client.go -package=fake
+
+// Interface wraps an actual Azure library client to allow for easier testing.
+type Interface interface {
+	GetSecurityGroup(ctx context.Context, groupName string) (*armnetwork.SecurityGroup, error)
+	CreateOrUpdateSecurityRule(ctx context.Context, groupName, ruleName string, rule *armnetwork.SecurityRule) error
+	DeleteSecurityRule(ctx context.Context, groupName, ruleName string) error
+}
+
+type azureClient struct {
+	resourceGroup       string
+	securityGroupClient *armnetwork.SecurityGroupsClient
+	securityRuleClient  *armnetwork.SecurityRulesClient
+}
+
+// NewClient returns a client operating on the network resources of the given resource group.
+func NewClient(subscriptionID, resourceGroup string, credential azcore.TokenCredential, options *arm.ClientOptions) (Interface, error) {
+	securityGroupClient, err := armnetwork.NewSecurityGroupsClient(subscriptionID, credential, options)
+	if err != nil {
+		return nil, err
+	}
+
+	securityRuleClient, err := armnetwork.NewSecurityRulesClient(subscriptionID, credential, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azureClient{
+		resourceGroup:       resourceGroup,
+		securityGroupClient: securityGroupClient,
+		securityRuleClient:  securityRuleClient,
+	}, nil
+}
+
+func (a *azureClient) GetSecurityGroup(ctx context.Context, groupName string) (*armnetwork.SecurityGroup, error) {
+	response, err := a.securityGroupClient.Get(ctx, a.resourceGroup, groupName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.SecurityGroup, nil
+}
+
+func (a *azureClient) CreateOrUpdateSecurityRule(ctx context.Context, groupName, ruleName string, rule *armnetwork.SecurityRule) error {
+	poller, err := a.securityRuleClient.BeginCreateOrUpdate(ctx, a.resourceGroup, groupName, ruleName, *rule, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = poller.PollUntilDone(ctx, nil)
+
+	return err
+}
+
+func (a *azureClient) DeleteSecurityRule(ctx context.Context, groupName, ruleName string) error {
+	poller, err := a.securityRuleClient.BeginDelete(ctx, a.resourceGroup, groupName, ruleName, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = poller.PollUntilDone(ctx, nil)
+
+	return err
+}
+
+func IsAzureNotFoundError(err error) bool {
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode == http.StatusNotFound
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./client.go
+
+// Package fake is a generated GoMock package.
+package fake
+
+import (
+	context "context"
+	reflect "reflect"
+
+	armnetwork "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateOrUpdateSecurityRule mocks base method.
+func (m *MockInterface) CreateOrUpdateSecurityRule(ctx context.Context, groupName, ruleName string, rule *armnetwork.SecurityRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateSecurityRule", ctx, groupName, ruleName, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdateSecurityRule indicates an expected call of CreateOrUpdateSecurityRule.
+func (mr *MockInterfaceMockRecorder) CreateOrUpdateSecurityRule(ctx, groupName, ruleName, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateSecurityRule", reflect.TypeOf((*MockInterface)(nil).CreateOrUpdateSecurityRule), ctx, groupName, ruleName, rule)
+}
+
+// DeleteSecurityRule mocks base method.
+func (m *MockInterface) DeleteSecurityRule(ctx context.Context, groupName, ruleName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecurityRule", ctx, groupName, ruleName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecurityRule indicates an expected call of DeleteSecurityRule.
+func (mr *MockInterfaceMockRecorder) DeleteSecurityRule(ctx, groupName, ruleName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecurityRule", reflect.TypeOf((*MockInterface)(nil).DeleteSecurityRule), ctx, groupName, ruleName)
+}
+
+// GetSecurityGroup mocks base method.
+func (m *MockInterface) GetSecurityGroup(ctx context.Context, groupName string) (*armnetwork.SecurityGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecurityGroup", ctx, groupName)
+	ret0, _ := ret[0].(*armnetwork.SecurityGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecurityGroup indicates an expected call of GetSecurityGroup.
+func (mr *MockInterfaceMockRecorder) GetSecurityGroup(ctx, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecurityGroup", reflect.TypeOf((*MockInterface)(nil).GetSecurityGroup), ctx, groupName)
+}
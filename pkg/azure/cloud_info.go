@@ -0,0 +1,201 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	azureclient "github.com/submariner-io/cloud-prepare/pkg/azure/client"
+)
+
+type CloudInfo struct {
+	InfraID string
+	Region  string
+	Client  azureclient.Interface
+
+	// StateStore optionally records the provisioned resources, so that they can be cleaned up exactly.
+	StateStore api.StateStore
+}
+
+// SetStateStore sets the store recording the resources provisioned on Azure, which are then cleaned up exactly.
+func (c *CloudInfo) SetStateStore(store api.StateStore) {
+	c.StateStore = store
+}
+
+// Open the expected ports by creating or updating the related security rules in the cluster's network security group.
+// Each rule keeps its priority when updated; new rules get the lowest priority not used by any other rule.
+func (c *CloudInfo) openPorts(ctx context.Context, rules ...*armnetwork.SecurityRule) error {
+	groupName := securityGroupName(c.InfraID)
+
+	group, err := c.Client.GetSecurityGroup(ctx, groupName)
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving network security group %q", groupName)
+	}
+
+	priorities := rulePriorities(group)
+
+	for _, rule := range rules {
+		priority, err := allocatePriority(priorities, *rule.Name)
+		if err != nil {
+			return err
+		}
+
+		rule.Properties.Priority = &priority
+
+		if err := c.Client.CreateOrUpdateSecurityRule(ctx, groupName, *rule.Name, rule); err != nil {
+			return errors.Wrapf(err, "error creating or updating security rule %q", *rule.Name)
+		}
+
+		if err := api.RecordResources(ctx, c.StateStore, securityRuleResource(groupName, *rule.Name)); err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	return nil
+}
+
+// deleteSecurityRules deletes the security rules whose names start with the given prefix.
+func (c *CloudInfo) deleteSecurityRules(ctx context.Context, prefix string, reporter api.Reporter) error {
+	groupName := securityGroupName(c.InfraID)
+
+	reporter.Started("Deleting the security rules %q on Azure", prefix+"*")
+
+	ruleNames, err := c.findSecurityRules(ctx, groupName, prefix)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	for _, ruleName := range ruleNames {
+		if err := c.Client.DeleteSecurityRule(ctx, groupName, ruleName); err != nil && !azureclient.IsAzureNotFoundError(err) {
+			err = errors.Wrapf(err, "error deleting security rule %q", ruleName)
+			reporter.Failed(err)
+
+			return err
+		}
+
+		if err := api.ForgetResources(ctx, c.StateStore, securityRuleResource(groupName, ruleName)); err != nil {
+			reporter.Failed(err)
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	reporter.Succeeded("Deleted the security rules %q on Azure", prefix+"*")
+
+	return nil
+}
+
+// findSecurityRules returns the names of the recorded security rules with the given prefix or, if none were
+// recorded, of the rules with that prefix in the network security group.
+func (c *CloudInfo) findSecurityRules(ctx context.Context, groupName, prefix string) ([]string, error) {
+	ruleNames := []string{}
+
+	recorded, err := api.RecordedResources(ctx, c.StateStore, api.ResourceIngressRule)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	for i := range recorded {
+		if recorded[i].Parameters["group"] == groupName && strings.HasPrefix(recorded[i].Parameters["name"], prefix) {
+			ruleNames = append(ruleNames, recorded[i].Parameters["name"])
+		}
+	}
+
+	if len(ruleNames) > 0 {
+		return ruleNames, nil
+	}
+
+	group, err := c.Client.GetSecurityGroup(ctx, groupName)
+	if azureclient.IsAzureNotFoundError(err) {
+		return ruleNames, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving network security group %q", groupName)
+	}
+
+	for _, rule := range securityRules(group) {
+		if strings.HasPrefix(stringValue(rule.Name), prefix) {
+			ruleNames = append(ruleNames, *rule.Name)
+		}
+	}
+
+	return ruleNames, nil
+}
+
+func securityRules(group *armnetwork.SecurityGroup) []*armnetwork.SecurityRule {
+	if group.Properties == nil {
+		return nil
+	}
+
+	return group.Properties.SecurityRules
+}
+
+func findSecurityRule(group *armnetwork.SecurityGroup, name string) *armnetwork.SecurityRule {
+	for _, rule := range securityRules(group) {
+		if stringValue(rule.Name) == name {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// rulePriorities maps the priorities used in the network security group to the names of the rules using them.
+func rulePriorities(group *armnetwork.SecurityGroup) map[int32]string {
+	priorities := map[int32]string{}
+
+	for _, rule := range securityRules(group) {
+		if rule.Properties != nil && rule.Properties.Priority != nil {
+			priorities[*rule.Properties.Priority] = stringValue(rule.Name)
+		}
+	}
+
+	return priorities
+}
+
+func allocatePriority(priorities map[int32]string, ruleName string) (int32, error) {
+	for priority, name := range priorities {
+		if name == ruleName {
+			return priority, nil
+		}
+	}
+
+	for priority := basePriority; priority <= maxPriority; priority++ {
+		if _, used := priorities[priority]; !used {
+			priorities[priority] = ruleName
+			return priority, nil
+		}
+	}
+
+	return 0, fmt.Errorf("there are no free priorities left for security rule %q", ruleName)
+}
+
+func securityRuleResource(groupName, ruleName string) api.Resource {
+	return api.Resource{
+		Type:       api.ResourceIngressRule,
+		ID:         groupName + "/" + ruleName,
+		Parameters: map[string]string{"group": groupName, "name": ruleName},
+	}
+}
@@ -0,0 +1,96 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	azureclient "github.com/submariner-io/cloud-prepare/pkg/azure/client"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+)
+
+// Inspect returns the ports opened by the internal security rules on Azure. VNet peering isn't supported, so there
+// are no peerings.
+func (az *azureCloud) Inspect(ctx context.Context) (*api.CloudStatus, error) {
+	internalPorts, err := az.inspectSecurityRules(ctx, generateRulePrefix(az.InfraID, internalPortsRuleName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CloudStatus{InternalPorts: internalPorts, Peerings: []api.PeeringStatus{}}, nil
+}
+
+// Inspect returns the ports opened by the public security rules, the gateway nodes and their machine sets on Azure.
+func (d *ocpGatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	publicPorts, err := d.inspectSecurityRules(ctx, generateRulePrefix(d.InfraID, publicPortsRuleName))
+	if err != nil {
+		return nil, err
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	machineSets, err := ocp.ListGatewayMachineSets(ctx, d.msDeployer, d.InfraID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	return &api.GatewayStatus{
+		PublicPorts:        publicPorts,
+		GatewaySubnets:     []string{},
+		GatewayNodes:       k8s.NodeNames(gwNodes),
+		GatewayMachineSets: machineSets,
+	}, nil
+}
+
+// inspectSecurityRules returns the ports allowed by the security rules whose names start with the given prefix.
+func (c *CloudInfo) inspectSecurityRules(ctx context.Context, prefix string) ([]api.PortSpec, error) {
+	ports := []api.PortSpec{}
+	groupName := securityGroupName(c.InfraID)
+
+	group, err := c.Client.GetSecurityGroup(ctx, groupName)
+	if azureclient.IsAzureNotFoundError(err) {
+		return ports, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving network security group %q", groupName)
+	}
+
+	for _, rule := range securityRules(group) {
+		if !strings.HasPrefix(stringValue(rule.Name), prefix) {
+			continue
+		}
+
+		rulePorts, err := securityRulePorts(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		ports = append(ports, rulePorts...)
+	}
+
+	return ports, nil
+}
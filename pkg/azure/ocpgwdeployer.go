@@ -0,0 +1,338 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const zoneLabel = "topology.kubernetes.io/zone"
+
+type ocpGatewayDeployer struct {
+	ocp.MachineSetTemplate
+	CloudInfo
+	msDeployer   ocp.MachineSetDeployer
+	instanceType string
+	image        string
+	k8sClient    k8s.Interface
+}
+
+// NewOcpGatewayDeployer returns a GatewayDeployer capable of deploying gateways using OCP. Each gateway is a dedicated
+// node with a public IP, deployed by a machine set in its own availability zone. If no image is given, the image
+// created by the OpenShift installer in the cluster's resource group is used.
+func NewOcpGatewayDeployer(info CloudInfo, msDeployer ocp.MachineSetDeployer, instanceType, image string,
+	k8sClient k8s.Interface) api.GatewayDeployer {
+	if image == "" {
+		image = fmt.Sprintf("/resourceGroups/%s-rg/providers/Microsoft.Compute/images/%s", info.InfraID, info.InfraID)
+	}
+
+	return &ocpGatewayDeployer{
+		MachineSetTemplate: ocp.NewMachineSetTemplate(machineSetYAML),
+		CloudInfo:          info,
+		msDeployer:         msDeployer,
+		instanceType:       instanceType,
+		image:              image,
+		k8sClient:          k8sClient,
+	}
+}
+
+func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *ocpGatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	reporter.Started("Configuring the required security rules for inter-cluster traffic")
+
+	if input.RestrictsSources() {
+		err := errors.New("restricting the public ports to source CIDRs isn't supported on Azure")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	// Only the gateways have public IPs, so the public rules in the cluster's network security group only expose them.
+	rules, err := newSecurityRules(generateRulePrefix(d.InfraID, publicPortsRuleName), "*", input.PublicPorts)
+	if err == nil {
+		err = d.openPorts(ctx, rules...)
+	}
+
+	if err != nil {
+		return reportFailure(reporter, err, "error opening the public ports")
+	}
+
+	reporter.Succeeded("Opened external ports %q in network security group %q on Azure",
+		formatPorts(input.PublicPorts), securityGroupName(d.InfraID))
+
+	gateways, err := d.findGateways(ctx)
+	if err != nil {
+		return reportFailure(reporter, err, "error retrieving the current gateways")
+	}
+
+	gatewayNodesToDeploy := input.Gateways - len(gateways)
+
+	// The default policy keeps whatever gateways already exist.
+	if gatewayNodesToDeploy == 0 || (input.Gateways == 0 && gatewayNodesToDeploy < 0) {
+		reporter.Succeeded("Current gateways match the required number of gateways")
+		return nil
+	}
+
+	if gatewayNodesToDeploy < 0 {
+		for _, gateway := range k8s.SurplusGateways(gateways, input.Gateways) {
+			machineSetStep := api.ResourceStep(providerName, api.ResourceMachineSet, gateway.Name)
+
+			api.StepStarted(reporter, machineSetStep, "Removing the gateway machine set %q", gateway.Name)
+
+			err = d.deleteGateway(ctx, gateway.Zone)
+			if err != nil {
+				return reportStepFailure(reporter, machineSetStep, err, "error removing the gateway machine set %q", gateway.Name)
+			}
+
+			api.StepSucceeded(reporter, machineSetStep, "Removed the gateway machine set %q", gateway.Name)
+		}
+
+		return nil
+	}
+
+	eligibleZones, err := d.eligibleZones(ctx, gateways)
+	if err != nil {
+		return reportFailure(reporter, err, "error retrieving the zones of the worker nodes")
+	}
+
+	for _, zone := range eligibleZones {
+		machineSetStep := api.ResourceStep(providerName, api.ResourceMachineSet, d.machineSetName(zone))
+
+		api.StepStarted(reporter, machineSetStep, "Deploying dedicated gateway node in zone %q", zone)
+
+		err = d.deployGateway(ctx, zone)
+		if err != nil {
+			return reportStepFailure(reporter, machineSetStep, err, "error deploying gateway for zone %q", zone)
+		}
+
+		api.StepSucceeded(reporter, machineSetStep, "Deployed dedicated gateway node in zone %q", zone)
+
+		gatewayNodesToDeploy--
+		if gatewayNodesToDeploy <= 0 {
+			return nil
+		}
+	}
+
+	// We try to deploy a single gateway node per zone. If the number of gateways is more than the number of zones,
+	// it's treated as an error.
+	err = fmt.Errorf("there are an insufficient number of zones (%d) to deploy the desired number of gateways (%d)",
+		len(eligibleZones)+len(gateways), input.Gateways)
+	reporter.Failed(err)
+
+	return err
+}
+
+// findGateways returns the dedicated gateways deployed by machine sets, along with their zones.
+func (d *ocpGatewayDeployer) findGateways(ctx context.Context) ([]k8s.Gateway, error) {
+	machineSets, err := ocp.GatewayMachineSets(ctx, d.msDeployer, d.InfraID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	gateways := make([]k8s.Gateway, 0, len(machineSets))
+
+	for i := range machineSets {
+		zone, _, _ := unstructured.NestedString(machineSets[i].Object, "spec", "template", "spec", "providerSpec", "value", "zone")
+
+		gateways = append(gateways, k8s.Gateway{
+			Name:              machineSets[i].GetName(),
+			Zone:              zone,
+			CreationTimestamp: machineSets[i].GetCreationTimestamp().Time,
+		})
+	}
+
+	return gateways, nil
+}
+
+// eligibleZones returns the availability zones of the worker nodes which don't have a gateway yet. The zone label of
+// Azure nodes is the region followed by the zone number; nodes in regions without zones are labeled "0".
+func (d *ocpGatewayDeployer) eligibleZones(ctx context.Context, gateways []k8s.Gateway) ([]string, error) {
+	workerNodes, err := d.k8sClient.ListNodesWithLabelWithContext(ctx, "node-role.kubernetes.io/worker")
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the worker nodes")
+	}
+
+	zones := sets.NewString()
+
+	for i := range workerNodes.Items {
+		zones.Insert(strings.TrimPrefix(workerNodes.Items[i].Labels[zoneLabel], d.Region+"-"))
+	}
+
+	if zones.Has("0") {
+		zones.Delete("0")
+		zones.Insert("")
+	}
+
+	for i := range gateways {
+		zones.Delete(gateways[i].Zone)
+	}
+
+	eligible := zones.List()
+	sort.Strings(eligible)
+
+	return eligible, nil
+}
+
+type machineSetConfig struct {
+	Name         string
+	AZ           string
+	InfraID      string
+	InstanceType string
+	Region       string
+	Image        string
+}
+
+func (d *ocpGatewayDeployer) machineSetName(zone string) string {
+	return d.InfraID + "-submariner-gw-" + d.Region + zone
+}
+
+func (d *ocpGatewayDeployer) initMachineSet(zone string) (*unstructured.Unstructured, error) {
+	tplVars := machineSetConfig{
+		Name:         d.machineSetName(zone),
+		AZ:           zone,
+		InfraID:      d.InfraID,
+		InstanceType: d.instanceType,
+		Region:       d.Region,
+		Image:        d.image,
+	}
+
+	return d.RenderMachineSet(tplVars)
+}
+
+func (d *ocpGatewayDeployer) deployGateway(ctx context.Context, zone string) error {
+	machineSet, err := d.initMachineSet(zone)
+	if err != nil {
+		return err
+	}
+
+	err = d.msDeployer.DeployWithContext(ctx, machineSet)
+	if err != nil {
+		return errors.Wrapf(err, "error deploying machine set %q", machineSet.GetName())
+	}
+
+	return api.RecordResources(ctx, d.StateStore, machineSetResource(machineSet, zone))
+}
+
+func (d *ocpGatewayDeployer) deleteGateway(ctx context.Context, zone string) error {
+	machineSet, err := d.initMachineSet(zone)
+	if err != nil {
+		return err
+	}
+
+	err = d.msDeployer.DeleteWithContext(ctx, machineSet)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting machine set %q", machineSet.GetName())
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, machineSetResource(machineSet, zone))
+}
+
+func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *ocpGatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
+	err := d.deleteSecurityRules(ctx, generateRulePrefix(d.InfraID, publicPortsRuleName), reporter)
+	if err != nil {
+		return err
+	}
+
+	reporter.Started("Deleting the gateway machine sets")
+
+	zones, err := d.gatewayZones(ctx)
+	if err != nil {
+		return reportFailure(reporter, err, "error retrieving the gateway machine sets")
+	}
+
+	for _, zone := range zones {
+		err = d.deleteGateway(ctx, zone)
+		if err != nil {
+			return reportFailure(reporter, err, "error deleting the gateway in zone %q", zone)
+		}
+	}
+
+	reporter.Succeeded("Deleted the gateway machine sets")
+
+	return nil
+}
+
+// gatewayZones returns the zones of the recorded gateway machine sets or, if none were recorded, of the gateway
+// machine sets found in the cluster.
+func (d *ocpGatewayDeployer) gatewayZones(ctx context.Context) ([]string, error) {
+	recorded, err := api.RecordedResources(ctx, d.StateStore, api.ResourceMachineSet)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	zones := []string{}
+
+	for i := range recorded {
+		zones = append(zones, recorded[i].Parameters["zone"])
+	}
+
+	if len(zones) > 0 {
+		return zones, nil
+	}
+
+	gateways, err := d.findGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range gateways {
+		zones = append(zones, gateways[i].Zone)
+	}
+
+	return zones, nil
+}
+
+func machineSetResource(machineSet *unstructured.Unstructured, zone string) api.Resource {
+	return api.Resource{
+		Type:       api.ResourceMachineSet,
+		ID:         machineSet.GetName(),
+		Parameters: map[string]string{"namespace": machineSet.GetNamespace(), "zone": zone},
+	}
+}
+
+func reportFailure(reporter api.Reporter, failure error, format string, args ...interface{}) error {
+	err := errors.WithMessagef(failure, format, args...)
+	reporter.Failed(err)
+
+	return err
+}
+
+func reportStepFailure(reporter api.Reporter, step api.Step, failure error, format string, args ...interface{}) error {
+	err := errors.WithMessagef(failure, format, args...)
+	api.StepFailed(reporter, step, err)
+
+	return err
+}
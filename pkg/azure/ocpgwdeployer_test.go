@@ -0,0 +1,400 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/azure"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	ocpFake "github.com/submariner-io/cloud-prepare/pkg/ocp/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	publicUDPRuleName = infraID + "-submariner-public-udp"
+	gatewayPrefix     = infraID + "-submariner-gw-" + region
+)
+
+var _ = Describe("OCP GatewayDeployer", func() {
+	Context("on Deploy", testDeploy)
+	Context("on Plan", testPlanGateways)
+	Context("on Cleanup", testCleanup)
+	Context("on Inspect", testInspectGateways)
+})
+
+func testDeploy() {
+	t := newGatewayDeployerTestDriver()
+
+	var retError error
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{
+			newNode("node-1", region+"-1"),
+			newNode("node-2", region+"-2"),
+			newNode("node-3", region+"-2"),
+		}
+	})
+
+	JustBeforeEach(func() {
+		retError = t.doDeploy()
+	})
+
+	When("one gateway is requested", func() {
+		BeforeEach(func() {
+			t.numGateways = 1
+		})
+
+		It("should open the public ports and deploy a machine set in the first zone", func() {
+			Expect(retError).To(Succeed())
+
+			Expect(t.rules).To(HaveKey(publicUDPRuleName))
+			Expect(*t.rules[publicUDPRuleName].Properties.SourceAddressPrefix).To(Equal("*"))
+			Expect(rulePorts(t.rules[publicUDPRuleName])).To(Equal([]string{"4500"}))
+
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix + "1"}))
+			t.assertMachineSet(t.machineSets[gatewayPrefix+"1"], "1")
+		})
+	})
+
+	When("two gateways are requested", func() {
+		BeforeEach(func() {
+			t.numGateways = 2
+		})
+
+		It("should deploy a machine set in each zone", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix + "1", gatewayPrefix + "2"}))
+		})
+
+		Context("and there's an insufficient number of zones", func() {
+			BeforeEach(func() {
+				t.numGateways = 3
+			})
+
+			It("should return an error", func() {
+				Expect(retError).ToNot(Succeed())
+			})
+		})
+	})
+
+	When("the region has no zones", func() {
+		BeforeEach(func() {
+			t.nodes = []*corev1.Node{newNode("node-1", "0")}
+			t.numGateways = 1
+		})
+
+		It("should deploy a machine set without a zone", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix}))
+			t.assertMachineSet(t.machineSets[gatewayPrefix], "")
+		})
+	})
+
+	When("the requested number of gateways is increased", func() {
+		BeforeEach(func() {
+			t.addMachineSet("2", time.Now())
+			t.numGateways = 2
+		})
+
+		It("should deploy a machine set in a zone without a gateway", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix + "1", gatewayPrefix + "2"}))
+		})
+	})
+
+	When("the requested number of gateways is decreased", func() {
+		BeforeEach(func() {
+			t.addMachineSet("1", time.Now())
+			t.addMachineSet("2", time.Now().Add(-time.Hour))
+			t.numGateways = 1
+		})
+
+		It("should delete the newest gateway's machine set", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix + "2"}))
+		})
+
+		Context("to the default", func() {
+			BeforeEach(func() {
+				t.numGateways = 0
+			})
+
+			It("should keep the existing gateways", func() {
+				Expect(retError).To(Succeed())
+				Expect(t.machineSetNames()).To(HaveLen(2))
+			})
+		})
+	})
+
+	When("the public ports are restricted to source CIDRs", func() {
+		BeforeEach(func() {
+			t.numGateways = 1
+			t.sourceCIDRs = []string{"10.0.0.0/8"}
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+			Expect(t.machineSets).To(BeEmpty())
+		})
+	})
+
+	When("deploying a machine set fails", func() {
+		BeforeEach(func() {
+			t.numGateways = 1
+			t.deployError = errors.New("fake Deploy error")
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testPlanGateways() {
+	t := newGatewayDeployerTestDriver()
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{
+			newNode("node-1", region+"-1"),
+			newNode("node-2", region+"-2"),
+		}
+
+		t.addMachineSet("1", time.Now())
+	})
+
+	It("should return the actions without performing them", func() {
+		actions, err := t.gwDeployer.Plan(context.TODO(), api.GatewayDeployInput{Gateways: 2, PublicPorts: publicPorts})
+		Expect(err).To(Succeed())
+		Expect(actions).To(HaveLen(2))
+		Expect(actions[0].Kind).To(Equal(api.ActionInsertFirewallRule))
+		Expect(actions[0].Resource).To(Equal(publicUDPRuleName))
+		Expect(actions[1].Kind).To(Equal(api.ActionDeployMachineSet))
+		Expect(actions[1].Resource).To(Equal(gatewayPrefix + "2"))
+		Expect(actions[1].Parameters).To(HaveKeyWithValue("instance-type", instanceType))
+
+		Expect(t.rules).To(BeEmpty())
+		Expect(t.machineSetNames()).To(HaveLen(1))
+	})
+}
+
+func testCleanup() {
+	t := newGatewayDeployerTestDriver()
+
+	BeforeEach(func() {
+		t.addRule(publicUDPRuleName, "Udp", 2000, "4500")
+		t.addRule(internalUDPRuleName, "Udp", 2001, "4800")
+		t.addMachineSet("1", time.Now())
+		t.addMachineSet("2", time.Now())
+	})
+
+	It("should delete the public security rules and the gateway machine sets", func() {
+		Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+		Expect(t.rules).ToNot(HaveKey(publicUDPRuleName))
+		Expect(t.rules).To(HaveKey(internalUDPRuleName))
+		Expect(t.machineSetNames()).To(BeEmpty())
+	})
+
+	When("the network security group retrieval fails", func() {
+		BeforeEach(func() {
+			t.getError = errors.New("fake Get error")
+		})
+
+		It("should return an error", func() {
+			Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).ToNot(Succeed())
+		})
+	})
+}
+
+func testInspectGateways() {
+	t := newGatewayDeployerTestDriver()
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{
+			labelNode(newNode("node-1", region+"-1")),
+			newNode("node-2", region+"-2"),
+		}
+
+		t.addRule(publicUDPRuleName, "Udp", 2000, "4500")
+		t.addMachineSet("1", time.Now())
+	})
+
+	It("should return the public ports, the gateway nodes and their machine sets", func() {
+		status, err := t.gwDeployer.Inspect(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(status).To(Equal(&api.GatewayStatus{
+			PublicPorts:        []api.PortSpec{{Port: 4500, Protocol: "udp"}},
+			GatewaySubnets:     []string{},
+			GatewayNodes:       []string{"node-1"},
+			GatewayMachineSets: []string{gatewayPrefix + "1"},
+		}))
+	})
+}
+
+var publicPorts = []api.PortSpec{{Port: 4500, Protocol: "udp"}}
+
+type gatewayDeployerTestDriver struct {
+	fakeAzureClientBase
+	numGateways int
+	sourceCIDRs []string
+	msDeployer  *ocpFake.MockMachineSetDeployer
+	machineSets map[string]*unstructured.Unstructured
+	deployError error
+	kubeClient  *kubeFake.Clientset
+	nodes       []*corev1.Node
+	gwDeployer  api.GatewayDeployer
+}
+
+func newGatewayDeployerTestDriver() *gatewayDeployerTestDriver {
+	t := &gatewayDeployerTestDriver{}
+
+	BeforeEach(func() {
+		t.beforeEach()
+
+		t.nodes = []*corev1.Node{}
+		t.numGateways = 0
+		t.machineSets = map[string]*unstructured.Unstructured{}
+		t.deployError = nil
+		t.kubeClient = kubeFake.NewSimpleClientset()
+		t.msDeployer = ocpFake.NewMockMachineSetDeployer(t.mockCtrl)
+
+		t.msDeployer.EXPECT().DeployWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, ms *unstructured.Unstructured) error {
+				if t.deployError != nil {
+					return t.deployError
+				}
+
+				t.machineSets[ms.GetName()] = ms
+
+				return nil
+			}).AnyTimes()
+
+		t.msDeployer.EXPECT().DeleteWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, ms *unstructured.Unstructured) error {
+				delete(t.machineSets, ms.GetName())
+				return nil
+			}).AnyTimes()
+
+		t.msDeployer.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, ms *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+				Expect(ms.GetNamespace()).To(Equal("openshift-machine-api"))
+
+				worker := unstructured.Unstructured{}
+				worker.SetName(infraID + "-worker-" + region + "1")
+
+				list := []unstructured.Unstructured{worker}
+				for _, name := range t.machineSetNames() {
+					list = append(list, *t.machineSets[name])
+				}
+
+				return list, nil
+			}).AnyTimes()
+	})
+
+	JustBeforeEach(func() {
+		for _, node := range t.nodes {
+			_, err := t.kubeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		}
+
+		t.gwDeployer = azure.NewOcpGatewayDeployer(azure.CloudInfo{
+			InfraID: infraID,
+			Region:  region,
+			Client:  t.azureClient,
+		}, t.msDeployer, instanceType, "", k8s.NewInterface(t.kubeClient))
+	})
+
+	AfterEach(t.afterEach)
+
+	return t
+}
+
+func (t *gatewayDeployerTestDriver) doDeploy() error {
+	return t.gwDeployer.Deploy(api.GatewayDeployInput{
+		Gateways:          t.numGateways,
+		PublicPorts:       publicPorts,
+		PublicSourceCIDRs: t.sourceCIDRs,
+	}, api.NewLoggingReporter())
+}
+
+func (t *gatewayDeployerTestDriver) addMachineSet(zone string, created time.Time) {
+	ms := &unstructured.Unstructured{}
+	ms.SetName(gatewayPrefix + zone)
+	ms.SetCreationTimestamp(metav1.NewTime(created))
+	Expect(unstructured.SetNestedField(ms.Object, zone, "spec", "template", "spec", "providerSpec", "value", "zone")).To(Succeed())
+
+	t.machineSets[ms.GetName()] = ms
+}
+
+func (t *gatewayDeployerTestDriver) machineSetNames() []string {
+	names := []string{}
+	for name := range t.machineSets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (t *gatewayDeployerTestDriver) assertMachineSet(ms *unstructured.Unstructured, expZone string) {
+	Expect(ms).ToNot(BeNil())
+	Expect(ms.GetNamespace()).To(Equal("openshift-machine-api"))
+
+	providerSpec := []string{"spec", "template", "spec", "providerSpec", "value"}
+
+	zone, _, _ := unstructured.NestedString(ms.Object, append(providerSpec, "zone")...)
+	Expect(zone).To(Equal(expZone))
+
+	vmSize, _, _ := unstructured.NestedString(ms.Object, append(providerSpec, "vmSize")...)
+	Expect(vmSize).To(Equal(instanceType))
+
+	image, _, _ := unstructured.NestedString(ms.Object, append(providerSpec, "image", "resourceID")...)
+	Expect(image).To(Equal("/resourceGroups/" + infraID + "-rg/providers/Microsoft.Compute/images/" + infraID))
+
+	publicIP, _, _ := unstructured.NestedBool(ms.Object, append(providerSpec, "publicIP")...)
+	Expect(publicIP).To(BeTrue())
+}
+
+func newNode(name, withZone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"topology.kubernetes.io/zone":    withZone,
+				"node-role.kubernetes.io/worker": "",
+			},
+		},
+	}
+}
+
+func labelNode(node *corev1.Node) *corev1.Node {
+	node.Labels["submariner.io/gateway"] = "true"
+	return node
+}
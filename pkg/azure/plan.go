@@ -0,0 +1,138 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+)
+
+// Plan returns the security rule changes PrepareForSubmariner would make on Azure.
+func (az *azureCloud) Plan(ctx context.Context, input api.PrepareForSubmarinerInput) ([]api.Action, error) {
+	rules, err := newSecurityRules(generateRulePrefix(az.InfraID, internalPortsRuleName), "VirtualNetwork", input.InternalPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	return az.planOpenPorts(ctx, rules...)
+}
+
+// Plan returns the security rule changes and the gateway machine sets Deploy would create or remove on Azure.
+func (d *ocpGatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	if input.RestrictsSources() {
+		return nil, errors.New("restricting the public ports to source CIDRs isn't supported on Azure")
+	}
+
+	rules, err := newSecurityRules(generateRulePrefix(d.InfraID, publicPortsRuleName), "*", input.PublicPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := d.planOpenPorts(ctx, rules...)
+	if err != nil {
+		return nil, err
+	}
+
+	gateways, err := d.findGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gatewayNodesToDeploy := input.Gateways - len(gateways)
+	if gatewayNodesToDeploy < 0 && input.Gateways > 0 {
+		for _, gateway := range k8s.SurplusGateways(gateways, input.Gateways) {
+			actions = append(actions, api.Action{
+				Kind:       api.ActionDeleteMachineSet,
+				Resource:   gateway.Name,
+				Parameters: map[string]string{"zone": gateway.Zone},
+			})
+		}
+	}
+
+	if gatewayNodesToDeploy <= 0 {
+		return actions, nil
+	}
+
+	eligibleZones, err := d.eligibleZones(ctx, gateways)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zone := range eligibleZones {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionDeployMachineSet,
+			Resource: d.machineSetName(zone),
+			Parameters: map[string]string{
+				"namespace":     "openshift-machine-api",
+				"zone":          zone,
+				"instance-type": d.instanceType,
+				"image":         d.image,
+			},
+		})
+
+		gatewayNodesToDeploy--
+		if gatewayNodesToDeploy <= 0 {
+			return actions, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there are an insufficient number of zones (%d) to deploy the desired number of gateways (%d)",
+		len(eligibleZones)+len(gateways), input.Gateways)
+}
+
+// planOpenPorts returns the security rule creations, or the updates of rules which don't allow the expected ports,
+// openPorts would perform.
+func (c *CloudInfo) planOpenPorts(ctx context.Context, rules ...*armnetwork.SecurityRule) ([]api.Action, error) {
+	groupName := securityGroupName(c.InfraID)
+
+	group, err := c.Client.GetSecurityGroup(ctx, groupName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving network security group %q", groupName)
+	}
+
+	actions := []api.Action{}
+
+	for _, rule := range rules {
+		kind := api.ActionInsertFirewallRule
+
+		if existing := findSecurityRule(group, *rule.Name); existing != nil {
+			if formatRulePorts(existing) == formatRulePorts(rule) {
+				continue
+			}
+
+			kind = api.ActionUpdateFirewallRule
+		}
+
+		actions = append(actions, api.Action{
+			Kind:     kind,
+			Resource: *rule.Name,
+			Parameters: map[string]string{
+				"security-group": groupName,
+				"ports":          formatRulePorts(rule),
+			},
+		})
+	}
+
+	return actions, nil
+}
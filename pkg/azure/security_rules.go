@@ -0,0 +1,185 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+const (
+	internalPortsRuleName = "submariner-internal"
+	publicPortsRuleName   = "submariner-public"
+
+	// Submariner rules get the lowest free priorities from basePriority, leaving the lower ones to the installer.
+	basePriority int32 = 2000
+	maxPriority  int32 = 4096
+)
+
+// securityGroupName returns the name of the network security group OpenShift creates for the cluster's subnets.
+func securityGroupName(infraID string) string {
+	return infraID + "-nsg"
+}
+
+func generateRulePrefix(infraID, name string) string {
+	return fmt.Sprintf("%s-%s-", infraID, name)
+}
+
+// newSecurityRules returns an inbound rule per protocol, allowing the given ports from the given source. The rules are
+// named after the prefix and their protocol.
+func newSecurityRules(prefix, source string, ports []api.PortSpec) ([]*armnetwork.SecurityRule, error) {
+	portsByProtocol := map[armnetwork.SecurityRuleProtocol][]string{}
+
+	for _, port := range ports {
+		protocol, err := securityRuleProtocol(port.Protocol)
+		if err != nil {
+			return nil, err
+		}
+
+		portRange := "*"
+		if port.Port != 0 {
+			portRange = strconv.Itoa(int(port.Port))
+		}
+
+		portsByProtocol[protocol] = append(portsByProtocol[protocol], portRange)
+	}
+
+	protocols := make([]string, 0, len(portsByProtocol))
+	for protocol := range portsByProtocol {
+		protocols = append(protocols, string(protocol))
+	}
+
+	sort.Strings(protocols)
+
+	rules := make([]*armnetwork.SecurityRule, 0, len(protocols))
+
+	for _, protocol := range protocols {
+		portRanges := portsByProtocol[armnetwork.SecurityRuleProtocol(protocol)]
+		sort.Strings(portRanges)
+
+		rules = append(rules, &armnetwork.SecurityRule{
+			Name: stringPtr(prefix + strings.ToLower(protocol)),
+			Properties: &armnetwork.SecurityRulePropertiesFormat{
+				Access:                   accessPtr(armnetwork.SecurityRuleAccessAllow),
+				Direction:                directionPtr(armnetwork.SecurityRuleDirectionInbound),
+				Protocol:                 protocolPtr(armnetwork.SecurityRuleProtocol(protocol)),
+				SourceAddressPrefix:      stringPtr(source),
+				SourcePortRange:          stringPtr("*"),
+				DestinationAddressPrefix: stringPtr("*"),
+				DestinationPortRanges:    stringPtrs(portRanges),
+			},
+		})
+	}
+
+	return rules, nil
+}
+
+func securityRuleProtocol(protocol string) (armnetwork.SecurityRuleProtocol, error) {
+	for _, supported := range armnetwork.PossibleSecurityRuleProtocolValues() {
+		if strings.EqualFold(protocol, string(supported)) {
+			return supported, nil
+		}
+	}
+
+	return "", fmt.Errorf("protocol %q isn't supported by Azure network security groups", protocol)
+}
+
+// securityRulePorts returns the ports allowed by the given rule.
+func securityRulePorts(rule *armnetwork.SecurityRule) ([]api.PortSpec, error) {
+	ports := []api.PortSpec{}
+
+	if rule.Properties == nil || rule.Properties.Protocol == nil {
+		return ports, nil
+	}
+
+	protocol := strings.ToLower(string(*rule.Properties.Protocol))
+
+	for _, portRange := range rule.Properties.DestinationPortRanges {
+		if portRange == nil || *portRange == "*" {
+			ports = append(ports, api.PortSpec{Protocol: protocol})
+			continue
+		}
+
+		port, err := strconv.ParseUint(*portRange, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing port %q of security rule %q", *portRange, stringValue(rule.Name))
+		}
+
+		ports = append(ports, api.PortSpec{Port: uint16(port), Protocol: protocol})
+	}
+
+	return ports, nil
+}
+
+// formatRulePorts returns the ports allowed by the given rule, in a form which can be compared.
+func formatRulePorts(rule *armnetwork.SecurityRule) string {
+	if rule.Properties == nil || rule.Properties.Protocol == nil {
+		return ""
+	}
+
+	portStrs := []string{}
+	for _, portRange := range rule.Properties.DestinationPortRanges {
+		if portRange != nil {
+			portStrs = append(portStrs, *portRange+"/"+string(*rule.Properties.Protocol))
+		}
+	}
+
+	sort.Strings(portStrs)
+
+	return fmt.Sprintf("%s from %s", strings.Join(portStrs, ", "), stringValue(rule.Properties.SourceAddressPrefix))
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func stringPtrs(values []string) []*string {
+	ptrs := make([]*string, len(values))
+	for i := range values {
+		ptrs[i] = stringPtr(values[i])
+	}
+
+	return ptrs
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func accessPtr(access armnetwork.SecurityRuleAccess) *armnetwork.SecurityRuleAccess {
+	return &access
+}
+
+func directionPtr(direction armnetwork.SecurityRuleDirection) *armnetwork.SecurityRuleDirection {
+	return &direction
+}
+
+func protocolPtr(protocol armnetwork.SecurityRuleProtocol) *armnetwork.SecurityRuleProtocol {
+	return &protocol
+}
@@ -25,8 +25,10 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	compute "google.golang.org/api/compute/v1"
+	container "google.golang.org/api/container/v1"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
@@ -35,62 +37,89 @@ import (
 
 // Interface wraps an actual GCP library client to allow for easier testing.
 type Interface interface {
-	GetNetwork(projectID, networkName string) (*compute.Network, error)
-	DeleteVpcPeering(projectID, networkName string, removePeeringRequest *compute.NetworksRemovePeeringRequest) error
-	CreateVpcPeering(projectID, network string, peeringRequest *compute.NetworksAddPeeringRequest) error
-	InsertFirewallRule(projectID string, rule *compute.Firewall) error
-	GetFirewallRule(projectID, name string) (*compute.Firewall, error)
-	DeleteFirewallRule(projectID, name string) error
-	UpdateFirewallRule(projectID, name string, rule *compute.Firewall) error
-	GetInstance(zone string, instance string) (*compute.Instance, error)
-	ListInstances(zone string) (*compute.InstanceList, error)
-	ListZones() (*compute.ZoneList, error)
+	GetNetwork(ctx context.Context, projectID, networkName string) (*compute.Network, error)
+	DeleteVpcPeering(ctx context.Context, projectID, networkName string, removePeeringRequest *compute.NetworksRemovePeeringRequest) error
+	CreateVpcPeering(ctx context.Context, projectID, network string, peeringRequest *compute.NetworksAddPeeringRequest) error
+	InsertFirewallRule(ctx context.Context, projectID string, rule *compute.Firewall) error
+	GetFirewallRule(ctx context.Context, projectID, name string) (*compute.Firewall, error)
+	DeleteFirewallRule(ctx context.Context, projectID, name string) error
+	UpdateFirewallRule(ctx context.Context, projectID, name string, rule *compute.Firewall) error
+	ListFirewallRules(ctx context.Context, projectID, filter string) (*compute.FirewallList, error)
+	GetInstance(ctx context.Context, zone string, instance string) (*compute.Instance, error)
+	ListInstances(ctx context.Context, zone string) (*compute.InstanceList, error)
+	ListZones(ctx context.Context) (*compute.ZoneList, error)
 	InstanceHasPublicIP(instance *compute.Instance) (bool, error)
-	UpdateInstanceNetworkTags(project, zone, instance string, tags *compute.Tags) error
-	ConfigurePublicIPOnInstance(instance *compute.Instance) error
-	DeletePublicIPOnInstance(instance *compute.Instance) error
+	UpdateInstanceNetworkTags(ctx context.Context, project, zone, instance string, tags *compute.Tags) error
+	ConfigurePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error
+	DeletePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error
+	ConfigureStaticIPOnInstance(ctx context.Context, instance *compute.Instance, address string) error
+	ReserveAddress(ctx context.Context, region string, address *compute.Address) (*compute.Address, error)
+	GetAddress(ctx context.Context, region, name string) (*compute.Address, error)
+	ListAddresses(ctx context.Context, region string) (*compute.AddressList, error)
+	DeleteAddress(ctx context.Context, region, name string) error
+}
+
+// GKEInterface extends Interface with the Container API calls needed on GKE. The calls changing node pools wait for
+// their operation to complete.
+type GKEInterface interface {
+	Interface
+	GetCluster(ctx context.Context, location, clusterName string) (*container.Cluster, error)
+	GetNodePool(ctx context.Context, location, clusterName, nodePoolName string) (*container.NodePool, error)
+	CreateNodePool(ctx context.Context, location, clusterName string, nodePool *container.NodePool) error
+	SetNodePoolLocations(ctx context.Context, location, clusterName, nodePoolName string, locations []string) error
+	DeleteNodePool(ctx context.Context, location, clusterName, nodePoolName string) error
 }
 
 type gcpClient struct {
-	projectID     string
-	computeClient *compute.Service
+	projectID       string
+	computeClient   *compute.Service
+	containerClient *container.Service
 }
 
-func (g *gcpClient) GetNetwork(projectID, networkName string) (*compute.Network, error) {
-	return g.computeClient.Networks.Get(projectID, networkName).Context(context.TODO()).Do()
+func (g *gcpClient) GetNetwork(ctx context.Context, projectID, networkName string) (*compute.Network, error) {
+	return g.computeClient.Networks.Get(projectID, networkName).Context(ctx).Do()
 }
 
-func (g *gcpClient) DeleteVpcPeering(projectID, networkName string, removePeeringRequest *compute.NetworksRemovePeeringRequest) error {
-	_, err := g.computeClient.Networks.RemovePeering(projectID, networkName, removePeeringRequest).Context(context.TODO()).Do()
+func (g *gcpClient) DeleteVpcPeering(ctx context.Context, projectID, networkName string,
+	removePeeringRequest *compute.NetworksRemovePeeringRequest) error {
+	_, err := g.computeClient.Networks.RemovePeering(projectID, networkName, removePeeringRequest).Context(ctx).Do()
 	return err
 }
-func (g *gcpClient) CreateVpcPeering(projectID, networkName string, peeringRequest *compute.NetworksAddPeeringRequest) error {
-	_, err := g.computeClient.Networks.AddPeering(projectID, networkName, peeringRequest).Context(context.TODO()).Do()
+func (g *gcpClient) CreateVpcPeering(ctx context.Context, projectID, networkName string,
+	peeringRequest *compute.NetworksAddPeeringRequest) error {
+	_, err := g.computeClient.Networks.AddPeering(projectID, networkName, peeringRequest).Context(ctx).Do()
 	return err
 }
 
-func (g *gcpClient) InsertFirewallRule(projectID string, rule *compute.Firewall) error {
-	_, err := g.computeClient.Firewalls.Insert(projectID, rule).Context(context.TODO()).Do()
+func (g *gcpClient) InsertFirewallRule(ctx context.Context, projectID string, rule *compute.Firewall) error {
+	_, err := g.computeClient.Firewalls.Insert(projectID, rule).Context(ctx).Do()
 	return err
 }
 
-func (g *gcpClient) GetFirewallRule(projectID, name string) (*compute.Firewall, error) {
-	return g.computeClient.Firewalls.Get(projectID, name).Context(context.TODO()).Do()
+func (g *gcpClient) GetFirewallRule(ctx context.Context, projectID, name string) (*compute.Firewall, error) {
+	return g.computeClient.Firewalls.Get(projectID, name).Context(ctx).Do()
 }
 
-func (g *gcpClient) DeleteFirewallRule(projectID, name string) error {
-	_, err := g.computeClient.Firewalls.Delete(projectID, name).Context(context.TODO()).Do()
+func (g *gcpClient) DeleteFirewallRule(ctx context.Context, projectID, name string) error {
+	_, err := g.computeClient.Firewalls.Delete(projectID, name).Context(ctx).Do()
 	return err
 }
 
-func (g *gcpClient) UpdateFirewallRule(projectID, name string, rule *compute.Firewall) error {
-	_, err := g.computeClient.Firewalls.Update(projectID, name, rule).Context(context.TODO()).Do()
+func (g *gcpClient) UpdateFirewallRule(ctx context.Context, projectID, name string, rule *compute.Firewall) error {
+	_, err := g.computeClient.Firewalls.Update(projectID, name, rule).Context(ctx).Do()
 	return err
 }
 
+func (g *gcpClient) ListFirewallRules(ctx context.Context, projectID, filter string) (*compute.FirewallList, error) {
+	return g.computeClient.Firewalls.List(projectID).Filter(filter).Context(ctx).Do()
+}
+
 func NewClient(projectID string, options []option.ClientOption) (Interface, error) {
-	ctx := context.TODO()
+	return NewClientWithContext(context.Background(), projectID, options)
+}
 
+// NewClientWithContext is NewClient, creating the underlying services with the given context.
+func NewClientWithContext(ctx context.Context, projectID string, options []option.ClientOption) (Interface, error) {
 	computeClient, err := compute.NewService(ctx, options...)
 	if err != nil {
 		return nil, err
@@ -102,6 +131,30 @@ func NewClient(projectID string, options []option.ClientOption) (Interface, erro
 	}, nil
 }
 
+// NewGKEClient returns a client which can also manage GKE clusters through the Container API.
+func NewGKEClient(projectID string, options []option.ClientOption) (GKEInterface, error) {
+	return NewGKEClientWithContext(context.Background(), projectID, options)
+}
+
+// NewGKEClientWithContext is NewGKEClient, creating the underlying services with the given context.
+func NewGKEClientWithContext(ctx context.Context, projectID string, options []option.ClientOption) (GKEInterface, error) {
+	computeClient, err := compute.NewService(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	containerClient, err := container.NewService(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcpClient{
+		projectID:       projectID,
+		computeClient:   computeClient,
+		containerClient: containerClient,
+	}, nil
+}
+
 func IsGCPNotFoundError(err error) bool {
 	var gerr *googleapi.Error
 	if errors.As(err, &gerr) {
@@ -111,16 +164,16 @@ func IsGCPNotFoundError(err error) bool {
 	return false
 }
 
-func (g *gcpClient) GetInstance(zone, instance string) (*compute.Instance, error) {
-	return g.computeClient.Instances.Get(g.projectID, zone, instance).Context(context.TODO()).Do()
+func (g *gcpClient) GetInstance(ctx context.Context, zone, instance string) (*compute.Instance, error) {
+	return g.computeClient.Instances.Get(g.projectID, zone, instance).Context(ctx).Do()
 }
 
-func (g *gcpClient) ListInstances(zone string) (*compute.InstanceList, error) {
-	return g.computeClient.Instances.List(g.projectID, zone).Context(context.TODO()).Do()
+func (g *gcpClient) ListInstances(ctx context.Context, zone string) (*compute.InstanceList, error) {
+	return g.computeClient.Instances.List(g.projectID, zone).Context(ctx).Do()
 }
 
-func (g *gcpClient) ListZones() (*compute.ZoneList, error) {
-	return g.computeClient.Zones.List(g.projectID).Context(context.TODO()).Do()
+func (g *gcpClient) ListZones(ctx context.Context) (*compute.ZoneList, error) {
+	return g.computeClient.Zones.List(g.projectID).Context(ctx).Do()
 }
 
 func (g *gcpClient) InstanceHasPublicIP(instance *compute.Instance) (bool, error) {
@@ -133,13 +186,13 @@ func (g *gcpClient) InstanceHasPublicIP(instance *compute.Instance) (bool, error
 	return len(networkInterface.AccessConfigs) > 0, nil
 }
 
-func (g *gcpClient) UpdateInstanceNetworkTags(project, zone, instance string, tags *compute.Tags) error {
-	_, err := g.computeClient.Instances.SetTags(project, zone, instance, tags).Context(context.TODO()).Do()
+func (g *gcpClient) UpdateInstanceNetworkTags(ctx context.Context, project, zone, instance string, tags *compute.Tags) error {
+	_, err := g.computeClient.Instances.SetTags(project, zone, instance, tags).Context(ctx).Do()
 
 	return err
 }
 
-func (g *gcpClient) ConfigurePublicIPOnInstance(instance *compute.Instance) error {
+func (g *gcpClient) ConfigurePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error {
 	if len(instance.NetworkInterfaces) == 0 {
 		return fmt.Errorf("there are no network interfaces for instance %s", instance.Name)
 	}
@@ -154,12 +207,12 @@ func (g *gcpClient) ConfigurePublicIPOnInstance(instance *compute.Instance) erro
 
 	_, err := g.computeClient.Instances.AddAccessConfig(g.projectID, zone, instance.Name,
 		networkInterface.Name, &compute.AccessConfig{}).
-		Context(context.TODO()).Do()
+		Context(ctx).Do()
 
 	return err
 }
 
-func (g *gcpClient) DeletePublicIPOnInstance(instance *compute.Instance) error {
+func (g *gcpClient) DeletePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error {
 	if len(instance.NetworkInterfaces) == 0 {
 		return fmt.Errorf("there are no network interfaces for instance %s", instance.Name)
 	}
@@ -169,7 +222,181 @@ func (g *gcpClient) DeletePublicIPOnInstance(instance *compute.Instance) error {
 	networkInterface := instance.NetworkInterfaces[0]
 	_, err := g.computeClient.Instances.DeleteAccessConfig(
 		g.projectID, zone, instance.Name, "External NAT", networkInterface.Name).
-		Context(context.TODO()).Do()
+		Context(ctx).Do()
 
 	return err
 }
+
+// ConfigureStaticIPOnInstance replaces the external access configuration of the given instance with one using the given
+// static address, unless it already uses it.
+func (g *gcpClient) ConfigureStaticIPOnInstance(ctx context.Context, instance *compute.Instance, address string) error {
+	if len(instance.NetworkInterfaces) == 0 {
+		return fmt.Errorf("there are no network interfaces for instance %s", instance.Name)
+	}
+
+	zone := instance.Zone[strings.LastIndex(instance.Zone, "/")+1:]
+	networkInterface := instance.NetworkInterfaces[0]
+
+	for _, accessConfig := range networkInterface.AccessConfigs {
+		if accessConfig.NatIP == address {
+			return nil
+		}
+
+		operation, err := g.computeClient.Instances.DeleteAccessConfig(g.projectID, zone, instance.Name, accessConfig.Name,
+			networkInterface.Name).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+
+		err = g.waitForZoneOperation(ctx, zone, operation)
+		if err != nil {
+			return err
+		}
+	}
+
+	operation, err := g.computeClient.Instances.AddAccessConfig(g.projectID, zone, instance.Name, networkInterface.Name,
+		&compute.AccessConfig{Name: "External NAT", Type: "ONE_TO_ONE_NAT", NatIP: address}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	return g.waitForZoneOperation(ctx, zone, operation)
+}
+
+// ReserveAddress reserves the given regional address, and returns it once it's reserved.
+func (g *gcpClient) ReserveAddress(ctx context.Context, region string, address *compute.Address) (*compute.Address, error) {
+	operation, err := g.computeClient.Addresses.Insert(g.projectID, region, address).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.waitForRegionOperation(ctx, region, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.GetAddress(ctx, region, address.Name)
+}
+
+func (g *gcpClient) GetAddress(ctx context.Context, region, name string) (*compute.Address, error) {
+	return g.computeClient.Addresses.Get(g.projectID, region, name).Context(ctx).Do()
+}
+
+func (g *gcpClient) ListAddresses(ctx context.Context, region string) (*compute.AddressList, error) {
+	return g.computeClient.Addresses.List(g.projectID, region).Context(ctx).Do()
+}
+
+func (g *gcpClient) DeleteAddress(ctx context.Context, region, name string) error {
+	operation, err := g.computeClient.Addresses.Delete(g.projectID, region, name).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	return g.waitForRegionOperation(ctx, region, operation)
+}
+
+// waitForRegionOperation waits for the given regional Compute API operation to be done, returning its error if it failed.
+func (g *gcpClient) waitForRegionOperation(ctx context.Context, region string, operation *compute.Operation) error {
+	for operation.Status != "DONE" {
+		var err error
+
+		operation, err = g.computeClient.RegionOperations.Wait(g.projectID, region, operation.Name).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+	}
+
+	return computeOperationError(operation)
+}
+
+// waitForZoneOperation waits for the given zonal Compute API operation to be done, returning its error if it failed.
+func (g *gcpClient) waitForZoneOperation(ctx context.Context, zone string, operation *compute.Operation) error {
+	for operation.Status != "DONE" {
+		var err error
+
+		operation, err = g.computeClient.ZoneOperations.Wait(g.projectID, zone, operation.Name).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+	}
+
+	return computeOperationError(operation)
+}
+
+func computeOperationError(operation *compute.Operation) error {
+	if operation.Error == nil || len(operation.Error.Errors) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("operation %s failed: %s", operation.Name, operation.Error.Errors[0].Message)
+}
+
+func (g *gcpClient) clusterName(location, clusterName string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/clusters/%s", g.projectID, location, clusterName)
+}
+
+func (g *gcpClient) GetCluster(ctx context.Context, location, clusterName string) (*container.Cluster, error) {
+	return g.containerClient.Projects.Locations.Clusters.Get(g.clusterName(location, clusterName)).Context(ctx).Do()
+}
+
+func (g *gcpClient) GetNodePool(ctx context.Context, location, clusterName, nodePoolName string) (*container.NodePool, error) {
+	return g.containerClient.Projects.Locations.Clusters.NodePools.Get(
+		g.clusterName(location, clusterName) + "/nodePools/" + nodePoolName).Context(ctx).Do()
+}
+
+func (g *gcpClient) CreateNodePool(ctx context.Context, location, clusterName string, nodePool *container.NodePool) error {
+	operation, err := g.containerClient.Projects.Locations.Clusters.NodePools.Create(g.clusterName(location, clusterName),
+		&container.CreateNodePoolRequest{NodePool: nodePool}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	return g.waitForOperation(ctx, location, operation)
+}
+
+func (g *gcpClient) SetNodePoolLocations(ctx context.Context, location, clusterName, nodePoolName string, locations []string) error {
+	operation, err := g.containerClient.Projects.Locations.Clusters.NodePools.Update(
+		g.clusterName(location, clusterName)+"/nodePools/"+nodePoolName,
+		&container.UpdateNodePoolRequest{Locations: locations}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	return g.waitForOperation(ctx, location, operation)
+}
+
+func (g *gcpClient) DeleteNodePool(ctx context.Context, location, clusterName, nodePoolName string) error {
+	operation, err := g.containerClient.Projects.Locations.Clusters.NodePools.Delete(
+		g.clusterName(location, clusterName) + "/nodePools/" + nodePoolName).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	return g.waitForOperation(ctx, location, operation)
+}
+
+// waitForOperation polls the given Container API operation until it's done, returning its error if it failed.
+func (g *gcpClient) waitForOperation(ctx context.Context, location string, operation *container.Operation) error {
+	name := fmt.Sprintf("projects/%s/locations/%s/operations/%s", g.projectID, location, operation.Name)
+
+	for operation.Status != "DONE" {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
+
+		var err error
+
+		operation, err = g.containerClient.Projects.Locations.Operations.Get(name).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+	}
+
+	if operation.Error != nil {
+		return fmt.Errorf("operation %s failed: %s", operation.Name, operation.Error.Message)
+	}
+
+	return nil
+}
@@ -5,10 +5,12 @@
 package fake
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
 	compute "google.golang.org/api/compute/v1"
+	container "google.golang.org/api/container/v1"
 )
 
 // MockInterface is a mock of Interface interface.
@@ -35,132 +37,175 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 }
 
 // ConfigurePublicIPOnInstance mocks base method.
-func (m *MockInterface) ConfigurePublicIPOnInstance(instance *compute.Instance) error {
+func (m *MockInterface) ConfigurePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ConfigurePublicIPOnInstance", instance)
+	ret := m.ctrl.Call(m, "ConfigurePublicIPOnInstance", ctx, instance)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ConfigurePublicIPOnInstance indicates an expected call of ConfigurePublicIPOnInstance.
-func (mr *MockInterfaceMockRecorder) ConfigurePublicIPOnInstance(instance interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) ConfigurePublicIPOnInstance(ctx, instance interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigurePublicIPOnInstance", reflect.TypeOf((*MockInterface)(nil).ConfigurePublicIPOnInstance), instance)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigurePublicIPOnInstance", reflect.TypeOf((*MockInterface)(nil).ConfigurePublicIPOnInstance), ctx, instance)
+}
+
+// ConfigureStaticIPOnInstance mocks base method.
+func (m *MockInterface) ConfigureStaticIPOnInstance(ctx context.Context, instance *compute.Instance, address string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureStaticIPOnInstance", ctx, instance, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureStaticIPOnInstance indicates an expected call of ConfigureStaticIPOnInstance.
+func (mr *MockInterfaceMockRecorder) ConfigureStaticIPOnInstance(ctx, instance, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureStaticIPOnInstance", reflect.TypeOf((*MockInterface)(nil).ConfigureStaticIPOnInstance), ctx, instance, address)
 }
 
 // CreateVpcPeering mocks base method.
-func (m *MockInterface) CreateVpcPeering(projectID, network string, peeringRequest *compute.NetworksAddPeeringRequest) error {
+func (m *MockInterface) CreateVpcPeering(ctx context.Context, projectID, network string, peeringRequest *compute.NetworksAddPeeringRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateVpcPeering", projectID, network, peeringRequest)
+	ret := m.ctrl.Call(m, "CreateVpcPeering", ctx, projectID, network, peeringRequest)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateVpcPeering indicates an expected call of CreateVpcPeering.
-func (mr *MockInterfaceMockRecorder) CreateVpcPeering(projectID, network, peeringRequest interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) CreateVpcPeering(ctx, projectID, network, peeringRequest interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVpcPeering", reflect.TypeOf((*MockInterface)(nil).CreateVpcPeering), projectID, network, peeringRequest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVpcPeering", reflect.TypeOf((*MockInterface)(nil).CreateVpcPeering), ctx, projectID, network, peeringRequest)
+}
+
+// DeleteAddress mocks base method.
+func (m *MockInterface) DeleteAddress(ctx context.Context, region, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddress", ctx, region, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAddress indicates an expected call of DeleteAddress.
+func (mr *MockInterfaceMockRecorder) DeleteAddress(ctx, region, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddress", reflect.TypeOf((*MockInterface)(nil).DeleteAddress), ctx, region, name)
 }
 
 // DeleteFirewallRule mocks base method.
-func (m *MockInterface) DeleteFirewallRule(projectID, name string) error {
+func (m *MockInterface) DeleteFirewallRule(ctx context.Context, projectID, name string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFirewallRule", projectID, name)
+	ret := m.ctrl.Call(m, "DeleteFirewallRule", ctx, projectID, name)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteFirewallRule indicates an expected call of DeleteFirewallRule.
-func (mr *MockInterfaceMockRecorder) DeleteFirewallRule(projectID, name interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) DeleteFirewallRule(ctx, projectID, name interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFirewallRule", reflect.TypeOf((*MockInterface)(nil).DeleteFirewallRule), projectID, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFirewallRule", reflect.TypeOf((*MockInterface)(nil).DeleteFirewallRule), ctx, projectID, name)
 }
 
 // DeletePublicIPOnInstance mocks base method.
-func (m *MockInterface) DeletePublicIPOnInstance(instance *compute.Instance) error {
+func (m *MockInterface) DeletePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePublicIPOnInstance", instance)
+	ret := m.ctrl.Call(m, "DeletePublicIPOnInstance", ctx, instance)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePublicIPOnInstance indicates an expected call of DeletePublicIPOnInstance.
-func (mr *MockInterfaceMockRecorder) DeletePublicIPOnInstance(instance interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) DeletePublicIPOnInstance(ctx, instance interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublicIPOnInstance", reflect.TypeOf((*MockInterface)(nil).DeletePublicIPOnInstance), instance)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublicIPOnInstance", reflect.TypeOf((*MockInterface)(nil).DeletePublicIPOnInstance), ctx, instance)
 }
 
 // DeleteVpcPeering mocks base method.
-func (m *MockInterface) DeleteVpcPeering(projectID, networkName string, removePeeringRequest *compute.NetworksRemovePeeringRequest) error {
+func (m *MockInterface) DeleteVpcPeering(ctx context.Context, projectID, networkName string, removePeeringRequest *compute.NetworksRemovePeeringRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteVpcPeering", projectID, networkName, removePeeringRequest)
+	ret := m.ctrl.Call(m, "DeleteVpcPeering", ctx, projectID, networkName, removePeeringRequest)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteVpcPeering indicates an expected call of DeleteVpcPeering.
-func (mr *MockInterfaceMockRecorder) DeleteVpcPeering(projectID, networkName, removePeeringRequest interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) DeleteVpcPeering(ctx, projectID, networkName, removePeeringRequest interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVpcPeering", reflect.TypeOf((*MockInterface)(nil).DeleteVpcPeering), projectID, networkName, removePeeringRequest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVpcPeering", reflect.TypeOf((*MockInterface)(nil).DeleteVpcPeering), ctx, projectID, networkName, removePeeringRequest)
+}
+
+// GetAddress mocks base method.
+func (m *MockInterface) GetAddress(ctx context.Context, region, name string) (*compute.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", ctx, region, name)
+	ret0, _ := ret[0].(*compute.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress.
+func (mr *MockInterfaceMockRecorder) GetAddress(ctx, region, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockInterface)(nil).GetAddress), ctx, region, name)
 }
 
 // GetFirewallRule mocks base method.
-func (m *MockInterface) GetFirewallRule(projectID, name string) (*compute.Firewall, error) {
+func (m *MockInterface) GetFirewallRule(ctx context.Context, projectID, name string) (*compute.Firewall, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFirewallRule", projectID, name)
+	ret := m.ctrl.Call(m, "GetFirewallRule", ctx, projectID, name)
 	ret0, _ := ret[0].(*compute.Firewall)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFirewallRule indicates an expected call of GetFirewallRule.
-func (mr *MockInterfaceMockRecorder) GetFirewallRule(projectID, name interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) GetFirewallRule(ctx, projectID, name interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirewallRule", reflect.TypeOf((*MockInterface)(nil).GetFirewallRule), projectID, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirewallRule", reflect.TypeOf((*MockInterface)(nil).GetFirewallRule), ctx, projectID, name)
 }
 
 // GetInstance mocks base method.
-func (m *MockInterface) GetInstance(zone, instance string) (*compute.Instance, error) {
+func (m *MockInterface) GetInstance(ctx context.Context, zone, instance string) (*compute.Instance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInstance", zone, instance)
+	ret := m.ctrl.Call(m, "GetInstance", ctx, zone, instance)
 	ret0, _ := ret[0].(*compute.Instance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInstance indicates an expected call of GetInstance.
-func (mr *MockInterfaceMockRecorder) GetInstance(zone, instance interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) GetInstance(ctx, zone, instance interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstance", reflect.TypeOf((*MockInterface)(nil).GetInstance), zone, instance)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstance", reflect.TypeOf((*MockInterface)(nil).GetInstance), ctx, zone, instance)
 }
 
 // GetNetwork mocks base method.
-func (m *MockInterface) GetNetwork(projectID, networkName string) (*compute.Network, error) {
+func (m *MockInterface) GetNetwork(ctx context.Context, projectID, networkName string) (*compute.Network, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNetwork", projectID, networkName)
+	ret := m.ctrl.Call(m, "GetNetwork", ctx, projectID, networkName)
 	ret0, _ := ret[0].(*compute.Network)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetNetwork indicates an expected call of GetNetwork.
-func (mr *MockInterfaceMockRecorder) GetNetwork(projectID, networkName interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) GetNetwork(ctx, projectID, networkName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetwork", reflect.TypeOf((*MockInterface)(nil).GetNetwork), projectID, networkName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetwork", reflect.TypeOf((*MockInterface)(nil).GetNetwork), ctx, projectID, networkName)
 }
 
 // InsertFirewallRule mocks base method.
-func (m *MockInterface) InsertFirewallRule(projectID string, rule *compute.Firewall) error {
+func (m *MockInterface) InsertFirewallRule(ctx context.Context, projectID string, rule *compute.Firewall) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertFirewallRule", projectID, rule)
+	ret := m.ctrl.Call(m, "InsertFirewallRule", ctx, projectID, rule)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // InsertFirewallRule indicates an expected call of InsertFirewallRule.
-func (mr *MockInterfaceMockRecorder) InsertFirewallRule(projectID, rule interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) InsertFirewallRule(ctx, projectID, rule interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertFirewallRule", reflect.TypeOf((*MockInterface)(nil).InsertFirewallRule), projectID, rule)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertFirewallRule", reflect.TypeOf((*MockInterface)(nil).InsertFirewallRule), ctx, projectID, rule)
 }
 
 // InstanceHasPublicIP mocks base method.
@@ -178,60 +223,490 @@ func (mr *MockInterfaceMockRecorder) InstanceHasPublicIP(instance interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceHasPublicIP", reflect.TypeOf((*MockInterface)(nil).InstanceHasPublicIP), instance)
 }
 
+// ListAddresses mocks base method.
+func (m *MockInterface) ListAddresses(ctx context.Context, region string) (*compute.AddressList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAddresses", ctx, region)
+	ret0, _ := ret[0].(*compute.AddressList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAddresses indicates an expected call of ListAddresses.
+func (mr *MockInterfaceMockRecorder) ListAddresses(ctx, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAddresses", reflect.TypeOf((*MockInterface)(nil).ListAddresses), ctx, region)
+}
+
+// ListFirewallRules mocks base method.
+func (m *MockInterface) ListFirewallRules(ctx context.Context, projectID, filter string) (*compute.FirewallList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFirewallRules", ctx, projectID, filter)
+	ret0, _ := ret[0].(*compute.FirewallList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFirewallRules indicates an expected call of ListFirewallRules.
+func (mr *MockInterfaceMockRecorder) ListFirewallRules(ctx, projectID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFirewallRules", reflect.TypeOf((*MockInterface)(nil).ListFirewallRules), ctx, projectID, filter)
+}
+
 // ListInstances mocks base method.
-func (m *MockInterface) ListInstances(zone string) (*compute.InstanceList, error) {
+func (m *MockInterface) ListInstances(ctx context.Context, zone string) (*compute.InstanceList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListInstances", zone)
+	ret := m.ctrl.Call(m, "ListInstances", ctx, zone)
 	ret0, _ := ret[0].(*compute.InstanceList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListInstances indicates an expected call of ListInstances.
-func (mr *MockInterfaceMockRecorder) ListInstances(zone interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) ListInstances(ctx, zone interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInstances", reflect.TypeOf((*MockInterface)(nil).ListInstances), zone)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInstances", reflect.TypeOf((*MockInterface)(nil).ListInstances), ctx, zone)
 }
 
 // ListZones mocks base method.
-func (m *MockInterface) ListZones() (*compute.ZoneList, error) {
+func (m *MockInterface) ListZones(ctx context.Context) (*compute.ZoneList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListZones")
+	ret := m.ctrl.Call(m, "ListZones", ctx)
 	ret0, _ := ret[0].(*compute.ZoneList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListZones indicates an expected call of ListZones.
-func (mr *MockInterfaceMockRecorder) ListZones() *gomock.Call {
+func (mr *MockInterfaceMockRecorder) ListZones(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListZones", reflect.TypeOf((*MockInterface)(nil).ListZones), ctx)
+}
+
+// ReserveAddress mocks base method.
+func (m *MockInterface) ReserveAddress(ctx context.Context, region string, address *compute.Address) (*compute.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveAddress", ctx, region, address)
+	ret0, _ := ret[0].(*compute.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveAddress indicates an expected call of ReserveAddress.
+func (mr *MockInterfaceMockRecorder) ReserveAddress(ctx, region, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveAddress", reflect.TypeOf((*MockInterface)(nil).ReserveAddress), ctx, region, address)
+}
+
+// UpdateFirewallRule mocks base method.
+func (m *MockInterface) UpdateFirewallRule(ctx context.Context, projectID, name string, rule *compute.Firewall) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFirewallRule", ctx, projectID, name, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFirewallRule indicates an expected call of UpdateFirewallRule.
+func (mr *MockInterfaceMockRecorder) UpdateFirewallRule(ctx, projectID, name, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFirewallRule", reflect.TypeOf((*MockInterface)(nil).UpdateFirewallRule), ctx, projectID, name, rule)
+}
+
+// UpdateInstanceNetworkTags mocks base method.
+func (m *MockInterface) UpdateInstanceNetworkTags(ctx context.Context, project, zone, instance string, tags *compute.Tags) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInstanceNetworkTags", ctx, project, zone, instance, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInstanceNetworkTags indicates an expected call of UpdateInstanceNetworkTags.
+func (mr *MockInterfaceMockRecorder) UpdateInstanceNetworkTags(ctx, project, zone, instance, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstanceNetworkTags", reflect.TypeOf((*MockInterface)(nil).UpdateInstanceNetworkTags), ctx, project, zone, instance, tags)
+}
+
+// MockGKEInterface is a mock of GKEInterface interface.
+type MockGKEInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockGKEInterfaceMockRecorder
+}
+
+// MockGKEInterfaceMockRecorder is the mock recorder for MockGKEInterface.
+type MockGKEInterfaceMockRecorder struct {
+	mock *MockGKEInterface
+}
+
+// NewMockGKEInterface creates a new mock instance.
+func NewMockGKEInterface(ctrl *gomock.Controller) *MockGKEInterface {
+	mock := &MockGKEInterface{ctrl: ctrl}
+	mock.recorder = &MockGKEInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGKEInterface) EXPECT() *MockGKEInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ConfigurePublicIPOnInstance mocks base method.
+func (m *MockGKEInterface) ConfigurePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigurePublicIPOnInstance", ctx, instance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigurePublicIPOnInstance indicates an expected call of ConfigurePublicIPOnInstance.
+func (mr *MockGKEInterfaceMockRecorder) ConfigurePublicIPOnInstance(ctx, instance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigurePublicIPOnInstance", reflect.TypeOf((*MockGKEInterface)(nil).ConfigurePublicIPOnInstance), ctx, instance)
+}
+
+// ConfigureStaticIPOnInstance mocks base method.
+func (m *MockGKEInterface) ConfigureStaticIPOnInstance(ctx context.Context, instance *compute.Instance, address string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureStaticIPOnInstance", ctx, instance, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureStaticIPOnInstance indicates an expected call of ConfigureStaticIPOnInstance.
+func (mr *MockGKEInterfaceMockRecorder) ConfigureStaticIPOnInstance(ctx, instance, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureStaticIPOnInstance", reflect.TypeOf((*MockGKEInterface)(nil).ConfigureStaticIPOnInstance), ctx, instance, address)
+}
+
+// CreateNodePool mocks base method.
+func (m *MockGKEInterface) CreateNodePool(ctx context.Context, location, clusterName string, nodePool *container.NodePool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNodePool", ctx, location, clusterName, nodePool)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNodePool indicates an expected call of CreateNodePool.
+func (mr *MockGKEInterfaceMockRecorder) CreateNodePool(ctx, location, clusterName, nodePool interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNodePool", reflect.TypeOf((*MockGKEInterface)(nil).CreateNodePool), ctx, location, clusterName, nodePool)
+}
+
+// CreateVpcPeering mocks base method.
+func (m *MockGKEInterface) CreateVpcPeering(ctx context.Context, projectID, network string, peeringRequest *compute.NetworksAddPeeringRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVpcPeering", ctx, projectID, network, peeringRequest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVpcPeering indicates an expected call of CreateVpcPeering.
+func (mr *MockGKEInterfaceMockRecorder) CreateVpcPeering(ctx, projectID, network, peeringRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVpcPeering", reflect.TypeOf((*MockGKEInterface)(nil).CreateVpcPeering), ctx, projectID, network, peeringRequest)
+}
+
+// DeleteAddress mocks base method.
+func (m *MockGKEInterface) DeleteAddress(ctx context.Context, region, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddress", ctx, region, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAddress indicates an expected call of DeleteAddress.
+func (mr *MockGKEInterfaceMockRecorder) DeleteAddress(ctx, region, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddress", reflect.TypeOf((*MockGKEInterface)(nil).DeleteAddress), ctx, region, name)
+}
+
+// DeleteFirewallRule mocks base method.
+func (m *MockGKEInterface) DeleteFirewallRule(ctx context.Context, projectID, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFirewallRule", ctx, projectID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFirewallRule indicates an expected call of DeleteFirewallRule.
+func (mr *MockGKEInterfaceMockRecorder) DeleteFirewallRule(ctx, projectID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFirewallRule", reflect.TypeOf((*MockGKEInterface)(nil).DeleteFirewallRule), ctx, projectID, name)
+}
+
+// DeleteNodePool mocks base method.
+func (m *MockGKEInterface) DeleteNodePool(ctx context.Context, location, clusterName, nodePoolName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNodePool", ctx, location, clusterName, nodePoolName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNodePool indicates an expected call of DeleteNodePool.
+func (mr *MockGKEInterfaceMockRecorder) DeleteNodePool(ctx, location, clusterName, nodePoolName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNodePool", reflect.TypeOf((*MockGKEInterface)(nil).DeleteNodePool), ctx, location, clusterName, nodePoolName)
+}
+
+// DeletePublicIPOnInstance mocks base method.
+func (m *MockGKEInterface) DeletePublicIPOnInstance(ctx context.Context, instance *compute.Instance) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePublicIPOnInstance", ctx, instance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePublicIPOnInstance indicates an expected call of DeletePublicIPOnInstance.
+func (mr *MockGKEInterfaceMockRecorder) DeletePublicIPOnInstance(ctx, instance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublicIPOnInstance", reflect.TypeOf((*MockGKEInterface)(nil).DeletePublicIPOnInstance), ctx, instance)
+}
+
+// DeleteVpcPeering mocks base method.
+func (m *MockGKEInterface) DeleteVpcPeering(ctx context.Context, projectID, networkName string, removePeeringRequest *compute.NetworksRemovePeeringRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVpcPeering", ctx, projectID, networkName, removePeeringRequest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVpcPeering indicates an expected call of DeleteVpcPeering.
+func (mr *MockGKEInterfaceMockRecorder) DeleteVpcPeering(ctx, projectID, networkName, removePeeringRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVpcPeering", reflect.TypeOf((*MockGKEInterface)(nil).DeleteVpcPeering), ctx, projectID, networkName, removePeeringRequest)
+}
+
+// GetAddress mocks base method.
+func (m *MockGKEInterface) GetAddress(ctx context.Context, region, name string) (*compute.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", ctx, region, name)
+	ret0, _ := ret[0].(*compute.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress.
+func (mr *MockGKEInterfaceMockRecorder) GetAddress(ctx, region, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockGKEInterface)(nil).GetAddress), ctx, region, name)
+}
+
+// GetCluster mocks base method.
+func (m *MockGKEInterface) GetCluster(ctx context.Context, location, clusterName string) (*container.Cluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCluster", ctx, location, clusterName)
+	ret0, _ := ret[0].(*container.Cluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCluster indicates an expected call of GetCluster.
+func (mr *MockGKEInterfaceMockRecorder) GetCluster(ctx, location, clusterName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockGKEInterface)(nil).GetCluster), ctx, location, clusterName)
+}
+
+// GetFirewallRule mocks base method.
+func (m *MockGKEInterface) GetFirewallRule(ctx context.Context, projectID, name string) (*compute.Firewall, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirewallRule", ctx, projectID, name)
+	ret0, _ := ret[0].(*compute.Firewall)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirewallRule indicates an expected call of GetFirewallRule.
+func (mr *MockGKEInterfaceMockRecorder) GetFirewallRule(ctx, projectID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirewallRule", reflect.TypeOf((*MockGKEInterface)(nil).GetFirewallRule), ctx, projectID, name)
+}
+
+// GetInstance mocks base method.
+func (m *MockGKEInterface) GetInstance(ctx context.Context, zone, instance string) (*compute.Instance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstance", ctx, zone, instance)
+	ret0, _ := ret[0].(*compute.Instance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstance indicates an expected call of GetInstance.
+func (mr *MockGKEInterfaceMockRecorder) GetInstance(ctx, zone, instance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstance", reflect.TypeOf((*MockGKEInterface)(nil).GetInstance), ctx, zone, instance)
+}
+
+// GetNetwork mocks base method.
+func (m *MockGKEInterface) GetNetwork(ctx context.Context, projectID, networkName string) (*compute.Network, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetwork", ctx, projectID, networkName)
+	ret0, _ := ret[0].(*compute.Network)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNetwork indicates an expected call of GetNetwork.
+func (mr *MockGKEInterfaceMockRecorder) GetNetwork(ctx, projectID, networkName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetwork", reflect.TypeOf((*MockGKEInterface)(nil).GetNetwork), ctx, projectID, networkName)
+}
+
+// GetNodePool mocks base method.
+func (m *MockGKEInterface) GetNodePool(ctx context.Context, location, clusterName, nodePoolName string) (*container.NodePool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNodePool", ctx, location, clusterName, nodePoolName)
+	ret0, _ := ret[0].(*container.NodePool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNodePool indicates an expected call of GetNodePool.
+func (mr *MockGKEInterfaceMockRecorder) GetNodePool(ctx, location, clusterName, nodePoolName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodePool", reflect.TypeOf((*MockGKEInterface)(nil).GetNodePool), ctx, location, clusterName, nodePoolName)
+}
+
+// InsertFirewallRule mocks base method.
+func (m *MockGKEInterface) InsertFirewallRule(ctx context.Context, projectID string, rule *compute.Firewall) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertFirewallRule", ctx, projectID, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertFirewallRule indicates an expected call of InsertFirewallRule.
+func (mr *MockGKEInterfaceMockRecorder) InsertFirewallRule(ctx, projectID, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertFirewallRule", reflect.TypeOf((*MockGKEInterface)(nil).InsertFirewallRule), ctx, projectID, rule)
+}
+
+// InstanceHasPublicIP mocks base method.
+func (m *MockGKEInterface) InstanceHasPublicIP(instance *compute.Instance) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstanceHasPublicIP", instance)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InstanceHasPublicIP indicates an expected call of InstanceHasPublicIP.
+func (mr *MockGKEInterfaceMockRecorder) InstanceHasPublicIP(instance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceHasPublicIP", reflect.TypeOf((*MockGKEInterface)(nil).InstanceHasPublicIP), instance)
+}
+
+// ListAddresses mocks base method.
+func (m *MockGKEInterface) ListAddresses(ctx context.Context, region string) (*compute.AddressList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAddresses", ctx, region)
+	ret0, _ := ret[0].(*compute.AddressList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAddresses indicates an expected call of ListAddresses.
+func (mr *MockGKEInterfaceMockRecorder) ListAddresses(ctx, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAddresses", reflect.TypeOf((*MockGKEInterface)(nil).ListAddresses), ctx, region)
+}
+
+// ListFirewallRules mocks base method.
+func (m *MockGKEInterface) ListFirewallRules(ctx context.Context, projectID, filter string) (*compute.FirewallList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFirewallRules", ctx, projectID, filter)
+	ret0, _ := ret[0].(*compute.FirewallList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFirewallRules indicates an expected call of ListFirewallRules.
+func (mr *MockGKEInterfaceMockRecorder) ListFirewallRules(ctx, projectID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFirewallRules", reflect.TypeOf((*MockGKEInterface)(nil).ListFirewallRules), ctx, projectID, filter)
+}
+
+// ListInstances mocks base method.
+func (m *MockGKEInterface) ListInstances(ctx context.Context, zone string) (*compute.InstanceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInstances", ctx, zone)
+	ret0, _ := ret[0].(*compute.InstanceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInstances indicates an expected call of ListInstances.
+func (mr *MockGKEInterfaceMockRecorder) ListInstances(ctx, zone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInstances", reflect.TypeOf((*MockGKEInterface)(nil).ListInstances), ctx, zone)
+}
+
+// ListZones mocks base method.
+func (m *MockGKEInterface) ListZones(ctx context.Context) (*compute.ZoneList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListZones", ctx)
+	ret0, _ := ret[0].(*compute.ZoneList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListZones indicates an expected call of ListZones.
+func (mr *MockGKEInterfaceMockRecorder) ListZones(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListZones", reflect.TypeOf((*MockGKEInterface)(nil).ListZones), ctx)
+}
+
+// ReserveAddress mocks base method.
+func (m *MockGKEInterface) ReserveAddress(ctx context.Context, region string, address *compute.Address) (*compute.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveAddress", ctx, region, address)
+	ret0, _ := ret[0].(*compute.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveAddress indicates an expected call of ReserveAddress.
+func (mr *MockGKEInterfaceMockRecorder) ReserveAddress(ctx, region, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveAddress", reflect.TypeOf((*MockGKEInterface)(nil).ReserveAddress), ctx, region, address)
+}
+
+// SetNodePoolLocations mocks base method.
+func (m *MockGKEInterface) SetNodePoolLocations(ctx context.Context, location, clusterName, nodePoolName string, locations []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNodePoolLocations", ctx, location, clusterName, nodePoolName, locations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNodePoolLocations indicates an expected call of SetNodePoolLocations.
+func (mr *MockGKEInterfaceMockRecorder) SetNodePoolLocations(ctx, location, clusterName, nodePoolName, locations interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListZones", reflect.TypeOf((*MockInterface)(nil).ListZones))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNodePoolLocations", reflect.TypeOf((*MockGKEInterface)(nil).SetNodePoolLocations), ctx, location, clusterName, nodePoolName, locations)
 }
 
 // UpdateFirewallRule mocks base method.
-func (m *MockInterface) UpdateFirewallRule(projectID, name string, rule *compute.Firewall) error {
+func (m *MockGKEInterface) UpdateFirewallRule(ctx context.Context, projectID, name string, rule *compute.Firewall) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateFirewallRule", projectID, name, rule)
+	ret := m.ctrl.Call(m, "UpdateFirewallRule", ctx, projectID, name, rule)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateFirewallRule indicates an expected call of UpdateFirewallRule.
-func (mr *MockInterfaceMockRecorder) UpdateFirewallRule(projectID, name, rule interface{}) *gomock.Call {
+func (mr *MockGKEInterfaceMockRecorder) UpdateFirewallRule(ctx, projectID, name, rule interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFirewallRule", reflect.TypeOf((*MockInterface)(nil).UpdateFirewallRule), projectID, name, rule)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFirewallRule", reflect.TypeOf((*MockGKEInterface)(nil).UpdateFirewallRule), ctx, projectID, name, rule)
 }
 
 // UpdateInstanceNetworkTags mocks base method.
-func (m *MockInterface) UpdateInstanceNetworkTags(project, zone, instance string, tags *compute.Tags) error {
+func (m *MockGKEInterface) UpdateInstanceNetworkTags(ctx context.Context, project, zone, instance string, tags *compute.Tags) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateInstanceNetworkTags", project, zone, instance, tags)
+	ret := m.ctrl.Call(m, "UpdateInstanceNetworkTags", ctx, project, zone, instance, tags)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateInstanceNetworkTags indicates an expected call of UpdateInstanceNetworkTags.
-func (mr *MockInterfaceMockRecorder) UpdateInstanceNetworkTags(project, zone, instance, tags interface{}) *gomock.Call {
+func (mr *MockGKEInterfaceMockRecorder) UpdateInstanceNetworkTags(ctx, project, zone, instance, tags interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstanceNetworkTags", reflect.TypeOf((*MockInterface)(nil).UpdateInstanceNetworkTags), project, zone, instance, tags)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstanceNetworkTags", reflect.TypeOf((*MockGKEInterface)(nil).UpdateInstanceNetworkTags), ctx, project, zone, instance, tags)
 }
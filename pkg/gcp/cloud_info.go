@@ -18,6 +18,10 @@ limitations under the License.
 package gcp
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	gcpclient "github.com/submariner-io/cloud-prepare/pkg/gcp/client"
@@ -29,19 +33,56 @@ type CloudInfo struct {
 	Region    string
 	ProjectID string
 	Client    gcpclient.Interface
+
+	// Network is the name of the cluster's network. It defaults to "{infraID}-network", as created by the OpenShift
+	// installer; NewGKECloudInfo discovers it on GKE.
+	Network string
+
+	// NodeTags are the network tags of the cluster's nodes. They default to the "{infraID}-worker" and "{infraID}-master"
+	// tags set by the OpenShift installer; NewGKECloudInfo discovers them on GKE.
+	NodeTags []string
+
+	// StateStore optionally records the provisioned resources, so that they can be cleaned up exactly.
+	StateStore api.StateStore
+
+	gke *gkeCluster
+}
+
+func (c *CloudInfo) networkName() string {
+	if c.Network != "" {
+		return c.Network
+	}
+
+	return c.InfraID + "-network"
+}
+
+func (c *CloudInfo) networkURL() string {
+	return fmt.Sprintf("projects/%s/global/networks/%s", c.ProjectID, c.networkName())
+}
+
+func (c *CloudInfo) nodeTags() []string {
+	if len(c.NodeTags) > 0 {
+		return c.NodeTags
+	}
+
+	return []string{c.InfraID + "-worker", c.InfraID + "-master"}
 }
 
 // Open expected ports by creating related firewall rule.
 // - if the firewall rule is not found, we will create it.
 // - if the firewall rule is found and changed, we will update it.
-func (c *CloudInfo) openPorts(rules ...*compute.Firewall) error {
+func (c *CloudInfo) openPorts(ctx context.Context, rules ...*compute.Firewall) error {
 	for _, rule := range rules {
-		_, err := c.Client.GetFirewallRule(c.ProjectID, rule.Name)
+		_, err := c.Client.GetFirewallRule(ctx, c.ProjectID, rule.Name)
 		if gcpclient.IsGCPNotFoundError(err) {
-			if err := c.Client.InsertFirewallRule(c.ProjectID, rule); err != nil {
+			if err := c.Client.InsertFirewallRule(ctx, c.ProjectID, rule); err != nil {
 				return errors.Wrapf(err, "error inserting firewall rule %#v", rule)
 			}
 
+			if err := api.RecordResources(ctx, c.StateStore, c.firewallRuleResource(rule.Name)); err != nil {
+				return err // nolint:wrapcheck // Already wrapped.
+			}
+
 			continue
 		}
 
@@ -49,32 +90,113 @@ func (c *CloudInfo) openPorts(rules ...*compute.Firewall) error {
 			return errors.Wrapf(err, "error retrieving firewall rule %q", rule.Name)
 		}
 
-		if err := c.Client.UpdateFirewallRule(c.ProjectID, rule.Name, rule); err != nil {
+		if err := c.Client.UpdateFirewallRule(ctx, c.ProjectID, rule.Name, rule); err != nil {
 			return errors.Wrapf(err, "error updating firewall rule %#v", rule)
 		}
+
+		if err := api.RecordResources(ctx, c.StateStore, c.firewallRuleResource(rule.Name)); err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
 	}
 
 	return nil
 }
 
-func (c *CloudInfo) deleteFirewallRule(name string, reporter api.Reporter) error {
-	reporter.Started("Deleting firewall rule %q on GCP", name)
+// openPublicPorts opens the public ports to their source CIDRs, updating the existing rules in place, and deletes the
+// rules of the ports which no longer have their own sources. It returns the names of the rules opening the ports.
+func (c *CloudInfo) openPublicPorts(ctx context.Context, input *api.GatewayDeployInput) ([]string, error) {
+	ingressRules := c.newExternalFirewallRules(input)
+
+	if err := c.openPorts(ctx, ingressRules...); err != nil {
+		return nil, err
+	}
 
-	if err := c.Client.DeleteFirewallRule(c.ProjectID, name); err != nil {
+	staleRules, err := c.stalePublicRules(ctx, ingressRules)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range staleRules {
+		if err := c.Client.DeleteFirewallRule(ctx, c.ProjectID, name); err != nil && !gcpclient.IsGCPNotFoundError(err) {
+			return nil, errors.Wrapf(err, "error deleting firewall rule %q", name)
+		}
+
+		if err := api.ForgetResources(ctx, c.StateStore, c.firewallRuleResource(name)); err != nil {
+			return nil, err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	names := []string{}
+	for _, rule := range ingressRules {
+		names = append(names, rule.Name)
+	}
+
+	return names, nil
+}
+
+// updatePublicPorts updates the source CIDRs of the rules opening the public ports in place.
+func (c *CloudInfo) updatePublicPorts(ctx context.Context, input *api.GatewayDeployInput, reporter api.Reporter) error {
+	reporter.Started("Updating the source CIDRs of the public ports")
+
+	ruleNames, err := c.openPublicPorts(ctx, input)
+	if err != nil {
+		return reportFailure(reporter, err, "error updating the firewall rules of the public ports")
+	}
+
+	reporter.Succeeded("Updated the source CIDRs of the public ports with firewall rules %q", strings.Join(ruleNames, ", "))
+
+	return nil
+}
+
+// stalePublicRules returns the names of the existing rules opening public ports which aren't among the given rules.
+func (c *CloudInfo) stalePublicRules(ctx context.Context, ingressRules []*compute.Firewall) ([]string, error) {
+	existingRules, err := c.Client.ListFirewallRules(ctx, c.ProjectID, publicRulesFilter(c.InfraID))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the public firewall rules")
+	}
+
+	wanted := map[string]bool{}
+	for _, rule := range ingressRules {
+		wanted[rule.Name] = true
+	}
+
+	staleRules := []string{}
+
+	for _, rule := range existingRules.Items {
+		if !wanted[rule.Name] {
+			staleRules = append(staleRules, rule.Name)
+		}
+	}
+
+	return staleRules, nil
+}
+
+func (c *CloudInfo) deleteFirewallRule(ctx context.Context, name string, reporter api.Reporter) error {
+	step := api.ResourceStep(providerName, api.ResourceFirewallRule, name)
+
+	api.StepStarted(reporter, step, "Deleting firewall rule %q on GCP", name)
+
+	if err := c.Client.DeleteFirewallRule(ctx, c.ProjectID, name); err != nil {
 		if !gcpclient.IsGCPNotFoundError(err) {
-			reporter.Failed(err)
+			api.StepFailed(reporter, step, err)
 			return errors.Wrapf(err, "error deleting firewall rule %q", name)
 		}
 	}
 
-	reporter.Succeeded("Deleted firewall rule %q on GCP", name)
+	if err := api.ForgetResources(ctx, c.StateStore, c.firewallRuleResource(name)); err != nil {
+		api.StepFailed(reporter, step, err)
+		return err // nolint:wrapcheck // Already wrapped.
+	}
+
+	api.StepSucceeded(reporter, step, "Deleted firewall rule %q on GCP", name)
 
 	return nil
 }
 
-func (c *CloudInfo) createVpcPeering(projectID, network string, peeringRequest *compute.NetworksAddPeeringRequest, reporter api.Reporter) error {
+func (c *CloudInfo) createVpcPeering(ctx context.Context, projectID, network string, peeringRequest *compute.NetworksAddPeeringRequest,
+	reporter api.Reporter) error {
 	reporter.Started("Peering VPC %s with %s GCP", network, peeringRequest.PeerNetwork)
-	if err := c.Client.CreateVpcPeering(projectID, network, peeringRequest); err != nil {
+	if err := c.Client.CreateVpcPeering(ctx, projectID, network, peeringRequest); err != nil {
 		reporter.Failed(err)
 		return errors.Wrapf(err, "error peering vpc %q on GCP", peeringRequest.Name)
 	}
@@ -82,9 +204,10 @@ func (c *CloudInfo) createVpcPeering(projectID, network string, peeringRequest *
 	return nil
 }
 
-func (c *CloudInfo) deleteVpcPeering(projectID, network string, removePeeringRequest *compute.NetworksRemovePeeringRequest, reporter api.Reporter) error {
+func (c *CloudInfo) deleteVpcPeering(ctx context.Context, projectID, network string,
+	removePeeringRequest *compute.NetworksRemovePeeringRequest, reporter api.Reporter) error {
 	reporter.Started("Removing VPC Peering %s.", removePeeringRequest.Name)
-	if err := c.Client.DeleteVpcPeering(projectID, network, removePeeringRequest); err != nil {
+	if err := c.Client.DeleteVpcPeering(ctx, projectID, network, removePeeringRequest); err != nil {
 		reporter.Failed(err)
 		return errors.Wrapf(err, "error peering vpc %q on GCP", removePeeringRequest.Name)
 	}
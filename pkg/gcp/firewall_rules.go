@@ -20,6 +20,7 @@ package gcp
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"google.golang.org/api/compute/v1"
@@ -28,41 +29,61 @@ import (
 const (
 	ingressDirection         = "INGRESS"
 	publicPortsRuleName      = "submariner-public-ports"
+	publicPortRulePrefix     = "submariner-public"
 	internalPortsRuleName    = "submariner-internal-ports"
 	submarinerGatewayNodeTag = "submariner-io-gateway-node"
 )
 
-func newExternalFirewallRules(projectID, infraID string, ports []api.PortSpec) (ingress *compute.Firewall) {
-	ingressName := generateRuleName(infraID, publicPortsRuleName)
+// newExternalFirewallRules returns the rules opening the public ports to their source CIDRs: one rule for the ports
+// restricted to the input's sources, and one rule per port which has its own sources.
+func (c *CloudInfo) newExternalFirewallRules(input *api.GatewayDeployInput) []*compute.Firewall {
+	sharedPorts := []api.PortSpec{}
+	ingressRules := []*compute.Firewall{}
 
+	for _, port := range input.PublicPorts {
+		if len(port.SourceCIDRs) == 0 {
+			sharedPorts = append(sharedPorts, port)
+			continue
+		}
+
+		ingressRules = append(ingressRules, c.newExternalFirewallRule(publicPortRuleName(c.InfraID, port), []api.PortSpec{port},
+			input.SourceCIDRs(port)))
+	}
+
+	if len(sharedPorts) > 0 || len(ingressRules) == 0 {
+		ingressRules = append([]*compute.Firewall{
+			c.newExternalFirewallRule(generateRuleName(c.InfraID, publicPortsRuleName), sharedPorts,
+				input.SourceCIDRs(api.PortSpec{})),
+		}, ingressRules...)
+	}
+
+	return ingressRules
+}
+
+func (c *CloudInfo) newExternalFirewallRule(name string, ports []api.PortSpec, sourceRanges []string) *compute.Firewall {
 	// We want the external firewall rules to be applied only to Gateway nodes. So, we use the TargetTags
 	// field and include submarinerGatewayNodeTag for selection of Gateway nodes. All the Submariner Gateway
 	// instances will be tagged with submarinerGatewayNodeTag.
-	ingressRule := newFirewallRule(projectID, infraID, ingressName, ingressDirection, ports)
+	ingressRule := c.newFirewallRule(name, ingressDirection, ports)
 	ingressRule.TargetTags = []string{
 		submarinerGatewayNodeTag,
 	}
+	ingressRule.SourceRanges = sourceRanges
 
 	return ingressRule
 }
 
-func newInternalFirewallRule(projectID, infraID string, ports []api.PortSpec) *compute.Firewall {
-	ingressName := generateRuleName(infraID, internalPortsRuleName)
+func (c *CloudInfo) newInternalFirewallRule(ports []api.PortSpec) *compute.Firewall {
+	ingressName := generateRuleName(c.InfraID, internalPortsRuleName)
 
-	rule := newFirewallRule(projectID, infraID, ingressName, ingressDirection, ports)
-	rule.TargetTags = []string{
-		fmt.Sprintf("%s-worker", infraID),
-		fmt.Sprintf("%s-master", infraID),
-	}
-	rule.SourceTags = []string{
-		fmt.Sprintf("%s-worker", infraID),
-		fmt.Sprintf("%s-master", infraID),
-	}
+	rule := c.newFirewallRule(ingressName, ingressDirection, ports)
+	rule.TargetTags = c.nodeTags()
+	rule.SourceTags = c.nodeTags()
 
 	return rule
 }
 
-func newFirewallRule(projectID, infraID, name, direction string, ports []api.PortSpec) *compute.Firewall {
+func (c *CloudInfo) newFirewallRule(name, direction string, ports []api.PortSpec) *compute.Firewall {
 	allowedPorts := []*compute.FirewallAllowed{}
 
 	for _, port := range ports {
@@ -78,7 +99,7 @@ func newFirewallRule(projectID, infraID, name, direction string, ports []api.Por
 
 	return &compute.Firewall{
 		Name:      name,
-		Network:   fmt.Sprintf("projects/%s/global/networks/%s-network", projectID, infraID),
+		Network:   c.networkURL(),
 		Direction: direction,
 		Allowed:   allowedPorts,
 	}
@@ -87,3 +108,13 @@ func newFirewallRule(projectID, infraID, name, direction string, ports []api.Por
 func generateRuleName(infraID, name string) (ingressName string) {
 	return fmt.Sprintf("%s-%s-ingress", infraID, name)
 }
+
+// publicPortRuleName returns the name of the rule opening the given public port to its own sources.
+func publicPortRuleName(infraID string, port api.PortSpec) string {
+	return generateRuleName(infraID, fmt.Sprintf("%s-%s-%d", publicPortRulePrefix, strings.ToLower(port.Protocol), port.Port))
+}
+
+// publicRulesFilter matches the names of all the rules opening public ports.
+func publicRulesFilter(infraID string) string {
+	return fmt.Sprintf("name eq %s-%s-.*", infraID, publicPortRulePrefix)
+}
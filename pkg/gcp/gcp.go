@@ -18,6 +18,7 @@ limitations under the License.
 package gcp
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -26,6 +27,8 @@ import (
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 )
 
+const providerName = "gcp"
+
 type gcpCloud struct {
 	CloudInfo
 }
@@ -37,16 +40,22 @@ func NewCloud(info CloudInfo) api.Cloud {
 
 // PrepareForSubmariner prepares submariner cluster environment on GCP.
 func (gc *gcpCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
+	return gc.PrepareForSubmarinerWithContext(context.Background(), input, reporter)
+}
+
+func (gc *gcpCloud) PrepareForSubmarinerWithContext(ctx context.Context, input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
 	// Create the inbound firewall rule for submariner internal ports.
-	reporter.Started("Opening internal ports %q for intra-cluster communications on GCP", formatPorts(input.InternalPorts))
+	internalIngress := gc.newInternalFirewallRule(input.InternalPorts)
+	step := api.ResourceStep(providerName, api.ResourceFirewallRule, internalIngress.Name)
 
-	internalIngress := newInternalFirewallRule(gc.ProjectID, gc.InfraID, input.InternalPorts)
-	if err := gc.openPorts(internalIngress); err != nil {
-		reporter.Failed(err)
+	api.StepStarted(reporter, step, "Opening internal ports %q for intra-cluster communications on GCP", formatPorts(input.InternalPorts))
+
+	if err := gc.openPorts(ctx, internalIngress); err != nil {
+		api.StepFailed(reporter, step, err)
 		return err
 	}
 
-	reporter.Succeeded("Opened internal ports %q with firewall rule %q on GCP",
+	api.StepSucceeded(reporter, step, "Opened internal ports %q with firewall rule %q on GCP",
 		formatPorts(input.InternalPorts), internalIngress.Name)
 
 	return nil
@@ -54,10 +63,28 @@ func (gc *gcpCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, re
 
 // CleanupAfterSubmariner clean up submariner cluster environment on GCP.
 func (gc *gcpCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
+	return gc.CleanupAfterSubmarinerWithContext(context.Background(), reporter)
+}
+
+func (gc *gcpCloud) CleanupAfterSubmarinerWithContext(ctx context.Context, reporter api.Reporter) error {
 	// Delete the inbound and outbound firewall rules to close submariner internal ports.
-	internalIngressName := generateRuleName(gc.InfraID, internalPortsRuleName)
+	ruleNames, err := gc.recordedFirewallRules(ctx, internalRuleScope)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
 
-	return gc.deleteFirewallRule(internalIngressName, reporter)
+	if len(ruleNames) == 0 {
+		ruleNames = []string{generateRuleName(gc.InfraID, internalPortsRuleName)}
+	}
+
+	for _, name := range ruleNames {
+		if err := gc.deleteFirewallRule(ctx, name, reporter); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func formatPorts(ports []api.PortSpec) string {
@@ -72,6 +99,10 @@ func formatPorts(ports []api.PortSpec) string {
 // CreateVpcPeering Creates a VPC Peering to the target cloud. Only the same
 // Cloud Provider is supported.
 func (gc *gcpCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return gc.CreateVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (gc *gcpCloud) CreateVpcPeeringWithContext(ctx context.Context, target api.Cloud, reporter api.Reporter) error {
 	// validate type
 	targetCloud, ok := target.(*gcpCloud)
 	if !ok {
@@ -82,12 +113,12 @@ func (gc *gcpCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) er
 
 	// https://cloud.google.com/vpc/docs/vpc-peering
 
-	NETWORK_NAME := gc.InfraID + "-network"
-	TARGET_NETWORK_NAME := targetCloud.InfraID + "-network"
+	NETWORK_NAME := gc.networkName()
+	TARGET_NETWORK_NAME := targetCloud.networkName()
 
 	// Get Network URLs
-	NETWORK := GetNetworkURL(gc.ProjectID, gc.InfraID)
-	TARGET_NETWORK := GetNetworkURL(targetCloud.ProjectID, targetCloud.InfraID)
+	NETWORK := gc.networkURL()
+	TARGET_NETWORK := targetCloud.networkURL()
 
 	reporter.Started("Started VPC Peering between %q and %q", NETWORK, TARGET_NETWORK)
 
@@ -96,14 +127,14 @@ func (gc *gcpCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) er
 	targetPeeringRequest := NewVpcPeeringRequest(targetCloud.InfraID, NETWORK)
 
 	// Peer VPC with Target VPC (A-B)
-	if err := gc.createVpcPeering(gc.ProjectID, NETWORK_NAME, peeringRequest, reporter); err != nil {
+	if err := gc.createVpcPeering(ctx, gc.ProjectID, NETWORK_NAME, peeringRequest, reporter); err != nil {
 		err_msg := errors.Wrapf(err, "Failed peering from %s to %s", NETWORK_NAME, TARGET_NETWORK_NAME)
 		reporter.Failed(err_msg)
 		return err
 	}
 
 	// Peer Target VPC with VPC (B-A)
-	if err := gc.createVpcPeering(targetCloud.ProjectID, TARGET_NETWORK_NAME, targetPeeringRequest, reporter); err != nil {
+	if err := gc.createVpcPeering(ctx, targetCloud.ProjectID, TARGET_NETWORK_NAME, targetPeeringRequest, reporter); err != nil {
 		err_msg := errors.Wrapf(err, "Failed peering from %s to %s", TARGET_NETWORK_NAME, NETWORK_NAME)
 		reporter.Failed(err_msg)
 		return err
@@ -116,6 +147,10 @@ func (gc *gcpCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) er
 
 // CleanupVpcPeering Removes the VPC Peering with the target cloud and the related Routes.
 func (gc *gcpCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return gc.CleanupVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (gc *gcpCloud) CleanupVpcPeeringWithContext(ctx context.Context, target api.Cloud, reporter api.Reporter) error {
 	targetCloud, ok := target.(*gcpCloud)
 	if !ok {
 		err := errors.New("only GCP clients are supported")
@@ -123,10 +158,10 @@ func (gc *gcpCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) e
 		return err
 	}
 
-	NETWORK_NAME := gc.InfraID + "-network"
-	TARGET_NETWORK_NAME := targetCloud.InfraID + "-network"
-	NETWORK := GetNetworkURL(gc.ProjectID, gc.InfraID)
-	TARGET_NETWORK := GetNetworkURL(targetCloud.ProjectID, targetCloud.InfraID)
+	NETWORK_NAME := gc.networkName()
+	TARGET_NETWORK_NAME := targetCloud.networkName()
+	NETWORK := gc.networkURL()
+	TARGET_NETWORK := targetCloud.networkURL()
 
 	reporter.Started("Started Removing VPC Peering between %q and %q", NETWORK, TARGET_NETWORK)
 
@@ -135,7 +170,7 @@ func (gc *gcpCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) e
 	targetRemovePeeringRequest := RemoveVpcPeeringRequest(targetCloud.InfraID)
 
 	// Peer VPC with Target VPC (A-B)
-	if err := gc.deleteVpcPeering(gc.ProjectID, NETWORK_NAME, removePeeringRequest, reporter); err != nil {
+	if err := gc.deleteVpcPeering(ctx, gc.ProjectID, NETWORK_NAME, removePeeringRequest, reporter); err != nil {
 		err_msg := errors.Wrapf(err, "Failed peering from target to host %s to %s", NETWORK_NAME, TARGET_NETWORK_NAME)
 		reporter.Failed(err_msg)
 		return err
@@ -143,7 +178,7 @@ func (gc *gcpCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) e
 
 	// Peer Target VPC with VPC (B-A) with retries as you cannot delete at the same time
 	deletePeeringFn := func() error {
-		return gc.deleteVpcPeering(targetCloud.ProjectID, TARGET_NETWORK_NAME, targetRemovePeeringRequest, reporter)
+		return gc.deleteVpcPeering(ctx, targetCloud.ProjectID, TARGET_NETWORK_NAME, targetRemovePeeringRequest, reporter)
 	}
 
 	deleteError := RunWithRetries(10, deletePeeringFn)
@@ -152,7 +187,7 @@ func (gc *gcpCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) e
 		reporter.Failed(err_msg)
 		return err_msg
 	}
-	// if err := gc.deleteVpcPeering(targetCloud.ProjectID, TARGET_NETWORK_NAME, targetRemovePeeringRequest, reporter); err != nil {
+	// if err := gc.deleteVpcPeering(ctx, targetCloud.ProjectID, TARGET_NETWORK_NAME, targetRemovePeeringRequest, reporter); err != nil {
 	// 	err_msg := errors.Wrapf(err, "Failed peering from target to host %s to %s", TARGET_NETWORK_NAME, NETWORK_NAME)
 	// 	reporter.Failed(err_msg)
 	// 	return err
@@ -18,8 +18,15 @@ limitations under the License.
 package gcp_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
@@ -34,7 +41,10 @@ const ingressRuleName = "test-infraID-submariner-internal-ports-ingress"
 
 var _ = Describe("Cloud", func() {
 	Describe("PrepareForSubmariner", testPrepareForSubmariner)
+	Describe("PrepareForSubmariner with a JSON reporter", testPrepareWithJSONReporter)
 	Describe("CleanupAfterSubmariner", testCleanupAfterSubmariner)
+	Describe("Plan", testPlan)
+	Describe("Inspect", testInspect)
 })
 
 func testPrepareForSubmariner() {
@@ -59,17 +69,18 @@ func testPrepareForSubmariner() {
 
 	When("the firewall rule doesn't exist", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().GetFirewallRule(projectID, ingressRuleName).Return(nil, &googleapi.Error{Code: http.StatusNotFound})
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(nil, &googleapi.Error{Code: http.StatusNotFound})
 		})
 
 		Context("", func() {
 			var actualRule *compute.Firewall
 
 			BeforeEach(func() {
-				t.gcpClient.EXPECT().InsertFirewallRule(projectID, gomock.Any()).DoAndReturn(func(_ string, rule *compute.Firewall) error {
-					actualRule = rule
-					return nil
-				})
+				t.gcpClient.EXPECT().InsertFirewallRule(gomock.Any(), projectID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, rule *compute.Firewall) error {
+						actualRule = rule
+						return nil
+					})
 			})
 
 			It("should correctly insert it", func() {
@@ -82,7 +93,7 @@ func testPrepareForSubmariner() {
 
 		Context("and insertion fails", func() {
 			BeforeEach(func() {
-				t.gcpClient.EXPECT().InsertFirewallRule(projectID, gomock.Any()).Return(errors.New("fake insert error"))
+				t.gcpClient.EXPECT().InsertFirewallRule(gomock.Any(), projectID, gomock.Any()).Return(errors.New("fake insert error"))
 			})
 
 			It("should return an error", func() {
@@ -93,17 +104,18 @@ func testPrepareForSubmariner() {
 
 	When("the firewall rule already exists", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().GetFirewallRule(projectID, ingressRuleName).DoAndReturn(func(_, ruleName string) (*compute.Firewall, error) {
-				return &compute.Firewall{Name: ruleName}, nil
-			})
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).DoAndReturn(
+				func(_ context.Context, _, ruleName string) (*compute.Firewall, error) {
+					return &compute.Firewall{Name: ruleName}, nil
+				})
 		})
 
 		Context("", func() {
 			var actualRule *compute.Firewall
 
 			BeforeEach(func() {
-				t.gcpClient.EXPECT().UpdateFirewallRule(projectID, ingressRuleName, gomock.Any()).DoAndReturn(
-					func(_, _ string, rule *compute.Firewall) error {
+				t.gcpClient.EXPECT().UpdateFirewallRule(gomock.Any(), projectID, ingressRuleName, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _, _ string, rule *compute.Firewall) error {
 						actualRule = rule
 						return nil
 					})
@@ -119,7 +131,7 @@ func testPrepareForSubmariner() {
 
 		Context("and update fails", func() {
 			BeforeEach(func() {
-				t.gcpClient.EXPECT().UpdateFirewallRule(projectID, ingressRuleName, gomock.Any()).Return(errors.New("fake update error"))
+				t.gcpClient.EXPECT().UpdateFirewallRule(gomock.Any(), projectID, ingressRuleName, gomock.Any()).Return(errors.New("fake update error"))
 			})
 
 			It("should return an error", func() {
@@ -130,7 +142,172 @@ func testPrepareForSubmariner() {
 
 	When("retrieval of the firewall rule fails", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().GetFirewallRule(projectID, ingressRuleName).Return(nil, errors.New("fake get error"))
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(nil, errors.New("fake get error"))
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testPrepareWithJSONReporter() {
+	t := newCloudTestDriver()
+
+	It("should report the firewall rule it operates on", func() {
+		t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(&compute.Firewall{}, nil)
+		t.gcpClient.EXPECT().UpdateFirewallRule(gomock.Any(), projectID, ingressRuleName, gomock.Any()).Return(nil)
+
+		out := &bytes.Buffer{}
+		Expect(t.cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{
+			InternalPorts: []api.PortSpec{{Port: 100, Protocol: "TCP"}},
+		}, api.NewJSONReporter(out, ""))).To(Succeed())
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		for i, eventType := range []api.EventType{api.EventStarted, api.EventSucceeded} {
+			event := api.Event{}
+			Expect(json.Unmarshal([]byte(lines[i]), &event)).To(Succeed())
+			Expect(event.Type).To(Equal(eventType))
+			Expect(event.Step).To(Equal(api.Step{
+				ID:           "gcp/FirewallRule/" + ingressRuleName,
+				Provider:     "gcp",
+				ResourceType: string(api.ResourceFirewallRule),
+				ResourceID:   ingressRuleName,
+			}))
+		}
+	})
+}
+
+func testPlan() {
+	t := newCloudTestDriver()
+
+	var (
+		actions  []api.Action
+		retError error
+	)
+
+	JustBeforeEach(func() {
+		actions, retError = t.cloud.Plan(context.TODO(), api.PrepareForSubmarinerInput{
+			InternalPorts: []api.PortSpec{
+				{
+					Port:     100,
+					Protocol: "TCP",
+				},
+			},
+		})
+	})
+
+	When("the firewall rule doesn't exist", func() {
+		BeforeEach(func() {
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(nil, &googleapi.Error{Code: http.StatusNotFound})
+		})
+
+		It("should return an insertion without inserting it", func() {
+			Expect(retError).To(Succeed())
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].Kind).To(Equal(api.ActionInsertFirewallRule))
+			Expect(actions[0].Resource).To(Equal(ingressRuleName))
+			Expect(actions[0].Parameters).To(HaveKeyWithValue("ports", "100/TCP"))
+		})
+	})
+
+	When("the firewall rule already exists", func() {
+		BeforeEach(func() {
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(&compute.Firewall{}, nil)
+		})
+
+		It("should return an update without updating it", func() {
+			Expect(retError).To(Succeed())
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].Kind).To(Equal(api.ActionUpdateFirewallRule))
+		})
+	})
+
+	When("the firewall rule already allows the expected ports", func() {
+		BeforeEach(func() {
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(&compute.Firewall{
+				Allowed: []*compute.FirewallAllowed{{IPProtocol: "TCP", Ports: []string{"100"}}},
+			}, nil)
+		})
+
+		It("should return no actions", func() {
+			Expect(retError).To(Succeed())
+			Expect(actions).To(BeEmpty())
+		})
+	})
+
+	When("retrieval of the firewall rule fails", func() {
+		BeforeEach(func() {
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(nil, errors.New("fake get error"))
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testInspect() {
+	t := newCloudTestDriver()
+
+	var (
+		status   *api.CloudStatus
+		retError error
+	)
+
+	BeforeEach(func() {
+		t.gcpClient.EXPECT().GetNetwork(gomock.Any(), projectID, infraID+"-network").Return(&compute.Network{
+			Peerings: []*compute.NetworkPeering{{
+				Name:    infraID + "-peering",
+				Network: "projects/test-project/global/networks/other-network",
+				State:   "ACTIVE",
+			}},
+		}, nil).AnyTimes()
+	})
+
+	JustBeforeEach(func() {
+		status, retError = t.cloud.Inspect(context.TODO())
+	})
+
+	When("the firewall rule exists", func() {
+		BeforeEach(func() {
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(&compute.Firewall{
+				Allowed: []*compute.FirewallAllowed{
+					{IPProtocol: "TCP", Ports: []string{"100"}},
+					{IPProtocol: "esp"},
+				},
+			}, nil)
+		})
+
+		It("should return its ports and the network peerings", func() {
+			Expect(retError).To(Succeed())
+			Expect(status).To(Equal(&api.CloudStatus{
+				InternalPorts: []api.PortSpec{{Port: 100, Protocol: "TCP"}, {Protocol: "esp"}},
+				Peerings: []api.PeeringStatus{{
+					ID:    infraID + "-peering",
+					Peer:  "projects/test-project/global/networks/other-network",
+					State: "ACTIVE",
+				}},
+			}))
+		})
+	})
+
+	When("the firewall rule doesn't exist", func() {
+		BeforeEach(func() {
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(nil, &googleapi.Error{Code: http.StatusNotFound})
+		})
+
+		It("should return no internal ports", func() {
+			Expect(retError).To(Succeed())
+			Expect(status.InternalPorts).To(BeEmpty())
+		})
+	})
+
+	When("retrieval of the firewall rule fails", func() {
+		BeforeEach(func() {
+			t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(nil, errors.New("fake get error"))
 		})
 
 		It("should return an error", func() {
@@ -150,7 +327,7 @@ func testCleanupAfterSubmariner() {
 
 	Context("on success", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().DeleteFirewallRule(projectID, ingressRuleName).Return(nil)
+			t.gcpClient.EXPECT().DeleteFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(nil)
 		})
 
 		It("should delete the firewall rule", func() {
@@ -160,7 +337,7 @@ func testCleanupAfterSubmariner() {
 
 	When("the firewall rule doesn't exist", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().DeleteFirewallRule(projectID, ingressRuleName).Return(&googleapi.Error{Code: http.StatusNotFound})
+			t.gcpClient.EXPECT().DeleteFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(&googleapi.Error{Code: http.StatusNotFound})
 		})
 
 		It("should succeed", func() {
@@ -170,13 +347,57 @@ func testCleanupAfterSubmariner() {
 
 	When("deletion fails", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().DeleteFirewallRule(projectID, ingressRuleName).Return(errors.New("fake delete error"))
+			t.gcpClient.EXPECT().DeleteFirewallRule(gomock.Any(), projectID, ingressRuleName).Return(errors.New("fake delete error"))
 		})
 
 		It("should return an error", func() {
 			Expect(retError).ToNot(Succeed())
 		})
 	})
+
+	When("the firewall rules are recorded in a state store", func() {
+		const recordedRuleName = "recorded-internal-ports-ingress"
+
+		var (
+			dir   string
+			store api.StateStore
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			dir, err = ioutil.TempDir("", "state")
+			Expect(err).To(Succeed())
+
+			store = api.NewFileStateStore(filepath.Join(dir, "state.json"))
+			t.cloud.(api.StateRecorder).SetStateStore(store)
+
+			Expect(api.RecordResources(context.TODO(), store, api.Resource{
+				Type:       api.ResourceFirewallRule,
+				ID:         recordedRuleName,
+				Parameters: map[string]string{"scope": "internal"},
+			}, api.Resource{
+				Type:       api.ResourceFirewallRule,
+				ID:         "recorded-public-ports-ingress",
+				Parameters: map[string]string{"scope": "public"},
+			})).To(Succeed())
+
+			t.gcpClient.EXPECT().DeleteFirewallRule(gomock.Any(), projectID, recordedRuleName).Return(nil)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should delete exactly the recorded internal rules and forget them", func() {
+			Expect(retError).To(Succeed())
+
+			recorded, err := api.RecordedResources(context.TODO(), store, api.ResourceFirewallRule)
+			Expect(err).To(Succeed())
+			Expect(recorded).To(HaveLen(1))
+			Expect(recorded[0].ID).To(Equal("recorded-public-ports-ingress"))
+		})
+	})
 }
 
 type cloudTestDriver struct {
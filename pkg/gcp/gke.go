@@ -0,0 +1,91 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	gcpclient "github.com/submariner-io/cloud-prepare/pkg/gcp/client"
+)
+
+// gkeCluster holds what's needed to manage the gateways of a GKE cluster.
+type gkeCluster struct {
+	client       gcpclient.GKEInterface
+	location     string
+	name         string
+	zones        []string
+	privateNodes bool
+}
+
+// NewGKECloudInfo returns the CloudInfo of the given GKE cluster, discovering its region, network and node tags
+// through the Container API. The location is the cluster's region, or its zone for a zonal cluster.
+func NewGKECloudInfo(ctx context.Context, client gcpclient.GKEInterface, projectID, location, clusterName string) (CloudInfo, error) {
+	cluster, err := client.GetCluster(ctx, location, clusterName)
+	if err != nil {
+		return CloudInfo{}, errors.Wrapf(err, "error retrieving GKE cluster %q in %q", clusterName, location)
+	}
+
+	// GKE tags the nodes of all the default node pools with "gke-{cluster}-{first 8 characters of its ID}-node".
+	clusterID := cluster.Id
+	if len(clusterID) > 8 {
+		clusterID = clusterID[:8]
+	}
+
+	nodeTags := stringset.New("gke-" + cluster.Name + "-" + clusterID + "-node")
+
+	for _, nodePool := range cluster.NodePools {
+		if nodePool.Name == gkeGatewayNodePool || nodePool.Config == nil {
+			continue
+		}
+
+		nodeTags.AddAll(nodePool.Config.Tags...)
+	}
+
+	sortedNodeTags := nodeTags.Elements()
+	sort.Strings(sortedNodeTags)
+
+	return CloudInfo{
+		InfraID:   cluster.Name,
+		Region:    gkeRegion(location),
+		ProjectID: projectID,
+		Client:    client,
+		Network:   cluster.Network,
+		NodeTags:  sortedNodeTags,
+		gke: &gkeCluster{
+			client:       client,
+			location:     location,
+			name:         cluster.Name,
+			zones:        cluster.Locations,
+			privateNodes: cluster.PrivateClusterConfig != nil && cluster.PrivateClusterConfig.EnablePrivateNodes,
+		},
+	}, nil
+}
+
+// gkeRegion returns the region of the given location, which is either a region such as "us-east1" or a zone such as
+// "us-east1-b".
+func gkeRegion(location string) string {
+	if strings.Count(location, "-") > 1 {
+		return location[:strings.LastIndex(location, "-")]
+	}
+
+	return location
+}
@@ -0,0 +1,397 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	gcpclient "github.com/submariner-io/cloud-prepare/pkg/gcp/client"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"google.golang.org/api/container/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	gkeGatewayNodePool = "submariner-gw"
+	gkeNodePoolLabel   = "cloud.google.com/gke-nodepool"
+	zoneLabel          = "topology.kubernetes.io/zone"
+)
+
+type gkeGatewayDeployer struct {
+	CloudInfo
+	machineType       string
+	dedicatedNodePool bool
+	k8sClient         k8s.Interface
+}
+
+// NewGKEGatewayDeployer returns a GatewayDeployer capable of deploying gateways on GKE, either in a dedicated node pool
+// whose nodes get external IPs, or by configuring existing nodes as gateways. The CloudInfo must have been obtained
+// from NewGKECloudInfo.
+func NewGKEGatewayDeployer(info CloudInfo, machineType string, dedicatedNodePool bool, k8sClient k8s.Interface) (api.GatewayDeployer,
+	error) {
+	if info.gke == nil {
+		return nil, errors.New("the cloud info wasn't obtained from a GKE cluster")
+	}
+
+	if dedicatedNodePool && info.gke.privateNodes {
+		return nil, fmt.Errorf("GKE cluster %q uses private nodes, which can't get the external IPs gateways need",
+			info.gke.name)
+	}
+
+	return &gkeGatewayDeployer{
+		CloudInfo:         info,
+		machineType:       machineType,
+		dedicatedNodePool: dedicatedNodePool,
+		k8sClient:         k8sClient,
+	}, nil
+}
+
+func (d *gkeGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *gkeGatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	reporter.Started("Configuring the required firewall rules for inter-cluster traffic")
+
+	ruleNames, err := d.openPublicPorts(ctx, &input)
+	if err != nil {
+		return reportFailure(reporter, err, "error opening the external ports")
+	}
+
+	reporter.Succeeded("Opened External ports %q with firewall rules %q on GCP",
+		formatPorts(input.PublicPorts), strings.Join(ruleNames, ", "))
+
+	if d.dedicatedNodePool {
+		return d.deployNodePool(ctx, input.Gateways, reporter)
+	}
+
+	return d.deployGatewayNodes(ctx, input.Gateways, reporter)
+}
+
+// UpdatePublicPorts updates the source CIDRs of the firewall rules opening the public ports, without redeploying the
+// gateways.
+func (d *gkeGatewayDeployer) UpdatePublicPorts(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.updatePublicPorts(ctx, &input, reporter)
+}
+
+func (d *gkeGatewayDeployer) deployNodePool(ctx context.Context, gateways int, reporter api.Reporter) error {
+	reporter.Started(fmt.Sprintf("Deploying the gateway node pool %q", gkeGatewayNodePool))
+
+	existing, zones, err := d.nodePoolZones(ctx, gateways)
+	if err != nil {
+		return reportFailure(reporter, err, "error determining the zones of the gateway node pool")
+	}
+
+	switch {
+	case existing == nil && len(zones) == 0:
+		reporter.Succeeded("No gateways are required")
+	case existing == nil:
+		err = d.gke.client.CreateNodePool(ctx, d.gke.location, d.gke.name, d.newNodePool(zones))
+		if err != nil {
+			return reportFailure(reporter, err, "error creating the gateway node pool %q", gkeGatewayNodePool)
+		}
+
+		err = api.RecordResources(ctx, d.StateStore, nodePoolResource(gkeGatewayNodePool))
+		if err != nil {
+			return reportFailure(reporter, err, "error recording the gateway node pool")
+		}
+
+		reporter.Succeeded("Created the gateway node pool %q in zones %q", gkeGatewayNodePool, strings.Join(zones, ", "))
+	case len(existing) == len(zones) && len(stringset.New(existing...).Difference(stringset.New(zones...))) == 0:
+		reporter.Succeeded("Current gateways match the required number of gateways")
+	default:
+		err = d.gke.client.SetNodePoolLocations(ctx, d.gke.location, d.gke.name, gkeGatewayNodePool, zones)
+		if err != nil {
+			return reportFailure(reporter, err, "error updating the zones of the gateway node pool %q", gkeGatewayNodePool)
+		}
+
+		reporter.Succeeded("Updated the gateway node pool %q to zones %q", gkeGatewayNodePool, strings.Join(zones, ", "))
+	}
+
+	return nil
+}
+
+// nodePoolZones returns the zones the gateway node pool currently spans, or nil if it doesn't exist, and the zones it
+// should span to run the desired number of gateways, one per zone. The zones already used are kept first.
+func (d *gkeGatewayDeployer) nodePoolZones(ctx context.Context, gateways int) ([]string, []string, error) {
+	var existing []string
+
+	nodePool, err := d.gke.client.GetNodePool(ctx, d.gke.location, d.gke.name, gkeGatewayNodePool)
+	if err == nil {
+		existing = nodePool.Locations
+	} else if !gcpclient.IsGCPNotFoundError(err) {
+		return nil, nil, errors.Wrapf(err, "error retrieving node pool %q", gkeGatewayNodePool)
+	}
+
+	// The default policy keeps whatever gateways already exist.
+	if gateways == 0 {
+		return existing, existing, nil
+	}
+
+	zones := []string{}
+
+	for _, zone := range append(append([]string{}, existing...), d.gke.zones...) {
+		if len(zones) < gateways && !stringset.New(zones...).Contains(zone) {
+			zones = append(zones, zone)
+		}
+	}
+
+	if len(zones) < gateways {
+		return nil, nil, fmt.Errorf("there are an insufficient number of zones (%d) to deploy the desired number of gateways (%d)",
+			len(d.gke.zones), gateways)
+	}
+
+	return existing, zones, nil
+}
+
+func (d *gkeGatewayDeployer) newNodePool(zones []string) *container.NodePool {
+	return &container.NodePool{
+		Name:             gkeGatewayNodePool,
+		InitialNodeCount: 1,
+		Locations:        zones,
+		Config: &container.NodeConfig{
+			MachineType: d.machineType,
+			Tags:        []string{submarinerGatewayNodeTag},
+			Labels:      map[string]string{k8s.SubmarinerGatewayLabel: "true"},
+		},
+	}
+}
+
+func (d *gkeGatewayDeployer) deployGatewayNodes(ctx context.Context, gateways int, reporter api.Reporter) error {
+	reporter.Started("Verifying if current gateways match the required number of gateways")
+
+	toConfigure, toRemove, err := d.selectGatewayNodes(ctx, gateways)
+	if err != nil {
+		return reportFailure(reporter, err, "error selecting the gateway nodes")
+	}
+
+	if len(toConfigure) == 0 && len(toRemove) == 0 {
+		reporter.Succeeded("Current gateways match the required number of gateways")
+		return nil
+	}
+
+	for _, node := range toRemove {
+		reporter.Started(fmt.Sprintf("Removing the gateway configuration from node %q", node.Name))
+
+		err = d.removeGatewayNode(ctx, node)
+		if err != nil {
+			return reportFailure(reporter, err, "error removing the gateway node %q", node.Name)
+		}
+
+		reporter.Succeeded("Removed the gateway node %q", node.Name)
+	}
+
+	for _, node := range toConfigure {
+		zone := node.Labels[zoneLabel]
+
+		reporter.Started(fmt.Sprintf("Configuring node %q in zone %q as gateway node", node.Name, zone))
+
+		// On GKE, the nodes are named after their instances.
+		_, err = d.configureInstanceAsGW(ctx, zone, node.Name, "")
+		if err != nil {
+			return reportFailure(reporter, err, "error configuring gateway node %q", node.Name)
+		}
+
+		err = d.k8sClient.AddGWLabelOnNodeWithContext(ctx, node.Name)
+		if err != nil {
+			return reportFailure(reporter, err, "error labeling node %q", node.Name)
+		}
+
+		err = api.RecordResources(ctx, d.StateStore, gatewayInstanceResource(zone, node.Name, false))
+		if err != nil {
+			return reportFailure(reporter, err, "error recording the gateway node %q", node.Name)
+		}
+
+		reporter.Succeeded("Successfully deployed gateway node")
+	}
+
+	return nil
+}
+
+// selectGatewayNodes returns the nodes to configure as gateways, at most one per zone, and the gateway nodes to remove
+// so that the desired number of gateways run.
+func (d *gkeGatewayDeployer) selectGatewayNodes(ctx context.Context, gateways int) ([]*v1.Node, []*v1.Node, error) {
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	// The default policy keeps whatever gateways already exist.
+	if gateways == 0 {
+		return nil, nil, nil
+	}
+
+	if len(gwNodes.Items) >= gateways {
+		return nil, k8s.SurplusGatewayNodes(gwNodes, gateways), nil
+	}
+
+	zonesWithGW := stringset.New()
+	for i := range gwNodes.Items {
+		zonesWithGW.Add(gwNodes.Items[i].Labels[zoneLabel])
+	}
+
+	toConfigure := []*v1.Node{}
+
+	for _, zone := range d.gke.zones {
+		if zonesWithGW.Contains(zone) {
+			continue
+		}
+
+		nodes, err := d.k8sClient.ListNodesWithLabelWithContext(ctx, zoneLabel+"="+zone+","+gkeNodePoolLabel+"!="+gkeGatewayNodePool)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error listing the nodes in zone %q", zone)
+		}
+
+		if len(nodes.Items) > 0 {
+			toConfigure = append(toConfigure, &nodes.Items[0])
+		}
+
+		if len(gwNodes.Items)+len(toConfigure) == gateways {
+			return toConfigure, nil, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("there are an insufficient number of zones (%d) to deploy the desired number of gateways (%d)",
+		len(d.gke.zones), gateways)
+}
+
+func (d *gkeGatewayDeployer) removeGatewayNode(ctx context.Context, node *v1.Node) error {
+	zone := node.Labels[zoneLabel]
+
+	err := d.resetGatewayInstance(ctx, zone, node.Name)
+	if err != nil {
+		return err
+	}
+
+	err = d.k8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, node)
+	if err != nil {
+		return errors.Wrapf(err, "error removing the gateway label from node %q", node.Name)
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, gatewayInstanceResource(zone, node.Name, false))
+}
+
+// resetGatewayInstance removes the gateway configuration from the given instance, if it still exists.
+func (d *gkeGatewayDeployer) resetGatewayInstance(ctx context.Context, zone, instanceName string) error {
+	instance, err := d.Client.GetInstance(ctx, zone, instanceName)
+	if gcpclient.IsGCPNotFoundError(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving GCP instance %q in zone %q", instanceName, zone)
+	}
+
+	if !isInstanceGatewayNode(instance) {
+		return nil
+	}
+
+	return d.resetExistingGWNode(ctx, zone, instance)
+}
+
+func (d *gkeGatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *gkeGatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
+	reporter.Started("Retrieving the Submariner gateway firewall rules")
+
+	err := d.deleteExternalFWRules(ctx, reporter)
+	if err != nil {
+		return reportFailure(reporter, err, "failed to delete the gateway firewall rules in the project %q", d.ProjectID)
+	}
+
+	reporter.Succeeded("Successfully deleted the firewall rules")
+
+	reporter.Started(fmt.Sprintf("Deleting the gateway node pool %q", gkeGatewayNodePool))
+
+	err = d.gke.client.DeleteNodePool(ctx, d.gke.location, d.gke.name, gkeGatewayNodePool)
+	if err != nil && !gcpclient.IsGCPNotFoundError(err) {
+		return reportFailure(reporter, err, "error deleting the gateway node pool %q", gkeGatewayNodePool)
+	}
+
+	err = api.ForgetResources(ctx, d.StateStore, nodePoolResource(gkeGatewayNodePool))
+	if err != nil {
+		return reportFailure(reporter, err, "error forgetting the gateway node pool")
+	}
+
+	reporter.Succeeded("Successfully deleted the node pool")
+
+	gateways, err := d.gatewayNodesToCleanup(ctx)
+	if err != nil {
+		return reportFailure(reporter, err, "error retrieving the gateway nodes")
+	}
+
+	for i := range gateways {
+		instanceName := gateways[i].Parameters["instance"]
+
+		reporter.Started(fmt.Sprintf("Removing the gateway configuration from instance %q", instanceName))
+
+		err = d.resetGatewayInstance(ctx, gateways[i].ID, instanceName)
+		if err != nil {
+			return reportFailure(reporter, err, "failed to reset gateway instance %q", instanceName)
+		}
+
+		err = api.ForgetResources(ctx, d.StateStore, gateways[i])
+		if err != nil {
+			return reportFailure(reporter, err, "error forgetting the gateway instance %q", instanceName)
+		}
+
+		reporter.Succeeded("Successfully reconfigured the instance")
+	}
+
+	reporter.Started("Removing the Submariner gateway label from worker nodes")
+
+	err = d.k8sClient.RemoveGWLabelFromWorkerNodesWithContext(ctx)
+	if err != nil {
+		return reportFailure(reporter, err, "error removing the gateway label from worker nodes")
+	}
+
+	reporter.Succeeded("Successfully removed the label from the worker nodes")
+
+	return nil
+}
+
+// gatewayNodesToCleanup returns the recorded gateway nodes or, if none were recorded, the labeled nodes outside the
+// gateway node pool.
+func (d *gkeGatewayDeployer) gatewayNodesToCleanup(ctx context.Context) ([]api.Resource, error) {
+	recorded, err := api.RecordedResources(ctx, d.StateStore, api.ResourceGatewayInstance)
+	if err != nil || len(recorded) > 0 {
+		return recorded, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	gateways := []api.Resource{}
+
+	for i := range gwNodes.Items {
+		if gwNodes.Items[i].Labels[gkeNodePoolLabel] != gkeGatewayNodePool {
+			gateways = append(gateways, gatewayInstanceResource(gwNodes.Items[i].Labels[zoneLabel], gwNodes.Items[i].Name, false))
+		}
+	}
+
+	return gateways, nil
+}
@@ -0,0 +1,342 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/gcp"
+	"github.com/submariner-io/cloud-prepare/pkg/gcp/client/fake"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/container/v1"
+	"google.golang.org/api/googleapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	gkeLocation    = "us-east1"
+	gkeCluster     = "test-cluster"
+	gkeGatewayPool = "submariner-gw"
+	gkeZone1       = "us-east1-b"
+	gkeZone2       = "us-east1-c"
+)
+
+var _ = Describe("GKE", func() {
+	Context("on NewGKECloudInfo", testGKECloudInfo)
+	Context("on Deploy with a dedicated node pool", testGKEDeployNodePool)
+	Context("on Deploy with existing nodes", testGKEDeployExistingNodes)
+	Context("on Cleanup", testGKECleanup)
+})
+
+func testGKECloudInfo() {
+	t := newGKETestDriver()
+
+	It("should discover the cluster's region, network and node tags", func() {
+		info, err := gcp.NewGKECloudInfo(context.TODO(), t.gkeClient, projectID, gkeLocation, gkeCluster)
+		Expect(err).To(Succeed())
+		Expect(info.InfraID).To(Equal(gkeCluster))
+		Expect(info.Region).To(Equal(gkeLocation))
+		Expect(info.Network).To(Equal("test-network"))
+		Expect(info.NodeTags).To(Equal([]string{"custom-tag", "gke-test-cluster-01234567-node"}))
+	})
+
+	It("should create the internal firewall rule on the cluster's network", func() {
+		info, err := gcp.NewGKECloudInfo(context.TODO(), t.gkeClient, projectID, gkeLocation, gkeCluster)
+		Expect(err).To(Succeed())
+
+		var actualRule *compute.Firewall
+
+		t.gkeClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, gomock.Any()).Return(nil, &googleapi.Error{Code: http.StatusNotFound})
+		t.gkeClient.EXPECT().InsertFirewallRule(gomock.Any(), projectID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, rule *compute.Firewall) error {
+				actualRule = rule
+				return nil
+			})
+
+		Expect(gcp.NewCloud(info).PrepareForSubmariner(api.PrepareForSubmarinerInput{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "UDP"}},
+		}, api.NewLoggingReporter())).To(Succeed())
+
+		Expect(actualRule.Network).To(Equal("projects/" + projectID + "/global/networks/test-network"))
+		Expect(actualRule.TargetTags).To(Equal(info.NodeTags))
+	})
+
+	When("given a zonal cluster", func() {
+		It("should derive the region from the zone", func() {
+			info, err := gcp.NewGKECloudInfo(context.TODO(), t.gkeClient, projectID, gkeZone1, gkeCluster)
+			Expect(err).To(Succeed())
+			Expect(info.Region).To(Equal(gkeLocation))
+		})
+	})
+
+	When("the CloudInfo doesn't come from GKE", func() {
+		It("should fail to create a GKE gateway deployer", func() {
+			_, err := gcp.NewGKEGatewayDeployer(gcp.CloudInfo{InfraID: infraID}, instanceType, true, k8s.NewInterface(t.kubeClient))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the cluster uses private nodes", func() {
+		BeforeEach(func() {
+			t.cluster.PrivateClusterConfig = &container.PrivateClusterConfig{EnablePrivateNodes: true}
+		})
+
+		It("should fail to create a GKE gateway deployer with a dedicated node pool", func() {
+			info, err := gcp.NewGKECloudInfo(context.TODO(), t.gkeClient, projectID, gkeLocation, gkeCluster)
+			Expect(err).To(Succeed())
+
+			_, err = gcp.NewGKEGatewayDeployer(info, instanceType, true, k8s.NewInterface(t.kubeClient))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func testGKEDeployNodePool() {
+	t := newGKETestDriver()
+
+	BeforeEach(func() {
+		t.dedicatedNodePool = true
+		t.expectPublicPortsRule()
+	})
+
+	When("the gateway node pool doesn't exist", func() {
+		It("should create it in the desired number of zones", func() {
+			var created *container.NodePool
+
+			t.gkeClient.EXPECT().CreateNodePool(gomock.Any(), gkeLocation, gkeCluster, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _ string, nodePool *container.NodePool) error {
+					created = nodePool
+					return nil
+				})
+
+			Expect(t.doDeploy(2)).To(Succeed())
+			Expect(created.Name).To(Equal(gkeGatewayPool))
+			Expect(created.Locations).To(Equal([]string{gkeZone1, gkeZone2}))
+			Expect(created.Config.MachineType).To(Equal(instanceType))
+			Expect(created.Config.Tags).To(ContainElement(submarinerGatewayNodeTag))
+			Expect(created.Config.Labels).To(HaveKeyWithValue("submariner.io/gateway", "true"))
+		})
+
+		It("should plan its creation", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), api.GatewayDeployInput{Gateways: 1})
+			Expect(err).To(Succeed())
+			Expect(actions).To(ContainElement(api.Action{
+				Kind:       api.ActionCreateNodePool,
+				Resource:   gkeGatewayPool,
+				Parameters: map[string]string{"zones": gkeZone1, "machine-type": instanceType},
+			}))
+		})
+	})
+
+	When("the gateway node pool exists with fewer zones than desired", func() {
+		BeforeEach(func() {
+			t.nodePool = &container.NodePool{Name: gkeGatewayPool, Locations: []string{gkeZone2}}
+		})
+
+		It("should extend it, keeping its existing zones", func() {
+			t.gkeClient.EXPECT().SetNodePoolLocations(gomock.Any(), gkeLocation, gkeCluster, gkeGatewayPool,
+				[]string{gkeZone2, gkeZone1}).Return(nil)
+
+			Expect(t.doDeploy(2)).To(Succeed())
+		})
+	})
+
+	When("the gateway node pool exists and the desired number of gateways isn't specified", func() {
+		BeforeEach(func() {
+			t.nodePool = &container.NodePool{Name: gkeGatewayPool, Locations: []string{gkeZone1, gkeZone2}}
+		})
+
+		It("should keep it", func() {
+			Expect(t.doDeploy(0)).To(Succeed())
+		})
+	})
+
+	When("there are too few zones", func() {
+		It("should return an error", func() {
+			Expect(t.doDeploy(3)).ToNot(Succeed())
+		})
+	})
+}
+
+func testGKEDeployExistingNodes() {
+	t := newGKETestDriver()
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{newGKENode("node1", gkeZone1), newGKENode("node2", gkeZone2)}
+		t.expectPublicPortsRule()
+	})
+
+	It("should configure one node per zone as gateway", func() {
+		t.expectInstanceConfigured(gkeZone1, "node1")
+		t.expectInstanceConfigured(gkeZone2, "node2")
+
+		Expect(t.doDeploy(2)).To(Succeed())
+		Expect(t.gatewayNodes()).To(ConsistOf("node1", "node2"))
+	})
+
+	When("there are more gateways than desired", func() {
+		BeforeEach(func() {
+			t.nodes = []*corev1.Node{labelNode(newGKENode("node1", gkeZone1)), labelNode(newGKENode("node2", gkeZone2))}
+			t.nodes[1].CreationTimestamp = metav1.Now()
+		})
+
+		It("should reset the newest gateway", func() {
+			instance := &compute.Instance{Name: "node2", Tags: &compute.Tags{Items: []string{submarinerGatewayNodeTag}}}
+
+			t.gkeClient.EXPECT().GetInstance(gomock.Any(), gkeZone2, "node2").Return(instance, nil)
+			t.gkeClient.EXPECT().UpdateInstanceNetworkTags(gomock.Any(), projectID, gkeZone2, "node2", gomock.Any()).Return(nil)
+			t.gkeClient.EXPECT().DeletePublicIPOnInstance(gomock.Any(), instance).Return(nil)
+
+			Expect(t.doDeploy(1)).To(Succeed())
+			Expect(t.gatewayNodes()).To(ConsistOf("node1"))
+		})
+	})
+}
+
+func testGKECleanup() {
+	t := newGKETestDriver()
+
+	BeforeEach(func() {
+		t.dedicatedNodePool = true
+		t.nodes = []*corev1.Node{labelNode(newGKENode("gw-pool-node", gkeZone1))}
+		t.nodes[0].Labels["cloud.google.com/gke-nodepool"] = gkeGatewayPool
+	})
+
+	It("should delete the firewall rule and the gateway node pool", func() {
+		t.gkeClient.EXPECT().DeleteFirewallRule(gomock.Any(), projectID, gkeCluster+"-submariner-public-ports-ingress").Return(nil)
+		t.gkeClient.EXPECT().DeleteNodePool(gomock.Any(), gkeLocation, gkeCluster, gkeGatewayPool).Return(nil)
+
+		Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+		Expect(t.gatewayNodes()).To(BeEmpty())
+	})
+}
+
+type gkeTestDriver struct {
+	gkeClient         *fake.MockGKEInterface
+	mockCtrl          *gomock.Controller
+	kubeClient        *kubeFake.Clientset
+	cluster           *container.Cluster
+	nodePool          *container.NodePool
+	nodes             []*corev1.Node
+	dedicatedNodePool bool
+	gwDeployer        api.GatewayDeployer
+}
+
+func newGKETestDriver() *gkeTestDriver {
+	t := &gkeTestDriver{}
+
+	BeforeEach(func() {
+		t.mockCtrl = gomock.NewController(GinkgoT())
+		t.gkeClient = fake.NewMockGKEInterface(t.mockCtrl)
+		t.kubeClient = kubeFake.NewSimpleClientset()
+		t.nodePool = nil
+		t.nodes = []*corev1.Node{}
+		t.dedicatedNodePool = false
+
+		t.cluster = &container.Cluster{
+			Name:      gkeCluster,
+			Id:        "0123456789abcdef",
+			Network:   "test-network",
+			Locations: []string{gkeZone1, gkeZone2},
+			NodePools: []*container.NodePool{
+				{Name: "default-pool", Config: &container.NodeConfig{Tags: []string{"custom-tag"}}},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		t.gkeClient.EXPECT().GetCluster(gomock.Any(), gomock.Any(), gkeCluster).Return(t.cluster, nil).AnyTimes()
+		t.gkeClient.EXPECT().ListFirewallRules(gomock.Any(), projectID, gomock.Any()).Return(&compute.FirewallList{}, nil).AnyTimes()
+		t.gkeClient.EXPECT().GetNodePool(gomock.Any(), gkeLocation, gkeCluster, gkeGatewayPool).DoAndReturn(
+			func(_ context.Context, _, _, _ string) (*container.NodePool, error) {
+				if t.nodePool == nil {
+					return nil, &googleapi.Error{Code: http.StatusNotFound}
+				}
+
+				return t.nodePool, nil
+			}).AnyTimes()
+
+		for _, node := range t.nodes {
+			_, err := t.kubeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		}
+
+		info, err := gcp.NewGKECloudInfo(context.TODO(), t.gkeClient, projectID, gkeLocation, gkeCluster)
+		Expect(err).To(Succeed())
+
+		t.gwDeployer, err = gcp.NewGKEGatewayDeployer(info, instanceType, t.dedicatedNodePool, k8s.NewInterface(t.kubeClient))
+		Expect(err).To(Succeed())
+	})
+
+	AfterEach(func() {
+		t.mockCtrl.Finish()
+	})
+
+	return t
+}
+
+func (t *gkeTestDriver) expectPublicPortsRule() {
+	t.gkeClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, gomock.Any()).Return(&compute.Firewall{}, nil).AnyTimes()
+	t.gkeClient.EXPECT().UpdateFirewallRule(gomock.Any(), projectID, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+}
+
+func (t *gkeTestDriver) doDeploy(gateways int) error {
+	return t.gwDeployer.Deploy(api.GatewayDeployInput{
+		Gateways:    gateways,
+		PublicPorts: []api.PortSpec{{Port: 4500, Protocol: "UDP"}},
+	}, api.NewLoggingReporter())
+}
+
+func (t *gkeTestDriver) expectInstanceConfigured(zone, name string) {
+	instance := &compute.Instance{Name: name, Tags: &compute.Tags{}}
+
+	t.gkeClient.EXPECT().GetInstance(gomock.Any(), zone, name).Return(instance, nil)
+	t.gkeClient.EXPECT().UpdateInstanceNetworkTags(gomock.Any(), projectID, zone, name, &compute.Tags{
+		Items: []string{submarinerGatewayNodeTag},
+	}).Return(nil)
+	t.gkeClient.EXPECT().ConfigurePublicIPOnInstance(gomock.Any(), instance).Return(nil)
+}
+
+func (t *gkeTestDriver) gatewayNodes() []string {
+	nodes, err := t.kubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: "submariner.io/gateway=true"})
+	Expect(err).To(Succeed())
+
+	return k8s.NodeNames(nodes)
+}
+
+func newGKENode(name, zone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"topology.kubernetes.io/zone":   zone,
+				"cloud.google.com/gke-nodepool": "default-pool",
+			},
+			CreationTimestamp: metav1.NewTime(metav1.Now().AddDate(0, 0, -1)),
+		},
+	}
+}
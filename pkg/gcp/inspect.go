@@ -0,0 +1,132 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcp
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	gcpclient "github.com/submariner-io/cloud-prepare/pkg/gcp/client"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+)
+
+// Inspect returns the ports opened by the internal firewall rule and the peerings of the cluster's network on GCP.
+func (gc *gcpCloud) Inspect(ctx context.Context) (*api.CloudStatus, error) {
+	internalPorts, err := gc.inspectFirewallRule(ctx, generateRuleName(gc.InfraID, internalPortsRuleName))
+	if err != nil {
+		return nil, err
+	}
+
+	networkName := gc.networkName()
+
+	network, err := gc.Client.GetNetwork(ctx, gc.ProjectID, networkName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving network %q", networkName)
+	}
+
+	status := &api.CloudStatus{InternalPorts: internalPorts, Peerings: []api.PeeringStatus{}}
+
+	for _, peering := range network.Peerings {
+		status.Peerings = append(status.Peerings, api.PeeringStatus{
+			ID:    peering.Name,
+			Peer:  peering.Network,
+			State: peering.State,
+		})
+	}
+
+	return status, nil
+}
+
+// Inspect returns the ports opened by the public firewall rule, the gateway nodes and their machine sets on GCP.
+func (d *ocpGatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	publicPorts, err := d.inspectFirewallRule(ctx, generateRuleName(d.InfraID, publicPortsRuleName))
+	if err != nil {
+		return nil, err
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	machineSets, err := ocp.ListGatewayMachineSets(ctx, d.msDeployer, d.InfraID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	return &api.GatewayStatus{
+		PublicPorts:        publicPorts,
+		GatewaySubnets:     []string{},
+		GatewayNodes:       k8s.NodeNames(gwNodes),
+		GatewayMachineSets: machineSets,
+	}, nil
+}
+
+// Inspect returns the ports opened by the public firewall rule and the gateway nodes on GKE.
+func (d *gkeGatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	publicPorts, err := d.inspectFirewallRule(ctx, generateRuleName(d.InfraID, publicPortsRuleName))
+	if err != nil {
+		return nil, err
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	return &api.GatewayStatus{
+		PublicPorts:        publicPorts,
+		GatewaySubnets:     []string{},
+		GatewayNodes:       k8s.NodeNames(gwNodes),
+		GatewayMachineSets: []string{},
+	}, nil
+}
+
+// inspectFirewallRule returns the ports allowed by the given firewall rule, if it exists.
+func (c *CloudInfo) inspectFirewallRule(ctx context.Context, name string) ([]api.PortSpec, error) {
+	ports := []api.PortSpec{}
+
+	rule, err := c.Client.GetFirewallRule(ctx, c.ProjectID, name)
+	if gcpclient.IsGCPNotFoundError(err) {
+		return ports, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving firewall rule %q", name)
+	}
+
+	for _, allowed := range rule.Allowed {
+		if len(allowed.Ports) == 0 {
+			ports = append(ports, api.PortSpec{Protocol: allowed.IPProtocol})
+		}
+
+		for _, port := range allowed.Ports {
+			value, err := strconv.ParseUint(port, 10, 16)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error parsing port %q of firewall rule %q", port, name)
+			}
+
+			ports = append(ports, api.PortSpec{Port: uint16(value), Protocol: allowed.IPProtocol})
+		}
+	}
+
+	return ports, nil
+}
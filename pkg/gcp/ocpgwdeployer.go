@@ -18,10 +18,10 @@ limitations under the License.
 package gcp
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"strings"
-	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/stringset"
@@ -30,74 +30,133 @@ import (
 	"github.com/submariner-io/cloud-prepare/pkg/ocp"
 	"google.golang.org/api/compute/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 )
 
 type ocpGatewayDeployer struct {
+	ocp.MachineSetTemplate
 	CloudInfo
-	msDeployer      ocp.MachineSetDeployer
-	instanceType    string
-	image           string
-	dedicatedGWNode bool
-	k8sClient       k8s.Interface
+	msDeployer        ocp.MachineSetDeployer
+	instanceType      string
+	image             string
+	dedicatedGWNode   bool
+	k8sClient         k8s.Interface
+	spot              *ocp.SpotConfig
+	staticIPs         *StaticIPConfig
+	deployedStaticIPs []StaticIP
 }
 
 // NewOcpGatewayDeployer returns a GatewayDeployer capable of deploying gateways using OCP.
 func NewOcpGatewayDeployer(info CloudInfo, msDeployer ocp.MachineSetDeployer, instanceType, image string,
 	dedicatedGWNode bool, k8sClient k8s.Interface) api.GatewayDeployer {
 	return &ocpGatewayDeployer{
-		CloudInfo:       info,
-		msDeployer:      msDeployer,
-		instanceType:    instanceType,
-		image:           image,
-		dedicatedGWNode: dedicatedGWNode,
-		k8sClient:       k8sClient,
+		MachineSetTemplate: ocp.NewMachineSetTemplate(machineSetYAML),
+		CloudInfo:          info,
+		msDeployer:         msDeployer,
+		instanceType:       instanceType,
+		image:              image,
+		dedicatedGWNode:    dedicatedGWNode,
+		k8sClient:          k8sClient,
 	}
 }
 
+// SetSpotInstances requests dedicated gateway nodes on preemptible VMs, falling back to on-demand VMs if preemptible
+// capacity can't be obtained in time.
+func (d *ocpGatewayDeployer) SetSpotInstances(config *ocp.SpotConfig) {
+	d.spot = config
+}
+
 func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *ocpGatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	err := d.deployGateways(ctx, input, reporter)
+	if err != nil || d.staticIPs == nil {
+		return err
+	}
+
+	d.deployedStaticIPs, err = d.listStaticIPs(ctx)
+
+	return err
+}
+
+func (d *ocpGatewayDeployer) deployGateways(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
 	reporter.Started("Configuring the required firewall rules for inter-cluster traffic")
 
-	externalIngress := newExternalFirewallRules(d.ProjectID, d.InfraID, input.PublicPorts)
-	if err := d.openPorts(externalIngress); err != nil {
-		return reportFailure(reporter, err, "error creating firewall rule %q", externalIngress.Name)
+	ruleNames, err := d.openPublicPorts(ctx, &input)
+	if err != nil {
+		return reportFailure(reporter, err, "error opening the external ports")
 	}
 
-	reporter.Succeeded("Opened External ports %q with firewall rule %q on GCP",
-		formatPorts(input.PublicPorts), externalIngress.Name)
+	reporter.Succeeded("Opened External ports %q with firewall rules %q on GCP",
+		formatPorts(input.PublicPorts), strings.Join(ruleNames, ", "))
 
-	numGatewayNodes, eligibleZonesForGW, err := d.parseCurrentGatewayInstances(reporter)
+	gatewayInstances, eligibleZonesForGW, err := d.parseCurrentGatewayInstances(ctx, reporter)
 	if err != nil {
 		return reportFailure(reporter, err, "error parsing current gateway instances")
 	}
 
-	gatewayNodesToDeploy := input.Gateways - numGatewayNodes
+	gatewayNodesToDeploy := input.Gateways - len(gatewayInstances)
 
-	if gatewayNodesToDeploy == 0 {
+	surplusZones := []string{}
+	if input.Gateways > 0 && gatewayNodesToDeploy < 0 {
+		surplusZones = surplusGatewayZones(gatewayInstances, input.Gateways)
+	}
+
+	if d.staticIPs != nil {
+		err = d.reconcileStaticIPs(ctx, gatewayInstances, surplusZones, reporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The default policy keeps whatever gateways already exist.
+	if gatewayNodesToDeploy == 0 || (input.Gateways == 0 && gatewayNodesToDeploy < 0) {
 		reporter.Succeeded("Current gateways match the required number of gateways")
 		return nil
 	}
 
-	// Currently, we only support increasing the number of Gateway nodes which could be a valid use-case
-	// to convert a non-HA deployment to an HA deployment. We are not supporting decreasing the Gateway
-	// nodes (for now) as it might impact the datapath if we accidentally delete the active GW node.
 	if gatewayNodesToDeploy < 0 {
-		reporter.Failed(fmt.Errorf("decreasing the number of Gateway nodes is not currently supported"))
+		for _, zone := range surplusZones {
+			reporter.Started(fmt.Sprintf("Removing the gateway instance %q in zone %q", gatewayInstances[zone].Name, zone))
+
+			err = d.removeGateway(ctx, zone, gatewayInstances[zone])
+			if err != nil {
+				return reportFailure(reporter, err, "error removing the gateway instance %q", gatewayInstances[zone].Name)
+			}
+
+			reporter.Succeeded("Removed the gateway instance %q", gatewayInstances[zone].Name)
+		}
+
 		return nil
 	}
 
 	if d.dedicatedGWNode {
 		for _, zone := range eligibleZonesForGW.Elements() {
-			reporter.Started(fmt.Sprintf("Deploying dedicated gateway node in zone %q", zone))
+			machineSetStep := d.machineSetStep(zone)
+
+			api.StepStarted(reporter, machineSetStep, "Deploying dedicated gateway node in zone %q", zone)
 
-			err = d.deployGateway(zone)
+			spot, err := d.deployGateway(ctx, zone)
 			if err != nil {
-				return reportFailure(reporter, err, "error deploying gateway for zone %q", zone)
+				return reportStepFailure(reporter, machineSetStep, err, "error deploying gateway for zone %q", zone)
+			}
+
+			api.StepSucceeded(reporter, machineSetStep, "Deployed dedicated gateway node in zone %q on %s VMs", zone, preemptibleKind(spot))
+
+			if d.staticIPs != nil {
+				reporter.Started(fmt.Sprintf("Attaching a static address to the dedicated gateway node in zone %q", zone))
+
+				address, err := d.attachStaticIP(ctx, zone)
+				if err != nil {
+					return reportFailure(reporter, err, "error attaching a static address to the gateway in zone %q", zone)
+				}
+
+				reporter.Succeeded("Attached static address %s to the dedicated gateway node in zone %q", address.Address, zone)
 			}
 
 			gatewayNodesToDeploy--
 			if gatewayNodesToDeploy <= 0 {
-				reporter.Succeeded("Successfully deployed gateway node")
 				return nil
 			}
 		}
@@ -105,26 +164,18 @@ func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.R
 		// Query the list of instances in the eligibleZones of the current region and if it's a worker node,
 		// configure the instance as Submariner Gateway node.
 		for _, zone := range eligibleZonesForGW.Elements() {
-			workerNodes, err := d.k8sClient.ListNodesWithLabel("topology.kubernetes.io/zone=" + zone + ",node-role.kubernetes.io/worker")
+			nodeName, instanceName, err := d.findWorkerNodeForGW(ctx, zone)
 			if err != nil {
 				return reportFailure(reporter, err, "failed to list k8s nodes in zone %q of project %q", zone, d.ProjectID)
 			}
 
-			for i := range workerNodes.Items {
-				node := &workerNodes.Items[i]
-				machineSetInfo := node.GetAnnotations()["machine.openshift.io/machine"]
-				gcpInstanceInfo := strings.Split(machineSetInfo, "/")
-				if len(gcpInstanceInfo) <= 1 {
-					continue
-				}
-
-				reporter.Started(fmt.Sprintf("Configuring worker node %q in zone %q as gateway node", node.Name, zone))
-				if err := d.configureExistingNodeAsGW(zone, gcpInstanceInfo[1], node.Name); err != nil {
-					return reportFailure(reporter, err, "error configuring gateway node %q", node.Name)
+			if nodeName != "" {
+				reporter.Started(fmt.Sprintf("Configuring worker node %q in zone %q as gateway node", nodeName, zone))
+				if err := d.configureExistingNodeAsGW(ctx, zone, instanceName, nodeName); err != nil {
+					return reportFailure(reporter, err, "error configuring gateway node %q", nodeName)
 				}
 
 				gatewayNodesToDeploy--
-				break
 			}
 
 			if gatewayNodesToDeploy <= 0 {
@@ -143,15 +194,44 @@ func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.R
 	return err
 }
 
-func (d *ocpGatewayDeployer) parseCurrentGatewayInstances(reporter api.Reporter) (int, stringset.Interface, error) {
-	zones, err := d.retrieveZones(reporter)
+// findWorkerNodeForGW returns the name of a worker node in the given zone which can be configured as a gateway,
+// along with the name of its GCP instance. Empty names are returned if no such node exists.
+func (d *ocpGatewayDeployer) findWorkerNodeForGW(ctx context.Context, zone string) (string, string, error) {
+	workerNodes, err := d.k8sClient.ListNodesWithLabelWithContext(ctx, "topology.kubernetes.io/zone="+zone+",node-role.kubernetes.io/worker")
+	if err != nil {
+		return "", "", errors.Wrap(err, "error listing worker nodes")
+	}
+
+	for i := range workerNodes.Items {
+		node := &workerNodes.Items[i]
+		machineSetInfo := node.GetAnnotations()["machine.openshift.io/machine"]
+
+		gcpInstanceInfo := strings.Split(machineSetInfo, "/")
+		if len(gcpInstanceInfo) > 1 {
+			return node.Name, gcpInstanceInfo[1], nil
+		}
+	}
+
+	return "", "", nil
+}
+
+func (d *ocpGatewayDeployer) parseCurrentGatewayInstances(ctx context.Context, reporter api.Reporter) (map[string]*compute.Instance,
+	stringset.Interface, error) {
+	zones, err := d.retrieveZones(ctx, reporter)
 	if err != nil {
-		return 0, nil, err
+		return nil, nil, err
 	}
 
 	reporter.Started("Verifying if current gateways match the required number of gateways")
 
-	zonesWithSubmarinerGW := stringset.New()
+	return d.findGatewayInstances(ctx, zones)
+}
+
+// findGatewayInstances returns the gateway instance of each zone which already has one, and the zones in the
+// region which are still eligible for one.
+func (d *ocpGatewayDeployer) findGatewayInstances(ctx context.Context, zones *compute.ZoneList) (map[string]*compute.Instance,
+	stringset.Interface, error) {
+	zonesWithSubmarinerGW := map[string]*compute.Instance{}
 	eligibleZonesForGW := stringset.New()
 
 	for _, zone := range zones.Items {
@@ -161,9 +241,9 @@ func (d *ocpGatewayDeployer) parseCurrentGatewayInstances(reporter api.Reporter)
 			continue
 		}
 
-		instanceList, err := d.Client.ListInstances(zone.Name)
+		instanceList, err := d.Client.ListInstances(ctx, zone.Name)
 		if err != nil {
-			return 0, nil, errors.Wrapf(err, "failed to list instances in zone %q of project %q", zone.Name, d.ProjectID)
+			return nil, nil, errors.Wrapf(err, "failed to list instances in zone %q of project %q", zone.Name, d.ProjectID)
 		}
 
 		for _, instance := range instanceList.Items {
@@ -174,18 +254,91 @@ func (d *ocpGatewayDeployer) parseCurrentGatewayInstances(reporter api.Reporter)
 
 			// A GatewayNode will always be tagged with submarinerGatewayNodeTag when deployed with OCPMachineSet
 			// as well as when an existing worker node is updated as a Gateway node.
-			if d.isInstanceGatewayNode(instance) {
-				zonesWithSubmarinerGW.Add(zone.Name)
+			if isInstanceGatewayNode(instance) {
+				zonesWithSubmarinerGW[zone.Name] = instance
 				break
 			}
 		}
 
-		if !zonesWithSubmarinerGW.Contains(zone.Name) {
+		if _, found := zonesWithSubmarinerGW[zone.Name]; !found {
 			eligibleZonesForGW.Add(zone.Name)
 		}
 	}
 
-	return zonesWithSubmarinerGW.Size(), eligibleZonesForGW, nil
+	return zonesWithSubmarinerGW, eligibleZonesForGW, nil
+}
+
+// surplusGatewayZones returns the zones whose gateway should be removed so that only the desired number of gateways
+// remain, keeping the oldest gateways.
+func surplusGatewayZones(gatewayInstances map[string]*compute.Instance, desired int) []string {
+	gateways := []k8s.Gateway{}
+
+	for zone, instance := range gatewayInstances {
+		// Instances without a valid creation timestamp are considered the newest.
+		created, err := time.Parse(time.RFC3339, instance.CreationTimestamp)
+		if err != nil {
+			created = time.Now()
+		}
+
+		gateways = append(gateways, k8s.Gateway{Name: zone, Zone: zone, CreationTimestamp: created})
+	}
+
+	zones := []string{}
+	for _, gateway := range k8s.SurplusGateways(gateways, desired) {
+		zones = append(zones, gateway.Zone)
+	}
+
+	return zones
+}
+
+// isDedicatedGateway checks whether the given gateway instance was deployed using the OCPMachineSet API rather than
+// being an existing worker node.
+func (d *ocpGatewayDeployer) isDedicatedGateway(zone string, instance *compute.Instance) bool {
+	return strings.HasPrefix(instance.Name, d.InfraID+"-submariner-gw-"+zone)
+}
+
+// removeGateway removes the gateway of the given zone and releases its static address, if any. The gateway is only
+// forgotten once both succeeded, so that a failed removal is retried from its record.
+func (d *ocpGatewayDeployer) removeGateway(ctx context.Context, zone string, instance *compute.Instance) error {
+	var err error
+
+	if d.isDedicatedGateway(zone, instance) {
+		err = d.deleteGateway(ctx, zone)
+	} else {
+		err = d.resetExistingGWNode(ctx, zone, instance)
+		if err == nil {
+			err = d.unlabelGatewayNode(ctx, instance)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = d.releaseStaticIP(ctx, zone)
+	if err != nil {
+		return err
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, api.Resource{Type: api.ResourceGatewayInstance, ID: zone})
+}
+
+// unlabelGatewayNode removes the gateway label from the node of the given instance.
+func (d *ocpGatewayDeployer) unlabelGatewayNode(ctx context.Context, instance *compute.Instance) error {
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	for i := range gwNodes.Items {
+		machineSetInfo := strings.Split(gwNodes.Items[i].GetAnnotations()["machine.openshift.io/machine"], "/")
+		if len(machineSetInfo) > 1 && machineSetInfo[1] == instance.Name {
+			return errors.Wrapf(d.k8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, &gwNodes.Items[i]),
+				"error removing the gateway label from node %q", gwNodes.Items[i].Name)
+		}
+	}
+
+	return nil
 }
 
 type machineSetConfig struct {
@@ -198,80 +351,97 @@ type machineSetConfig struct {
 	SubmarinerGWNodeTag string
 }
 
-func (d *ocpGatewayDeployer) loadGatewayYAML(zone, image string) ([]byte, error) {
-	var buf bytes.Buffer
-
-	// TODO: Not working properly, but we should revisit this as it makes more sense
-	// tpl, err := template.ParseFiles("pkg/aws/gw-machineset.yaml.template")
-	tpl, err := template.New("").Parse(machineSetYAML)
-	if err != nil {
-		return nil, errors.Wrap(err, "error parsing machine set YAML")
-	}
-
+func (d *ocpGatewayDeployer) initMachineSet(zone string) (*unstructured.Unstructured, error) {
 	tplVars := machineSetConfig{
 		AZ:                  zone,
 		InfraID:             d.InfraID,
 		ProjectID:           d.ProjectID,
 		InstanceType:        d.instanceType,
 		Region:              d.Region,
-		Image:               image,
+		Image:               d.image,
 		SubmarinerGWNodeTag: submarinerGatewayNodeTag,
 	}
 
-	err = tpl.Execute(&buf, tplVars)
+	return d.RenderMachineSet(tplVars)
+}
+
+// deployGateway deploys the gateway machine set of the given zone, and returns whether its VMs are preemptible.
+func (d *ocpGatewayDeployer) deployGateway(ctx context.Context, zone string) (bool, error) {
+	machineSet, err := d.initMachineSet(zone)
 	if err != nil {
-		return nil, errors.Wrap(err, "error executing the template")
+		return false, err
 	}
 
-	return buf.Bytes(), nil
-}
+	if d.image == "" {
+		// TODO: use machineSetClient.List() instead of hard coding.
+		workerNodeList := []string{d.InfraID + "-worker-b", d.InfraID + "-worker-c", d.InfraID + "-worker-d"}
 
-func (d *ocpGatewayDeployer) initMachineSet(zone string) (*unstructured.Unstructured, error) {
-	gatewayYAML, err := d.loadGatewayYAML(zone, d.image)
-	if err != nil {
-		return nil, err
+		d.image, err = d.msDeployer.GetWorkerNodeImageWithContext(ctx, workerNodeList, machineSet, d.InfraID)
+		if err != nil {
+			return false, errors.Wrap(err, "error retrieving worker node image")
+		}
+
+		machineSet, err = d.initMachineSet(zone)
+		if err != nil {
+			return false, err
+		}
 	}
 
-	unstructDecoder := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+	preemptibleMachineSet := machineSet.DeepCopy()
 
-	machineSet := &unstructured.Unstructured{}
+	err = unstructured.SetNestedField(preemptibleMachineSet.Object, true,
+		"spec", "template", "spec", "providerSpec", "value", "preemptible")
+	if err != nil {
+		return false, errors.Wrapf(err, "error requesting preemptible VMs in machine set %q", machineSet.GetName())
+	}
 
-	_, _, err = unstructDecoder.Decode(gatewayYAML, nil, machineSet)
+	spot, err := ocp.DeploySpotMachineSet(ctx, d.msDeployer, d.spot, preemptibleMachineSet, machineSet)
 	if err != nil {
-		return nil, errors.Wrap(err, "error converting YAML to machine set")
+		return false, errors.Wrapf(err, "error deploying machine set %q", machineSet.GetName())
 	}
 
-	return machineSet, nil
+	return spot, api.RecordResources(ctx, d.StateStore, gatewayInstanceResource(zone, machineSet.GetName(), true))
 }
 
-func (d *ocpGatewayDeployer) deployGateway(zone string) error {
-	machineSet, err := d.initMachineSet(zone)
-	if err != nil {
-		return err
+func preemptibleKind(preemptible bool) string {
+	if preemptible {
+		return "preemptible"
 	}
 
-	if d.image == "" {
-		// TODO: use machineSetClient.List() instead of hard coding.
-		workerNodeList := []string{d.InfraID + "-worker-b", d.InfraID + "-worker-c", d.InfraID + "-worker-d"}
+	return "on-demand"
+}
 
-		d.image, err = d.msDeployer.GetWorkerNodeImage(workerNodeList, machineSet, d.InfraID)
-		if err != nil {
-			return errors.Wrap(err, "error retrieving worker node image")
-		}
+func (d *ocpGatewayDeployer) configureExistingNodeAsGW(ctx context.Context, zone, gcpInstanceInfo, nodeName string) error {
+	staticIP := ""
 
-		machineSet, err = d.initMachineSet(zone)
+	if d.staticIPs != nil {
+		address, err := d.reserveStaticIP(ctx, zone)
 		if err != nil {
 			return err
 		}
+
+		staticIP = address.Address
 	}
 
-	return errors.Wrapf(d.msDeployer.Deploy(machineSet), "error deploying machine set %q", machineSet.GetName())
+	instance, err := d.configureInstanceAsGW(ctx, zone, gcpInstanceInfo, staticIP)
+	if err != nil {
+		return err
+	}
+
+	err = d.k8sClient.AddGWLabelOnNodeWithContext(ctx, nodeName)
+	if err != nil {
+		return errors.Wrapf(err, "error labeling node %q", nodeName)
+	}
+
+	return api.RecordResources(ctx, d.StateStore, gatewayInstanceResource(zone, instance.Name, false))
 }
 
-func (d *ocpGatewayDeployer) configureExistingNodeAsGW(zone, gcpInstanceInfo, nodeName string) error {
-	instance, err := d.Client.GetInstance(zone, gcpInstanceInfo)
+// configureInstanceAsGW tags the given instance as a gateway and configures a public IP on it: the given static address,
+// or an ephemeral one if empty.
+func (c *CloudInfo) configureInstanceAsGW(ctx context.Context, zone, instanceName, staticIP string) (*compute.Instance, error) {
+	instance, err := c.Client.GetInstance(ctx, zone, instanceName)
 	if err != nil {
-		return errors.Wrapf(err, "error retrieving GCP instance %q in zode %q", gcpInstanceInfo, zone)
+		return nil, errors.Wrapf(err, "error retrieving GCP instance %q in zode %q", instanceName, zone)
 	}
 
 	tags := &compute.Tags{
@@ -281,35 +451,74 @@ func (d *ocpGatewayDeployer) configureExistingNodeAsGW(zone, gcpInstanceInfo, no
 
 	tags.Items = append(tags.Items, submarinerGatewayNodeTag)
 
-	err = d.Client.UpdateInstanceNetworkTags(d.ProjectID, zone, instance.Name, tags)
+	err = c.Client.UpdateInstanceNetworkTags(ctx, c.ProjectID, zone, instance.Name, tags)
 	if err != nil {
-		return errors.Wrapf(err, "error updating network tags for GCP instance %q in zode %q", instance.Name, zone)
+		return nil, errors.Wrapf(err, "error updating network tags for GCP instance %q in zode %q", instance.Name, zone)
 	}
 
-	err = d.Client.ConfigurePublicIPOnInstance(instance)
-	if err != nil {
-		return errors.Wrapf(err, "error configuring public IP for GCP instance %q in zode %q", instance.Name, zone)
+	if staticIP != "" {
+		err = c.Client.ConfigureStaticIPOnInstance(ctx, instance, staticIP)
+	} else {
+		err = c.Client.ConfigurePublicIPOnInstance(ctx, instance)
 	}
 
-	err = d.k8sClient.AddGWLabelOnNode(nodeName)
 	if err != nil {
-		return errors.Wrapf(err, "error labeling node %q", nodeName)
+		return nil, errors.Wrapf(err, "error configuring public IP for GCP instance %q in zode %q", instance.Name, zone)
 	}
 
-	return nil
+	return instance, nil
 }
 
 func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *ocpGatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
 	reporter.Started("Retrieving the Submariner gateway firewall rules")
 
-	err := d.deleteExternalFWRules(reporter)
+	err := d.deleteExternalFWRules(ctx, reporter)
 	if err != nil {
 		return reportFailure(reporter, err, "failed to delete the gateway firewall rules in the project %q", d.ProjectID)
 	}
 
 	reporter.Succeeded("Successfully deleted the firewall rules")
 
-	zones, err := d.retrieveZones(reporter)
+	cleanedUp, err := d.cleanupRecordedGateways(ctx, reporter)
+	if err != nil {
+		return reportFailure(reporter, err, "error removing the recorded gateways")
+	}
+
+	if !cleanedUp {
+		err = d.cleanupDiscoveredGateways(ctx, reporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	reporter.Started("Releasing the static addresses of the gateways")
+
+	err = d.releaseStaticIPs(ctx)
+	if err != nil {
+		return reportFailure(reporter, err, "error releasing the static addresses of the gateways")
+	}
+
+	reporter.Succeeded("Released the static addresses of the gateways")
+
+	reporter.Started("Removing the Submariner gateway label from worker nodes")
+
+	err = d.k8sClient.RemoveGWLabelFromWorkerNodesWithContext(ctx)
+	if err != nil {
+		return reportFailure(reporter, err, "error removing the gateway label from worker nodes")
+	}
+
+	reporter.Succeeded("Successfully removed the label from the worker nodes")
+
+	return nil
+}
+
+// cleanupDiscoveredGateways removes the gateways found from the names and network tags of the instances in the region.
+func (d *ocpGatewayDeployer) cleanupDiscoveredGateways(ctx context.Context, reporter api.Reporter) error {
+	zones, err := d.retrieveZones(ctx, reporter)
 	if err != nil {
 		return reportFailure(reporter, err, "error retrieving zones")
 	}
@@ -319,7 +528,7 @@ func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
 			continue
 		}
 
-		instanceList, err := d.Client.ListInstances(zone.Name)
+		instanceList, err := d.Client.ListInstances(ctx, zone.Name)
 		if err != nil {
 			return reportFailure(reporter, err, "failed to list instances in zone %q of project %q", zone.Name, d.ProjectID)
 		}
@@ -330,26 +539,25 @@ func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
 				continue
 			}
 
-			if !d.isInstanceGatewayNode(instance) {
+			if !isInstanceGatewayNode(instance) {
 				continue
 			}
 
-			// If the instance name matches with d.InfraID + "-submariner-gw-" + zone.Name, it implies that
-			// the gateway node was deployed using the OCPMachineSet API otherwise it's an existing worker node.
-			prefix := d.InfraID + "-submariner-gw-" + zone.Name
-			if strings.HasPrefix(instance.Name, prefix) {
-				reporter.Started(fmt.Sprintf("Deleting the gateway instance %q", instance.Name))
+			if d.isDedicatedGateway(zone.Name, instance) {
+				machineSetStep := d.machineSetStep(zone.Name)
+
+				api.StepStarted(reporter, machineSetStep, "Deleting the gateway instance %q", instance.Name)
 
-				err := d.deleteGateway(zone.Name)
+				err := d.deleteGateway(ctx, zone.Name)
 				if err != nil {
-					return reportFailure(reporter, err, "failed to delete dedicated gateway instance %q", instance.Name)
+					return reportStepFailure(reporter, machineSetStep, err, "failed to delete dedicated gateway instance %q", instance.Name)
 				}
 
-				reporter.Succeeded("Successfully deleted the instance")
+				api.StepSucceeded(reporter, machineSetStep, "Successfully deleted the instance")
 			} else {
 				reporter.Started(fmt.Sprintf("Removing the gateway configuration from instance %q", instance.Name))
 
-				err = d.resetExistingGWNode(zone.Name, instance)
+				err = d.resetExistingGWNode(ctx, zone.Name, instance)
 				if err != nil {
 					return reportFailure(reporter, err, "failed to delete gateway instance %q", instance.Name)
 				}
@@ -359,37 +567,60 @@ func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
 		}
 	}
 
-	reporter.Started("Removing the Submariner gateway label from worker nodes")
+	return nil
+}
 
-	err = d.k8sClient.RemoveGWLabelFromWorkerNodes()
+func (d *ocpGatewayDeployer) deleteGateway(ctx context.Context, zone string) error {
+	machineSet, err := d.initMachineSet(zone)
 	if err != nil {
-		return reportFailure(reporter, err, "error removing the gateway label from worker nodes")
+		return err
 	}
 
-	reporter.Succeeded("Successfully removed the label from the worker nodes")
-
-	return nil
+	return errors.Wrapf(d.msDeployer.DeleteWithContext(ctx, machineSet), "error deleting machine set %q", machineSet.GetName())
 }
 
-func (d *ocpGatewayDeployer) deleteGateway(zone string) error {
-	machineSet, err := d.initMachineSet(zone)
+func (c *CloudInfo) deleteExternalFWRules(ctx context.Context, reporter api.Reporter) error {
+	recordedRules, err := c.recordedFirewallRules(ctx, publicRuleScope)
 	if err != nil {
 		return err
 	}
 
-	return errors.Wrapf(d.msDeployer.Delete(machineSet), "error deleting machine set %q", machineSet.GetName())
-}
+	if len(recordedRules) > 0 {
+		for _, name := range recordedRules {
+			if err := c.deleteFirewallRule(ctx, name, reporter); err != nil {
+				return errors.Wrapf(err, "error deleting firewall rule %q", name)
+			}
+		}
+
+		return nil
+	}
 
-func (d *ocpGatewayDeployer) deleteExternalFWRules(reporter api.Reporter) error {
-	ingressName := generateRuleName(d.InfraID, publicPortsRuleName)
+	ingressName := generateRuleName(c.InfraID, publicPortsRuleName)
 
-	if err := d.deleteFirewallRule(ingressName, reporter); err != nil {
+	if err := c.deleteFirewallRule(ctx, ingressName, reporter); err != nil {
 		return errors.Wrapf(err, "error deleting firewall rule %q", ingressName)
 	}
 
+	portRules, err := c.stalePublicRules(ctx, []*compute.Firewall{{Name: ingressName}})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range portRules {
+		if err := c.deleteFirewallRule(ctx, name, reporter); err != nil {
+			return errors.Wrapf(err, "error deleting firewall rule %q", name)
+		}
+	}
+
 	return nil
 }
 
+// UpdatePublicPorts updates the source CIDRs of the firewall rules opening the public ports, without redeploying the
+// gateways.
+func (d *ocpGatewayDeployer) UpdatePublicPorts(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.updatePublicPorts(ctx, &input, reporter)
+}
+
 func reportFailure(reporter api.Reporter, failure error, format string, args ...interface{}) error {
 	err := errors.WithMessagef(failure, format, args...)
 	reporter.Failed(err)
@@ -397,13 +628,25 @@ func reportFailure(reporter api.Reporter, failure error, format string, args ...
 	return err
 }
 
+func reportStepFailure(reporter api.Reporter, step api.Step, failure error, format string, args ...interface{}) error {
+	err := errors.WithMessagef(failure, format, args...)
+	api.StepFailed(reporter, step, err)
+
+	return err
+}
+
+// machineSetStep returns the step operating on the gateway machine set of the given zone.
+func (d *ocpGatewayDeployer) machineSetStep(zone string) api.Step {
+	return api.ResourceStep(providerName, api.ResourceMachineSet, d.InfraID+"-submariner-gw-"+zone)
+}
+
 func (d *ocpGatewayDeployer) ignoreZone(zone *compute.Zone) bool {
 	region := zone.Region[strings.LastIndex(zone.Region, "/")+1:]
 
 	return region != d.Region
 }
 
-func (d *ocpGatewayDeployer) isInstanceGatewayNode(instance *compute.Instance) bool {
+func isInstanceGatewayNode(instance *compute.Instance) bool {
 	if instance.Tags == nil {
 		return false
 	}
@@ -417,7 +660,7 @@ func (d *ocpGatewayDeployer) isInstanceGatewayNode(instance *compute.Instance) b
 	return false
 }
 
-func (d *ocpGatewayDeployer) resetExistingGWNode(zone string, instance *compute.Instance) error {
+func (c *CloudInfo) resetExistingGWNode(ctx context.Context, zone string, instance *compute.Instance) error {
 	for i := range instance.Tags.Items {
 		if instance.Tags.Items[i] == submarinerGatewayNodeTag {
 			instance.Tags.Items = append(instance.Tags.Items[:i], instance.Tags.Items[i+1:]...)
@@ -429,12 +672,12 @@ func (d *ocpGatewayDeployer) resetExistingGWNode(zone string, instance *compute.
 		Fingerprint: instance.Tags.Fingerprint,
 	}
 
-	err := d.Client.UpdateInstanceNetworkTags(d.ProjectID, zone, instance.Name, tags)
+	err := c.Client.UpdateInstanceNetworkTags(ctx, c.ProjectID, zone, instance.Name, tags)
 	if err != nil {
 		return errors.Wrapf(err, "error updating network tags for GCP instance %q in zode %q", instance.Name, zone)
 	}
 
-	err = d.Client.DeletePublicIPOnInstance(instance)
+	err = c.Client.DeletePublicIPOnInstance(ctx, instance)
 	if err != nil {
 		return errors.Wrapf(err, "error deleting public IP for GCP instance %q in zode %q", instance.Name, zone)
 	}
@@ -442,10 +685,10 @@ func (d *ocpGatewayDeployer) resetExistingGWNode(zone string, instance *compute.
 	return nil
 }
 
-func (d *ocpGatewayDeployer) retrieveZones(reporter api.Reporter) (*compute.ZoneList, error) {
+func (d *ocpGatewayDeployer) retrieveZones(ctx context.Context, reporter api.Reporter) (*compute.ZoneList, error) {
 	reporter.Started("Retrieving the current zones in the project")
 
-	zones, err := d.Client.ListZones()
+	zones, err := d.Client.ListZones(ctx)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list the zones in the project %q", d.ProjectID)
 	}
@@ -22,7 +22,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
@@ -30,6 +33,7 @@ import (
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/cloud-prepare/pkg/gcp"
 	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
 	ocpFake "github.com/submariner-io/cloud-prepare/pkg/ocp/fake"
 	"google.golang.org/api/compute/v1"
 	"google.golang.org/api/googleapi"
@@ -47,6 +51,8 @@ const (
 var _ = Describe("OCP GatewayDeployer", func() {
 	Context("on Deploy", testDeploy)
 	Context("on Cleanup", testCleanup)
+	Context("on Inspect", testInspectGateways)
+	Context("on UpdatePublicPorts", testUpdatePublicPorts)
 })
 
 func testDeploy() {
@@ -60,11 +66,13 @@ func testDeploy() {
 	BeforeEach(func() {
 		actualRule = nil
 
-		t.gcpClient.EXPECT().GetFirewallRule(projectID, publicPortsRuleName).Return(nil, &googleapi.Error{Code: http.StatusNotFound})
-		t.gcpClient.EXPECT().InsertFirewallRule(projectID, gomock.Any()).DoAndReturn(func(_ string, rule *compute.Firewall) error {
-			actualRule = rule
-			return nil
-		})
+		t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, publicPortsRuleName).Return(nil,
+			&googleapi.Error{Code: http.StatusNotFound})
+		t.gcpClient.EXPECT().InsertFirewallRule(gomock.Any(), projectID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, rule *compute.Firewall) error {
+				actualRule = rule
+				return nil
+			})
 	})
 
 	JustBeforeEach(func() {
@@ -128,6 +136,46 @@ func testDeploy() {
 		})
 	})
 
+	When("static addresses are requested for the gateways", func() {
+		BeforeEach(func() {
+			t.nodes = []*corev1.Node{
+				newNode("node-1", zone1, instance1),
+			}
+
+			t.staticIPs = &gcp.StaticIPConfig{}
+			t.numGateways = 1
+		})
+
+		Context("and the address isn't reserved yet", func() {
+			BeforeEach(func() {
+				t.expAddressReserved(zone1, "10.0.0.1")
+				t.expInstanceTaggedWithStaticIP(zone1, t.instances[zone1][0], "10.0.0.1")
+			})
+
+			It("should attach a newly reserved address to the gateway node", func() {
+				Expect(retError).To(Succeed())
+				t.assertLabeledNodes("node-1")
+				Expect(t.gwDeployer.(gcp.StaticIPRequester).DeployedStaticIPs()).To(Equal([]gcp.StaticIP{{
+					Name:    staticIPName(zone1),
+					Zone:    zone1,
+					Address: "10.0.0.1",
+				}}))
+			})
+		})
+
+		Context("and the address is already reserved", func() {
+			BeforeEach(func() {
+				t.addAddress(zone1, "10.0.0.2")
+				t.expInstanceTaggedWithStaticIP(zone1, t.instances[zone1][0], "10.0.0.2")
+			})
+
+			It("should attach the existing address to the gateway node", func() {
+				Expect(retError).To(Succeed())
+				t.assertLabeledNodes("node-1")
+			})
+		})
+	})
+
 	When("the requested number of gateways is increased", func() {
 		BeforeEach(func() {
 			t.nodes = []*corev1.Node{
@@ -154,13 +202,99 @@ func testDeploy() {
 			}
 
 			t.instances[zone1][0].Tags.Items = []string{submarinerGatewayNodeTag}
+			t.instances[zone1][0].CreationTimestamp = "2022-02-01T10:00:00Z"
 			t.instances[zone2][0].Tags.Items = []string{submarinerGatewayNodeTag}
+			t.instances[zone2][0].CreationTimestamp = "2022-01-01T10:00:00Z"
 			t.numGateways = 1
 		})
 
-		It("should do nothing", func() {
-			Expect(retError).To(Succeed())
-			t.assertLabeledNodes("node-1", "node-2")
+		Context("and the gateways are labeled worker nodes", func() {
+			BeforeEach(func() {
+				t.expInstanceUntagged(zone1, t.instances[zone1][0])
+			})
+
+			It("should remove the newest gateway", func() {
+				Expect(retError).To(Succeed())
+				t.assertLabeledNodes("node-2")
+			})
+		})
+
+		Context("and the gateways are dedicated nodes", func() {
+			var machineSets map[string]*unstructured.Unstructured
+
+			BeforeEach(func() {
+				t.instances[zone1][0].Name = infraID + "-submariner-gw-" + zone1
+				t.instances[zone2][0].Name = infraID + "-submariner-gw-" + zone2
+				t.msDeployer.EXPECT().DeleteWithContext(gomock.Any(), gomock.Any()).DoAndReturn(machineSetFn(&machineSets))
+				t.dedicatedGWNode = true
+			})
+
+			It("should delete the newest gateway's machine set", func() {
+				Expect(retError).To(Succeed())
+				Expect(machineSets).To(HaveLen(1))
+				t.assertMachineSet(machineSets[zone1], "")
+			})
+		})
+
+		Context("and deleting the newest gateway fails", func() {
+			var (
+				dir   string
+				store api.StateStore
+			)
+
+			BeforeEach(func() {
+				var err error
+
+				dir, err = ioutil.TempDir("", "state")
+				Expect(err).To(Succeed())
+
+				store = api.NewFileStateStore(filepath.Join(dir, "state.json"))
+				t.stateStore = store
+
+				Expect(api.RecordResources(context.TODO(), store, api.Resource{Type: api.ResourceGatewayInstance, ID: zone1},
+					api.Resource{Type: api.ResourceGatewayInstance, ID: zone2})).To(Succeed())
+
+				t.instances[zone1][0].Name = infraID + "-submariner-gw-" + zone1
+				t.instances[zone2][0].Name = infraID + "-submariner-gw-" + zone2
+				t.msDeployer.EXPECT().DeleteWithContext(gomock.Any(), gomock.Any()).Return(errors.New("fake delete error"))
+				t.dedicatedGWNode = true
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			})
+
+			It("should return an error and keep its record", func() {
+				Expect(retError).ToNot(Succeed())
+
+				recorded, err := api.RecordedResources(context.TODO(), store, api.ResourceGatewayInstance)
+				Expect(err).To(Succeed())
+				Expect(recorded).To(HaveLen(2))
+			})
+		})
+
+		Context("and the newest gateway has a static address", func() {
+			BeforeEach(func() {
+				t.expInstanceUntagged(zone1, t.instances[zone1][0])
+				t.addAddress(zone1, "10.0.0.1")
+				t.expAddressDeleted(zone1)
+			})
+
+			It("should release its address", func() {
+				Expect(retError).To(Succeed())
+				Expect(t.addresses).To(BeEmpty())
+			})
+		})
+
+		Context("to the default", func() {
+			BeforeEach(func() {
+				t.numGateways = 0
+			})
+
+			It("should keep the existing gateways", func() {
+				Expect(retError).To(Succeed())
+				t.assertLabeledNodes("node-1", "node-2")
+			})
 		})
 	})
 
@@ -188,12 +322,56 @@ func testDeploy() {
 		})
 	})
 
+	When("the gateways are already deployed and static addresses are requested", func() {
+		BeforeEach(func() {
+			t.nodes = []*corev1.Node{
+				labelNode(newNode("node-1", zone1, instance1)),
+				labelNode(newNode("node-2", zone2, instance2)),
+			}
+
+			t.instances[zone1][0].Tags.Items = []string{submarinerGatewayNodeTag}
+			t.instances[zone1][0].NetworkInterfaces = withExternalIP("1.1.1.1")
+			t.instances[zone2][0].Tags.Items = []string{submarinerGatewayNodeTag}
+			t.instances[zone2][0].NetworkInterfaces = withExternalIP("2.2.2.2")
+			t.staticIPs = &gcp.StaticIPConfig{}
+			t.numGateways = 2
+		})
+
+		Context("and the addresses aren't reserved yet", func() {
+			BeforeEach(func() {
+				t.expAddressReserved(zone1, "10.0.0.1")
+				t.expAddressReserved(zone2, "10.0.0.2")
+				t.gcpClient.EXPECT().ConfigureStaticIPOnInstance(gomock.Any(), t.instances[zone1][0], "10.0.0.1")
+				t.gcpClient.EXPECT().ConfigureStaticIPOnInstance(gomock.Any(), t.instances[zone2][0], "10.0.0.2")
+			})
+
+			It("should attach newly reserved addresses to the existing gateways", func() {
+				Expect(retError).To(Succeed())
+				Expect(t.gwDeployer.(gcp.StaticIPRequester).DeployedStaticIPs()).To(HaveLen(2))
+			})
+		})
+
+		Context("and a gateway instance was recreated with an ephemeral address", func() {
+			BeforeEach(func() {
+				t.addAddress(zone1, "10.0.0.1")
+				t.addAddress(zone2, "10.0.0.2")
+				t.instances[zone1][0].NetworkInterfaces = withExternalIP("10.0.0.1")
+				t.gcpClient.EXPECT().ConfigureStaticIPOnInstance(gomock.Any(), t.instances[zone2][0], "10.0.0.2")
+			})
+
+			It("should only attach its address back to the recreated gateway", func() {
+				Expect(retError).To(Succeed())
+			})
+		})
+	})
+
 	When("dedicated gateway nodes are requested", func() {
 		var machineSets map[string]*unstructured.Unstructured
 
 		BeforeEach(func() {
-			t.msDeployer.EXPECT().GetWorkerNodeImage(gomock.Any(), infraID).Return("test-image", nil).AnyTimes()
-			t.msDeployer.EXPECT().Deploy(gomock.Any()).DoAndReturn(machineSetFn(&machineSets)).Times(2)
+			t.msDeployer.EXPECT().GetWorkerNodeImageWithContext(gomock.Any(), gomock.Any(), gomock.Any(), infraID).
+				Return("test-image", nil).AnyTimes()
+			t.msDeployer.EXPECT().DeployWithContext(gomock.Any(), gomock.Any()).DoAndReturn(machineSetFn(&machineSets)).Times(2)
 
 			t.dedicatedGWNode = true
 			t.numGateways = 2
@@ -220,11 +398,45 @@ func testDeploy() {
 				t.assertMachineSet(machineSets[zone2], "custom-image")
 			})
 		})
+
+		Context("with static addresses", func() {
+			BeforeEach(func() {
+				t.staticIPs = &gcp.StaticIPConfig{}
+
+				t.instances[zone1] = append(t.instances[zone1], &compute.Instance{Name: staticIPName(zone1) + "-a", Status: "RUNNING"})
+				t.instances[zone2] = append(t.instances[zone2], &compute.Instance{Name: staticIPName(zone2) + "-b", Status: "RUNNING"})
+
+				t.expAddressReserved(zone1, "10.0.0.1")
+				t.expAddressReserved(zone2, "10.0.0.2")
+				t.gcpClient.EXPECT().ConfigureStaticIPOnInstance(gomock.Any(), t.instances[zone1][2], "10.0.0.1")
+				t.gcpClient.EXPECT().ConfigureStaticIPOnInstance(gomock.Any(), t.instances[zone2][1], "10.0.0.2")
+			})
+
+			It("should attach a static address to each gateway node", func() {
+				Expect(retError).To(Succeed())
+				Expect(t.gwDeployer.(gcp.StaticIPRequester).DeployedStaticIPs()).To(HaveLen(2))
+			})
+		})
+
+		Context("with customized machine sets", func() {
+			BeforeEach(func() {
+				t.msOverrides.Patch = `{"spec": {"template": {"spec": {"providerSpec": {"value": {"preemptible": true}}}}}}`
+			})
+
+			It("should deploy the customized machine sets", func() {
+				Expect(retError).To(Succeed())
+
+				Expect(machineSets).To(HaveLen(2))
+				preemptible, _, _ := unstructured.NestedBool(machineSets[zone1].Object, "spec", "template", "spec", "providerSpec",
+					"value", "preemptible")
+				Expect(preemptible).To(BeTrue())
+			})
+		})
 	})
 
 	When("zone retrieval fails", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().ListZones().Return(nil, errors.New("fake error"))
+			t.gcpClient.EXPECT().ListZones(gomock.Any()).Return(nil, errors.New("fake error"))
 		})
 
 		It("should return an error", func() {
@@ -233,6 +445,145 @@ func testDeploy() {
 	})
 }
 
+func testUpdatePublicPorts() {
+	const (
+		udpPortRuleName = "test-infraID-submariner-public-udp-200-ingress"
+		tcpPortRuleName = "test-infraID-submariner-public-tcp-100-ingress"
+	)
+
+	t := newGatewayDeployerTestDriver()
+
+	var (
+		input        api.GatewayDeployInput
+		updatedRules map[string]*compute.Firewall
+		deletedRules []string
+	)
+
+	BeforeEach(func() {
+		input = api.GatewayDeployInput{
+			PublicPorts: []api.PortSpec{
+				{Port: 100, Protocol: "TCP"},
+				{Port: 200, Protocol: "UDP", SourceCIDRs: []string{"10.2.0.0/16"}},
+			},
+			PublicSourceCIDRs: []string{"10.1.0.0/16"},
+		}
+
+		updatedRules = map[string]*compute.Firewall{}
+		deletedRules = []string{}
+
+		t.firewallRules = []*compute.Firewall{{Name: publicPortsRuleName}, {Name: tcpPortRuleName}}
+
+		t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, name string) (*compute.Firewall, error) {
+				for _, rule := range t.firewallRules {
+					if rule.Name == name {
+						return rule, nil
+					}
+				}
+
+				return nil, &googleapi.Error{Code: http.StatusNotFound}
+			}).AnyTimes()
+
+		t.gcpClient.EXPECT().UpdateFirewallRule(gomock.Any(), projectID, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, name string, rule *compute.Firewall) error {
+				updatedRules[name] = rule
+				return nil
+			}).AnyTimes()
+
+		t.gcpClient.EXPECT().InsertFirewallRule(gomock.Any(), projectID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, rule *compute.Firewall) error {
+				updatedRules[rule.Name] = rule
+				return nil
+			}).AnyTimes()
+
+		t.gcpClient.EXPECT().DeleteFirewallRule(gomock.Any(), projectID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, name string) error {
+				deletedRules = append(deletedRules, name)
+				return nil
+			}).AnyTimes()
+	})
+
+	It("should update the source ranges of the rules in place", func() {
+		Expect(t.gwDeployer.(api.PublicPortsUpdater).UpdatePublicPorts(context.TODO(), input, api.NewLoggingReporter())).To(Succeed())
+
+		Expect(updatedRules).To(HaveLen(2))
+		Expect(updatedRules[publicPortsRuleName].SourceRanges).To(Equal([]string{"10.1.0.0/16"}))
+		Expect(updatedRules[publicPortsRuleName].Allowed).To(Equal([]*compute.FirewallAllowed{{IPProtocol: "TCP", Ports: []string{"100"}}}))
+		Expect(updatedRules[udpPortRuleName].SourceRanges).To(Equal([]string{"10.2.0.0/16"}))
+		Expect(updatedRules[udpPortRuleName].Allowed).To(Equal([]*compute.FirewallAllowed{{IPProtocol: "UDP", Ports: []string{"200"}}}))
+		Expect(deletedRules).To(Equal([]string{tcpPortRuleName}))
+	})
+
+	It("should plan the rule changes", func() {
+		actions, err := t.gwDeployer.Plan(context.TODO(), input)
+		Expect(err).To(Succeed())
+		Expect(actions).To(ContainElements(
+			api.Action{
+				Kind:     api.ActionUpdateFirewallRule,
+				Resource: publicPortsRuleName,
+				Parameters: map[string]string{
+					"network":       "projects/test-projectID/global/networks/test-infraID-network",
+					"ports":         "100/TCP",
+					"source-ranges": "10.1.0.0/16",
+				},
+			},
+			api.Action{
+				Kind:     api.ActionInsertFirewallRule,
+				Resource: udpPortRuleName,
+				Parameters: map[string]string{
+					"network":       "projects/test-projectID/global/networks/test-infraID-network",
+					"ports":         "200/UDP",
+					"source-ranges": "10.2.0.0/16",
+				},
+			},
+			api.Action{
+				Kind:     api.ActionDeleteFirewallRule,
+				Resource: tcpPortRuleName,
+			},
+		))
+		Expect(updatedRules).To(BeEmpty())
+	})
+}
+
+func testInspectGateways() {
+	t := newGatewayDeployerTestDriver()
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{
+			labelNode(newNode("node-1", zone1, instance1)),
+			newNode("node-2", zone2, instance2),
+		}
+
+		t.gcpClient.EXPECT().GetFirewallRule(gomock.Any(), projectID, publicPortsRuleName).Return(&compute.Firewall{
+			Allowed: []*compute.FirewallAllowed{{IPProtocol: "udp", Ports: []string{"4500"}}},
+		}, nil)
+
+		t.msDeployer.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, machineSet *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+				Expect(machineSet.GetNamespace()).To(Equal("openshift-machine-api"))
+
+				gateway := unstructured.Unstructured{}
+				gateway.SetName(infraID + "-submariner-gw-" + zone1)
+
+				worker := unstructured.Unstructured{}
+				worker.SetName(infraID + "-worker-" + zone1)
+
+				return []unstructured.Unstructured{gateway, worker}, nil
+			})
+	})
+
+	It("should return the public ports, the gateway nodes and their machine sets", func() {
+		status, err := t.gwDeployer.Inspect(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(status).To(Equal(&api.GatewayStatus{
+			PublicPorts:        []api.PortSpec{{Port: 4500, Protocol: "udp"}},
+			GatewaySubnets:     []string{},
+			GatewayNodes:       []string{"node-1"},
+			GatewayMachineSets: []string{infraID + "-submariner-gw-" + zone1},
+		}))
+	})
+}
+
 func testCleanup() {
 	t := newGatewayDeployerTestDriver()
 
@@ -246,7 +597,7 @@ func testCleanup() {
 	})
 
 	JustBeforeEach(func() {
-		t.gcpClient.EXPECT().DeleteFirewallRule(projectID, publicPortsRuleName).Return(deleteFirewallRule)
+		t.gcpClient.EXPECT().DeleteFirewallRule(gomock.Any(), projectID, publicPortsRuleName).Return(deleteFirewallRule)
 		retError = t.gwDeployer.Cleanup(api.NewLoggingReporter())
 	})
 
@@ -284,7 +635,7 @@ func testCleanup() {
 			t.instances[zone1][0].Tags.Items = []string{submarinerGatewayNodeTag}
 			t.instances[zone2][0].Tags.Items = []string{submarinerGatewayNodeTag}
 
-			t.msDeployer.EXPECT().Delete(gomock.Any()).DoAndReturn(machineSetFn(&machineSets)).Times(2)
+			t.msDeployer.EXPECT().DeleteWithContext(gomock.Any(), gomock.Any()).DoAndReturn(machineSetFn(&machineSets)).Times(2)
 		})
 
 		It("should delete them", func() {
@@ -296,9 +647,25 @@ func testCleanup() {
 		})
 	})
 
+	Context("with static addresses reserved for the gateways", func() {
+		BeforeEach(func() {
+			t.addAddress(zone1, "10.0.0.1")
+			t.addAddress(zone2, "10.0.0.2")
+			t.addresses["other-address"] = &compute.Address{Name: "other-address"}
+
+			t.expAddressDeleted(zone1)
+			t.expAddressDeleted(zone2)
+		})
+
+		It("should release them", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.addresses).To(HaveLen(1))
+		})
+	})
+
 	When("zone retrieval fails", func() {
 		BeforeEach(func() {
-			t.gcpClient.EXPECT().ListZones().Return(nil, errors.New("fake error"))
+			t.gcpClient.EXPECT().ListZones(gomock.Any()).Return(nil, errors.New("fake error"))
 		})
 
 		It("should return an error", func() {
@@ -322,6 +689,11 @@ type gatewayDeployerTestDriver struct {
 	numGateways     int
 	dedicatedGWNode bool
 	image           string
+	msOverrides     ocp.MachineSetOverrides
+	staticIPs       *gcp.StaticIPConfig
+	stateStore      api.StateStore
+	addresses       map[string]*compute.Address
+	firewallRules   []*compute.Firewall
 	kubeClient      *kubeFake.Clientset
 	msDeployer      *ocpFake.MockMachineSetDeployer
 	nodes           []*corev1.Node
@@ -373,31 +745,62 @@ func newGatewayDeployerTestDriver() *gatewayDeployerTestDriver {
 
 		t.dedicatedGWNode = false
 		t.image = ""
+		t.msOverrides = ocp.MachineSetOverrides{}
+		t.staticIPs = nil
+		t.stateStore = nil
+		t.addresses = map[string]*compute.Address{}
+		t.firewallRules = nil
 		t.msDeployer = ocpFake.NewMockMachineSetDeployer(t.mockCtrl)
 		t.kubeClient = kubeFake.NewSimpleClientset()
 	})
 
 	JustBeforeEach(func() {
-		t.gcpClient.EXPECT().ListZones().Return(&compute.ZoneList{Items: t.zones}, nil).AnyTimes()
-		t.gcpClient.EXPECT().ListInstances(gomock.Any()).DoAndReturn(func(zone string) (*compute.InstanceList, error) {
-			list := t.instances[zone]
-			if list != nil {
-				return &compute.InstanceList{Items: list}, nil
-			}
+		t.gcpClient.EXPECT().ListZones(gomock.Any()).Return(&compute.ZoneList{Items: t.zones}, nil).AnyTimes()
+		t.gcpClient.EXPECT().ListFirewallRules(gomock.Any(), projectID, "name eq test-infraID-submariner-public-.*").DoAndReturn(
+			func(_ context.Context, _, _ string) (*compute.FirewallList, error) {
+				return &compute.FirewallList{Items: t.firewallRules}, nil
+			}).AnyTimes()
+		t.gcpClient.EXPECT().ListInstances(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, zone string) (*compute.InstanceList, error) {
+				list := t.instances[zone]
+				if list != nil {
+					return &compute.InstanceList{Items: list}, nil
+				}
 
-			return &compute.InstanceList{}, nil
-		}).AnyTimes()
+				return &compute.InstanceList{}, nil
+			}).AnyTimes()
 
-		t.gcpClient.EXPECT().GetInstance(gomock.Any(), gomock.Any()).DoAndReturn(func(zone, instance string) (*compute.Instance, error) {
-			list := t.instances[zone]
-			for _, i := range list {
-				if i.Name == instance {
-					return i, nil
+		t.gcpClient.EXPECT().GetInstance(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, zone, instance string) (*compute.Instance, error) {
+				list := t.instances[zone]
+				for _, i := range list {
+					if i.Name == instance {
+						return i, nil
+					}
+				}
+
+				return nil, fmt.Errorf("instance %q not found", instance)
+			}).AnyTimes()
+
+		t.gcpClient.EXPECT().GetAddress(gomock.Any(), region, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, name string) (*compute.Address, error) {
+				address, ok := t.addresses[name]
+				if !ok {
+					return nil, &googleapi.Error{Code: http.StatusNotFound}
 				}
-			}
 
-			return nil, fmt.Errorf("instance %q not found", instance)
-		}).AnyTimes()
+				return address, nil
+			}).AnyTimes()
+
+		t.gcpClient.EXPECT().ListAddresses(gomock.Any(), region).DoAndReturn(
+			func(_ context.Context, _ string) (*compute.AddressList, error) {
+				list := &compute.AddressList{}
+				for _, address := range t.addresses {
+					list.Items = append(list.Items, address)
+				}
+
+				return list, nil
+			}).AnyTimes()
 
 		for _, node := range t.nodes {
 			_, err := t.kubeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
@@ -412,6 +815,13 @@ func newGatewayDeployerTestDriver() *gatewayDeployerTestDriver {
 			ProjectID: projectID,
 			Client:    t.gcpClient,
 		}, t.msDeployer, instanceType, t.image, t.dedicatedGWNode, k8s.NewInterface(t.kubeClient))
+
+		Expect(t.gwDeployer.(ocp.MachineSetCustomizer).SetMachineSetOverrides(t.msOverrides)).To(Succeed())
+		t.gwDeployer.(gcp.StaticIPRequester).SetStaticIPs(t.staticIPs)
+
+		if t.stateStore != nil {
+			t.gwDeployer.(api.StateRecorder).SetStateStore(t.stateStore)
+		}
 	})
 
 	return t
@@ -460,6 +870,7 @@ func (t *gatewayDeployerTestDriver) assertLabeledNodes(expNodes ...string) {
 func (t *gatewayDeployerTestDriver) assertIngressRule(rule *compute.Firewall) {
 	Expect(rule.Name).To(Equal(publicPortsRuleName))
 	Expect(rule.Direction).To(Equal("INGRESS"))
+	Expect(rule.SourceRanges).To(Equal([]string{api.AnyIPv4CIDR}))
 	Expect(rule.Allowed).To(HaveLen(2))
 	Expect(rule.Allowed[0]).To(Equal(&compute.FirewallAllowed{
 		IPProtocol: "TCP",
@@ -472,19 +883,59 @@ func (t *gatewayDeployerTestDriver) assertIngressRule(rule *compute.Firewall) {
 }
 
 func (t *gatewayDeployerTestDriver) expInstanceTagged(zone string, instance *compute.Instance) {
-	t.gcpClient.EXPECT().UpdateInstanceNetworkTags(projectID, zone, instance.Name, &compute.Tags{
+	t.gcpClient.EXPECT().UpdateInstanceNetworkTags(gomock.Any(), projectID, zone, instance.Name, &compute.Tags{
 		Items: []string{submarinerGatewayNodeTag},
 	})
 
-	t.gcpClient.EXPECT().ConfigurePublicIPOnInstance(instance)
+	t.gcpClient.EXPECT().ConfigurePublicIPOnInstance(gomock.Any(), instance)
+}
+
+func (t *gatewayDeployerTestDriver) expInstanceTaggedWithStaticIP(zone string, instance *compute.Instance, address string) {
+	t.gcpClient.EXPECT().UpdateInstanceNetworkTags(gomock.Any(), projectID, zone, instance.Name, &compute.Tags{
+		Items: []string{submarinerGatewayNodeTag},
+	})
+
+	t.gcpClient.EXPECT().ConfigureStaticIPOnInstance(gomock.Any(), instance, address)
+}
+
+func withExternalIP(address string) []*compute.NetworkInterface {
+	return []*compute.NetworkInterface{{
+		Name:          "nic0",
+		AccessConfigs: []*compute.AccessConfig{{Name: "External NAT", NatIP: address}},
+	}}
+}
+
+func (t *gatewayDeployerTestDriver) addAddress(zone, address string) {
+	t.addresses[staticIPName(zone)] = &compute.Address{
+		Name:    staticIPName(zone),
+		Address: address,
+		Status:  "RESERVED",
+	}
+}
+
+func (t *gatewayDeployerTestDriver) expAddressReserved(zone, address string) {
+	t.gcpClient.EXPECT().ReserveAddress(gomock.Any(), region, addressNamed(staticIPName(zone))).DoAndReturn(
+		func(_ context.Context, _ string, reserved *compute.Address) (*compute.Address, error) {
+			t.addAddress(zone, address)
+
+			return t.addresses[reserved.Name], nil
+		})
+}
+
+func (t *gatewayDeployerTestDriver) expAddressDeleted(zone string) {
+	t.gcpClient.EXPECT().DeleteAddress(gomock.Any(), region, staticIPName(zone)).DoAndReturn(
+		func(_ context.Context, _, name string) error {
+			delete(t.addresses, name)
+			return nil
+		})
 }
 
 func (t *gatewayDeployerTestDriver) expInstanceUntagged(zone string, instance *compute.Instance) {
-	t.gcpClient.EXPECT().UpdateInstanceNetworkTags(projectID, zone, instance.Name, &compute.Tags{
+	t.gcpClient.EXPECT().UpdateInstanceNetworkTags(gomock.Any(), projectID, zone, instance.Name, &compute.Tags{
 		Items: []string{},
 	})
 
-	t.gcpClient.EXPECT().DeletePublicIPOnInstance(instance)
+	t.gcpClient.EXPECT().DeletePublicIPOnInstance(gomock.Any(), instance)
 }
 
 func (t *gatewayDeployerTestDriver) assertMachineSet(ms *unstructured.Unstructured, expImage string) {
@@ -533,10 +984,10 @@ func labelNode(node *corev1.Node) *corev1.Node {
 }
 
 // nolint:gocritic // Error: "consider `machineSets' to be of non-pointer type"
-func machineSetFn(machineSets *map[string]*unstructured.Unstructured) func(ms *unstructured.Unstructured) error {
+func machineSetFn(machineSets *map[string]*unstructured.Unstructured) func(_ context.Context, ms *unstructured.Unstructured) error {
 	*machineSets = map[string]*unstructured.Unstructured{}
 
-	return func(ms *unstructured.Unstructured) error {
+	return func(_ context.Context, ms *unstructured.Unstructured) error {
 		zone, ok, _ := unstructured.NestedString(ms.Object, "spec", "template", "spec", "providerSpec", "value", "zone")
 		Expect(ok).To(BeTrue())
 
@@ -545,3 +996,18 @@ func machineSetFn(machineSets *map[string]*unstructured.Unstructured) func(ms *u
 		return nil
 	}
 }
+
+func staticIPName(zone string) string {
+	return infraID + "-submariner-gw-" + zone
+}
+
+type addressNamed string
+
+func (m addressNamed) Matches(x interface{}) bool {
+	address, ok := x.(*compute.Address)
+	return ok && address.Name == string(m)
+}
+
+func (m addressNamed) String() string {
+	return "is an address named " + string(m)
+}
@@ -0,0 +1,303 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	gcpclient "github.com/submariner-io/cloud-prepare/pkg/gcp/client"
+	"google.golang.org/api/compute/v1"
+)
+
+// Plan returns the firewall rule changes PrepareForSubmariner would make on GCP.
+func (gc *gcpCloud) Plan(ctx context.Context, input api.PrepareForSubmarinerInput) ([]api.Action, error) {
+	return gc.planOpenPorts(ctx, gc.newInternalFirewallRule(input.InternalPorts))
+}
+
+// Plan returns the firewall rule changes and the gateway nodes Deploy would create, configure or remove on GCP.
+func (d *ocpGatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	actions, err := d.planPublicPorts(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := d.Client.ListZones(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the zones in the project %q", d.ProjectID)
+	}
+
+	gatewayInstances, eligibleZonesForGW, err := d.findGatewayInstances(ctx, zones)
+	if err != nil {
+		return nil, err
+	}
+
+	gatewayNodesToDeploy := input.Gateways - len(gatewayInstances)
+	if gatewayNodesToDeploy < 0 && input.Gateways > 0 {
+		for _, zone := range surplusGatewayZones(gatewayInstances, input.Gateways) {
+			actions = append(actions, d.planGatewayRemoval(zone, gatewayInstances[zone])...)
+		}
+	}
+
+	if gatewayNodesToDeploy <= 0 {
+		return actions, nil
+	}
+
+	for _, zone := range eligibleZonesForGW.Elements() {
+		if d.dedicatedGWNode {
+			machineSet, err := d.initMachineSet(zone)
+			if err != nil {
+				return nil, err
+			}
+
+			actions = append(actions, api.Action{
+				Kind:     api.ActionDeployMachineSet,
+				Resource: machineSet.GetName(),
+				Parameters: map[string]string{
+					"namespace":     machineSet.GetNamespace(),
+					"zone":          zone,
+					"instance-type": d.instanceType,
+					"image":         d.image,
+				},
+			})
+		} else {
+			nodeName, instanceName, err := d.findWorkerNodeForGW(ctx, zone)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "failed to list k8s nodes in zone %q of project %q", zone, d.ProjectID)
+			}
+
+			if nodeName == "" {
+				continue
+			}
+
+			actions = append(actions,
+				api.Action{
+					Kind:       api.ActionTagInstance,
+					Resource:   instanceName,
+					Parameters: map[string]string{"zone": zone, "tag": submarinerGatewayNodeTag},
+				},
+				api.Action{
+					Kind:       api.ActionConfigurePublicIP,
+					Resource:   instanceName,
+					Parameters: map[string]string{"zone": zone},
+				},
+				api.Action{
+					Kind:     api.ActionLabelNode,
+					Resource: nodeName,
+				})
+		}
+
+		gatewayNodesToDeploy--
+		if gatewayNodesToDeploy <= 0 {
+			return actions, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there are an insufficient number of zones (%d) to deploy the desired number of gateways (%d)",
+		eligibleZonesForGW.Size(), input.Gateways)
+}
+
+func (d *ocpGatewayDeployer) planGatewayRemoval(zone string, instance *compute.Instance) []api.Action {
+	if d.isDedicatedGateway(zone, instance) {
+		return []api.Action{{
+			Kind:       api.ActionDeleteMachineSet,
+			Resource:   d.InfraID + "-submariner-gw-" + zone,
+			Parameters: map[string]string{"zone": zone},
+		}}
+	}
+
+	return planGatewayNodeRemoval(zone, instance.Name)
+}
+
+// planGatewayNodeRemoval returns the actions removing the gateway configuration from an existing node, whose instance
+// has the same name.
+func planGatewayNodeRemoval(zone, instanceName string) []api.Action {
+	return []api.Action{
+		{
+			Kind:       api.ActionUntagInstance,
+			Resource:   instanceName,
+			Parameters: map[string]string{"zone": zone, "tag": submarinerGatewayNodeTag},
+		},
+		{
+			Kind:       api.ActionDeletePublicIP,
+			Resource:   instanceName,
+			Parameters: map[string]string{"zone": zone},
+		},
+		{
+			Kind:     api.ActionUnlabelNode,
+			Resource: instanceName,
+		},
+	}
+}
+
+// planOpenPorts returns the firewall rule insertions, or the updates of rules which don't allow the expected ports,
+// openPorts would perform.
+func (c *CloudInfo) planOpenPorts(ctx context.Context, rules ...*compute.Firewall) ([]api.Action, error) {
+	actions := []api.Action{}
+
+	for _, rule := range rules {
+		kind := api.ActionUpdateFirewallRule
+
+		existing, err := c.Client.GetFirewallRule(ctx, c.ProjectID, rule.Name)
+		if gcpclient.IsGCPNotFoundError(err) {
+			kind = api.ActionInsertFirewallRule
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "error retrieving firewall rule %q", rule.Name)
+		} else if formatFirewallPorts(existing.Allowed) == formatFirewallPorts(rule.Allowed) &&
+			formatSourceRanges(existing.SourceRanges) == formatSourceRanges(rule.SourceRanges) {
+			continue
+		}
+
+		action := api.Action{
+			Kind:     kind,
+			Resource: rule.Name,
+			Parameters: map[string]string{
+				"network": rule.Network,
+				"ports":   formatFirewallPorts(rule.Allowed),
+			},
+		}
+
+		if len(rule.SourceRanges) > 0 {
+			action.Parameters["source-ranges"] = formatSourceRanges(rule.SourceRanges)
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+// planPublicPorts returns the actions needed to open the public ports to their source CIDRs, and to delete the rules of
+// the ports which no longer have their own sources.
+func (c *CloudInfo) planPublicPorts(ctx context.Context, input *api.GatewayDeployInput) ([]api.Action, error) {
+	ingressRules := c.newExternalFirewallRules(input)
+
+	actions, err := c.planOpenPorts(ctx, ingressRules...)
+	if err != nil {
+		return nil, err
+	}
+
+	staleRules, err := c.stalePublicRules(ctx, ingressRules)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range staleRules {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionDeleteFirewallRule,
+			Resource: name,
+		})
+	}
+
+	return actions, nil
+}
+
+func formatSourceRanges(sourceRanges []string) string {
+	sorted := append([]string{}, sourceRanges...)
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, ", ")
+}
+
+func formatFirewallPorts(allowed []*compute.FirewallAllowed) string {
+	portStrs := []string{}
+
+	for _, rule := range allowed {
+		if len(rule.Ports) == 0 {
+			portStrs = append(portStrs, "0/"+rule.IPProtocol)
+		}
+
+		for _, port := range rule.Ports {
+			portStrs = append(portStrs, port+"/"+rule.IPProtocol)
+		}
+	}
+
+	sort.Strings(portStrs)
+
+	return strings.Join(portStrs, ", ")
+}
+
+// Plan returns the firewall rule changes and the gateway node pool or nodes Deploy would create, configure or remove on
+// GKE.
+func (d *gkeGatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	actions, err := d.planPublicPorts(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.dedicatedNodePool {
+		existing, zones, err := d.nodePoolZones(ctx, input.Gateways)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing == nil && len(zones) > 0 {
+			actions = append(actions, api.Action{
+				Kind:     api.ActionCreateNodePool,
+				Resource: gkeGatewayNodePool,
+				Parameters: map[string]string{
+					"zones":        strings.Join(zones, ","),
+					"machine-type": d.machineType,
+				},
+			})
+		} else if existing != nil && strings.Join(existing, ",") != strings.Join(zones, ",") {
+			actions = append(actions, api.Action{
+				Kind:       api.ActionUpdateNodePool,
+				Resource:   gkeGatewayNodePool,
+				Parameters: map[string]string{"zones": strings.Join(zones, ",")},
+			})
+		}
+
+		return actions, nil
+	}
+
+	toConfigure, toRemove, err := d.selectGatewayNodes(ctx, input.Gateways)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range toRemove {
+		actions = append(actions, planGatewayNodeRemoval(node.Labels[zoneLabel], node.Name)...)
+	}
+
+	for _, node := range toConfigure {
+		zone := node.Labels[zoneLabel]
+
+		actions = append(actions,
+			api.Action{
+				Kind:       api.ActionTagInstance,
+				Resource:   node.Name,
+				Parameters: map[string]string{"zone": zone, "tag": submarinerGatewayNodeTag},
+			},
+			api.Action{
+				Kind:       api.ActionConfigurePublicIP,
+				Resource:   node.Name,
+				Parameters: map[string]string{"zone": zone},
+			},
+			api.Action{
+				Kind:     api.ActionLabelNode,
+				Resource: node.Name,
+			})
+	}
+
+	return actions, nil
+}
@@ -0,0 +1,161 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// SetStateStore sets the store recording the resources provisioned on GCP, which are then cleaned up exactly.
+func (c *CloudInfo) SetStateStore(store api.StateStore) {
+	c.StateStore = store
+}
+
+const (
+	internalRuleScope = "internal"
+	publicRuleScope   = "public"
+)
+
+// firewallRuleResource records a firewall rule along with its scope, either the internal ports opened by the cloud or
+// the public ports opened by the gateway deployer.
+func (c *CloudInfo) firewallRuleResource(name string) api.Resource {
+	scope := publicRuleScope
+	if name == generateRuleName(c.InfraID, internalPortsRuleName) {
+		scope = internalRuleScope
+	}
+
+	return api.Resource{
+		Type:       api.ResourceFirewallRule,
+		ID:         name,
+		Parameters: map[string]string{"scope": scope},
+	}
+}
+
+// recordedFirewallRules returns the names of the recorded firewall rules of the given scope.
+func (c *CloudInfo) recordedFirewallRules(ctx context.Context, scope string) ([]string, error) {
+	recorded, err := api.RecordedResources(ctx, c.StateStore, api.ResourceFirewallRule)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	names := []string{}
+
+	for i := range recorded {
+		if recorded[i].Parameters["scope"] == scope {
+			names = append(names, recorded[i].ID)
+		}
+	}
+
+	return names, nil
+}
+
+// gatewayInstanceResource records the gateway of a zone, which is either a dedicated instance deployed by a machine set
+// or an existing worker instance configured as gateway.
+func gatewayInstanceResource(zone, instanceName string, dedicated bool) api.Resource {
+	return api.Resource{
+		Type: api.ResourceGatewayInstance,
+		ID:   zone,
+		Parameters: map[string]string{
+			"instance":  instanceName,
+			"dedicated": strconv.FormatBool(dedicated),
+		},
+	}
+}
+
+// cleanupRecordedGateways removes the recorded gateways, returning false if there are no records to clean up from.
+func (d *ocpGatewayDeployer) cleanupRecordedGateways(ctx context.Context, reporter api.Reporter) (bool, error) {
+	recorded, err := api.RecordedResources(ctx, d.StateStore, api.ResourceGatewayInstance)
+	if err != nil || len(recorded) == 0 {
+		return false, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	for i := range recorded {
+		zone := recorded[i].ID
+		instanceName := recorded[i].Parameters["instance"]
+
+		if recorded[i].Parameters["dedicated"] == "true" {
+			machineSetStep := d.machineSetStep(zone)
+
+			api.StepStarted(reporter, machineSetStep, "Deleting the gateway machine set in zone %q", zone)
+
+			err = d.deleteGateway(ctx, zone)
+			if err != nil {
+				return true, errors.WithMessagef(err, "failed to delete the dedicated gateway in zone %q", zone)
+			}
+
+			api.StepSucceeded(reporter, machineSetStep, "Successfully deleted the machine set")
+		} else {
+			reporter.Started(fmt.Sprintf("Removing the gateway configuration from instance %q", instanceName))
+
+			instance, err := d.Client.GetInstance(ctx, zone, instanceName)
+			if err != nil {
+				return true, errors.Wrapf(err, "error retrieving GCP instance %q in zone %q", instanceName, zone)
+			}
+
+			err = d.resetExistingGWNode(ctx, zone, instance)
+			if err != nil {
+				return true, errors.WithMessagef(err, "failed to delete gateway instance %q", instanceName)
+			}
+
+			reporter.Succeeded("Successfully reconfigured the instance")
+		}
+
+		err = api.ForgetResources(ctx, d.StateStore, recorded[i])
+		if err != nil {
+			return true, err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	return true, nil
+}
+
+func staticIPResource(address *compute.Address, zone string) api.Resource {
+	return api.Resource{
+		Type: api.ResourceFloatingIP,
+		ID:   address.Name,
+		Parameters: map[string]string{
+			"address": address.Address,
+			"zone":    zone,
+		},
+	}
+}
+
+// recordedStaticIPZones returns the zones of the recorded static addresses.
+func (d *ocpGatewayDeployer) recordedStaticIPZones(ctx context.Context) ([]string, error) {
+	recorded, err := api.RecordedResources(ctx, d.StateStore, api.ResourceFloatingIP)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	zones := []string{}
+	for i := range recorded {
+		zones = append(zones, recorded[i].Parameters["zone"])
+	}
+
+	return zones, nil
+}
+
+func nodePoolResource(name string) api.Resource {
+	return api.Resource{Type: api.ResourceNodePool, ID: name}
+}
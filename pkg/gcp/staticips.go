@@ -0,0 +1,321 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	gcpclient "github.com/submariner-io/cloud-prepare/pkg/gcp/client"
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultStaticIPTimeout is how long a gateway instance is waited for when StaticIPConfig doesn't specify a timeout.
+	DefaultStaticIPTimeout = 10 * time.Minute
+
+	staticIPPollInterval = 10 * time.Second
+	addressInUse         = "IN_USE"
+)
+
+// StaticIPConfig requests static external addresses for the gateways, so that their public IPs survive their
+// recreation.
+type StaticIPConfig struct {
+	// Timeout bounds the waits for a dedicated gateway instance to be running, so that its address can be attached to
+	// it, and for a removed gateway to release its address. Zero uses DefaultStaticIPTimeout.
+	Timeout time.Duration
+}
+
+// StaticIP is a static external address reserved for the gateway of a zone.
+type StaticIP struct {
+	Name     string
+	Zone     string
+	Address  string
+	Instance string
+}
+
+// StaticIPRequester is implemented by the gateway deployers which can attach static external addresses to the gateways.
+type StaticIPRequester interface {
+	// SetStaticIPs requests a static external address for each gateway; nil reverts to ephemeral addresses.
+	SetStaticIPs(config *StaticIPConfig)
+
+	// DeployedStaticIPs returns the static addresses of the gateways, as of the last successful Deploy.
+	DeployedStaticIPs() []StaticIP
+}
+
+// SetStaticIPs requests a static external address for each gateway. The address of a zone is reused by the gateways
+// recreated in it.
+func (d *ocpGatewayDeployer) SetStaticIPs(config *StaticIPConfig) {
+	d.staticIPs = config
+}
+
+func (d *ocpGatewayDeployer) DeployedStaticIPs() []StaticIP {
+	return d.deployedStaticIPs
+}
+
+func (d *ocpGatewayDeployer) staticIPPrefix() string {
+	return d.InfraID + "-submariner-gw-"
+}
+
+func (d *ocpGatewayDeployer) staticIPTimeout() time.Duration {
+	if d.staticIPs == nil || d.staticIPs.Timeout == 0 {
+		return DefaultStaticIPTimeout
+	}
+
+	return d.staticIPs.Timeout
+}
+
+// reserveStaticIP returns the static address of the given zone, reserving it if needed.
+func (d *ocpGatewayDeployer) reserveStaticIP(ctx context.Context, zone string) (*compute.Address, error) {
+	name := d.staticIPPrefix() + zone
+
+	address, err := d.Client.GetAddress(ctx, d.Region, name)
+	if err == nil {
+		return address, nil
+	}
+
+	if !gcpclient.IsGCPNotFoundError(err) {
+		return nil, errors.Wrapf(err, "error retrieving address %q", name)
+	}
+
+	address, err = d.Client.ReserveAddress(ctx, d.Region, &compute.Address{
+		Name:        name,
+		AddressType: "EXTERNAL",
+		Description: fmt.Sprintf("Submariner gateway address of cluster %s in zone %s", d.InfraID, zone),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reserving address %q", name)
+	}
+
+	return address, api.RecordResources(ctx, d.StateStore, staticIPResource(address, zone))
+}
+
+// attachStaticIP attaches the static address of the given zone to its dedicated gateway instance, once it's running.
+func (d *ocpGatewayDeployer) attachStaticIP(ctx context.Context, zone string) (*compute.Address, error) {
+	address, err := d.reserveStaticIP(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := d.waitForDedicatedGatewayInstance(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Client.ConfigureStaticIPOnInstance(ctx, instance, address.Address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error attaching address %q to GCP instance %q", address.Address, instance.Name)
+	}
+
+	return address, nil
+}
+
+// reconcileStaticIPs attaches the static address of their zone, reserving it if needed, to the existing gateway
+// instances outside the given zones. This covers the gateways deployed before static addresses were requested, and
+// those recreated by their machine set with an ephemeral address.
+func (d *ocpGatewayDeployer) reconcileStaticIPs(ctx context.Context, gatewayInstances map[string]*compute.Instance,
+	skippedZones []string, reporter api.Reporter) error {
+	skipped := stringset.New(skippedZones...)
+	zones := []string{}
+
+	for zone := range gatewayInstances {
+		if !skipped.Contains(zone) {
+			zones = append(zones, zone)
+		}
+	}
+
+	sort.Strings(zones)
+
+	for _, zone := range zones {
+		instance := gatewayInstances[zone]
+
+		address, err := d.reserveStaticIP(ctx, zone)
+		if err != nil {
+			return reportFailure(reporter, err, "error reserving the static address of the gateway in zone %q", zone)
+		}
+
+		if hasExternalIP(instance, address.Address) {
+			continue
+		}
+
+		reporter.Started(fmt.Sprintf("Attaching static address %s to the gateway instance %q", address.Address, instance.Name))
+
+		err = d.Client.ConfigureStaticIPOnInstance(ctx, instance, address.Address)
+		if err != nil {
+			return reportFailure(reporter, err, "error attaching address %q to GCP instance %q", address.Address, instance.Name)
+		}
+
+		reporter.Succeeded("Attached static address %s to the gateway instance %q", address.Address, instance.Name)
+	}
+
+	return nil
+}
+
+// hasExternalIP checks whether the given instance is already reachable through the given external address.
+func hasExternalIP(instance *compute.Instance, address string) bool {
+	for _, networkInterface := range instance.NetworkInterfaces {
+		for _, accessConfig := range networkInterface.AccessConfigs {
+			if accessConfig.NatIP == address {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// waitForDedicatedGatewayInstance waits for the dedicated gateway instance of the given zone to be running.
+func (d *ocpGatewayDeployer) waitForDedicatedGatewayInstance(ctx context.Context, zone string) (*compute.Instance, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.staticIPTimeout())
+	defer cancel()
+
+	var gateway *compute.Instance
+
+	err := wait.PollImmediateUntil(staticIPPollInterval, func() (bool, error) {
+		instanceList, err := d.Client.ListInstances(ctx, zone)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to list instances in zone %q of project %q", zone, d.ProjectID)
+		}
+
+		for _, instance := range instanceList.Items {
+			if d.isDedicatedGateway(zone, instance) && instance.Status == "RUNNING" {
+				gateway = instance
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}, ctx.Done())
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return nil, fmt.Errorf("timed out after %v waiting for the dedicated gateway instance in zone %q to be running",
+			d.staticIPTimeout(), zone)
+	}
+
+	return gateway, err
+}
+
+// releaseStaticIP releases the static address of the given zone, if any, once it's no longer used by its gateway.
+func (d *ocpGatewayDeployer) releaseStaticIP(ctx context.Context, zone string) error {
+	name := d.staticIPPrefix() + zone
+
+	waitCtx, cancel := context.WithTimeout(ctx, d.staticIPTimeout())
+	defer cancel()
+
+	var address *compute.Address
+
+	err := wait.PollImmediateUntil(staticIPPollInterval, func() (bool, error) {
+		var err error
+
+		address, err = d.Client.GetAddress(waitCtx, d.Region, name)
+		if gcpclient.IsGCPNotFoundError(err) {
+			address = nil
+			return true, nil
+		}
+
+		if err != nil {
+			return false, errors.Wrapf(err, "error retrieving address %q", name)
+		}
+
+		return address.Status != addressInUse, nil
+	}, waitCtx.Done())
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timed out after %v waiting for address %q to be released by its gateway", d.staticIPTimeout(), name)
+	}
+
+	if err != nil || address == nil {
+		return err
+	}
+
+	err = d.Client.DeleteAddress(ctx, d.Region, name)
+	if err != nil && !gcpclient.IsGCPNotFoundError(err) {
+		return errors.Wrapf(err, "error releasing address %q", name)
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, staticIPResource(address, zone))
+}
+
+// releaseStaticIPs releases the recorded static addresses or, if none were recorded, those of all the zones.
+func (d *ocpGatewayDeployer) releaseStaticIPs(ctx context.Context) error {
+	zones, err := d.recordedStaticIPZones(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(zones) == 0 {
+		addresses, err := d.listStaticIPs(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := range addresses {
+			zones = append(zones, addresses[i].Zone)
+		}
+	}
+
+	for _, zone := range zones {
+		err = d.releaseStaticIP(ctx, zone)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listStaticIPs returns the static addresses reserved for the gateways, sorted by zone.
+func (d *ocpGatewayDeployer) listStaticIPs(ctx context.Context) ([]StaticIP, error) {
+	addressList, err := d.Client.ListAddresses(ctx, d.Region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the addresses in region %q", d.Region)
+	}
+
+	staticIPs := []StaticIP{}
+
+	for _, address := range addressList.Items {
+		if !strings.HasPrefix(address.Name, d.staticIPPrefix()) {
+			continue
+		}
+
+		staticIP := StaticIP{
+			Name:    address.Name,
+			Zone:    strings.TrimPrefix(address.Name, d.staticIPPrefix()),
+			Address: address.Address,
+		}
+
+		if len(address.Users) > 0 {
+			staticIP.Instance = address.Users[0][strings.LastIndex(address.Users[0], "/")+1:]
+		}
+
+		staticIPs = append(staticIPs, staticIP)
+	}
+
+	sort.Slice(staticIPs, func(i, j int) bool {
+		return staticIPs[i].Zone < staticIPs[j].Zone
+	})
+
+	return staticIPs, nil
+}
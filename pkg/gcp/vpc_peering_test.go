@@ -19,6 +19,7 @@ package gcp_test
 
 import (
 	"fmt"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
@@ -26,18 +27,8 @@ import (
 	"github.com/submariner-io/cloud-prepare/pkg/gcp"
 )
 
-type invalidCloud struct{}
-
-func (f *invalidCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
-	panic("not implemented")
-}
-
-func (f *invalidCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) error {
-	panic("not implemented")
-}
-
-func (f *invalidCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
-	panic("not implemented")
+type invalidCloud struct {
+	api.Cloud
 }
 
 var _ = Describe("GCP Peering", func() {
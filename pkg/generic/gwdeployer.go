@@ -19,16 +19,20 @@ limitations under the License.
 package generic
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/cloud-prepare/pkg/k8s"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type gatewayDeployer struct {
-	k8sClient k8s.Interface
+	k8sClient  k8s.Interface
+	stateStore api.StateStore
 }
 
 // NewGatewayDeployer creates a generic GatewayDeployer implementation.
@@ -36,8 +40,17 @@ func NewGatewayDeployer(k8sClient k8s.Interface) api.GatewayDeployer {
 	return &gatewayDeployer{k8sClient: k8sClient}
 }
 
+// SetStateStore sets the store recording the labeled gateway nodes, which are then unlabeled exactly on clean up.
+func (g *gatewayDeployer) SetStateStore(store api.StateStore) {
+	g.stateStore = store
+}
+
 func (g *gatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
-	gwNodes, err := g.k8sClient.ListGatewayNodes()
+	return g.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (g *gatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	gwNodes, err := g.k8sClient.ListGatewayNodesWithContext(ctx)
 	if err != nil {
 		reporter.Failed(err)
 		return errors.Wrap(err, "error listing the gateway nodes")
@@ -45,20 +58,17 @@ func (g *gatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Repo
 
 	gatewayNodesToDeploy := input.Gateways - len(gwNodes.Items)
 
-	if gatewayNodesToDeploy == 0 {
+	// The default policy keeps whatever gateways already exist.
+	if gatewayNodesToDeploy == 0 || (input.Gateways == 0 && gatewayNodesToDeploy < 0) {
 		reporter.Succeeded("Current gateways match the desired number of gateways")
 		return nil
 	}
 
-	// Currently, we only support increasing the number of Gateway nodes which could be a valid use-case
-	// to convert a non-HA deployment to an HA deployment. We are not supporting decreasing the Gateway
-	// nodes (for now) as it might impact the datapath if we accidentally delete the active GW node.
 	if gatewayNodesToDeploy < 0 {
-		reporter.Failed(fmt.Errorf("decreasing the number of Gateway nodes is not currently supported"))
-		return nil
+		return g.removeSurplusGateways(ctx, gwNodes, input.Gateways, reporter)
 	}
 
-	nonGWNodes, err := g.k8sClient.ListNodesWithLabel("!submariner.io/gateway")
+	nonGWNodes, err := g.k8sClient.ListNodesWithLabelWithContext(ctx, "!submariner.io/gateway")
 	if err != nil {
 		reporter.Failed(err)
 		return errors.Wrap(err, "error listing the gateway nodes")
@@ -71,12 +81,18 @@ func (g *gatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Repo
 			continue
 		}
 
-		err = g.k8sClient.AddGWLabelOnNode(node.Name)
+		err = g.k8sClient.AddGWLabelOnNodeWithContext(ctx, node.Name)
 		if err != nil {
 			reporter.Failed(err)
 			return errors.Wrapf(err, "error adding the gateway label on node %q", node.Name)
 		}
 
+		err = api.RecordResources(ctx, g.stateStore, nodeLabelResource(node.Name))
+		if err != nil {
+			reporter.Failed(err)
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+
 		gatewayNodesToDeploy--
 
 		if gatewayNodesToDeploy <= 0 {
@@ -92,8 +108,106 @@ func (g *gatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Repo
 	return err
 }
 
+// Plan returns the nodes Deploy would label as gateways, or unlabel when scaling the gateways down.
+func (g *gatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	gwNodes, err := g.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	actions := []api.Action{}
+
+	gatewayNodesToDeploy := input.Gateways - len(gwNodes.Items)
+	if gatewayNodesToDeploy < 0 && input.Gateways > 0 {
+		for _, node := range k8s.SurplusGatewayNodes(gwNodes, input.Gateways) {
+			actions = append(actions, api.Action{Kind: api.ActionUnlabelNode, Resource: node.Name})
+		}
+	}
+
+	if gatewayNodesToDeploy <= 0 {
+		return actions, nil
+	}
+
+	nonGWNodes, err := g.k8sClient.ListNodesWithLabelWithContext(ctx, "!submariner.io/gateway")
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	for i := range nonGWNodes.Items {
+		node := &nonGWNodes.Items[i]
+		if isMasterNode(node) {
+			continue
+		}
+
+		actions = append(actions, api.Action{Kind: api.ActionLabelNode, Resource: node.Name})
+
+		gatewayNodesToDeploy--
+		if gatewayNodesToDeploy <= 0 {
+			return actions, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there are an insufficient number of worker nodes (%d) to satisfy the desired number of gateways (%d)",
+		len(nonGWNodes.Items), input.Gateways)
+}
+
+// Inspect returns the nodes labeled as gateways; the generic deployer doesn't manage ports or machine sets.
+func (g *gatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	gwNodes, err := g.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	return &api.GatewayStatus{
+		PublicPorts:        []api.PortSpec{},
+		GatewaySubnets:     []string{},
+		GatewayNodes:       k8s.NodeNames(gwNodes),
+		GatewayMachineSets: []string{},
+	}, nil
+}
+
+// removeSurplusGateways unlabels gateway nodes until only the desired number remain, keeping the oldest nodes
+// spread across zones.
+func (g *gatewayDeployer) removeSurplusGateways(ctx context.Context, gwNodes *v1.NodeList, desired int, reporter api.Reporter) error {
+	for _, gateway := range k8s.SurplusGatewayNodes(gwNodes, desired) {
+		reporter.Started("Removing the gateway label from node %q", gateway.Name)
+
+		err := g.k8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, gateway)
+		if err != nil {
+			err = errors.Wrapf(err, "error removing the gateway label from node %q", gateway.Name)
+			reporter.Failed(err)
+
+			return err
+		}
+
+		err = api.ForgetResources(ctx, g.stateStore, nodeLabelResource(gateway.Name))
+		if err != nil {
+			reporter.Failed(err)
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+
+		reporter.Succeeded("Removed the gateway label from node %q", gateway.Name)
+	}
+
+	return nil
+}
+
 func (g *gatewayDeployer) Cleanup(reporter api.Reporter) error {
-	err := g.k8sClient.RemoveGWLabelFromWorkerNodes()
+	return g.CleanupWithContext(context.Background(), reporter)
+}
+
+func (g *gatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
+	recorded, err := api.RecordedResources(ctx, g.stateStore, api.ResourceNodeLabel)
+	if err != nil {
+		reporter.Failed(err)
+		return err // nolint:wrapcheck // Already wrapped.
+	}
+
+	if len(recorded) > 0 {
+		return g.unlabelRecordedNodes(ctx, recorded, reporter)
+	}
+
+	err = g.k8sClient.RemoveGWLabelFromWorkerNodesWithContext(ctx)
 	if err != nil {
 		reporter.Failed(err)
 		return errors.Wrap(err, "error removing the gateway label from all worker nodes")
@@ -104,6 +218,35 @@ func (g *gatewayDeployer) Cleanup(reporter api.Reporter) error {
 	return nil
 }
 
+// unlabelRecordedNodes removes the gateway label from the recorded nodes, ignoring the nodes which no longer exist.
+func (g *gatewayDeployer) unlabelRecordedNodes(ctx context.Context, recorded []api.Resource, reporter api.Reporter) error {
+	for i := range recorded {
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: recorded[i].ID}}
+
+		err := g.k8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, node)
+		if err != nil && !apierrors.IsNotFound(err) {
+			err = errors.Wrapf(err, "error removing the gateway label from node %q", node.Name)
+			reporter.Failed(err)
+
+			return err
+		}
+
+		err = api.ForgetResources(ctx, g.stateStore, recorded[i])
+		if err != nil {
+			reporter.Failed(err)
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	reporter.Succeeded("Successfully removed Submariner gateway label from the recorded worker nodes")
+
+	return nil
+}
+
+func nodeLabelResource(nodeName string) api.Resource {
+	return api.Resource{Type: api.ResourceNodeLabel, ID: nodeName}
+}
+
 func isMasterNode(node *v1.Node) bool {
 	for _, taint := range node.Spec.Taints {
 		if taint.Key == "node-role.kubernetes.io/master" && taint.Effect == v1.TaintEffectNoSchedule {
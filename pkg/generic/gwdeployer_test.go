@@ -20,6 +20,7 @@ package generic_test
 import (
 	"context"
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -155,16 +156,93 @@ var _ = Describe("GatewayDeployer", func() {
 			t.nodes = []*corev1.Node{
 				newNonMasterNode("node-1"),
 				newNonMasterNode("node-2"),
+				newNonMasterNode("node-3"),
 			}
 
+			t.nodes[0].CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+			t.nodes[1].CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			t.nodes[0].Labels["topology.kubernetes.io/zone"] = "zone-a"
+			t.nodes[1].Labels["topology.kubernetes.io/zone"] = "zone-a"
+			t.nodes[2].Labels["topology.kubernetes.io/zone"] = "zone-b"
+
 			setGWLabel(t.nodes[0])
 			setGWLabel(t.nodes[1])
-			t.numGateways = 1
+			setGWLabel(t.nodes[2])
+			t.numGateways = 2
 		})
 
-		It("should not unlabel the subtracted gateway nodes", func() {
+		It("should unlabel the surplus gateway nodes, keeping the oldest spread across zones", func() {
 			Expect(t.doDeploy()).To(Succeed())
 			t.awaitLabeledNodes(2)
+			Expect(t.getLabeledWorkerNodes()[0].Name).To(Equal("node-2"))
+			Expect(t.getLabeledWorkerNodes()[1].Name).To(Equal("node-3"))
+		})
+
+		It("should plan to unlabel the surplus gateway nodes", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), api.GatewayDeployInput{Gateways: t.numGateways})
+			Expect(err).To(Succeed())
+			Expect(actions).To(Equal([]api.Action{{Kind: api.ActionUnlabelNode, Resource: "node-1"}}))
+		})
+
+		Context("to the default", func() {
+			BeforeEach(func() {
+				t.numGateways = 0
+			})
+
+			It("should keep the existing gateway nodes", func() {
+				Expect(t.doDeploy()).To(Succeed())
+				t.awaitLabeledNodes(3)
+			})
+		})
+	})
+
+	Context("on Plan", func() {
+		BeforeEach(func() {
+			t.nodes = []*corev1.Node{
+				newMasterNode("master-node"),
+				newNonMasterNode("node-1"),
+				newNonMasterNode("node-2"),
+			}
+
+			setGWLabel(t.nodes[1])
+			t.numGateways = 2
+		})
+
+		It("should return the nodes to label without labeling them", func() {
+			actions, err := t.gwDeployer.Plan(context.TODO(), api.GatewayDeployInput{Gateways: t.numGateways})
+			Expect(err).To(Succeed())
+			Expect(actions).To(Equal([]api.Action{{Kind: api.ActionLabelNode, Resource: "node-2"}}))
+			t.awaitLabeledNodes(1)
+		})
+
+		When("there's an insufficient number of worker nodes", func() {
+			BeforeEach(func() {
+				t.numGateways = 3
+			})
+
+			It("should return an error", func() {
+				_, err := t.gwDeployer.Plan(context.TODO(), api.GatewayDeployInput{Gateways: t.numGateways})
+				Expect(err).ToNot(Succeed())
+			})
+		})
+	})
+
+	Context("on Inspect", func() {
+		BeforeEach(func() {
+			t.nodes = []*corev1.Node{
+				newMasterNode("master-node"),
+				newNonMasterNode("node-1"),
+				newNonMasterNode("node-2"),
+			}
+
+			setGWLabel(t.nodes[2])
+		})
+
+		It("should return the gateway nodes", func() {
+			status, err := t.gwDeployer.Inspect(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(status.GatewayNodes).To(Equal([]string{"node-2"}))
+			Expect(status.GatewayMachineSets).To(BeEmpty())
 		})
 	})
 
@@ -194,9 +272,40 @@ var _ = Describe("GatewayDeployer", func() {
 				Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).ToNot(Succeed())
 			})
 		})
+
+		When("the labeled nodes were recorded", func() {
+			JustBeforeEach(func() {
+				store := &memoryStateStore{}
+				t.gwDeployer.(api.StateRecorder).SetStateStore(store)
+
+				Expect(api.RecordResources(context.TODO(), store,
+					api.Resource{Type: api.ResourceNodeLabel, ID: "node-2"},
+					api.Resource{Type: api.ResourceNodeLabel, ID: "deleted-node"})).To(Succeed())
+			})
+
+			It("should only unlabel the recorded nodes", func() {
+				Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+				t.awaitLabeledNodes(1)
+				Expect(t.getLabeledWorkerNodes()[0].Name).To(Equal("node-1"))
+			})
+		})
 	})
 })
 
+type memoryStateStore struct {
+	state api.State
+}
+
+func (m *memoryStateStore) Load(_ context.Context) (*api.State, error) {
+	state := m.state
+	return &state, nil
+}
+
+func (m *memoryStateStore) Save(_ context.Context, state *api.State) error {
+	m.state = *state
+	return nil
+}
+
 type gatewayDeployerTestDriver struct {
 	numGateways int
 	kubeClient  *kubeFake.Clientset
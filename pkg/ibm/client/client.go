@@ -0,0 +1,272 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nolint:wrapcheck // The functions are wrappers so let the caller wrap errors.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+)
+
+//go:generate mockgen -source=./client.go -destination=./fake/client.go -package=fake
+
+// Interface wraps an actual IBM Cloud VPC library client to allow for easier testing.
+type Interface interface {
+	GetSecurityGroup(ctx context.Context, vpcName, groupName string) (*vpcv1.SecurityGroup, error)
+	CreateSecurityGroup(ctx context.Context, vpcID, resourceGroupID, groupName string) (*vpcv1.SecurityGroup, error)
+	DeleteSecurityGroup(ctx context.Context, groupID string) error
+	CreateSecurityGroupRule(ctx context.Context, groupID string, rule vpcv1.SecurityGroupRulePrototypeIntf) (string, error)
+	DeleteSecurityGroupRule(ctx context.Context, groupID, ruleID string) error
+	ListInstances(ctx context.Context, vpcName string) ([]vpcv1.Instance, error)
+	GetFloatingIP(ctx context.Context, name string) (*vpcv1.FloatingIP, error)
+	CreateFloatingIP(ctx context.Context, name, resourceGroupID, networkInterfaceID string) (*vpcv1.FloatingIP, error)
+	UpdateFloatingIPTarget(ctx context.Context, floatingIPID, networkInterfaceID string) error
+	DeleteFloatingIP(ctx context.Context, floatingIPID string) error
+}
+
+type ibmClient struct {
+	vpcService *vpcv1.VpcV1
+}
+
+// NewClient returns a client for the VPC API of the given region, authenticated with an IBM Cloud API key.
+func NewClient(apiKey, region string) (Interface, error) {
+	vpcService, err := vpcv1.NewVpcV1(&vpcv1.VpcV1Options{
+		URL:           fmt.Sprintf("https://%s.iaas.cloud.ibm.com/v1", region),
+		Authenticator: &core.IamAuthenticator{ApiKey: apiKey},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ibmClient{vpcService: vpcService}, nil
+}
+
+func (c *ibmClient) GetSecurityGroup(ctx context.Context, vpcName, groupName string) (*vpcv1.SecurityGroup, error) {
+	options := &vpcv1.ListSecurityGroupsOptions{VPCName: &vpcName}
+
+	for {
+		result, response, err := c.vpcService.ListSecurityGroupsWithContext(ctx, options)
+		if err != nil {
+			return nil, responseError(response, err)
+		}
+
+		for i := range result.SecurityGroups {
+			if core.StringNilMapper(result.SecurityGroups[i].Name) == groupName {
+				return &result.SecurityGroups[i], nil
+			}
+		}
+
+		if result.Next == nil || result.Next.Href == nil {
+			return nil, NewNotFoundError("security group %q", groupName)
+		}
+
+		options.Start, err = core.GetQueryParam(result.Next.Href, "start")
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *ibmClient) CreateSecurityGroup(ctx context.Context, vpcID, resourceGroupID, groupName string) (*vpcv1.SecurityGroup, error) {
+	group, response, err := c.vpcService.CreateSecurityGroupWithContext(ctx, &vpcv1.CreateSecurityGroupOptions{
+		VPC:           &vpcv1.VPCIdentityByID{ID: &vpcID},
+		ResourceGroup: &vpcv1.ResourceGroupIdentityByID{ID: &resourceGroupID},
+		Name:          &groupName,
+	})
+
+	return group, responseError(response, err)
+}
+
+func (c *ibmClient) DeleteSecurityGroup(ctx context.Context, groupID string) error {
+	response, err := c.vpcService.DeleteSecurityGroupWithContext(ctx, &vpcv1.DeleteSecurityGroupOptions{ID: &groupID})
+	return responseError(response, err)
+}
+
+func (c *ibmClient) CreateSecurityGroupRule(ctx context.Context, groupID string,
+	rule vpcv1.SecurityGroupRulePrototypeIntf) (string, error) {
+	result, response, err := c.vpcService.CreateSecurityGroupRuleWithContext(ctx, &vpcv1.CreateSecurityGroupRuleOptions{
+		SecurityGroupID:            &groupID,
+		SecurityGroupRulePrototype: rule,
+	})
+	if err != nil {
+		return "", responseError(response, err)
+	}
+
+	if created, ok := result.(*vpcv1.SecurityGroupRule); ok {
+		return core.StringNilMapper(created.ID), nil
+	}
+
+	if created, ok := result.(*vpcv1.SecurityGroupRuleSecurityGroupRuleProtocolTcpudp); ok {
+		return core.StringNilMapper(created.ID), nil
+	}
+
+	return "", nil
+}
+
+func (c *ibmClient) DeleteSecurityGroupRule(ctx context.Context, groupID, ruleID string) error {
+	response, err := c.vpcService.DeleteSecurityGroupRuleWithContext(ctx, &vpcv1.DeleteSecurityGroupRuleOptions{
+		SecurityGroupID: &groupID,
+		ID:              &ruleID,
+	})
+
+	return responseError(response, err)
+}
+
+func (c *ibmClient) ListInstances(ctx context.Context, vpcName string) ([]vpcv1.Instance, error) {
+	instances := []vpcv1.Instance{}
+	options := &vpcv1.ListInstancesOptions{VPCName: &vpcName}
+
+	for {
+		result, response, err := c.vpcService.ListInstancesWithContext(ctx, options)
+		if err != nil {
+			return nil, responseError(response, err)
+		}
+
+		instances = append(instances, result.Instances...)
+
+		if result.Next == nil || result.Next.Href == nil {
+			return instances, nil
+		}
+
+		options.Start, err = core.GetQueryParam(result.Next.Href, "start")
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *ibmClient) GetFloatingIP(ctx context.Context, name string) (*vpcv1.FloatingIP, error) {
+	options := &vpcv1.ListFloatingIpsOptions{}
+
+	for {
+		result, response, err := c.vpcService.ListFloatingIpsWithContext(ctx, options)
+		if err != nil {
+			return nil, responseError(response, err)
+		}
+
+		for i := range result.FloatingIps {
+			if core.StringNilMapper(result.FloatingIps[i].Name) == name {
+				return &result.FloatingIps[i], nil
+			}
+		}
+
+		if result.Next == nil || result.Next.Href == nil {
+			return nil, NewNotFoundError("floating IP %q", name)
+		}
+
+		options.Start, err = core.GetQueryParam(result.Next.Href, "start")
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *ibmClient) CreateFloatingIP(ctx context.Context, name, resourceGroupID, networkInterfaceID string) (*vpcv1.FloatingIP, error) {
+	floatingIP, response, err := c.vpcService.CreateFloatingIPWithContext(ctx, &vpcv1.CreateFloatingIPOptions{
+		FloatingIPPrototype: &vpcv1.FloatingIPPrototypeFloatingIPByTarget{
+			Name:          &name,
+			ResourceGroup: &vpcv1.ResourceGroupIdentityByID{ID: &resourceGroupID},
+			Target:        &vpcv1.FloatingIPByTargetNetworkInterfaceIdentityNetworkInterfaceIdentityByID{ID: &networkInterfaceID},
+		},
+	})
+
+	return floatingIP, responseError(response, err)
+}
+
+func (c *ibmClient) UpdateFloatingIPTarget(ctx context.Context, floatingIPID, networkInterfaceID string) error {
+	patch, err := (&vpcv1.FloatingIPPatch{
+		Target: &vpcv1.FloatingIPTargetPatchNetworkInterfaceIdentityByID{ID: &networkInterfaceID},
+	}).AsPatch()
+	if err != nil {
+		return err
+	}
+
+	_, response, err := c.vpcService.UpdateFloatingIPWithContext(ctx, &vpcv1.UpdateFloatingIPOptions{
+		ID:              &floatingIPID,
+		FloatingIPPatch: patch,
+	})
+
+	return responseError(response, err)
+}
+
+func (c *ibmClient) DeleteFloatingIP(ctx context.Context, floatingIPID string) error {
+	response, err := c.vpcService.DeleteFloatingIPWithContext(ctx, &vpcv1.DeleteFloatingIPOptions{ID: &floatingIPID})
+	return responseError(response, err)
+}
+
+type notFoundError struct {
+	s string
+}
+
+func (e *notFoundError) Error() string {
+	return e.s + " not found"
+}
+
+// NewNotFoundError returns an error meaning that the described IBM Cloud resource doesn't exist.
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &notFoundError{fmt.Sprintf(format, args...)}
+}
+
+// statusError is the error of a response, along with the response's HTTP status.
+type statusError struct {
+	statusCode int
+	err        error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func responseError(response *core.DetailedResponse, err error) error {
+	if err != nil && response != nil {
+		return &statusError{statusCode: response.StatusCode, err: err}
+	}
+
+	return err
+}
+
+// IsNotFoundError returns whether the given error means that the requested IBM Cloud resource doesn't exist.
+func IsNotFoundError(err error) bool {
+	var e *notFoundError
+	if errors.As(err, &e) {
+		return true
+	}
+
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflictError returns whether the given error means that the IBM Cloud resource is still in use, e.g. a security
+// group still attached to instances.
+func IsConflictError(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var e *statusError
+	return errors.As(err, &e) && e.statusCode == statusCode
+}
@@ -0,0 +1,199 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./client.go
+
+// Package fake is a generated GoMock package.
+package fake
+
+import (
+	context "context"
+	reflect "reflect"
+
+	vpcv1 "github.com/IBM/vpc-go-sdk/vpcv1"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateFloatingIP mocks base method.
+func (m *MockInterface) CreateFloatingIP(ctx context.Context, name, resourceGroupID, networkInterfaceID string) (*vpcv1.FloatingIP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFloatingIP", ctx, name, resourceGroupID, networkInterfaceID)
+	ret0, _ := ret[0].(*vpcv1.FloatingIP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFloatingIP indicates an expected call of CreateFloatingIP.
+func (mr *MockInterfaceMockRecorder) CreateFloatingIP(ctx, name, resourceGroupID, networkInterfaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFloatingIP", reflect.TypeOf((*MockInterface)(nil).CreateFloatingIP), ctx, name, resourceGroupID, networkInterfaceID)
+}
+
+// CreateSecurityGroup mocks base method.
+func (m *MockInterface) CreateSecurityGroup(ctx context.Context, vpcID, resourceGroupID, groupName string) (*vpcv1.SecurityGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecurityGroup", ctx, vpcID, resourceGroupID, groupName)
+	ret0, _ := ret[0].(*vpcv1.SecurityGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecurityGroup indicates an expected call of CreateSecurityGroup.
+func (mr *MockInterfaceMockRecorder) CreateSecurityGroup(ctx, vpcID, resourceGroupID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecurityGroup", reflect.TypeOf((*MockInterface)(nil).CreateSecurityGroup), ctx, vpcID, resourceGroupID, groupName)
+}
+
+// CreateSecurityGroupRule mocks base method.
+func (m *MockInterface) CreateSecurityGroupRule(ctx context.Context, groupID string, rule vpcv1.SecurityGroupRulePrototypeIntf) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecurityGroupRule", ctx, groupID, rule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecurityGroupRule indicates an expected call of CreateSecurityGroupRule.
+func (mr *MockInterfaceMockRecorder) CreateSecurityGroupRule(ctx, groupID, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecurityGroupRule", reflect.TypeOf((*MockInterface)(nil).CreateSecurityGroupRule), ctx, groupID, rule)
+}
+
+// DeleteFloatingIP mocks base method.
+func (m *MockInterface) DeleteFloatingIP(ctx context.Context, floatingIPID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFloatingIP", ctx, floatingIPID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFloatingIP indicates an expected call of DeleteFloatingIP.
+func (mr *MockInterfaceMockRecorder) DeleteFloatingIP(ctx, floatingIPID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFloatingIP", reflect.TypeOf((*MockInterface)(nil).DeleteFloatingIP), ctx, floatingIPID)
+}
+
+// DeleteSecurityGroup mocks base method.
+func (m *MockInterface) DeleteSecurityGroup(ctx context.Context, groupID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecurityGroup", ctx, groupID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecurityGroup indicates an expected call of DeleteSecurityGroup.
+func (mr *MockInterfaceMockRecorder) DeleteSecurityGroup(ctx, groupID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecurityGroup", reflect.TypeOf((*MockInterface)(nil).DeleteSecurityGroup), ctx, groupID)
+}
+
+// DeleteSecurityGroupRule mocks base method.
+func (m *MockInterface) DeleteSecurityGroupRule(ctx context.Context, groupID, ruleID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecurityGroupRule", ctx, groupID, ruleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecurityGroupRule indicates an expected call of DeleteSecurityGroupRule.
+func (mr *MockInterfaceMockRecorder) DeleteSecurityGroupRule(ctx, groupID, ruleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecurityGroupRule", reflect.TypeOf((*MockInterface)(nil).DeleteSecurityGroupRule), ctx, groupID, ruleID)
+}
+
+// GetFloatingIP mocks base method.
+func (m *MockInterface) GetFloatingIP(ctx context.Context, name string) (*vpcv1.FloatingIP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFloatingIP", ctx, name)
+	ret0, _ := ret[0].(*vpcv1.FloatingIP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFloatingIP indicates an expected call of GetFloatingIP.
+func (mr *MockInterfaceMockRecorder) GetFloatingIP(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFloatingIP", reflect.TypeOf((*MockInterface)(nil).GetFloatingIP), ctx, name)
+}
+
+// GetSecurityGroup mocks base method.
+func (m *MockInterface) GetSecurityGroup(ctx context.Context, vpcName, groupName string) (*vpcv1.SecurityGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecurityGroup", ctx, vpcName, groupName)
+	ret0, _ := ret[0].(*vpcv1.SecurityGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecurityGroup indicates an expected call of GetSecurityGroup.
+func (mr *MockInterfaceMockRecorder) GetSecurityGroup(ctx, vpcName, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecurityGroup", reflect.TypeOf((*MockInterface)(nil).GetSecurityGroup), ctx, vpcName, groupName)
+}
+
+// ListInstances mocks base method.
+func (m *MockInterface) ListInstances(ctx context.Context, vpcName string) ([]vpcv1.Instance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInstances", ctx, vpcName)
+	ret0, _ := ret[0].([]vpcv1.Instance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInstances indicates an expected call of ListInstances.
+func (mr *MockInterfaceMockRecorder) ListInstances(ctx, vpcName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInstances", reflect.TypeOf((*MockInterface)(nil).ListInstances), ctx, vpcName)
+}
+
+// UpdateFloatingIPTarget mocks base method.
+func (m *MockInterface) UpdateFloatingIPTarget(ctx context.Context, floatingIPID, networkInterfaceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFloatingIPTarget", ctx, floatingIPID, networkInterfaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFloatingIPTarget indicates an expected call of UpdateFloatingIPTarget.
+func (mr *MockInterfaceMockRecorder) UpdateFloatingIPTarget(ctx, floatingIPID, networkInterfaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFloatingIPTarget", reflect.TypeOf((*MockInterface)(nil).UpdateFloatingIPTarget), ctx, floatingIPID, networkInterfaceID)
+}
@@ -0,0 +1,176 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm
+
+import (
+	"context"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	ibmclient "github.com/submariner-io/cloud-prepare/pkg/ibm/client"
+)
+
+// CloudInfo identifies the cluster's resources on IBM Cloud VPC.
+type CloudInfo struct {
+	InfraID string
+	Region  string
+
+	// VPCName is the name of the cluster's VPC, "{infraID}-vpc" as created by the OpenShift installer by default.
+	VPCName string
+
+	// WorkerSecurityGroup is the name of the security group of the worker nodes, "{infraID}-sg-cluster-wide" as created
+	// by the OpenShift installer by default. On ROKS, it is the "kube-{clusterID}" security group.
+	WorkerSecurityGroup string
+
+	Client     ibmclient.Interface
+	StateStore api.StateStore
+}
+
+// SetStateStore sets the store recording the resources provisioned on IBM Cloud, which are then cleaned up exactly.
+func (c *CloudInfo) SetStateStore(store api.StateStore) {
+	c.StateStore = store
+}
+
+func (c *CloudInfo) vpcName() string {
+	if c.VPCName != "" {
+		return c.VPCName
+	}
+
+	return c.InfraID + "-vpc"
+}
+
+func (c *CloudInfo) workerSecurityGroupName() string {
+	if c.WorkerSecurityGroup != "" {
+		return c.WorkerSecurityGroup
+	}
+
+	return c.InfraID + "-sg-cluster-wide"
+}
+
+func (c *CloudInfo) getSecurityGroup(ctx context.Context, groupName string) (*vpcv1.SecurityGroup, error) {
+	group, err := c.Client.GetSecurityGroup(ctx, c.vpcName(), groupName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving security group %q", groupName)
+	}
+
+	return group, nil
+}
+
+// missingPorts returns the ports which aren't allowed yet from the given remote by the security group.
+func missingPorts(group *vpcv1.SecurityGroup, remote string, ports []api.PortSpec) ([]api.PortSpec, error) {
+	rules := inboundRules(group)
+	missing := []api.PortSpec{}
+
+	for _, port := range ports {
+		if _, err := ruleProtocol(port); err != nil {
+			return nil, err
+		}
+
+		if findRule(rules, port, remote) == nil {
+			missing = append(missing, port)
+		}
+	}
+
+	return missing, nil
+}
+
+// openPorts creates a rule for each of the ports which aren't allowed yet from the given remote security group ID or
+// CIDR block by the security group. Rules which already exist are left alone.
+func (c *CloudInfo) openPorts(ctx context.Context, group *vpcv1.SecurityGroup, remote string, ports []api.PortSpec) error {
+	groupID := core.StringNilMapper(group.ID)
+
+	missing, err := missingPorts(group, remote, ports)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range missing {
+		rule, err := newRulePrototype(port, remote)
+		if err != nil {
+			return err
+		}
+
+		ruleID, err := c.Client.CreateSecurityGroupRule(ctx, groupID, rule)
+		if err != nil {
+			return errors.Wrapf(err, "error creating a rule for port %d/%s in security group %q", port.Port, port.Protocol,
+				core.StringNilMapper(group.Name))
+		}
+
+		if err := api.RecordResources(ctx, c.StateStore, ingressRuleResource(groupID, ruleID, port)); err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	return nil
+}
+
+// deleteRules deletes the recorded rules of the security group or, if none were recorded, the single port rules
+// allowing traffic from the given remote. Such rules aren't created by the OpenShift installer.
+func (c *CloudInfo) deleteRules(ctx context.Context, group *vpcv1.SecurityGroup, remote string) error {
+	groupID := core.StringNilMapper(group.ID)
+
+	recorded, err := api.RecordedResources(ctx, c.StateStore, api.ResourceIngressRule)
+	if err != nil {
+		return err // nolint:wrapcheck // Already wrapped.
+	}
+
+	ruleIDs := []string{}
+
+	for i := range recorded {
+		if recorded[i].Parameters["group"] == groupID {
+			ruleIDs = append(ruleIDs, recorded[i].ID)
+		}
+	}
+
+	if len(ruleIDs) == 0 {
+		for _, rule := range inboundRules(group) {
+			if rule.remote == remote && rule.port.Port != 0 {
+				ruleIDs = append(ruleIDs, rule.id)
+			}
+		}
+	}
+
+	for _, ruleID := range ruleIDs {
+		err := c.Client.DeleteSecurityGroupRule(ctx, groupID, ruleID)
+		if err != nil && !ibmclient.IsNotFoundError(err) {
+			return errors.Wrapf(err, "error deleting rule %q of security group %q", ruleID, core.StringNilMapper(group.Name))
+		}
+
+		if err := api.ForgetResources(ctx, c.StateStore, api.Resource{Type: api.ResourceIngressRule, ID: ruleID}); err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	return nil
+}
+
+// rulePorts returns the ports allowed from the given remote by the security group.
+func rulePorts(group *vpcv1.SecurityGroup, remote string) []api.PortSpec {
+	ports := []api.PortSpec{}
+
+	for _, rule := range inboundRules(group) {
+		if rule.remote == remote {
+			ports = append(ports, rule.port)
+		}
+	}
+
+	return ports
+}
@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm
+
+import (
+	"context"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	ibmclient "github.com/submariner-io/cloud-prepare/pkg/ibm/client"
+)
+
+const providerName = "ibm"
+
+type ibmCloud struct {
+	CloudInfo
+}
+
+// NewCloud creates a new api.Cloud instance which can prepare IBM Cloud VPC for Submariner to be deployed on it.
+func NewCloud(info CloudInfo) api.Cloud {
+	return &ibmCloud{CloudInfo: info}
+}
+
+// PrepareForSubmariner prepares submariner cluster environment on IBM Cloud.
+func (ic *ibmCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
+	return ic.PrepareForSubmarinerWithContext(context.Background(), input, reporter)
+}
+
+func (ic *ibmCloud) PrepareForSubmarinerWithContext(ctx context.Context, input api.PrepareForSubmarinerInput,
+	reporter api.Reporter) error {
+	reporter.Started("Opening internal ports %q for intra-cluster communications on IBM Cloud", formatPorts(input.InternalPorts))
+
+	group, err := ic.getSecurityGroup(ctx, ic.workerSecurityGroupName())
+	if err == nil {
+		// Internal traffic is only allowed from the worker nodes themselves.
+		err = ic.openPorts(ctx, group, core.StringNilMapper(group.ID), input.InternalPorts)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Opened internal ports %q in security group %q on IBM Cloud",
+		formatPorts(input.InternalPorts), ic.workerSecurityGroupName())
+
+	return nil
+}
+
+// CleanupAfterSubmariner clean up submariner cluster environment on IBM Cloud.
+func (ic *ibmCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
+	return ic.CleanupAfterSubmarinerWithContext(context.Background(), reporter)
+}
+
+func (ic *ibmCloud) CleanupAfterSubmarinerWithContext(ctx context.Context, reporter api.Reporter) error {
+	reporter.Started("Revoking intra-cluster communication permissions on IBM Cloud")
+
+	group, err := ic.Client.GetSecurityGroup(ctx, ic.vpcName(), ic.workerSecurityGroupName())
+	if ibmclient.IsNotFoundError(err) {
+		reporter.Succeeded("Security group %q doesn't exist", ic.workerSecurityGroupName())
+		return nil
+	}
+
+	if err == nil {
+		err = ic.deleteRules(ctx, group, core.StringNilMapper(group.ID))
+	} else {
+		err = errors.Wrapf(err, "error retrieving security group %q", ic.workerSecurityGroupName())
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Revoked intra-cluster communication permissions on IBM Cloud")
+
+	return nil
+}
+
+// CreateVpcPeering isn't supported on IBM Cloud yet.
+func (ic *ibmCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return ic.CreateVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (ic *ibmCloud) CreateVpcPeeringWithContext(_ context.Context, _ api.Cloud, reporter api.Reporter) error {
+	err := errors.New("VPC peering isn't supported on IBM Cloud")
+	reporter.Failed(err)
+
+	return err
+}
+
+// CleanupVpcPeering isn't supported on IBM Cloud yet.
+func (ic *ibmCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return ic.CleanupVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (ic *ibmCloud) CleanupVpcPeeringWithContext(_ context.Context, _ api.Cloud, reporter api.Reporter) error {
+	err := errors.New("VPC peering isn't supported on IBM Cloud")
+	reporter.Failed(err)
+
+	return err
+}
@@ -0,0 +1,219 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/ibm"
+)
+
+var internalPorts = []api.PortSpec{
+	{Port: 4800, Protocol: "udp"},
+	{Port: 8080, Protocol: "TCP"},
+}
+
+var _ = Describe("Cloud", func() {
+	Describe("PrepareForSubmariner", testPrepareForSubmariner)
+	Describe("CleanupAfterSubmariner", testCleanupAfterSubmariner)
+	Describe("Plan", testPlan)
+	Describe("Inspect", testInspect)
+})
+
+func testPrepareForSubmariner() {
+	t := newCloudTestDriver()
+
+	var (
+		ports    []api.PortSpec
+		retError error
+	)
+
+	BeforeEach(func() {
+		ports = internalPorts
+	})
+
+	JustBeforeEach(func() {
+		retError = t.cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{InternalPorts: ports}, api.NewLoggingReporter())
+	})
+
+	When("the rules don't exist", func() {
+		It("should add a rule per port from the worker security group", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.groupPorts(workerGroupName, workerGroupID)).To(Equal([]string{"4800/udp", "8080/tcp"}))
+		})
+	})
+
+	When("a rule already exists", func() {
+		BeforeEach(func() {
+			t.addRule(workerGroupName, directionInbound, protocolUDP, 4800, fromGroup(workerGroupID))
+		})
+
+		It("should only add the missing rule", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.groupPorts(workerGroupName, workerGroupID)).To(Equal([]string{"4800/udp", "8080/tcp"}))
+		})
+	})
+
+	When("a port uses an unsupported protocol", func() {
+		BeforeEach(func() {
+			ports = append([]api.PortSpec{}, internalPorts...)
+			ports = append(ports, api.PortSpec{Protocol: "esp"})
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+			Expect(t.groupPorts(workerGroupName, workerGroupID)).To(BeEmpty())
+		})
+	})
+
+	When("retrieval of the worker security group fails", func() {
+		BeforeEach(func() {
+			t.getError = errors.New("fake Get error")
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testCleanupAfterSubmariner() {
+	t := newCloudTestDriver()
+
+	BeforeEach(func() {
+		t.addRule(workerGroupName, directionInbound, protocolUDP, 4800, fromGroup(workerGroupID))
+		t.addRule(workerGroupName, directionInbound, protocolTCP, 22, fromCIDR("10.0.0.0/8"))
+	})
+
+	It("should delete the rules from the worker security group", func() {
+		Expect(t.cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+		Expect(t.groupPorts(workerGroupName, workerGroupID)).To(BeEmpty())
+		Expect(t.groupPorts(workerGroupName, "10.0.0.0/8")).To(Equal([]string{"22/tcp"}))
+	})
+
+	When("the rules were recorded", func() {
+		var store *memoryStateStore
+
+		BeforeEach(func() {
+			store = &memoryStateStore{}
+			t.cloud.(api.StateRecorder).SetStateStore(store)
+			Expect(t.cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{InternalPorts: internalPorts},
+				api.NewLoggingReporter())).To(Succeed())
+		})
+
+		It("should only delete the recorded rules", func() {
+			Expect(t.cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+			Expect(t.groupPorts(workerGroupName, workerGroupID)).To(Equal([]string{"4800/udp"}))
+			Expect(store.state.Resources).To(BeEmpty())
+		})
+	})
+
+	When("the worker security group doesn't exist", func() {
+		BeforeEach(func() {
+			delete(t.groups, workerGroupName)
+		})
+
+		It("should succeed", func() {
+			Expect(t.cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+		})
+	})
+}
+
+func testPlan() {
+	t := newCloudTestDriver()
+
+	BeforeEach(func() {
+		t.addRule(workerGroupName, directionInbound, protocolUDP, 4800, fromGroup(workerGroupID))
+		t.addRule(workerGroupName, directionOutbound, protocolTCP, 8080, fromGroup(workerGroupID))
+	})
+
+	It("should return the missing rules without adding them", func() {
+		actions, err := t.cloud.Plan(context.TODO(), api.PrepareForSubmarinerInput{InternalPorts: internalPorts})
+		Expect(err).To(Succeed())
+		Expect(actions).To(Equal([]api.Action{{
+			Kind:     api.ActionAuthorizeIngress,
+			Resource: workerGroupID,
+			Parameters: map[string]string{
+				"protocol":     "TCP",
+				"port":         "8080",
+				"source-group": workerGroupID,
+			},
+		}}))
+		Expect(t.groupPorts(workerGroupName, workerGroupID)).To(HaveLen(2))
+	})
+}
+
+func testInspect() {
+	t := newCloudTestDriver()
+
+	BeforeEach(func() {
+		t.addRule(workerGroupName, directionInbound, protocolUDP, 4800, fromGroup(workerGroupID))
+		t.addRule(workerGroupName, directionInbound, protocolTCP, 22, fromCIDR("10.0.0.0/8"))
+	})
+
+	It("should return the ports allowed from the worker security group", func() {
+		status, err := t.cloud.Inspect(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(status).To(Equal(&api.CloudStatus{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "udp"}},
+			Peerings:      []api.PeeringStatus{},
+		}))
+	})
+}
+
+type cloudTestDriver struct {
+	fakeIBMClientBase
+	cloud api.Cloud
+}
+
+func newCloudTestDriver() *cloudTestDriver {
+	t := &cloudTestDriver{}
+
+	BeforeEach(func() {
+		t.beforeEach()
+
+		t.cloud = ibm.NewCloud(ibm.CloudInfo{
+			InfraID: infraID,
+			Region:  region,
+			Client:  t.ibmClient,
+		})
+	})
+
+	AfterEach(t.afterEach)
+
+	return t
+}
+
+type memoryStateStore struct {
+	state api.State
+}
+
+func (m *memoryStateStore) Load(_ context.Context) (*api.State, error) {
+	state := m.state
+	return &state, nil
+}
+
+func (m *memoryStateStore) Save(_ context.Context, state *api.State) error {
+	m.state = *state
+	return nil
+}
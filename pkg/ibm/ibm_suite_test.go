@@ -0,0 +1,276 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ibmclient "github.com/submariner-io/cloud-prepare/pkg/ibm/client"
+	"github.com/submariner-io/cloud-prepare/pkg/ibm/client/fake"
+)
+
+const (
+	infraID           = "test-infraID"
+	region            = "us-east"
+	vpcName           = infraID + "-vpc"
+	workerGroupName   = infraID + "-sg-cluster-wide"
+	workerGroupID     = "worker-sg-id"
+	gatewayGroupName  = infraID + "-submariner-gw-sg"
+	resourceGroupID   = "resource-group-id"
+	vpcID             = "vpc-id"
+	allIPv4CIDR       = "0.0.0.0/0"
+	protocolTCP       = "tcp"
+	protocolUDP       = "udp"
+	directionInbound  = "inbound"
+	directionOutbound = "outbound"
+)
+
+func TestIBM(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "IBM Cloud Suite")
+}
+
+// fakeIBMClientBase fakes the security groups, instances and floating IPs of the cluster's VPC in memory.
+type fakeIBMClientBase struct {
+	ibmClient   *fake.MockInterface
+	mockCtrl    *gomock.Controller
+	groups      map[string]*vpcv1.SecurityGroup
+	instances   []vpcv1.Instance
+	floatingIPs map[string]*vpcv1.FloatingIP
+	nextID      int
+	getError    error
+}
+
+func (f *fakeIBMClientBase) beforeEach() {
+	f.mockCtrl = gomock.NewController(GinkgoT())
+	f.ibmClient = fake.NewMockInterface(f.mockCtrl)
+	f.groups = map[string]*vpcv1.SecurityGroup{}
+	f.instances = []vpcv1.Instance{}
+	f.floatingIPs = map[string]*vpcv1.FloatingIP{}
+	f.nextID = 0
+	f.getError = nil
+
+	f.addGroup(workerGroupName, workerGroupID)
+
+	f.ibmClient.EXPECT().GetSecurityGroup(gomock.Any(), vpcName, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, groupName string) (*vpcv1.SecurityGroup, error) {
+			if f.getError != nil {
+				return nil, f.getError
+			}
+
+			if group, found := f.groups[groupName]; found {
+				return group, nil
+			}
+
+			return nil, ibmclient.NewNotFoundError("security group %q", groupName)
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().CreateSecurityGroup(gomock.Any(), vpcID, resourceGroupID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, groupName string) (*vpcv1.SecurityGroup, error) {
+			return f.addGroup(groupName, f.newID("sg")), nil
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().DeleteSecurityGroup(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, groupID string) error {
+			delete(f.groups, f.groupByID(groupID))
+			return nil
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().CreateSecurityGroupRule(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, groupID string, intf vpcv1.SecurityGroupRulePrototypeIntf) (string, error) {
+			prototype, ok := intf.(*vpcv1.SecurityGroupRulePrototypeSecurityGroupRuleProtocolTcpudp)
+			Expect(ok).To(BeTrue())
+
+			remote := &vpcv1.SecurityGroupRuleRemote{}
+			switch r := prototype.Remote.(type) {
+			case *vpcv1.SecurityGroupRuleRemotePrototypeCIDR:
+				remote.CIDRBlock = r.CIDRBlock
+			case *vpcv1.SecurityGroupRuleRemotePrototypeSecurityGroupIdentitySecurityGroupIdentityByID:
+				remote.ID = r.ID
+			default:
+				Fail(fmt.Sprintf("unexpected remote %#v", prototype.Remote))
+			}
+
+			id := f.newID("rule")
+			group := f.groups[f.groupByID(groupID)]
+			group.Rules = append(group.Rules, &vpcv1.SecurityGroupRuleSecurityGroupRuleProtocolTcpudp{
+				ID:        &id,
+				Direction: prototype.Direction,
+				Protocol:  prototype.Protocol,
+				PortMin:   prototype.PortMin,
+				PortMax:   prototype.PortMax,
+				Remote:    remote,
+			})
+
+			return id, nil
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().DeleteSecurityGroupRule(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, groupID, ruleID string) error {
+			group := f.groups[f.groupByID(groupID)]
+			for i := range group.Rules {
+				if *group.Rules[i].(*vpcv1.SecurityGroupRuleSecurityGroupRuleProtocolTcpudp).ID == ruleID {
+					group.Rules = append(group.Rules[:i], group.Rules[i+1:]...)
+					return nil
+				}
+			}
+
+			return ibmclient.NewNotFoundError("rule %q", ruleID)
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().ListInstances(gomock.Any(), vpcName).DoAndReturn(
+		func(_ context.Context, _ string) ([]vpcv1.Instance, error) {
+			return f.instances, nil
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().GetFloatingIP(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, name string) (*vpcv1.FloatingIP, error) {
+			if floatingIP, found := f.floatingIPs[name]; found {
+				return floatingIP, nil
+			}
+
+			return nil, ibmclient.NewNotFoundError("floating IP %q", name)
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().CreateFloatingIP(gomock.Any(), gomock.Any(), resourceGroupID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, name, _, nicID string) (*vpcv1.FloatingIP, error) {
+			return f.addFloatingIP(name, nicID), nil
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().UpdateFloatingIPTarget(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, floatingIPID, nicID string) error {
+			for _, floatingIP := range f.floatingIPs {
+				if *floatingIP.ID == floatingIPID {
+					floatingIP.Target = &vpcv1.FloatingIPTarget{ID: &nicID}
+				}
+			}
+
+			return nil
+		}).AnyTimes()
+
+	f.ibmClient.EXPECT().DeleteFloatingIP(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, floatingIPID string) error {
+			for name, floatingIP := range f.floatingIPs {
+				if *floatingIP.ID == floatingIPID {
+					delete(f.floatingIPs, name)
+				}
+			}
+
+			return nil
+		}).AnyTimes()
+}
+
+func (f *fakeIBMClientBase) afterEach() {
+	f.mockCtrl.Finish()
+}
+
+func (f *fakeIBMClientBase) newID(prefix string) string {
+	f.nextID++
+	return fmt.Sprintf("%s-%d", prefix, f.nextID)
+}
+
+func (f *fakeIBMClientBase) addGroup(name, id string) *vpcv1.SecurityGroup {
+	group := &vpcv1.SecurityGroup{
+		ID:            &id,
+		Name:          &name,
+		VPC:           &vpcv1.VPCReference{ID: stringPtr(vpcID)},
+		ResourceGroup: &vpcv1.ResourceGroupReference{ID: stringPtr(resourceGroupID)},
+	}
+
+	f.groups[name] = group
+
+	return group
+}
+
+func (f *fakeIBMClientBase) groupByID(id string) string {
+	for name, group := range f.groups {
+		if *group.ID == id {
+			return name
+		}
+	}
+
+	Fail(fmt.Sprintf("security group %q not found", id))
+
+	return ""
+}
+
+// addRule adds an inbound rule to the security group, from the remote security group ID or CIDR block.
+func (f *fakeIBMClientBase) addRule(groupName, direction, protocol string, port int64, remote *vpcv1.SecurityGroupRuleRemote) {
+	group := f.groups[groupName]
+	group.Rules = append(group.Rules, &vpcv1.SecurityGroupRuleSecurityGroupRuleProtocolTcpudp{
+		ID:        stringPtr(f.newID("rule")),
+		Direction: &direction,
+		Protocol:  &protocol,
+		PortMin:   &port,
+		PortMax:   &port,
+		Remote:    remote,
+	})
+}
+
+func (f *fakeIBMClientBase) addFloatingIP(name, nicID string) *vpcv1.FloatingIP {
+	floatingIP := &vpcv1.FloatingIP{
+		ID:      stringPtr(f.newID("fip")),
+		Name:    &name,
+		Address: stringPtr(fmt.Sprintf("169.61.0.%d", f.nextID)),
+		Target:  &vpcv1.FloatingIPTarget{ID: &nicID},
+	}
+
+	f.floatingIPs[name] = floatingIP
+
+	return floatingIP
+}
+
+// groupPorts returns the "port/protocol" allowed by the rules of the security group from the given remote.
+func (f *fakeIBMClientBase) groupPorts(groupName, remote string) []string {
+	ports := []string{}
+
+	for _, intf := range f.groups[groupName].Rules {
+		rule := intf.(*vpcv1.SecurityGroupRuleSecurityGroupRuleProtocolTcpudp)
+		ruleRemote := rule.Remote.(*vpcv1.SecurityGroupRuleRemote)
+
+		if (ruleRemote.ID != nil && *ruleRemote.ID == remote) || (ruleRemote.CIDRBlock != nil && *ruleRemote.CIDRBlock == remote) {
+			port := int64(0)
+			if rule.PortMin != nil {
+				port = *rule.PortMin
+			}
+
+			ports = append(ports, fmt.Sprintf("%d/%s", port, *rule.Protocol))
+		}
+	}
+
+	return ports
+}
+
+func fromGroup(id string) *vpcv1.SecurityGroupRuleRemote {
+	return &vpcv1.SecurityGroupRuleRemote{ID: &id}
+}
+
+func fromCIDR(cidr string) *vpcv1.SecurityGroupRuleRemote {
+	return &vpcv1.SecurityGroupRuleRemote{CIDRBlock: &cidr}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,73 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm
+
+import (
+	"context"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	ibmclient "github.com/submariner-io/cloud-prepare/pkg/ibm/client"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+)
+
+// Inspect returns the ports the worker security group allows from the worker nodes on IBM Cloud. VPC peering isn't
+// supported, so there are no peerings.
+func (ic *ibmCloud) Inspect(ctx context.Context) (*api.CloudStatus, error) {
+	group, err := ic.getSecurityGroup(ctx, ic.workerSecurityGroupName())
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CloudStatus{
+		InternalPorts: rulePorts(group, core.StringNilMapper(group.ID)),
+		Peerings:      []api.PeeringStatus{},
+	}, nil
+}
+
+// Inspect returns the ports open in the gateway security group, the gateway nodes and their machine sets on IBM Cloud.
+func (d *ocpGatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	publicPorts := []api.PortSpec{}
+
+	group, err := d.Client.GetSecurityGroup(ctx, d.vpcName(), d.gatewaySecurityGroupName())
+	if err == nil {
+		publicPorts = rulePorts(group, allIPv4CIDR)
+	} else if !ibmclient.IsNotFoundError(err) {
+		return nil, errors.Wrapf(err, "error retrieving security group %q", d.gatewaySecurityGroupName())
+	}
+
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	machineSets, err := ocp.ListGatewayMachineSets(ctx, d.msDeployer, d.InfraID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	return &api.GatewayStatus{
+		PublicPorts:        publicPorts,
+		GatewaySubnets:     []string{},
+		GatewayNodes:       k8s.NodeNames(gwNodes),
+		GatewayMachineSets: machineSets,
+	}, nil
+}
@@ -0,0 +1,595 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	ibmclient "github.com/submariner-io/cloud-prepare/pkg/ibm/client"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	zoneLabel           = "topology.kubernetes.io/zone"
+	defaultInstanceType = "bx2-2x8"
+)
+
+var (
+	instancePollInterval = 10 * time.Second
+	instanceWaitTimeout  = 10 * time.Minute
+)
+
+type ocpGatewayDeployer struct {
+	ocp.MachineSetTemplate
+	CloudInfo
+	msDeployer   ocp.MachineSetDeployer
+	instanceType string
+	image        string
+	k8sClient    k8s.Interface
+}
+
+// NewOcpGatewayDeployer returns a GatewayDeployer capable of deploying gateways using OCP. Each gateway is a dedicated
+// node deployed by a machine set in its own zone, in the gateway security group, and reachable through a floating IP.
+// If no instance type is given, bx2-2x8 is used; if no image is given, the image created by the OpenShift installer is.
+func NewOcpGatewayDeployer(info CloudInfo, msDeployer ocp.MachineSetDeployer, instanceType, image string,
+	k8sClient k8s.Interface) api.GatewayDeployer {
+	if instanceType == "" {
+		instanceType = defaultInstanceType
+	}
+
+	if image == "" {
+		image = info.InfraID + "-rhcos"
+	}
+
+	return &ocpGatewayDeployer{
+		MachineSetTemplate: ocp.NewMachineSetTemplate(machineSetYAML),
+		CloudInfo:          info,
+		msDeployer:         msDeployer,
+		instanceType:       instanceType,
+		image:              image,
+		k8sClient:          k8sClient,
+	}
+}
+
+func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *ocpGatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	groupStep := d.gatewaySGStep()
+
+	api.StepStarted(reporter, groupStep, "Configuring the gateway security group %q", d.gatewaySecurityGroupName())
+
+	if input.RestrictsSources() {
+		err := errors.New("restricting the public ports to source CIDRs isn't supported on IBM Cloud")
+		api.StepFailed(reporter, groupStep, err)
+
+		return err
+	}
+
+	workerGroup, err := d.getSecurityGroup(ctx, d.workerSecurityGroupName())
+	if err != nil {
+		api.StepFailed(reporter, groupStep, err)
+		return err
+	}
+
+	gatewayGroup, err := d.createGatewaySG(ctx, workerGroup)
+	if err == nil {
+		err = d.openPorts(ctx, gatewayGroup, allIPv4CIDR, input.PublicPorts)
+	}
+
+	if err != nil {
+		api.StepFailed(reporter, groupStep, err)
+		return err
+	}
+
+	api.StepSucceeded(reporter, groupStep, "Opened external ports %q in security group %q on IBM Cloud",
+		formatPorts(input.PublicPorts), d.gatewaySecurityGroupName())
+
+	gateways, err := d.findGateways(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	gatewayNodesToDeploy := input.Gateways - len(gateways)
+
+	surplus := map[string]bool{}
+
+	if gatewayNodesToDeploy < 0 && input.Gateways > 0 {
+		for _, gateway := range k8s.SurplusGateways(gateways, input.Gateways) {
+			machineSetStep := api.ResourceStep(providerName, api.ResourceMachineSet, gateway.Name)
+
+			api.StepStarted(reporter, machineSetStep, "Removing the gateway machine set %q", gateway.Name)
+
+			if err := d.deleteGateway(ctx, gateway.Zone); err != nil {
+				api.StepFailed(reporter, machineSetStep, err)
+				return err
+			}
+
+			surplus[gateway.Name] = true
+
+			api.StepSucceeded(reporter, machineSetStep, "Removed the gateway machine set %q", gateway.Name)
+		}
+	}
+
+	zones := []string{}
+
+	for i := range gateways {
+		if !surplus[gateways[i].Name] {
+			zones = append(zones, gateways[i].Zone)
+		}
+	}
+
+	if gatewayNodesToDeploy > 0 {
+		newZones, err := d.deployGateways(ctx, gateways, gatewayNodesToDeploy, reporter)
+		if err != nil {
+			return err
+		}
+
+		zones = append(zones, newZones...)
+	}
+
+	resourceGroupID := ""
+	if workerGroup.ResourceGroup != nil {
+		resourceGroupID = core.StringNilMapper(workerGroup.ResourceGroup.ID)
+	}
+
+	// Replaced gateway instances get their floating IPs back.
+	for _, zone := range zones {
+		reporter.Started("Attaching a floating IP to the gateway in zone %q", zone)
+
+		address, err := d.attachFloatingIP(ctx, zone, resourceGroupID)
+		if err != nil {
+			reporter.Failed(err)
+			return err
+		}
+
+		reporter.Succeeded("Attached floating IP %s to the gateway in zone %q", address, zone)
+	}
+
+	return nil
+}
+
+// deployGateways deploys the given number of gateways in the zones without a gateway, and returns those zones.
+func (d *ocpGatewayDeployer) deployGateways(ctx context.Context, gateways []k8s.Gateway, count int, reporter api.Reporter,
+) ([]string, error) {
+	eligibleZones, err := d.eligibleZones(ctx, gateways)
+	if err != nil {
+		reporter.Failed(err)
+		return nil, err
+	}
+
+	// We deploy a single gateway node per zone. If the number of gateways is more than the number of zones, it's
+	// treated as an error.
+	if len(eligibleZones) < count {
+		err = fmt.Errorf("there are an insufficient number of zones (%d) to deploy the desired number of gateways (%d)",
+			len(eligibleZones)+len(gateways), len(gateways)+count)
+		reporter.Failed(err)
+
+		return nil, err
+	}
+
+	for _, zone := range eligibleZones[:count] {
+		machineSetStep := api.ResourceStep(providerName, api.ResourceMachineSet, d.machineSetName(zone))
+
+		api.StepStarted(reporter, machineSetStep, "Deploying dedicated gateway node in zone %q", zone)
+
+		if err := d.deployGateway(ctx, zone); err != nil {
+			api.StepFailed(reporter, machineSetStep, err)
+			return nil, err
+		}
+
+		api.StepSucceeded(reporter, machineSetStep, "Deployed dedicated gateway node in zone %q", zone)
+	}
+
+	return eligibleZones[:count], nil
+}
+
+func (d *ocpGatewayDeployer) gatewaySecurityGroupName() string {
+	return d.InfraID + "-submariner-gw-sg"
+}
+
+// gatewaySGStep returns the step operating on the gateway security group.
+func (d *ocpGatewayDeployer) gatewaySGStep() api.Step {
+	return api.ResourceStep(providerName, api.ResourceSecurityGroup, d.gatewaySecurityGroupName())
+}
+
+// createGatewaySG creates the gateway security group, in the VPC and resource group of the worker security group, if it
+// doesn't exist yet.
+func (d *ocpGatewayDeployer) createGatewaySG(ctx context.Context, workerGroup *vpcv1.SecurityGroup) (*vpcv1.SecurityGroup, error) {
+	groupName := d.gatewaySecurityGroupName()
+
+	group, err := d.Client.GetSecurityGroup(ctx, d.vpcName(), groupName)
+	if err == nil {
+		return group, nil
+	}
+
+	if !ibmclient.IsNotFoundError(err) {
+		return nil, errors.Wrapf(err, "error retrieving security group %q", groupName)
+	}
+
+	vpcID, resourceGroupID := "", ""
+	if workerGroup.VPC != nil {
+		vpcID = core.StringNilMapper(workerGroup.VPC.ID)
+	}
+
+	if workerGroup.ResourceGroup != nil {
+		resourceGroupID = core.StringNilMapper(workerGroup.ResourceGroup.ID)
+	}
+
+	group, err = d.Client.CreateSecurityGroup(ctx, vpcID, resourceGroupID, groupName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating security group %q", groupName)
+	}
+
+	err = api.RecordResources(ctx, d.StateStore, api.Resource{
+		Type:       api.ResourceSecurityGroup,
+		ID:         core.StringNilMapper(group.ID),
+		Parameters: map[string]string{"name": groupName},
+	})
+
+	return group, err // nolint:wrapcheck // Already wrapped.
+}
+
+// deleteGatewaySG deletes the gateway security group, waiting for the deleted gateway instances to release it.
+func (d *ocpGatewayDeployer) deleteGatewaySG(ctx context.Context) error {
+	groupName := d.gatewaySecurityGroupName()
+
+	groupID, err := d.recordedSecurityGroupID(ctx, groupName)
+	if err != nil {
+		return err
+	}
+
+	if groupID == "" {
+		group, err := d.Client.GetSecurityGroup(ctx, d.vpcName(), groupName)
+		if ibmclient.IsNotFoundError(err) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving security group %q", groupName)
+		}
+
+		groupID = core.StringNilMapper(group.ID)
+	}
+
+	backoff := wait.Backoff{
+		Steps:    30,
+		Duration: 500 * time.Millisecond,
+		Factor:   1.2,
+		Cap:      10 * time.Minute,
+	}
+
+	err = retry.OnError(backoff, ibmclient.IsConflictError, func() error {
+		return d.Client.DeleteSecurityGroup(ctx, groupID)
+	})
+	if err != nil && !ibmclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "error deleting security group %q", groupName)
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, api.Resource{Type: api.ResourceSecurityGroup, ID: groupID})
+}
+
+// recordedSecurityGroupID returns the ID of the security group recorded with the given name, or an empty string if
+// there's none.
+func (d *ocpGatewayDeployer) recordedSecurityGroupID(ctx context.Context, groupName string) (string, error) {
+	recorded, err := api.RecordedResources(ctx, d.StateStore, api.ResourceSecurityGroup)
+	if err != nil {
+		return "", err // nolint:wrapcheck // Already wrapped.
+	}
+
+	for i := range recorded {
+		if recorded[i].Parameters["name"] == groupName {
+			return recorded[i].ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// findGateways returns the dedicated gateways deployed by machine sets, along with their zones.
+func (d *ocpGatewayDeployer) findGateways(ctx context.Context) ([]k8s.Gateway, error) {
+	machineSets, err := ocp.GatewayMachineSets(ctx, d.msDeployer, d.InfraID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	gateways := make([]k8s.Gateway, 0, len(machineSets))
+
+	for i := range machineSets {
+		zone, _, _ := unstructured.NestedString(machineSets[i].Object, "spec", "template", "spec", "providerSpec", "value", "zone")
+
+		gateways = append(gateways, k8s.Gateway{
+			Name:              machineSets[i].GetName(),
+			Zone:              zone,
+			CreationTimestamp: machineSets[i].GetCreationTimestamp().Time,
+		})
+	}
+
+	return gateways, nil
+}
+
+// eligibleZones returns the zones of the worker nodes which don't have a gateway yet.
+func (d *ocpGatewayDeployer) eligibleZones(ctx context.Context, gateways []k8s.Gateway) ([]string, error) {
+	workerNodes, err := d.k8sClient.ListNodesWithLabelWithContext(ctx, "node-role.kubernetes.io/worker")
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the worker nodes")
+	}
+
+	zones := sets.NewString()
+
+	for i := range workerNodes.Items {
+		if zone := workerNodes.Items[i].Labels[zoneLabel]; zone != "" {
+			zones.Insert(zone)
+		}
+	}
+
+	for i := range gateways {
+		zones.Delete(gateways[i].Zone)
+	}
+
+	eligible := zones.List()
+	sort.Strings(eligible)
+
+	return eligible, nil
+}
+
+type machineSetConfig struct {
+	Name                 string
+	AZ                   string
+	InfraID              string
+	InstanceType         string
+	Region               string
+	Image                string
+	VPCName              string
+	WorkerSecurityGroup  string
+	GatewaySecurityGroup string
+}
+
+func (d *ocpGatewayDeployer) machineSetName(zone string) string {
+	return d.InfraID + "-submariner-gw-" + zone
+}
+
+func (d *ocpGatewayDeployer) floatingIPName(zone string) string {
+	return d.machineSetName(zone) + "-fip"
+}
+
+func (d *ocpGatewayDeployer) initMachineSet(zone string) (*unstructured.Unstructured, error) {
+	tplVars := machineSetConfig{
+		Name:                 d.machineSetName(zone),
+		AZ:                   zone,
+		InfraID:              d.InfraID,
+		InstanceType:         d.instanceType,
+		Region:               d.Region,
+		Image:                d.image,
+		VPCName:              d.vpcName(),
+		WorkerSecurityGroup:  d.workerSecurityGroupName(),
+		GatewaySecurityGroup: d.gatewaySecurityGroupName(),
+	}
+
+	return d.RenderMachineSet(tplVars)
+}
+
+func (d *ocpGatewayDeployer) deployGateway(ctx context.Context, zone string) error {
+	machineSet, err := d.initMachineSet(zone)
+	if err != nil {
+		return err
+	}
+
+	err = d.msDeployer.DeployWithContext(ctx, machineSet)
+	if err != nil {
+		return errors.Wrapf(err, "error deploying machine set %q", machineSet.GetName())
+	}
+
+	return api.RecordResources(ctx, d.StateStore, machineSetResource(machineSet, zone))
+}
+
+func (d *ocpGatewayDeployer) deleteGateway(ctx context.Context, zone string) error {
+	machineSet, err := d.initMachineSet(zone)
+	if err != nil {
+		return err
+	}
+
+	err = d.msDeployer.DeleteWithContext(ctx, machineSet)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting machine set %q", machineSet.GetName())
+	}
+
+	if err := d.deleteFloatingIP(ctx, zone); err != nil {
+		return err
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, machineSetResource(machineSet, zone))
+}
+
+// waitForGatewayInstance waits until the instance of the gateway machine set in the given zone exists.
+func (d *ocpGatewayDeployer) waitForGatewayInstance(ctx context.Context, zone string) (*vpcv1.Instance, error) {
+	var instance *vpcv1.Instance
+
+	prefix := d.machineSetName(zone) + "-"
+
+	ctx, cancel := context.WithTimeout(ctx, instanceWaitTimeout)
+	defer cancel()
+
+	err := wait.PollImmediateUntil(instancePollInterval, func() (bool, error) {
+		instances, err := d.Client.ListInstances(ctx, d.vpcName())
+		if err != nil {
+			return false, errors.Wrap(err, "error listing the instances")
+		}
+
+		for i := range instances {
+			if strings.HasPrefix(core.StringNilMapper(instances[i].Name), prefix) && instances[i].PrimaryNetworkInterface != nil {
+				instance = &instances[i]
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}, ctx.Done())
+
+	return instance, errors.Wrapf(err, "error waiting for the gateway instance in zone %q", zone)
+}
+
+// attachFloatingIP attaches the floating IP of the gateway in the given zone to its instance, reserving it first if
+// needed, and returns its address.
+func (d *ocpGatewayDeployer) attachFloatingIP(ctx context.Context, zone, resourceGroupID string) (string, error) {
+	instance, err := d.waitForGatewayInstance(ctx, zone)
+	if err != nil {
+		return "", err
+	}
+
+	name := d.floatingIPName(zone)
+	nicID := core.StringNilMapper(instance.PrimaryNetworkInterface.ID)
+
+	floatingIP, err := d.Client.GetFloatingIP(ctx, name)
+	if ibmclient.IsNotFoundError(err) {
+		floatingIP, err = d.Client.CreateFloatingIP(ctx, name, resourceGroupID, nicID)
+		if err != nil {
+			return "", errors.Wrapf(err, "error creating floating IP %q", name)
+		}
+
+		err = api.RecordResources(ctx, d.StateStore, floatingIPResource(floatingIP, zone))
+
+		return core.StringNilMapper(floatingIP.Address), err // nolint:wrapcheck // Already wrapped.
+	}
+
+	if err != nil {
+		return "", errors.Wrapf(err, "error retrieving floating IP %q", name)
+	}
+
+	if target, ok := floatingIP.Target.(*vpcv1.FloatingIPTarget); !ok || core.StringNilMapper(target.ID) != nicID {
+		err = d.Client.UpdateFloatingIPTarget(ctx, core.StringNilMapper(floatingIP.ID), nicID)
+		if err != nil {
+			return "", errors.Wrapf(err, "error attaching floating IP %q to instance %q", name, core.StringNilMapper(instance.Name))
+		}
+	}
+
+	return core.StringNilMapper(floatingIP.Address), nil
+}
+
+func (d *ocpGatewayDeployer) deleteFloatingIP(ctx context.Context, zone string) error {
+	name := d.floatingIPName(zone)
+
+	floatingIP, err := d.Client.GetFloatingIP(ctx, name)
+	if err == nil {
+		err = d.Client.DeleteFloatingIP(ctx, core.StringNilMapper(floatingIP.ID))
+	}
+
+	if err != nil && !ibmclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "error deleting floating IP %q", name)
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, api.Resource{Type: api.ResourceFloatingIP, ID: name})
+}
+
+func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *ocpGatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
+	reporter.Started("Deleting the gateway machine sets and their floating IPs")
+
+	zones, err := d.gatewayZones(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	for _, zone := range zones {
+		if err := d.deleteGateway(ctx, zone); err != nil {
+			reporter.Failed(err)
+			return err
+		}
+	}
+
+	reporter.Succeeded("Deleted the gateway machine sets and their floating IPs")
+
+	groupStep := d.gatewaySGStep()
+
+	api.StepStarted(reporter, groupStep, "Deleting the gateway security group %q", d.gatewaySecurityGroupName())
+
+	if err := d.deleteGatewaySG(ctx); err != nil {
+		api.StepFailed(reporter, groupStep, err)
+		return err
+	}
+
+	api.StepSucceeded(reporter, groupStep, "Deleted the gateway security group %q", d.gatewaySecurityGroupName())
+
+	return nil
+}
+
+// gatewayZones returns the zones of the recorded gateway machine sets or, if none were recorded, of the gateway
+// machine sets found in the cluster.
+func (d *ocpGatewayDeployer) gatewayZones(ctx context.Context) ([]string, error) {
+	recorded, err := api.RecordedResources(ctx, d.StateStore, api.ResourceMachineSet)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	zones := []string{}
+
+	for i := range recorded {
+		zones = append(zones, recorded[i].Parameters["zone"])
+	}
+
+	if len(zones) > 0 {
+		return zones, nil
+	}
+
+	gateways, err := d.findGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range gateways {
+		zones = append(zones, gateways[i].Zone)
+	}
+
+	return zones, nil
+}
+
+func machineSetResource(machineSet *unstructured.Unstructured, zone string) api.Resource {
+	return api.Resource{
+		Type:       api.ResourceMachineSet,
+		ID:         machineSet.GetName(),
+		Parameters: map[string]string{"namespace": machineSet.GetNamespace(), "zone": zone},
+	}
+}
+
+func floatingIPResource(floatingIP *vpcv1.FloatingIP, zone string) api.Resource {
+	return api.Resource{
+		Type:       api.ResourceFloatingIP,
+		ID:         core.StringNilMapper(floatingIP.Name),
+		Parameters: map[string]string{"id": core.StringNilMapper(floatingIP.ID), "zone": zone},
+	}
+}
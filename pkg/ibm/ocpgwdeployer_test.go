@@ -0,0 +1,473 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/ibm"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	ocpFake "github.com/submariner-io/cloud-prepare/pkg/ocp/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	zone1         = region + "-1"
+	zone2         = region + "-2"
+	gatewayPrefix = infraID + "-submariner-gw-"
+)
+
+var publicPorts = []api.PortSpec{{Port: 4500, Protocol: "udp"}}
+
+var _ = Describe("OCP GatewayDeployer", func() {
+	Context("on Deploy", testDeploy)
+	Context("on Plan", testPlanGateways)
+	Context("on Cleanup", testCleanup)
+	Context("on Inspect", testInspectGateways)
+})
+
+func testDeploy() {
+	t := newGatewayDeployerTestDriver()
+
+	var retError error
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{
+			newNode("node-1", zone1),
+			newNode("node-2", zone2),
+			newNode("node-3", zone2),
+		}
+	})
+
+	JustBeforeEach(func() {
+		retError = t.doDeploy()
+	})
+
+	When("one gateway is requested", func() {
+		BeforeEach(func() {
+			t.numGateways = 1
+		})
+
+		It("should create the gateway security group and deploy a machine set in the first zone", func() {
+			Expect(retError).To(Succeed())
+
+			Expect(t.groups).To(HaveKey(gatewayGroupName))
+			Expect(t.groupPorts(gatewayGroupName, allIPv4CIDR)).To(Equal([]string{"4500/udp"}))
+
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix + zone1}))
+			t.assertMachineSet(t.machineSets[gatewayPrefix+zone1], zone1)
+		})
+
+		It("should attach a floating IP to the gateway instance", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.floatingIPs).To(HaveLen(1))
+			Expect(t.floatingIPs).To(HaveKey(gatewayPrefix + zone1 + "-fip"))
+			Expect(t.floatingIPTarget(zone1)).To(Equal(nicID(zone1)))
+		})
+	})
+
+	When("two gateways are requested", func() {
+		BeforeEach(func() {
+			t.numGateways = 2
+		})
+
+		It("should deploy a machine set in each zone", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix + zone1, gatewayPrefix + zone2}))
+			Expect(t.floatingIPs).To(HaveLen(2))
+		})
+
+		Context("and there's an insufficient number of zones", func() {
+			BeforeEach(func() {
+				t.numGateways = 3
+			})
+
+			It("should return an error", func() {
+				Expect(retError).ToNot(Succeed())
+				Expect(t.machineSets).To(BeEmpty())
+			})
+		})
+	})
+
+	When("a gateway instance was replaced", func() {
+		BeforeEach(func() {
+			t.addMachineSet(zone1, time.Now())
+			t.addFloatingIP(gatewayPrefix+zone1+"-fip", "deleted-nic")
+			t.numGateways = 1
+		})
+
+		It("should attach the existing floating IP to the new instance", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.floatingIPs).To(HaveLen(1))
+			Expect(t.floatingIPTarget(zone1)).To(Equal(nicID(zone1)))
+		})
+	})
+
+	When("the requested number of gateways is decreased", func() {
+		BeforeEach(func() {
+			t.addMachineSet(zone1, time.Now())
+			t.addMachineSet(zone2, time.Now().Add(-time.Hour))
+			t.addFloatingIP(gatewayPrefix+zone1+"-fip", nicID(zone1))
+			t.addFloatingIP(gatewayPrefix+zone2+"-fip", nicID(zone2))
+			t.numGateways = 1
+		})
+
+		It("should delete the newest gateway's machine set and floating IP", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.machineSetNames()).To(Equal([]string{gatewayPrefix + zone2}))
+			Expect(t.floatingIPs).To(HaveLen(1))
+			Expect(t.floatingIPs).To(HaveKey(gatewayPrefix + zone2 + "-fip"))
+		})
+
+		Context("to the default", func() {
+			BeforeEach(func() {
+				t.numGateways = 0
+			})
+
+			It("should keep the existing gateways", func() {
+				Expect(retError).To(Succeed())
+				Expect(t.machineSetNames()).To(HaveLen(2))
+				Expect(t.floatingIPs).To(HaveLen(2))
+			})
+		})
+	})
+
+	When("the public ports are restricted to source CIDRs", func() {
+		BeforeEach(func() {
+			t.numGateways = 1
+			t.sourceCIDRs = []string{"10.0.0.0/8"}
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+			Expect(t.machineSets).To(BeEmpty())
+		})
+	})
+
+	When("deploying a machine set fails", func() {
+		BeforeEach(func() {
+			t.numGateways = 1
+			t.deployError = errors.New("fake Deploy error")
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testPlanGateways() {
+	t := newGatewayDeployerTestDriver()
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{
+			newNode("node-1", zone1),
+			newNode("node-2", zone2),
+		}
+
+		t.addMachineSet(zone1, time.Now())
+	})
+
+	It("should return the actions without performing them", func() {
+		actions, err := t.gwDeployer.Plan(context.TODO(), api.GatewayDeployInput{Gateways: 2, PublicPorts: publicPorts})
+		Expect(err).To(Succeed())
+		Expect(actions).To(HaveLen(4))
+		Expect(actions[0].Kind).To(Equal(api.ActionCreateSecurityGroup))
+		Expect(actions[0].Resource).To(Equal(gatewayGroupName))
+		Expect(actions[1].Kind).To(Equal(api.ActionAuthorizeIngress))
+		Expect(actions[1].Parameters).To(HaveKeyWithValue("source-cidr", allIPv4CIDR))
+		Expect(actions[2].Kind).To(Equal(api.ActionDeployMachineSet))
+		Expect(actions[2].Resource).To(Equal(gatewayPrefix + zone2))
+		Expect(actions[3].Kind).To(Equal(api.ActionConfigurePublicIP))
+
+		Expect(t.groups).ToNot(HaveKey(gatewayGroupName))
+		Expect(t.machineSetNames()).To(HaveLen(1))
+	})
+}
+
+func testCleanup() {
+	t := newGatewayDeployerTestDriver()
+
+	BeforeEach(func() {
+		t.addGroup(gatewayGroupName, "gateway-sg-id")
+		t.addMachineSet(zone1, time.Now())
+		t.addMachineSet(zone2, time.Now())
+		t.addFloatingIP(gatewayPrefix+zone1+"-fip", nicID(zone1))
+		t.addFloatingIP("other-fip", "other-nic")
+	})
+
+	It("should delete the gateway machine sets, their floating IPs and the gateway security group", func() {
+		Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+		Expect(t.machineSetNames()).To(BeEmpty())
+		Expect(t.floatingIPs).To(HaveLen(1))
+		Expect(t.floatingIPs).To(HaveKey("other-fip"))
+		Expect(t.groups).ToNot(HaveKey(gatewayGroupName))
+		Expect(t.groups).To(HaveKey(workerGroupName))
+	})
+
+	When("the gateway security group is recorded", func() {
+		var store *memoryStateStore
+
+		BeforeEach(func() {
+			// The group can't be found by its name anymore, only the record knows it.
+			delete(t.groups, gatewayGroupName)
+			t.addGroup("renamed-gw-sg", "gateway-sg-id")
+
+			store = &memoryStateStore{}
+			Expect(api.RecordResources(context.TODO(), store, api.Resource{
+				Type:       api.ResourceSecurityGroup,
+				ID:         "gateway-sg-id",
+				Parameters: map[string]string{"name": gatewayGroupName},
+			})).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			t.gwDeployer.(api.StateRecorder).SetStateStore(store)
+		})
+
+		It("should delete the recorded group and forget it", func() {
+			Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+			Expect(t.groups).ToNot(HaveKey("renamed-gw-sg"))
+
+			recorded, err := api.RecordedResources(context.TODO(), store, api.ResourceSecurityGroup)
+			Expect(err).To(Succeed())
+			Expect(recorded).To(BeEmpty())
+		})
+	})
+
+	When("deleting a machine set fails", func() {
+		BeforeEach(func() {
+			t.deleteError = errors.New("fake Delete error")
+		})
+
+		It("should return an error", func() {
+			Expect(t.gwDeployer.Cleanup(api.NewLoggingReporter())).ToNot(Succeed())
+		})
+	})
+}
+
+func testInspectGateways() {
+	t := newGatewayDeployerTestDriver()
+
+	BeforeEach(func() {
+		t.nodes = []*corev1.Node{
+			labelNode(newNode("node-1", zone1)),
+			newNode("node-2", zone2),
+		}
+
+		t.addGroup(gatewayGroupName, "gateway-sg-id")
+		t.addRule(gatewayGroupName, directionInbound, protocolUDP, 4500, fromCIDR(allIPv4CIDR))
+		t.addMachineSet(zone1, time.Now())
+	})
+
+	It("should return the public ports, the gateway nodes and their machine sets", func() {
+		status, err := t.gwDeployer.Inspect(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(status).To(Equal(&api.GatewayStatus{
+			PublicPorts:        []api.PortSpec{{Port: 4500, Protocol: "udp"}},
+			GatewaySubnets:     []string{},
+			GatewayNodes:       []string{"node-1"},
+			GatewayMachineSets: []string{gatewayPrefix + zone1},
+		}))
+	})
+}
+
+type gatewayDeployerTestDriver struct {
+	fakeIBMClientBase
+	numGateways int
+	sourceCIDRs []string
+	msDeployer  *ocpFake.MockMachineSetDeployer
+	machineSets map[string]*unstructured.Unstructured
+	deployError error
+	deleteError error
+	kubeClient  *kubeFake.Clientset
+	nodes       []*corev1.Node
+	gwDeployer  api.GatewayDeployer
+}
+
+func newGatewayDeployerTestDriver() *gatewayDeployerTestDriver {
+	t := &gatewayDeployerTestDriver{}
+
+	BeforeEach(func() {
+		t.beforeEach()
+
+		t.nodes = []*corev1.Node{}
+		t.numGateways = 0
+		t.machineSets = map[string]*unstructured.Unstructured{}
+		t.deployError = nil
+		t.deleteError = nil
+		t.kubeClient = kubeFake.NewSimpleClientset()
+		t.msDeployer = ocpFake.NewMockMachineSetDeployer(t.mockCtrl)
+
+		t.msDeployer.EXPECT().DeployWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, ms *unstructured.Unstructured) error {
+				if t.deployError != nil {
+					return t.deployError
+				}
+
+				t.machineSets[ms.GetName()] = ms
+				t.addInstance(ms)
+
+				return nil
+			}).AnyTimes()
+
+		t.msDeployer.EXPECT().DeleteWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, ms *unstructured.Unstructured) error {
+				if t.deleteError != nil {
+					return t.deleteError
+				}
+
+				delete(t.machineSets, ms.GetName())
+
+				return nil
+			}).AnyTimes()
+
+		t.msDeployer.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, ms *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+				Expect(ms.GetNamespace()).To(Equal("openshift-machine-api"))
+
+				worker := unstructured.Unstructured{}
+				worker.SetName(infraID + "-worker-" + zone1)
+
+				list := []unstructured.Unstructured{worker}
+				for _, name := range t.machineSetNames() {
+					list = append(list, *t.machineSets[name])
+				}
+
+				return list, nil
+			}).AnyTimes()
+	})
+
+	JustBeforeEach(func() {
+		for _, node := range t.nodes {
+			_, err := t.kubeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		}
+
+		t.gwDeployer = ibm.NewOcpGatewayDeployer(ibm.CloudInfo{
+			InfraID: infraID,
+			Region:  region,
+			Client:  t.ibmClient,
+		}, t.msDeployer, "", "", k8s.NewInterface(t.kubeClient))
+	})
+
+	AfterEach(t.afterEach)
+
+	return t
+}
+
+func (t *gatewayDeployerTestDriver) doDeploy() error {
+	return t.gwDeployer.Deploy(api.GatewayDeployInput{
+		Gateways:          t.numGateways,
+		PublicPorts:       publicPorts,
+		PublicSourceCIDRs: t.sourceCIDRs,
+	}, api.NewLoggingReporter())
+}
+
+func (t *gatewayDeployerTestDriver) addMachineSet(zone string, created time.Time) {
+	ms := &unstructured.Unstructured{}
+	ms.SetName(gatewayPrefix + zone)
+	ms.SetCreationTimestamp(metav1.NewTime(created))
+	Expect(unstructured.SetNestedField(ms.Object, zone, "spec", "template", "spec", "providerSpec", "value", "zone")).To(Succeed())
+
+	t.machineSets[ms.GetName()] = ms
+	t.addInstance(ms)
+}
+
+// addInstance adds the instance of the machine set's machine, named after the machine set like OpenShift does.
+func (t *gatewayDeployerTestDriver) addInstance(ms *unstructured.Unstructured) {
+	zone, _, _ := unstructured.NestedString(ms.Object, "spec", "template", "spec", "providerSpec", "value", "zone")
+
+	t.instances = append(t.instances, vpcv1.Instance{
+		Name:                    stringPtr(ms.GetName() + "-x7k2p"),
+		PrimaryNetworkInterface: &vpcv1.NetworkInterfaceInstanceContextReference{ID: stringPtr(nicID(zone))},
+	})
+}
+
+func (t *gatewayDeployerTestDriver) machineSetNames() []string {
+	names := []string{}
+	for name := range t.machineSets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (t *gatewayDeployerTestDriver) floatingIPTarget(zone string) string {
+	floatingIP := t.floatingIPs[gatewayPrefix+zone+"-fip"]
+	Expect(floatingIP).ToNot(BeNil())
+
+	return *floatingIP.Target.(*vpcv1.FloatingIPTarget).ID
+}
+
+func (t *gatewayDeployerTestDriver) assertMachineSet(ms *unstructured.Unstructured, expZone string) {
+	Expect(ms).ToNot(BeNil())
+	Expect(ms.GetNamespace()).To(Equal("openshift-machine-api"))
+
+	providerSpec := []string{"spec", "template", "spec", "providerSpec", "value"}
+
+	zone, _, _ := unstructured.NestedString(ms.Object, append(providerSpec, "zone")...)
+	Expect(zone).To(Equal(expZone))
+
+	profile, _, _ := unstructured.NestedString(ms.Object, append(providerSpec, "profile")...)
+	Expect(profile).To(Equal("bx2-2x8"))
+
+	image, _, _ := unstructured.NestedString(ms.Object, append(providerSpec, "image")...)
+	Expect(image).To(Equal(infraID + "-rhcos"))
+
+	subnet, _, _ := unstructured.NestedString(ms.Object, append(providerSpec, "primaryNetworkInterface", "subnet")...)
+	Expect(subnet).To(Equal(infraID + "-subnet-compute-" + expZone))
+
+	groups, _, _ := unstructured.NestedStringSlice(ms.Object, append(providerSpec, "primaryNetworkInterface", "securityGroups")...)
+	Expect(groups).To(ContainElements(workerGroupName, gatewayGroupName))
+}
+
+func nicID(zone string) string {
+	return "nic-" + zone
+}
+
+func newNode(name, withZone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"topology.kubernetes.io/zone":    withZone,
+				"node-role.kubernetes.io/worker": "",
+			},
+		},
+	}
+}
+
+func labelNode(node *corev1.Node) *corev1.Node {
+	node.Labels["submariner.io/gateway"] = "true"
+	return node
+}
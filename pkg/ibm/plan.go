@@ -0,0 +1,147 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	ibmclient "github.com/submariner-io/cloud-prepare/pkg/ibm/client"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+)
+
+// Plan returns the rules PrepareForSubmariner would add to the worker security group on IBM Cloud.
+func (ic *ibmCloud) Plan(ctx context.Context, input api.PrepareForSubmarinerInput) ([]api.Action, error) {
+	group, err := ic.getSecurityGroup(ctx, ic.workerSecurityGroupName())
+	if err != nil {
+		return nil, err
+	}
+
+	groupID := core.StringNilMapper(group.ID)
+
+	return planIngress([]api.Action{}, group, groupID, "source-group", groupID, input.InternalPorts)
+}
+
+// Plan returns the gateway security group and rules, the gateway machine sets and the floating IPs Deploy would create
+// or remove on IBM Cloud.
+func (d *ocpGatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	if input.RestrictsSources() {
+		return nil, errors.New("restricting the public ports to source CIDRs isn't supported on IBM Cloud")
+	}
+
+	actions := []api.Action{}
+	groupName := d.gatewaySecurityGroupName()
+
+	gatewayGroup, err := d.Client.GetSecurityGroup(ctx, d.vpcName(), groupName)
+	if ibmclient.IsNotFoundError(err) {
+		actions = append(actions, api.Action{
+			Kind:       api.ActionCreateSecurityGroup,
+			Resource:   groupName,
+			Parameters: map[string]string{"vpc": d.vpcName()},
+		})
+		gatewayGroup = &vpcv1.SecurityGroup{ID: &groupName}
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving security group %q", groupName)
+	}
+
+	actions, err = planIngress(actions, gatewayGroup, core.StringNilMapper(gatewayGroup.ID), "source-cidr", allIPv4CIDR,
+		input.PublicPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	gateways, err := d.findGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gatewayNodesToDeploy := input.Gateways - len(gateways)
+	if gatewayNodesToDeploy < 0 && input.Gateways > 0 {
+		for _, gateway := range k8s.SurplusGateways(gateways, input.Gateways) {
+			actions = append(actions, api.Action{
+				Kind:       api.ActionDeleteMachineSet,
+				Resource:   gateway.Name,
+				Parameters: map[string]string{"zone": gateway.Zone},
+			}, api.Action{
+				Kind:     api.ActionDeletePublicIP,
+				Resource: d.floatingIPName(gateway.Zone),
+			})
+		}
+	}
+
+	if gatewayNodesToDeploy <= 0 {
+		return actions, nil
+	}
+
+	eligibleZones, err := d.eligibleZones(ctx, gateways)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(eligibleZones) < gatewayNodesToDeploy {
+		return nil, fmt.Errorf("there are an insufficient number of zones (%d) to deploy the desired number of gateways (%d)",
+			len(eligibleZones)+len(gateways), input.Gateways)
+	}
+
+	for _, zone := range eligibleZones[:gatewayNodesToDeploy] {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionDeployMachineSet,
+			Resource: d.machineSetName(zone),
+			Parameters: map[string]string{
+				"namespace":     "openshift-machine-api",
+				"zone":          zone,
+				"instance-type": d.instanceType,
+				"image":         d.image,
+			},
+		}, api.Action{
+			Kind:     api.ActionConfigurePublicIP,
+			Resource: d.floatingIPName(zone),
+		})
+	}
+
+	return actions, nil
+}
+
+// planIngress appends an ingress authorization to the group for each of the ports not allowed yet from the source.
+func planIngress(actions []api.Action, group *vpcv1.SecurityGroup, groupID, sourceKey, source string, ports []api.PortSpec,
+) ([]api.Action, error) {
+	missing, err := missingPorts(group, source, ports)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, port := range missing {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionAuthorizeIngress,
+			Resource: groupID,
+			Parameters: map[string]string{
+				"protocol": port.Protocol,
+				"port":     strconv.Itoa(int(port.Port)),
+				sourceKey:  source,
+			},
+		})
+	}
+
+	return actions, nil
+}
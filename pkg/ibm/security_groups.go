@@ -0,0 +1,165 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ibm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/IBM/vpc-go-sdk/vpcv1"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+const (
+	allIPv4CIDR   = "0.0.0.0/0"
+	ruleInbound   = "inbound"
+	ruleIPVersion = "ipv4"
+)
+
+// securityGroupRule is an inbound TCP or UDP rule of a security group; a port of 0 allows all ports.
+type securityGroupRule struct {
+	id     string
+	port   api.PortSpec
+	remote string
+}
+
+// inboundRules returns the inbound TCP and UDP rules of the security group allowing a single port, or all ports.
+// The remote of each rule is the ID of the remote security group or the remote CIDR block.
+func inboundRules(group *vpcv1.SecurityGroup) []securityGroupRule {
+	rules := []securityGroupRule{}
+
+	for _, intf := range group.Rules {
+		rule, ok := intf.(*vpcv1.SecurityGroupRuleSecurityGroupRuleProtocolTcpudp)
+		if !ok || core.StringNilMapper(rule.Direction) != ruleInbound {
+			continue
+		}
+
+		port := int64(0)
+
+		if rule.PortMin != nil && rule.PortMax != nil && !(*rule.PortMin == 1 && *rule.PortMax == 65535) {
+			if *rule.PortMin != *rule.PortMax {
+				continue
+			}
+
+			port = *rule.PortMin
+		}
+
+		rules = append(rules, securityGroupRule{
+			id:     core.StringNilMapper(rule.ID),
+			port:   api.PortSpec{Port: uint16(port), Protocol: core.StringNilMapper(rule.Protocol)},
+			remote: ruleRemote(rule.Remote),
+		})
+	}
+
+	return rules
+}
+
+func ruleRemote(intf vpcv1.SecurityGroupRuleRemoteIntf) string {
+	remote, ok := intf.(*vpcv1.SecurityGroupRuleRemote)
+	if !ok {
+		return ""
+	}
+
+	if remote.ID != nil {
+		return *remote.ID
+	}
+
+	if remote.CIDRBlock != nil {
+		return *remote.CIDRBlock
+	}
+
+	return core.StringNilMapper(remote.Address)
+}
+
+// findRule returns the inbound rule allowing the given port from the given remote, if any.
+func findRule(rules []securityGroupRule, port api.PortSpec, remote string) *securityGroupRule {
+	for i := range rules {
+		if rules[i].remote == remote && rules[i].port.Port == port.Port && strings.EqualFold(rules[i].port.Protocol, port.Protocol) {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
+
+func ruleProtocol(port api.PortSpec) (string, error) {
+	protocol := strings.ToLower(port.Protocol)
+	if protocol != "tcp" && protocol != "udp" {
+		return "", fmt.Errorf("protocol %q isn't supported on IBM Cloud, only TCP and UDP are", port.Protocol)
+	}
+
+	return protocol, nil
+}
+
+// newRulePrototype returns an inbound rule allowing the given port from the remote security group ID or CIDR block.
+func newRulePrototype(port api.PortSpec, remote string) (vpcv1.SecurityGroupRulePrototypeIntf, error) {
+	protocol, err := ruleProtocol(port)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &vpcv1.SecurityGroupRulePrototypeSecurityGroupRuleProtocolTcpudp{
+		Direction: stringPtr(ruleInbound),
+		IPVersion: stringPtr(ruleIPVersion),
+		Protocol:  &protocol,
+	}
+
+	if port.Port != 0 {
+		rule.PortMin = int64Ptr(int64(port.Port))
+		rule.PortMax = int64Ptr(int64(port.Port))
+	}
+
+	if strings.Contains(remote, "/") {
+		rule.Remote = &vpcv1.SecurityGroupRuleRemotePrototypeCIDR{CIDRBlock: &remote}
+	} else {
+		rule.Remote = &vpcv1.SecurityGroupRuleRemotePrototypeSecurityGroupIdentitySecurityGroupIdentityByID{ID: &remote}
+	}
+
+	return rule, nil
+}
+
+func ingressRuleResource(groupID, ruleID string, port api.PortSpec) api.Resource {
+	return api.Resource{
+		Type: api.ResourceIngressRule,
+		ID:   ruleID,
+		Parameters: map[string]string{
+			"group":    groupID,
+			"port":     fmt.Sprint(port.Port),
+			"protocol": port.Protocol,
+		},
+	}
+}
+
+func formatPorts(ports []api.PortSpec) string {
+	portStrs := []string{}
+	for _, port := range ports {
+		portStrs = append(portStrs, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+	}
+
+	return strings.Join(portStrs, ", ")
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
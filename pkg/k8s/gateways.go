@@ -0,0 +1,125 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package k8s
+
+import (
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const zoneLabel = "topology.kubernetes.io/zone"
+
+// Gateway is an existing gateway, which may be kept or removed when scaling the gateways down.
+type Gateway struct {
+	// Name identifies the gateway, e.g. the name of its node or instance.
+	Name string
+
+	// Zone the gateway runs in.
+	Zone string
+
+	// CreationTimestamp is when the gateway was created.
+	CreationTimestamp time.Time
+}
+
+// GatewayFromNode returns the Gateway running on the given node.
+func GatewayFromNode(node *v1.Node) Gateway {
+	return Gateway{
+		Name:              node.Name,
+		Zone:              node.Labels[zoneLabel],
+		CreationTimestamp: node.CreationTimestamp.Time,
+	}
+}
+
+// SurplusGateways returns the gateways to remove so that only the desired number of gateways remain. The gateways
+// kept are spread across as many zones as possible and, within that, are the oldest ones; remaining ties are broken
+// by name so the choice is deterministic.
+func SurplusGateways(gateways []Gateway, desired int) []Gateway {
+	if desired < 0 {
+		desired = 0
+	}
+
+	if len(gateways) <= desired {
+		return []Gateway{}
+	}
+
+	sorted := make([]Gateway, len(gateways))
+	copy(sorted, gateways)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return olderGateway(&sorted[i], &sorted[j])
+	})
+
+	// Rank each gateway within its zone, the oldest getting rank 0, so that the first gateway of every zone is kept
+	// before any second gateway in a zone.
+	ranks := make(map[string]int, len(sorted))
+	zoneCounts := map[string]int{}
+
+	for i := range sorted {
+		ranks[sorted[i].Name] = zoneCounts[sorted[i].Zone]
+		zoneCounts[sorted[i].Zone]++
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if ranks[sorted[i].Name] != ranks[sorted[j].Name] {
+			return ranks[sorted[i].Name] < ranks[sorted[j].Name]
+		}
+
+		return olderGateway(&sorted[i], &sorted[j])
+	})
+
+	return sorted[desired:]
+}
+
+// SurplusGatewayNodes returns the gateway nodes to remove so that only the desired number of gateways remain, as
+// chosen by SurplusGateways.
+func SurplusGatewayNodes(gwNodes *v1.NodeList, desired int) []*v1.Node {
+	gateways := make([]Gateway, len(gwNodes.Items))
+	nodes := make(map[string]*v1.Node, len(gwNodes.Items))
+
+	for i := range gwNodes.Items {
+		gateways[i] = GatewayFromNode(&gwNodes.Items[i])
+		nodes[gwNodes.Items[i].Name] = &gwNodes.Items[i]
+	}
+
+	surplus := []*v1.Node{}
+	for _, gateway := range SurplusGateways(gateways, desired) {
+		surplus = append(surplus, nodes[gateway.Name])
+	}
+
+	return surplus
+}
+
+func olderGateway(a, b *Gateway) bool {
+	if !a.CreationTimestamp.Equal(b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(b.CreationTimestamp)
+	}
+
+	return a.Name < b.Name
+}
+
+// NodeNames returns the names of the given nodes.
+func NodeNames(nodes *v1.NodeList) []string {
+	names := make([]string, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		names = append(names, nodes.Items[i].Name)
+	}
+
+	return names
+}
@@ -0,0 +1,73 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package k8s_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+)
+
+var _ = Describe("SurplusGateways", func() {
+	now := time.Now()
+
+	gateway := func(name, zone string, age time.Duration) k8s.Gateway {
+		return k8s.Gateway{Name: name, Zone: zone, CreationTimestamp: now.Add(-age)}
+	}
+
+	names := func(gateways []k8s.Gateway) []string {
+		names := []string{}
+		for i := range gateways {
+			names = append(names, gateways[i].Name)
+		}
+
+		return names
+	}
+
+	gateways := []k8s.Gateway{
+		gateway("gw-a1", "zone-a", 3*time.Hour),
+		gateway("gw-a2", "zone-a", 4*time.Hour),
+		gateway("gw-b1", "zone-b", time.Hour),
+		gateway("gw-c1", "zone-c", 2*time.Hour),
+	}
+
+	When("there are no more gateways than desired", func() {
+		It("should return none", func() {
+			Expect(k8s.SurplusGateways(gateways, 4)).To(BeEmpty())
+		})
+	})
+
+	When("there are more gateways than desired", func() {
+		It("should keep the oldest gateways spread across zones", func() {
+			Expect(names(k8s.SurplusGateways(gateways, 3))).To(Equal([]string{"gw-a1"}))
+			Expect(names(k8s.SurplusGateways(gateways, 2))).To(Equal([]string{"gw-b1", "gw-a1"}))
+			Expect(names(k8s.SurplusGateways(gateways, 0))).To(Equal([]string{"gw-a2", "gw-c1", "gw-b1", "gw-a1"}))
+		})
+	})
+
+	When("gateways have the same age", func() {
+		It("should keep them by name", func() {
+			Expect(names(k8s.SurplusGateways([]k8s.Gateway{
+				gateway("gw-2", "", time.Hour),
+				gateway("gw-1", "", time.Hour),
+			}, 1))).To(Equal([]string{"gw-2"}))
+		})
+	})
+})
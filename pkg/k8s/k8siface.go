@@ -41,6 +41,21 @@ type Interface interface {
 	AddGWLabelOnNode(nodeName string) error
 	RemoveGWLabelFromWorkerNodes() error
 	RemoveGWLabelFromWorkerNode(node *v1.Node) error
+
+	// ListNodesWithLabelWithContext is ListNodesWithLabel, bound to the given context.
+	ListNodesWithLabelWithContext(ctx context.Context, labelSelector string) (*v1.NodeList, error)
+
+	// ListGatewayNodesWithContext is ListGatewayNodes, bound to the given context.
+	ListGatewayNodesWithContext(ctx context.Context) (*v1.NodeList, error)
+
+	// AddGWLabelOnNodeWithContext is AddGWLabelOnNode, bound to the given context.
+	AddGWLabelOnNodeWithContext(ctx context.Context, nodeName string) error
+
+	// RemoveGWLabelFromWorkerNodesWithContext is RemoveGWLabelFromWorkerNodes, bound to the given context.
+	RemoveGWLabelFromWorkerNodesWithContext(ctx context.Context) error
+
+	// RemoveGWLabelFromWorkerNodeWithContext is RemoveGWLabelFromWorkerNode, bound to the given context.
+	RemoveGWLabelFromWorkerNodeWithContext(ctx context.Context, node *v1.Node) error
 }
 
 type k8sIface struct {
@@ -52,7 +67,11 @@ func NewInterface(clientSet kubernetes.Interface) Interface {
 }
 
 func (k *k8sIface) ListNodesWithLabel(labelSelector string) (*v1.NodeList, error) {
-	nodes, err := k.clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
+	return k.ListNodesWithLabelWithContext(context.Background(), labelSelector)
+}
+
+func (k *k8sIface) ListNodesWithLabelWithContext(ctx context.Context, labelSelector string) (*v1.NodeList, error) {
+	nodes, err := k.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to list the nodes in the cluster")
 	}
@@ -61,9 +80,13 @@ func (k *k8sIface) ListNodesWithLabel(labelSelector string) (*v1.NodeList, error
 }
 
 func (k *k8sIface) ListGatewayNodes() (*v1.NodeList, error) {
+	return k.ListGatewayNodesWithContext(context.Background())
+}
+
+func (k *k8sIface) ListGatewayNodesWithContext(ctx context.Context) (*v1.NodeList, error) {
 	labelSelector := SubmarinerGatewayLabel + "=true"
 
-	nodes, err := k.clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
+	nodes, err := k.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to list the Gateway nodes in the cluster")
 	}
@@ -71,7 +94,7 @@ func (k *k8sIface) ListGatewayNodes() (*v1.NodeList, error) {
 	return nodes, nil
 }
 
-func (k *k8sIface) updateLabel(nodeName string, mutate func(existing *v1.Node)) error {
+func (k *k8sIface) updateLabel(ctx context.Context, nodeName string, mutate func(existing *v1.Node)) error {
 	// nolint:wrapcheck // Let the caller wrap these errors.
 	client := &resource.InterfaceFuncs{
 		GetFunc: func(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error) {
@@ -82,7 +105,7 @@ func (k *k8sIface) updateLabel(nodeName string, mutate func(existing *v1.Node))
 		},
 	}
 
-	return errors.Wrap(util.Update(context.TODO(), client, &v1.Node{
+	return errors.Wrap(util.Update(ctx, client, &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: nodeName,
 		},
@@ -93,7 +116,11 @@ func (k *k8sIface) updateLabel(nodeName string, mutate func(existing *v1.Node))
 }
 
 func (k *k8sIface) AddGWLabelOnNode(nodeName string) error {
-	return k.updateLabel(nodeName, func(existing *v1.Node) {
+	return k.AddGWLabelOnNodeWithContext(context.Background(), nodeName)
+}
+
+func (k *k8sIface) AddGWLabelOnNodeWithContext(ctx context.Context, nodeName string) error {
+	return k.updateLabel(ctx, nodeName, func(existing *v1.Node) {
 		labels := existing.GetLabels()
 		if labels == nil {
 			labels = map[string]string{}
@@ -105,14 +132,18 @@ func (k *k8sIface) AddGWLabelOnNode(nodeName string) error {
 }
 
 func (k *k8sIface) RemoveGWLabelFromWorkerNodes() error {
-	gwNodeList, err := k.clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: SubmarinerGatewayLabel})
+	return k.RemoveGWLabelFromWorkerNodesWithContext(context.Background())
+}
+
+func (k *k8sIface) RemoveGWLabelFromWorkerNodesWithContext(ctx context.Context) error {
+	gwNodeList, err := k.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: SubmarinerGatewayLabel})
 	if err != nil {
 		return errors.Wrap(err, "error listing submariner gateway nodes")
 	}
 
 	gwNodes := gwNodeList.Items
 	for i := range gwNodes {
-		err = k.RemoveGWLabelFromWorkerNode(&gwNodes[i])
+		err = k.RemoveGWLabelFromWorkerNodeWithContext(ctx, &gwNodes[i])
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("error removing the label from the gateway node %q", gwNodes[i].Name))
 		}
@@ -122,7 +153,11 @@ func (k *k8sIface) RemoveGWLabelFromWorkerNodes() error {
 }
 
 func (k *k8sIface) RemoveGWLabelFromWorkerNode(node *v1.Node) error {
-	return k.updateLabel(node.Name, func(existing *v1.Node) {
+	return k.RemoveGWLabelFromWorkerNodeWithContext(context.Background(), node)
+}
+
+func (k *k8sIface) RemoveGWLabelFromWorkerNodeWithContext(ctx context.Context, node *v1.Node) error {
+	return k.updateLabel(ctx, node.Name, func(existing *v1.Node) {
 		delete(existing.Labels, SubmarinerGatewayLabel)
 	})
 }
@@ -0,0 +1,99 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const stateKey = "state.json"
+
+type configMapStateStore struct {
+	clientSet kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMapStateStore returns an api.StateStore persisting the State as JSON in the given ConfigMap, which is created
+// on the first save.
+func NewConfigMapStateStore(clientSet kubernetes.Interface, namespace, name string) api.StateStore {
+	return &configMapStateStore{clientSet: clientSet, namespace: namespace, name: name}
+}
+
+func (c *configMapStateStore) Load(ctx context.Context) (*api.State, error) {
+	state := &api.State{Resources: []api.Resource{}}
+
+	configMap, err := c.clientSet.CoreV1().ConfigMaps(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving the state ConfigMap %s/%s", c.namespace, c.name)
+	}
+
+	data, ok := configMap.Data[stateKey]
+	if !ok {
+		return state, nil
+	}
+
+	err = json.Unmarshal([]byte(data), state)
+
+	return state, errors.Wrapf(err, "error parsing the state ConfigMap %s/%s", c.namespace, c.name)
+}
+
+func (c *configMapStateStore) Save(ctx context.Context, state *api.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "error serializing the state")
+	}
+
+	configMaps := c.clientSet.CoreV1().ConfigMaps(c.namespace)
+
+	configMap, err := configMaps.Get(ctx, c.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: c.name, Namespace: c.namespace},
+			Data:       map[string]string{stateKey: string(data)},
+		}, metav1.CreateOptions{})
+
+		return errors.Wrapf(err, "error creating the state ConfigMap %s/%s", c.namespace, c.name)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving the state ConfigMap %s/%s", c.namespace, c.name)
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+
+	configMap.Data[stateKey] = string(data)
+
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+
+	return errors.Wrapf(err, "error updating the state ConfigMap %s/%s", c.namespace, c.name)
+}
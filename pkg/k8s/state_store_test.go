@@ -0,0 +1,83 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package k8s_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ConfigMap StateStore", func() {
+	const (
+		namespace = "submariner-operator"
+		name      = "cloud-prepare-state"
+	)
+
+	var (
+		kubeClient *kubeFake.Clientset
+		store      api.StateStore
+	)
+
+	BeforeEach(func() {
+		kubeClient = kubeFake.NewSimpleClientset()
+		store = k8s.NewConfigMapStateStore(kubeClient, namespace, name)
+	})
+
+	When("the ConfigMap doesn't exist", func() {
+		It("should load an empty state", func() {
+			state, err := store.Load(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(state.Resources).To(BeEmpty())
+		})
+	})
+
+	It("should create and then update the ConfigMap", func() {
+		sgResource := api.Resource{Type: api.ResourceSecurityGroup, ID: "sg-1"}
+		labelResource := api.Resource{Type: api.ResourceNodeLabel, ID: "node-1"}
+
+		Expect(api.RecordResources(context.TODO(), store, sgResource)).To(Succeed())
+
+		_, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+
+		Expect(api.RecordResources(context.TODO(), store, labelResource)).To(Succeed())
+
+		state, err := k8s.NewConfigMapStateStore(kubeClient, namespace, name).Load(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(state.Resources).To(Equal([]api.Resource{sgResource, labelResource}))
+	})
+
+	When("retrieval of the ConfigMap fails", func() {
+		BeforeEach(func() {
+			fake.NewFailingReactorForResource(&kubeClient.Fake, "configmaps").SetFailOnGet(errors.New("fake Get error"))
+		})
+
+		It("should return an error", func() {
+			_, err := store.Load(context.TODO())
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})
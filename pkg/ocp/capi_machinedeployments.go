@@ -0,0 +1,547 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ocp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	capiClusterNameLabel    = "cluster.x-k8s.io/cluster-name"
+	capiDeploymentNameLabel = "cluster.x-k8s.io/deployment-name"
+)
+
+var (
+	machineDeploymentGVK = schema.GroupVersionKind{
+		Group:   "cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "MachineDeployment",
+	}
+
+	kubeadmConfigTemplateGVK = schema.GroupVersionKind{
+		Group:   "bootstrap.cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "KubeadmConfigTemplate",
+	}
+)
+
+// CAPIConfig configures where and how the gateway MachineDeployments are created.
+type CAPIConfig struct {
+	// ClusterName is the name of the Cluster API Cluster the gateway machines join.
+	ClusterName string
+
+	// Namespace holds the Cluster API objects of the cluster.
+	Namespace string
+
+	// Version is the Kubernetes version of the gateway machines, typically that of the worker machines.
+	Version string
+
+	// BootstrapConfigTemplate is the name of the KubeadmConfigTemplate bootstrapping the worker machines. The gateway
+	// machines are bootstrapped with a copy of it which adds the gateway node labels and taints.
+	BootstrapConfigTemplate string
+}
+
+// capiInfrastructureTemplate translates the OpenShift provider spec of a kind into a Cluster API machine template.
+type capiInfrastructureTemplate struct {
+	gvk schema.GroupVersionKind
+
+	// translate returns the spec of the machine template and the failure domain of the machines.
+	translate func(providerSpec map[string]interface{}) (map[string]interface{}, string)
+
+	// imagePath is where the machine template holds the image of its machines.
+	imagePath []string
+}
+
+var capiInfrastructureTemplates = map[string]capiInfrastructureTemplate{
+	"AWSMachineProviderConfig": {
+		gvk: schema.GroupVersionKind{
+			Group:   "infrastructure.cluster.x-k8s.io",
+			Version: "v1beta2",
+			Kind:    "AWSMachineTemplate",
+		},
+		translate: awsMachineTemplateSpec,
+		imagePath: []string{"spec", "template", "spec", "ami", "id"},
+	},
+	"GCPMachineProviderSpec": {
+		gvk: schema.GroupVersionKind{
+			Group:   "infrastructure.cluster.x-k8s.io",
+			Version: "v1beta1",
+			Kind:    "GCPMachineTemplate",
+		},
+		translate: gcpMachineTemplateSpec,
+		imagePath: []string{"spec", "template", "spec", "image"},
+	},
+	"OpenstackProviderSpec": {
+		gvk: schema.GroupVersionKind{
+			Group:   "infrastructure.cluster.x-k8s.io",
+			Version: "v1alpha7",
+			Kind:    "OpenStackMachineTemplate",
+		},
+		translate: openStackMachineTemplateSpec,
+		imagePath: []string{"spec", "template", "spec", "image"},
+	},
+}
+
+type capiMachineDeploymentDeployer struct {
+	restMapper    meta.RESTMapper
+	dynamicClient dynamic.Interface
+	config        CAPIConfig
+}
+
+// NewCAPIMachineDeploymentDeployer returns a MachineSetDeployer which deploys the given OpenShift machine sets as Cluster API
+// MachineDeployments, along with the provider machine templates translated from their provider specs and the
+// bootstrap templates applying their node labels and taints. AWS, GCP and OpenStack provider specs are supported.
+func NewCAPIMachineDeploymentDeployer(restMapper meta.RESTMapper, dynamicClient dynamic.Interface, config CAPIConfig) MachineSetDeployer {
+	return &capiMachineDeploymentDeployer{
+		restMapper:    restMapper,
+		dynamicClient: dynamicClient,
+		config:        config,
+	}
+}
+
+func (d *capiMachineDeploymentDeployer) clientFor(gvk schema.GroupVersionKind) (dynamic.ResourceInterface, error) {
+	mapping, err := d.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting the REST mapping for %#v", gvk)
+	}
+
+	return d.dynamicClient.Resource(mapping.Resource).Namespace(d.config.Namespace), nil
+}
+
+func (d *capiMachineDeploymentDeployer) Deploy(machineSet *unstructured.Unstructured) error {
+	return d.DeployWithContext(context.Background(), machineSet)
+}
+
+func (d *capiMachineDeploymentDeployer) DeployWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	infraTemplate, failureDomain, err := d.newInfrastructureTemplate(machineSet)
+	if err != nil {
+		return err
+	}
+
+	bootstrapTemplate, err := d.newBootstrapTemplate(ctx, machineSet)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range []*unstructured.Unstructured{
+		infraTemplate, bootstrapTemplate, d.newMachineDeployment(machineSet, infraTemplate, failureDomain),
+	} {
+		client, err := d.clientFor(obj.GroupVersionKind())
+		if err != nil {
+			return err
+		}
+
+		_, err = util.CreateOrUpdate(ctx, resource.ForDynamic(client), obj, util.Replace(obj))
+		if err != nil {
+			return errors.Wrapf(err, "error creating %s %q", obj.GetKind(), obj.GetName())
+		}
+	}
+
+	return nil
+}
+
+func (d *capiMachineDeploymentDeployer) newInfrastructureTemplate(machineSet *unstructured.Unstructured) (*unstructured.Unstructured,
+	string, error) {
+	providerSpec, _, _ := unstructured.NestedMap(machineSet.Object, "spec", "template", "spec", "providerSpec", "value")
+	kind, _, _ := unstructured.NestedString(providerSpec, "kind")
+
+	template, found := capiInfrastructureTemplates[kind]
+	if !found {
+		return nil, "", fmt.Errorf("machine set %q has an unsupported provider spec kind %q", machineSet.GetName(), kind)
+	}
+
+	spec, failureDomain := template.translate(providerSpec)
+
+	infraTemplate := d.newObject(template.gvk, machineSet.GetName())
+	infraTemplate.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{"spec": spec},
+	}
+
+	return infraTemplate, failureDomain, nil
+}
+
+// newBootstrapTemplate copies the workers' bootstrap template, making kubelet register the gateway nodes with the
+// labels and taints of the machine set.
+func (d *capiMachineDeploymentDeployer) newBootstrapTemplate(ctx context.Context, machineSet *unstructured.Unstructured) (
+	*unstructured.Unstructured, error) {
+	client, err := d.clientFor(kubeadmConfigTemplateGVK)
+	if err != nil {
+		return nil, err
+	}
+
+	workerTemplate, err := client.Get(ctx, d.config.BootstrapConfigTemplate, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving the worker bootstrap template %q", d.config.BootstrapConfigTemplate)
+	}
+
+	nodeRegistration := []string{"spec", "template", "spec", "joinConfiguration", "nodeRegistration"}
+
+	bootstrapTemplate := d.newObject(kubeadmConfigTemplateGVK, machineSet.GetName())
+	bootstrapTemplate.Object["spec"] = workerTemplate.Object["spec"]
+
+	nodeLabels, _, _ := unstructured.NestedStringMap(machineSet.Object, "spec", "template", "spec", "metadata", "labels")
+	if len(nodeLabels) > 0 {
+		extraArgs := append(append([]string{}, nodeRegistration...), "kubeletExtraArgs")
+
+		kubeletArgs, _, _ := unstructured.NestedStringMap(bootstrapTemplate.Object, extraArgs...)
+		if kubeletArgs == nil {
+			kubeletArgs = map[string]string{}
+		}
+
+		kubeletArgs["node-labels"] = joinNodeLabels(kubeletArgs["node-labels"], nodeLabels)
+
+		err = unstructured.SetNestedStringMap(bootstrapTemplate.Object, kubeletArgs, extraArgs...)
+		if err != nil {
+			return nil, errors.Wrap(err, "error setting the node labels")
+		}
+	}
+
+	taints, _, _ := unstructured.NestedSlice(machineSet.Object, "spec", "template", "spec", "taints")
+	if len(taints) > 0 {
+		taintsPath := append(append([]string{}, nodeRegistration...), "taints")
+
+		existing, _, _ := unstructured.NestedSlice(bootstrapTemplate.Object, taintsPath...)
+
+		err = unstructured.SetNestedSlice(bootstrapTemplate.Object, append(existing, taints...), taintsPath...)
+		if err != nil {
+			return nil, errors.Wrap(err, "error setting the node taints")
+		}
+	}
+
+	return bootstrapTemplate, nil
+}
+
+func joinNodeLabels(existing string, nodeLabels map[string]string) string {
+	pairs := []string{}
+	if existing != "" {
+		pairs = append(pairs, existing)
+	}
+
+	keys := make([]string, 0, len(nodeLabels))
+	for key := range nodeLabels {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+nodeLabels[key])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (d *capiMachineDeploymentDeployer) newMachineDeployment(machineSet, infraTemplate *unstructured.Unstructured,
+	failureDomain string) *unstructured.Unstructured {
+	machineDeployment := d.newObject(machineDeploymentGVK, machineSet.GetName())
+
+	mdLabels := map[string]string{capiClusterNameLabel: d.config.ClusterName}
+	for key, value := range machineSet.GetLabels() {
+		mdLabels[key] = value
+	}
+
+	machineDeployment.SetLabels(mdLabels)
+
+	replicas, found, _ := unstructured.NestedInt64(machineSet.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+
+	machineLabels := map[string]interface{}{
+		capiClusterNameLabel:    d.config.ClusterName,
+		capiDeploymentNameLabel: machineSet.GetName(),
+	}
+
+	machineSpec := map[string]interface{}{
+		"clusterName": d.config.ClusterName,
+		"bootstrap": map[string]interface{}{
+			"configRef": map[string]interface{}{
+				"apiVersion": kubeadmConfigTemplateGVK.GroupVersion().String(),
+				"kind":       kubeadmConfigTemplateGVK.Kind,
+				"name":       machineSet.GetName(),
+			},
+		},
+		"infrastructureRef": map[string]interface{}{
+			"apiVersion": infraTemplate.GetAPIVersion(),
+			"kind":       infraTemplate.GetKind(),
+			"name":       infraTemplate.GetName(),
+		},
+	}
+
+	if d.config.Version != "" {
+		machineSpec["version"] = d.config.Version
+	}
+
+	if failureDomain != "" {
+		machineSpec["failureDomain"] = failureDomain
+	}
+
+	machineDeployment.Object["spec"] = map[string]interface{}{
+		"clusterName": d.config.ClusterName,
+		"replicas":    replicas,
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{capiDeploymentNameLabel: machineSet.GetName()},
+		},
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": machineLabels},
+			"spec":     machineSpec,
+		},
+	}
+
+	return machineDeployment
+}
+
+func (d *capiMachineDeploymentDeployer) newObject(gvk schema.GroupVersionKind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(d.config.Namespace)
+
+	return obj
+}
+
+func (d *capiMachineDeploymentDeployer) Delete(machineSet *unstructured.Unstructured) error {
+	return d.DeleteWithContext(context.Background(), machineSet)
+}
+
+func (d *capiMachineDeploymentDeployer) DeleteWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	// The MachineDeployment goes first, its machines still referencing the templates while they're deleted.
+	gvks := []schema.GroupVersionKind{machineDeploymentGVK, kubeadmConfigTemplateGVK}
+
+	providerSpecKind, _, _ := unstructured.NestedString(machineSet.Object, "spec", "template", "spec", "providerSpec", "value", "kind")
+	if template, found := capiInfrastructureTemplates[providerSpecKind]; found {
+		gvks = append(gvks, template.gvk)
+	}
+
+	for _, gvk := range gvks {
+		client, err := d.clientFor(gvk)
+		if err != nil {
+			return err
+		}
+
+		err = client.Delete(ctx, machineSet.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "error deleting %s %q", gvk.Kind, machineSet.GetName())
+		}
+	}
+
+	return nil
+}
+
+// List returns the MachineDeployments of the cluster which carry all the labels of the given machine set.
+func (d *capiMachineDeploymentDeployer) List(ctx context.Context, machineSet *unstructured.Unstructured) ([]unstructured.Unstructured,
+	error) {
+	client, err := d.clientFor(machineDeploymentGVK)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := map[string]string{capiClusterNameLabel: d.config.ClusterName}
+	for key, value := range machineSet.GetLabels() {
+		selector[key] = value
+	}
+
+	list, err := client.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing machine deployments in %q", d.config.Namespace)
+	}
+
+	return list.Items, nil
+}
+
+// GetWorkerNodeImage returns the image of the machine template of one of the cluster's MachineDeployments, other than
+// the gateway ones. The worker node names aren't used since MachineDeployments aren't named after them.
+func (d *capiMachineDeploymentDeployer) GetWorkerNodeImage(workerNodeList []string, machineSet *unstructured.Unstructured,
+	infraID string) (string, error) {
+	return d.GetWorkerNodeImageWithContext(context.Background(), workerNodeList, machineSet, infraID)
+}
+
+// GetWorkerNodeImageWithContext is GetWorkerNodeImage, bound to the given context.
+func (d *capiMachineDeploymentDeployer) GetWorkerNodeImageWithContext(ctx context.Context, _ []string, _ *unstructured.Unstructured,
+	infraID string) (string, error) {
+	client, err := d.clientFor(machineDeploymentGVK)
+	if err != nil {
+		return "", err
+	}
+
+	list, err := client.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{capiClusterNameLabel: d.config.ClusterName}).String(),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "error listing machine deployments in %q", d.config.Namespace)
+	}
+
+	for i := range list.Items {
+		if strings.HasPrefix(list.Items[i].GetName(), infraID+"-submariner-gw-") {
+			continue
+		}
+
+		image, err := d.machineTemplateImage(ctx, &list.Items[i])
+		if err != nil || image != "" {
+			return image, err
+		}
+	}
+
+	return "", fmt.Errorf("could not retrieve the image of one of the worker nodes from the infra %q", infraID)
+}
+
+func (d *capiMachineDeploymentDeployer) machineTemplateImage(ctx context.Context, machineDeployment *unstructured.Unstructured) (
+	string, error) {
+	ref, _, _ := unstructured.NestedStringMap(machineDeployment.Object, "spec", "template", "spec", "infrastructureRef")
+
+	for _, template := range capiInfrastructureTemplates {
+		if template.gvk.Kind != ref["kind"] {
+			continue
+		}
+
+		client, err := d.clientFor(template.gvk)
+		if err != nil {
+			return "", err
+		}
+
+		infraTemplate, err := client.Get(ctx, ref["name"], metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+
+		if err != nil {
+			return "", errors.Wrapf(err, "error retrieving %s %q", ref["kind"], ref["name"])
+		}
+
+		image, _, _ := unstructured.NestedString(infraTemplate.Object, template.imagePath...)
+
+		return image, nil
+	}
+
+	return "", nil
+}
+
+func awsMachineTemplateSpec(providerSpec map[string]interface{}) (map[string]interface{}, string) {
+	spec := map[string]interface{}{}
+
+	copyNestedField(providerSpec, spec, []string{"instanceType"}, "instanceType")
+	copyNestedField(providerSpec, spec, []string{"ami", "id"}, "ami", "id")
+	copyNestedField(providerSpec, spec, []string{"iamInstanceProfile", "id"}, "iamInstanceProfile")
+	copyNestedField(providerSpec, spec, []string{"publicIp"}, "publicIP")
+	copyNestedField(providerSpec, spec, []string{"subnet"}, "subnet")
+	copyNestedField(providerSpec, spec, []string{"securityGroups"}, "additionalSecurityGroups")
+
+	tags, _, _ := unstructured.NestedSlice(providerSpec, "tags")
+	additionalTags := map[string]interface{}{}
+
+	for _, t := range tags {
+		if tag, ok := t.(map[string]interface{}); ok {
+			additionalTags[fmt.Sprint(tag["name"])] = fmt.Sprint(tag["value"])
+		}
+	}
+
+	if len(additionalTags) > 0 {
+		spec["additionalTags"] = additionalTags
+	}
+
+	zone, _, _ := unstructured.NestedString(providerSpec, "placement", "availabilityZone")
+
+	return spec, zone
+}
+
+func gcpMachineTemplateSpec(providerSpec map[string]interface{}) (map[string]interface{}, string) {
+	spec := map[string]interface{}{}
+
+	copyNestedField(providerSpec, spec, []string{"machineType"}, "instanceType")
+	copyNestedField(providerSpec, spec, []string{"tags"}, "additionalNetworkTags")
+
+	disks, _, _ := unstructured.NestedSlice(providerSpec, "disks")
+	for _, d := range disks {
+		if disk, ok := d.(map[string]interface{}); ok && disk["boot"] == true {
+			copyNestedField(disk, spec, []string{"image"}, "image")
+			copyNestedField(disk, spec, []string{"sizeGb"}, "rootDeviceSize")
+		}
+	}
+
+	networkInterfaces, _, _ := unstructured.NestedSlice(providerSpec, "networkInterfaces")
+	if len(networkInterfaces) > 0 {
+		if networkInterface, ok := networkInterfaces[0].(map[string]interface{}); ok {
+			copyNestedField(networkInterface, spec, []string{"publicIP"}, "publicIP")
+			copyNestedField(networkInterface, spec, []string{"subnetwork"}, "subnet")
+		}
+	}
+
+	serviceAccounts, _, _ := unstructured.NestedSlice(providerSpec, "serviceAccounts")
+	if len(serviceAccounts) > 0 {
+		spec["serviceAccounts"] = serviceAccounts[0]
+	}
+
+	zone, _, _ := unstructured.NestedString(providerSpec, "zone")
+
+	return spec, zone
+}
+
+func openStackMachineTemplateSpec(providerSpec map[string]interface{}) (map[string]interface{}, string) {
+	spec := map[string]interface{}{}
+
+	copyNestedField(providerSpec, spec, []string{"flavor"}, "flavor")
+	copyNestedField(providerSpec, spec, []string{"image"}, "image")
+	copyNestedField(providerSpec, spec, []string{"cloudName"}, "cloudName")
+	copyNestedField(providerSpec, spec, []string{"tags"}, "tags")
+	copyNestedField(providerSpec, spec, []string{"trunk"}, "trunk")
+
+	if secret, found, _ := unstructured.NestedString(providerSpec, "cloudsSecret", "name"); found {
+		spec["identityRef"] = map[string]interface{}{"kind": "Secret", "name": secret}
+	}
+
+	securityGroups, _, _ := unstructured.NestedSlice(providerSpec, "securityGroups")
+	groups := []interface{}{}
+
+	for _, g := range securityGroups {
+		if group, ok := g.(map[string]interface{}); ok && group["name"] != nil {
+			groups = append(groups, map[string]interface{}{"name": group["name"]})
+		}
+	}
+
+	if len(groups) > 0 {
+		spec["securityGroups"] = groups
+	}
+
+	zone, _, _ := unstructured.NestedString(providerSpec, "availabilityZone")
+
+	return spec, zone
+}
+
+// copyNestedField copies the field at the given path of the source, if set, to the given path of the destination.
+func copyNestedField(from, to map[string]interface{}, fromPath []string, toPath ...string) {
+	value, found, _ := unstructured.NestedFieldCopy(from, fromPath...)
+	if found && value != nil {
+		_ = unstructured.SetNestedField(to, value, toPath...)
+	}
+}
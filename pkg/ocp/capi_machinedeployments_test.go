@@ -0,0 +1,263 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocp_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	fakeClient "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("CAPI MachineDeployment MachineSetDeployer", func() {
+	const (
+		clusterName    = "test-cluster"
+		namespace      = "test-ns"
+		machineSetName = "test-infraID-submariner-gw-us-east-1a"
+	)
+
+	var (
+		dynClient  *fakeClient.FakeDynamicClient
+		deployer   ocp.MachineSetDeployer
+		machineSet *unstructured.Unstructured
+		clients    map[string]dynamic.ResourceInterface
+	)
+
+	BeforeEach(func() {
+		machineSet = newGatewayMachineSet(machineSetName, map[string]interface{}{
+			"kind":         "AWSMachineProviderConfig",
+			"instanceType": "c5d.large",
+			"ami":          map[string]interface{}{"id": "ami-123"},
+			"publicIp":     true,
+			"placement":    map[string]interface{}{"availabilityZone": "us-east-1a", "region": "us-east-1"},
+			"tags": []interface{}{
+				map[string]interface{}{"name": "submariner.io", "value": "gateway"},
+			},
+		})
+
+		objs := []*unstructured.Unstructured{
+			newCAPIObject("cluster.x-k8s.io", "v1beta1", "MachineDeployment"),
+			newCAPIObject("bootstrap.cluster.x-k8s.io", "v1beta1", "KubeadmConfigTemplate"),
+			newCAPIObject("infrastructure.cluster.x-k8s.io", "v1beta2", "AWSMachineTemplate"),
+			newCAPIObject("infrastructure.cluster.x-k8s.io", "v1beta1", "GCPMachineTemplate"),
+			newCAPIObject("infrastructure.cluster.x-k8s.io", "v1alpha7", "OpenStackMachineTemplate"),
+		}
+
+		restMapper := test.GetRESTMapperFor(objs[0], objs[1], objs[2], objs[3], objs[4])
+		dynClient = fakeClient.NewSimpleDynamicClient(scheme.Scheme)
+		clients = map[string]dynamic.ResourceInterface{}
+
+		for _, obj := range objs {
+			clients[obj.GetKind()] = dynClient.Resource(*test.GetGroupVersionResourceFor(restMapper, obj)).Namespace(namespace)
+		}
+
+		workerTemplate := newCAPIObject("bootstrap.cluster.x-k8s.io", "v1beta1", "KubeadmConfigTemplate")
+		workerTemplate.SetName("workers")
+		workerTemplate.Object["spec"] = map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"joinConfiguration": map[string]interface{}{
+						"nodeRegistration": map[string]interface{}{
+							"kubeletExtraArgs": map[string]interface{}{"node-labels": "tier=worker"},
+						},
+					},
+				},
+			},
+		}
+
+		_, err := clients["KubeadmConfigTemplate"].Create(context.TODO(), workerTemplate, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+
+		deployer = ocp.NewCAPIMachineDeploymentDeployer(restMapper, dynClient, ocp.CAPIConfig{
+			ClusterName:             clusterName,
+			Namespace:               namespace,
+			Version:                 "v1.25.3",
+			BootstrapConfigTemplate: "workers",
+		})
+	})
+
+	get := func(kind, name string) *unstructured.Unstructured {
+		obj, err := clients[kind].Get(context.TODO(), name, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+
+		return obj
+	}
+
+	Context("on Deploy", func() {
+		It("should create the MachineDeployment referencing its templates", func() {
+			Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+
+			md := get("MachineDeployment", machineSetName)
+			Expect(md.GetLabels()).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", clusterName))
+
+			replicas, _, _ := unstructured.NestedInt64(md.Object, "spec", "replicas")
+			Expect(replicas).To(Equal(int64(1)))
+
+			spec, _, _ := unstructured.NestedMap(md.Object, "spec", "template", "spec")
+			Expect(spec).To(HaveKeyWithValue("clusterName", clusterName))
+			Expect(spec).To(HaveKeyWithValue("version", "v1.25.3"))
+			Expect(spec).To(HaveKeyWithValue("failureDomain", "us-east-1a"))
+
+			kind, _, _ := unstructured.NestedString(spec, "infrastructureRef", "kind")
+			Expect(kind).To(Equal("AWSMachineTemplate"))
+		})
+
+		It("should translate the provider spec to a machine template", func() {
+			Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+
+			spec, _, _ := unstructured.NestedMap(get("AWSMachineTemplate", machineSetName).Object, "spec", "template", "spec")
+			Expect(spec).To(HaveKeyWithValue("instanceType", "c5d.large"))
+			Expect(spec).To(HaveKeyWithValue("publicIP", true))
+			Expect(spec).To(HaveKeyWithValue("ami", map[string]interface{}{"id": "ami-123"}))
+			Expect(spec).To(HaveKeyWithValue("additionalTags", map[string]interface{}{"submariner.io": "gateway"}))
+		})
+
+		It("should bootstrap the nodes with the gateway labels and taints", func() {
+			Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+
+			nodeRegistration, _, _ := unstructured.NestedMap(get("KubeadmConfigTemplate", machineSetName).Object,
+				"spec", "template", "spec", "joinConfiguration", "nodeRegistration")
+			Expect(nodeRegistration["kubeletExtraArgs"]).To(HaveKeyWithValue("node-labels", "tier=worker,submariner.io/gateway=true"))
+			Expect(nodeRegistration["taints"]).To(HaveLen(1))
+		})
+
+		When("the provider spec is for GCP", func() {
+			BeforeEach(func() {
+				machineSet = newGatewayMachineSet(machineSetName, map[string]interface{}{
+					"kind":        "GCPMachineProviderSpec",
+					"machineType": "n1-standard-4",
+					"zone":        "us-east1-b",
+					"disks": []interface{}{
+						map[string]interface{}{"boot": true, "image": "test-image", "sizeGb": int64(128)},
+					},
+				})
+			})
+
+			It("should create a GCP machine template", func() {
+				Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+
+				spec, _, _ := unstructured.NestedMap(get("GCPMachineTemplate", machineSetName).Object, "spec", "template", "spec")
+				Expect(spec).To(HaveKeyWithValue("instanceType", "n1-standard-4"))
+				Expect(spec).To(HaveKeyWithValue("image", "test-image"))
+				Expect(spec).To(HaveKeyWithValue("rootDeviceSize", int64(128)))
+			})
+		})
+
+		When("the provider spec isn't supported", func() {
+			BeforeEach(func() {
+				machineSet = newGatewayMachineSet(machineSetName, map[string]interface{}{"kind": "UnknownProviderSpec"})
+			})
+
+			It("should return an error", func() {
+				Expect(deployer.DeployWithContext(context.TODO(), machineSet)).ToNot(Succeed())
+			})
+		})
+	})
+
+	Context("on Delete", func() {
+		It("should delete the MachineDeployment and its templates", func() {
+			Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+			Expect(deployer.DeleteWithContext(context.TODO(), machineSet)).To(Succeed())
+
+			for _, kind := range []string{"MachineDeployment", "KubeadmConfigTemplate", "AWSMachineTemplate"} {
+				_, err := clients[kind].Get(context.TODO(), machineSetName, metav1.GetOptions{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+
+		It("should succeed if they don't exist", func() {
+			Expect(deployer.DeleteWithContext(context.TODO(), machineSet)).To(Succeed())
+		})
+	})
+
+	Context("on List", func() {
+		It("should return the MachineDeployments carrying the labels", func() {
+			Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+
+			machineDeployments, err := deployer.List(context.TODO(), machineSet)
+			Expect(err).To(Succeed())
+			Expect(machineDeployments).To(HaveLen(1))
+			Expect(machineDeployments[0].GetName()).To(Equal(machineSetName))
+		})
+	})
+
+	Context("on GetWorkerNodeImage", func() {
+		It("should return the image of a worker MachineDeployment", func() {
+			worker := newGatewayMachineSet("workers", map[string]interface{}{
+				"kind":        "GCPMachineProviderSpec",
+				"machineType": "n1-standard-4",
+				"disks": []interface{}{
+					map[string]interface{}{"boot": true, "image": "worker-image"},
+				},
+			})
+			Expect(deployer.DeployWithContext(context.TODO(), worker)).To(Succeed())
+
+			image, err := deployer.GetWorkerNodeImageWithContext(context.TODO(), nil, machineSet, "test-infraID")
+			Expect(err).To(Succeed())
+			Expect(image).To(Equal("worker-image"))
+		})
+
+		It("should return an error if there are no workers", func() {
+			Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+
+			_, err := deployer.GetWorkerNodeImageWithContext(context.TODO(), nil, machineSet, "test-infraID")
+			Expect(err).ToNot(Succeed())
+		})
+	})
+})
+
+func newCAPIObject(group, version, kind string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: group, Version: version, Kind: kind})
+	obj.SetNamespace("test-ns")
+
+	return obj
+}
+
+func newGatewayMachineSet(name string, providerSpec map[string]interface{}) *unstructured.Unstructured {
+	ms := newMachineSet()
+	ms.SetName(name)
+	ms.SetNamespace("openshift-machine-api")
+	ms.SetLabels(map[string]string{"machine.openshift.io/cluster-api-cluster": "test-infraID"})
+	ms.Object["spec"] = map[string]interface{}{
+		"replicas": int64(1),
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"submariner.io/gateway": "true"},
+				},
+				"taints": []interface{}{
+					map[string]interface{}{"effect": "NoSchedule", "key": "node-role.submariner.io/gateway"},
+				},
+				"providerSpec": map[string]interface{}{"value": providerSpec},
+			},
+		},
+	}
+
+	return ms
+}
@@ -22,7 +22,9 @@ limitations under the License.
 package fake
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -65,6 +67,20 @@ func (mr *MockMachineSetDeployerMockRecorder) Delete(machineSet interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMachineSetDeployer)(nil).Delete), machineSet)
 }
 
+// DeleteWithContext mocks base method.
+func (m *MockMachineSetDeployer) DeleteWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithContext", ctx, machineSet)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWithContext indicates an expected call of DeleteWithContext.
+func (mr *MockMachineSetDeployerMockRecorder) DeleteWithContext(ctx, machineSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithContext", reflect.TypeOf((*MockMachineSetDeployer)(nil).DeleteWithContext), ctx, machineSet)
+}
+
 // Deploy mocks base method.
 func (m *MockMachineSetDeployer) Deploy(machineSet *unstructured.Unstructured) error {
 	m.ctrl.T.Helper()
@@ -79,17 +95,99 @@ func (mr *MockMachineSetDeployerMockRecorder) Deploy(machineSet interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deploy", reflect.TypeOf((*MockMachineSetDeployer)(nil).Deploy), machineSet)
 }
 
+// DeployWithContext mocks base method.
+func (m *MockMachineSetDeployer) DeployWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeployWithContext", ctx, machineSet)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeployWithContext indicates an expected call of DeployWithContext.
+func (mr *MockMachineSetDeployerMockRecorder) DeployWithContext(ctx, machineSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployWithContext", reflect.TypeOf((*MockMachineSetDeployer)(nil).DeployWithContext), ctx, machineSet)
+}
+
 // GetWorkerNodeImage mocks base method.
 func (m *MockMachineSetDeployer) GetWorkerNodeImage(workerNodeList []string, machineSet *unstructured.Unstructured, infraID string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWorkerNodeImage", machineSet, infraID)
+	ret := m.ctrl.Call(m, "GetWorkerNodeImage", workerNodeList, machineSet, infraID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWorkerNodeImage indicates an expected call of GetWorkerNodeImage.
-func (mr *MockMachineSetDeployerMockRecorder) GetWorkerNodeImage(machineSet, infraID interface{}) *gomock.Call {
+func (mr *MockMachineSetDeployerMockRecorder) GetWorkerNodeImage(workerNodeList, machineSet, infraID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkerNodeImage", reflect.TypeOf((*MockMachineSetDeployer)(nil).GetWorkerNodeImage), workerNodeList, machineSet, infraID)
+}
+
+// GetWorkerNodeImageWithContext mocks base method.
+func (m *MockMachineSetDeployer) GetWorkerNodeImageWithContext(ctx context.Context, workerNodeList []string, machineSet *unstructured.Unstructured, infraID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkerNodeImageWithContext", ctx, workerNodeList, machineSet, infraID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkerNodeImageWithContext indicates an expected call of GetWorkerNodeImageWithContext.
+func (mr *MockMachineSetDeployerMockRecorder) GetWorkerNodeImageWithContext(ctx, workerNodeList, machineSet, infraID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkerNodeImageWithContext", reflect.TypeOf((*MockMachineSetDeployer)(nil).GetWorkerNodeImageWithContext), ctx, workerNodeList, machineSet, infraID)
+}
+
+// List mocks base method.
+func (m *MockMachineSetDeployer) List(ctx context.Context, machineSet *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, machineSet)
+	ret0, _ := ret[0].([]unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMachineSetDeployerMockRecorder) List(ctx, machineSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMachineSetDeployer)(nil).List), ctx, machineSet)
+}
+
+// MockSpotMachineSetDeployer is a mock of SpotMachineSetDeployer interface.
+type MockSpotMachineSetDeployer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSpotMachineSetDeployerMockRecorder
+}
+
+// MockSpotMachineSetDeployerMockRecorder is the mock recorder for MockSpotMachineSetDeployer.
+type MockSpotMachineSetDeployerMockRecorder struct {
+	mock *MockSpotMachineSetDeployer
+}
+
+// NewMockSpotMachineSetDeployer creates a new mock instance.
+func NewMockSpotMachineSetDeployer(ctrl *gomock.Controller) *MockSpotMachineSetDeployer {
+	mock := &MockSpotMachineSetDeployer{ctrl: ctrl}
+	mock.recorder = &MockSpotMachineSetDeployerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSpotMachineSetDeployer) EXPECT() *MockSpotMachineSetDeployerMockRecorder {
+	return m.recorder
+}
+
+// DeploySpot mocks base method.
+func (m *MockSpotMachineSetDeployer) DeploySpot(ctx context.Context, spot, onDemand *unstructured.Unstructured, timeout time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeploySpot", ctx, spot, onDemand, timeout)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeploySpot indicates an expected call of DeploySpot.
+func (mr *MockSpotMachineSetDeployerMockRecorder) DeploySpot(ctx, spot, onDemand, timeout interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkerNodeImage", reflect.TypeOf((*MockMachineSetDeployer)(nil).GetWorkerNodeImage), machineSet, infraID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeploySpot", reflect.TypeOf((*MockSpotMachineSetDeployer)(nil).DeploySpot), ctx, spot, onDemand, timeout)
 }
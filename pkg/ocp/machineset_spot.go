@@ -0,0 +1,229 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ocp
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultSpotTimeout is how long spot capacity is waited for when SpotConfig doesn't specify a timeout.
+const DefaultSpotTimeout = 10 * time.Minute
+
+// SpotConfig requests gateways on spot (AWS) or preemptible (GCP) instances.
+type SpotConfig struct {
+	// Timeout bounds the wait for the spot instances to be provisioned; once elapsed, the gateway falls back to
+	// on-demand instances. Zero uses DefaultSpotTimeout.
+	Timeout time.Duration
+}
+
+// SpotInstanceRequester is implemented by the gateway deployers which can deploy gateways on spot or preemptible
+// instances.
+type SpotInstanceRequester interface {
+	// SetSpotInstances requests spot or preemptible gateway instances; nil reverts to on-demand instances.
+	SetSpotInstances(config *SpotConfig)
+}
+
+// DeploySpotMachineSet deploys the given spot machine set if spot instances are requested by the given configuration,
+// falling back to the given on-demand machine set if spot capacity can't be obtained in time. Without configuration,
+// the on-demand machine set is deployed. It returns whether the gateway runs on spot instances.
+func DeploySpotMachineSet(ctx context.Context, msDeployer MachineSetDeployer, config *SpotConfig,
+	spot, onDemand *unstructured.Unstructured) (bool, error) {
+	if config == nil {
+		return false, msDeployer.DeployWithContext(ctx, onDemand) // nolint:wrapcheck // Let the caller wrap it.
+	}
+
+	spotDeployer, ok := msDeployer.(SpotMachineSetDeployer)
+	if !ok {
+		return false, errors.New("the machine set deployer doesn't support spot instances")
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = DefaultSpotTimeout
+	}
+
+	return spotDeployer.DeploySpot(ctx, spot, onDemand, timeout) // nolint:wrapcheck // Let the caller wrap it.
+}
+
+func (msd *k8sMachineSetDeployer) DeploySpot(ctx context.Context, spot, onDemand *unstructured.Unstructured,
+	timeout time.Duration) (bool, error) {
+	machineSetClient, err := msd.clientFor(spot)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := machineSetClient.Get(ctx, spot.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "error retrieving machine set %q", spot.GetName())
+	}
+
+	// A machine set which already fell back to on-demand instances keeps them.
+	if err == nil && !hasSpotFields(existing, spot, onDemand) {
+		return false, msd.DeployWithContext(ctx, onDemand)
+	}
+
+	err = msd.createOrUpdate(ctx, spot)
+	if err != nil {
+		return false, err
+	}
+
+	provisioned, err := msd.waitForMachinesProvisioned(ctx, spot, timeout)
+	if err != nil {
+		return false, err
+	}
+
+	machineSet := spot
+
+	if !provisioned {
+		err = msd.createOrUpdate(ctx, onDemand)
+		if err != nil {
+			return false, err
+		}
+
+		err = msd.deleteUnprovisionedMachines(ctx, onDemand)
+		if err != nil {
+			return false, err
+		}
+
+		machineSet = onDemand
+	}
+
+	if msd.wait != nil && !msd.wait.SkipDeploy {
+		return provisioned, msd.waitForGatewayNodes(ctx, machineSet)
+	}
+
+	return provisioned, nil
+}
+
+// waitForMachinesProvisioned waits up to the given timeout for the given machine set's replicas to be provisioned
+// machines. It returns false if the timeout elapses or one of the machines fails.
+func (msd *k8sMachineSetDeployer) waitForMachinesProvisioned(ctx context.Context, machineSet *unstructured.Unstructured,
+	timeout time.Duration) (bool, error) {
+	replicas, found, _ := unstructured.NestedInt64(machineSet.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+
+	machineClient, err := msd.machineClientFor(machineSet)
+	if err != nil {
+		return false, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	failed := false
+
+	err = wait.PollImmediateUntil(machinePollInterval, func() (bool, error) {
+		machines, err := listMachines(waitCtx, machineClient, machineSet)
+		if err != nil {
+			return false, err
+		}
+
+		provisioned := int64(0)
+
+		for i := range machines.Items {
+			switch machinePhase(&machines.Items[i]) {
+			case "Failed":
+				failed = true
+				return true, nil
+			case "Provisioned", "Running":
+				provisioned++
+			}
+		}
+
+		return provisioned >= replicas, nil
+	}, waitCtx.Done())
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return false, nil
+	}
+
+	return !failed && err == nil, err
+}
+
+// deleteUnprovisionedMachines deletes the machines of the given machine set which aren't provisioned, so that the machine
+// set recreates them from its current template.
+func (msd *k8sMachineSetDeployer) deleteUnprovisionedMachines(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	machineClient, err := msd.machineClientFor(machineSet)
+	if err != nil {
+		return err
+	}
+
+	machines, err := listMachines(ctx, machineClient, machineSet)
+	if err != nil {
+		return err
+	}
+
+	for i := range machines.Items {
+		phase := machinePhase(&machines.Items[i])
+		if phase == "Provisioned" || phase == "Running" {
+			continue
+		}
+
+		err = machineClient.Delete(ctx, machines.Items[i].GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "error deleting %s machine %q", phase, machines.Items[i].GetName())
+		}
+	}
+
+	return nil
+}
+
+// hasSpotFields returns whether the given machine set's provider spec carries the fields which distinguish the given spot
+// machine set from the on-demand one.
+func hasSpotFields(machineSet, spot, onDemand *unstructured.Unstructured) bool {
+	providerSpec := []string{"spec", "template", "spec", "providerSpec", "value"}
+	spotSpec, _, _ := unstructured.NestedMap(spot.Object, providerSpec...)
+	onDemandSpec, _, _ := unstructured.NestedMap(onDemand.Object, providerSpec...)
+	existingSpec, _, _ := unstructured.NestedMap(machineSet.Object, providerSpec...)
+
+	for key, value := range spotSpec {
+		if !equality.Semantic.DeepEqual(value, onDemandSpec[key]) && !equality.Semantic.DeepEqual(value, existingSpec[key]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func machinePhase(machine *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(machine.Object, "status", "phase")
+	if phase == "" {
+		return "Pending"
+	}
+
+	return phase
+}
+
+// InstanceKind describes whether a gateway runs on spot or on-demand instances.
+func InstanceKind(spot bool) string {
+	if spot {
+		return "spot"
+	}
+
+	return "on-demand"
+}
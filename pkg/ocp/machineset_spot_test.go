@@ -0,0 +1,179 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ocp_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	fakeClient "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("DeploySpotMachineSet", func() {
+	const (
+		machineSetName = "test-infraID-submariner-gw-zone1"
+		machineName    = machineSetName + "-abcde"
+	)
+
+	var (
+		machineSetClient dynamic.ResourceInterface
+		machineClient    dynamic.ResourceInterface
+		deployer         ocp.MachineSetDeployer
+		config           *ocp.SpotConfig
+		onDemand         *unstructured.Unstructured
+		spot             *unstructured.Unstructured
+		existing         *unstructured.Unstructured
+		machine          *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		onDemand = newMachineSet()
+		onDemand.SetName(machineSetName)
+		onDemand.Object["spec"] = map[string]interface{}{
+			"replicas": int64(1),
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"machine.openshift.io/cluster-api-machineset": machineSetName},
+			},
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"providerSpec": map[string]interface{}{
+						"value": map[string]interface{}{"instanceType": "c5d.large"},
+					},
+				},
+			},
+		}
+
+		spot = onDemand.DeepCopy()
+		Expect(unstructured.SetNestedMap(spot.Object, map[string]interface{}{},
+			"spec", "template", "spec", "providerSpec", "value", "spotMarketOptions")).To(Succeed())
+
+		machine = &unstructured.Unstructured{}
+		machine.SetGroupVersionKind(schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "Machine"})
+		machine.SetNamespace(onDemand.GetNamespace())
+		machine.SetName(machineName)
+		machine.SetLabels(map[string]string{"machine.openshift.io/cluster-api-machineset": machineSetName})
+		machine.Object["status"] = map[string]interface{}{"phase": "Running"}
+
+		existing = nil
+		config = &ocp.SpotConfig{Timeout: 100 * time.Millisecond}
+
+		restMapper := test.GetRESTMapperFor(onDemand, machine)
+		dynClient := fakeClient.NewSimpleDynamicClient(scheme.Scheme)
+		machineSetClient = dynClient.Resource(*test.GetGroupVersionResourceFor(restMapper, onDemand)).Namespace(onDemand.GetNamespace())
+		machineClient = dynClient.Resource(*test.GetGroupVersionResourceFor(restMapper, machine)).Namespace(machine.GetNamespace())
+
+		deployer = ocp.NewK8sMachinesetDeployer(restMapper, dynClient)
+	})
+
+	JustBeforeEach(func() {
+		if existing != nil {
+			_, err := machineSetClient.Create(context.TODO(), existing, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		}
+
+		_, err := machineClient.Create(context.TODO(), machine, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+	})
+
+	deployedSpotMarketOptions := func() bool {
+		machineSet, err := machineSetClient.Get(context.TODO(), machineSetName, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+
+		_, found, _ := unstructured.NestedMap(machineSet.Object, "spec", "template", "spec", "providerSpec", "value", "spotMarketOptions")
+
+		return found
+	}
+
+	When("the spot machines are provisioned", func() {
+		It("should deploy the spot machine set", func() {
+			isSpot, err := ocp.DeploySpotMachineSet(context.TODO(), deployer, config, spot, onDemand)
+			Expect(err).To(Succeed())
+			Expect(isSpot).To(BeTrue())
+			Expect(deployedSpotMarketOptions()).To(BeTrue())
+		})
+	})
+
+	When("a spot machine fails to provision", func() {
+		BeforeEach(func() {
+			machine.Object["status"] = map[string]interface{}{
+				"phase":        "Failed",
+				"errorReason":  "InsufficientCapacity",
+				"errorMessage": "no spot capacity",
+			}
+		})
+
+		It("should fall back to the on-demand machine set and delete the failed machine", func() {
+			isSpot, err := ocp.DeploySpotMachineSet(context.TODO(), deployer, config, spot, onDemand)
+			Expect(err).To(Succeed())
+			Expect(isSpot).To(BeFalse())
+			Expect(deployedSpotMarketOptions()).To(BeFalse())
+
+			_, err = machineClient.Get(context.TODO(), machineName, metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	When("the spot machines aren't provisioned in time", func() {
+		BeforeEach(func() {
+			machine.Object["status"] = map[string]interface{}{"phase": "Provisioning"}
+		})
+
+		It("should fall back to the on-demand machine set", func() {
+			isSpot, err := ocp.DeploySpotMachineSet(context.TODO(), deployer, config, spot, onDemand)
+			Expect(err).To(Succeed())
+			Expect(isSpot).To(BeFalse())
+			Expect(deployedSpotMarketOptions()).To(BeFalse())
+		})
+	})
+
+	When("the machine set already fell back to on-demand instances", func() {
+		BeforeEach(func() {
+			existing = onDemand.DeepCopy()
+		})
+
+		It("should keep the on-demand machine set", func() {
+			isSpot, err := ocp.DeploySpotMachineSet(context.TODO(), deployer, config, spot, onDemand)
+			Expect(err).To(Succeed())
+			Expect(isSpot).To(BeFalse())
+			Expect(deployedSpotMarketOptions()).To(BeFalse())
+		})
+	})
+
+	When("spot instances aren't requested", func() {
+		BeforeEach(func() {
+			config = nil
+		})
+
+		It("should deploy the on-demand machine set", func() {
+			isSpot, err := ocp.DeploySpotMachineSet(context.TODO(), deployer, config, spot, onDemand)
+			Expect(err).To(Succeed())
+			Expect(isSpot).To(BeFalse())
+			Expect(deployedSpotMarketOptions()).To(BeFalse())
+		})
+	})
+})
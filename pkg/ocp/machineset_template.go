@@ -0,0 +1,208 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ocp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// MachineSetOverrides customize the gateway machine sets, e.g. to add labels, taints or tags, or to change the root
+// volume, spot or preemptible options or user data.
+type MachineSetOverrides struct {
+	// Template replaces the built-in machine set template of the provider. It's a Go template, rendered with the same
+	// parameters as the built-in one, such as {{.InfraID}} and {{.AZ}}.
+	Template string
+
+	// Patch is a JSON merge patch, in YAML or JSON, applied to the rendered machine set. It's rendered as a Go template
+	// first too. Lists, such as the taints, are replaced as a whole.
+	Patch string
+}
+
+// MachineSetCustomizer is implemented by the gateway deployers which deploy dedicated gateways as machine sets.
+type MachineSetCustomizer interface {
+	// SetMachineSetOverrides sets the overrides applied to the gateway machine sets. An error is returned if they
+	// can't be parsed; the machine sets they produce are validated before being deployed.
+	SetMachineSetOverrides(overrides MachineSetOverrides) error
+}
+
+// MachineSetTemplate renders the gateway machine sets of a provider from its built-in template, applying the
+// overrides. Gateway deployers embed it to implement MachineSetCustomizer.
+type MachineSetTemplate struct {
+	builtIn   string
+	overrides MachineSetOverrides
+}
+
+// NewMachineSetTemplate returns a MachineSetTemplate rendering the given built-in template.
+func NewMachineSetTemplate(builtIn string) MachineSetTemplate {
+	return MachineSetTemplate{builtIn: builtIn}
+}
+
+func (t *MachineSetTemplate) SetMachineSetOverrides(overrides MachineSetOverrides) error {
+	if overrides.Template != "" {
+		if _, err := template.New("").Parse(overrides.Template); err != nil {
+			return errors.Wrap(err, "error parsing the machine set template override")
+		}
+	}
+
+	if overrides.Patch != "" {
+		if _, err := template.New("").Parse(overrides.Patch); err != nil {
+			return errors.Wrap(err, "error parsing the machine set patch")
+		}
+	}
+
+	t.overrides = overrides
+
+	return nil
+}
+
+// RenderMachineSet renders the machine set with the given template parameters and applies the overrides. The result is
+// validated against the built-in machine set, so that it can still be found and its nodes are still gateways.
+func (t *MachineSetTemplate) RenderMachineSet(params interface{}) (*unstructured.Unstructured, error) {
+	builtIn, err := renderMachineSet(t.builtIn, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.overrides.Template == "" && t.overrides.Patch == "" {
+		return builtIn, nil
+	}
+
+	machineSet := builtIn
+
+	if t.overrides.Template != "" {
+		machineSet, err = renderMachineSet(t.overrides.Template, params)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error rendering the machine set template override")
+		}
+	}
+
+	if t.overrides.Patch != "" {
+		machineSet, err = patchMachineSet(machineSet, t.overrides.Patch, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return machineSet, validateMachineSet(machineSet, builtIn)
+}
+
+func executeTemplate(text string, params interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	tpl, err := template.New("").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing machine set YAML")
+	}
+
+	err = tpl.Execute(&buf, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "error executing the template")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderMachineSet(text string, params interface{}) (*unstructured.Unstructured, error) {
+	machineSetYAML, err := executeTemplate(text, params)
+	if err != nil {
+		return nil, err
+	}
+
+	machineSet := &unstructured.Unstructured{}
+
+	_, _, err = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme).Decode(machineSetYAML, nil, machineSet)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting YAML to machine set")
+	}
+
+	return machineSet, nil
+}
+
+func patchMachineSet(machineSet *unstructured.Unstructured, patch string, params interface{}) (*unstructured.Unstructured, error) {
+	patchYAML, err := executeTemplate(patch, params)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error rendering the machine set patch")
+	}
+
+	patchJSON, err := sigsyaml.YAMLToJSON(patchYAML)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting the machine set patch to JSON")
+	}
+
+	machineSetJSON, err := json.Marshal(machineSet.Object)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting the machine set to JSON")
+	}
+
+	patchedJSON, err := jsonpatch.MergePatch(machineSetJSON, patchJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "error applying the machine set patch")
+	}
+
+	patched := &unstructured.Unstructured{}
+
+	err = patched.UnmarshalJSON(patchedJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting the patched machine set")
+	}
+
+	return patched, nil
+}
+
+// validateMachineSet checks that the customized machine set keeps what's needed to find it and its gateway nodes.
+func validateMachineSet(machineSet, builtIn *unstructured.Unstructured) error {
+	if machineSet.GroupVersionKind() != builtIn.GroupVersionKind() {
+		return fmt.Errorf("the customized machine set is a %s instead of a %s", machineSet.GroupVersionKind(), builtIn.GroupVersionKind())
+	}
+
+	if machineSet.GetName() != builtIn.GetName() || machineSet.GetNamespace() != builtIn.GetNamespace() {
+		return fmt.Errorf("the customized machine set is named %s/%s instead of %s/%s", machineSet.GetNamespace(),
+			machineSet.GetName(), builtIn.GetNamespace(), builtIn.GetName())
+	}
+
+	if !labels.SelectorFromSet(builtIn.GetLabels()).Matches(labels.Set(machineSet.GetLabels())) {
+		return fmt.Errorf("the customized machine set %q doesn't keep the labels %v", machineSet.GetName(), builtIn.GetLabels())
+	}
+
+	selector, _, _ := unstructured.NestedStringMap(machineSet.Object, "spec", "selector", "matchLabels")
+	machineLabels, _, _ := unstructured.NestedStringMap(machineSet.Object, "spec", "template", "metadata", "labels")
+
+	if len(selector) == 0 || !labels.SelectorFromSet(selector).Matches(labels.Set(machineLabels)) {
+		return fmt.Errorf("the selector of the customized machine set %q doesn't match the labels of its machines",
+			machineSet.GetName())
+	}
+
+	nodeLabels, _, _ := unstructured.NestedStringMap(machineSet.Object, "spec", "template", "spec", "metadata", "labels")
+	if nodeLabels[k8s.SubmarinerGatewayLabel] != "true" {
+		return fmt.Errorf("the customized machine set %q doesn't label its nodes with %s=true", machineSet.GetName(),
+			k8s.SubmarinerGatewayLabel)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const builtInMachineSetYAML = `apiVersion: machine.openshift.io/v1beta1
+kind: MachineSet
+metadata:
+  labels:
+    machine.openshift.io/cluster-api-cluster: {{.InfraID}}
+  name: {{.InfraID}}-submariner-gw-{{.AZ}}
+  namespace: openshift-machine-api
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      machine.openshift.io/cluster-api-machineset: {{.InfraID}}-submariner-gw-{{.AZ}}
+  template:
+    metadata:
+      labels:
+        machine.openshift.io/cluster-api-machineset: {{.InfraID}}-submariner-gw-{{.AZ}}
+    spec:
+      metadata:
+        labels:
+          submariner.io/gateway: "true"
+      providerSpec:
+        value:
+          disks:
+          - sizeGb: 128
+            type: pd-ssd
+          zone: {{.AZ}}`
+
+var _ = Describe("MachineSetTemplate", func() {
+	type params struct {
+		InfraID string
+		AZ      string
+	}
+
+	var (
+		msTemplate ocp.MachineSetTemplate
+		overrides  ocp.MachineSetOverrides
+		machineSet *unstructured.Unstructured
+		err        error
+	)
+
+	BeforeEach(func() {
+		msTemplate = ocp.NewMachineSetTemplate(builtInMachineSetYAML)
+		overrides = ocp.MachineSetOverrides{}
+	})
+
+	JustBeforeEach(func() {
+		Expect(msTemplate.SetMachineSetOverrides(overrides)).To(Succeed())
+		machineSet, err = msTemplate.RenderMachineSet(params{InfraID: "test-infraID", AZ: "zone1"})
+	})
+
+	When("there are no overrides", func() {
+		It("should render the built-in template", func() {
+			Expect(err).To(Succeed())
+			Expect(machineSet.GetName()).To(Equal("test-infraID-submariner-gw-zone1"))
+		})
+	})
+
+	When("a patch is given", func() {
+		BeforeEach(func() {
+			overrides.Patch = `
+metadata:
+  labels:
+    team: networking
+spec:
+  template:
+    spec:
+      metadata:
+        labels:
+          zone: {{.AZ}}
+      providerSpec:
+        value:
+          disks:
+          - sizeGb: 256
+            type: pd-standard`
+		})
+
+		It("should merge it into the rendered machine set", func() {
+			Expect(err).To(Succeed())
+			Expect(machineSet.GetLabels()).To(HaveKeyWithValue("team", "networking"))
+			Expect(machineSet.GetLabels()).To(HaveKeyWithValue("machine.openshift.io/cluster-api-cluster", "test-infraID"))
+
+			nodeLabels, _, _ := unstructured.NestedStringMap(machineSet.Object, "spec", "template", "spec", "metadata", "labels")
+			Expect(nodeLabels).To(Equal(map[string]string{"submariner.io/gateway": "true", "zone": "zone1"}))
+
+			disks, _, _ := unstructured.NestedSlice(machineSet.Object, "spec", "template", "spec", "providerSpec", "value", "disks")
+			Expect(disks).To(Equal([]interface{}{map[string]interface{}{"sizeGb": int64(256), "type": "pd-standard"}}))
+		})
+	})
+
+	When("a template override is given", func() {
+		BeforeEach(func() {
+			overrides.Template = builtInMachineSetYAML + `
+          preemptible: true`
+		})
+
+		It("should render it instead", func() {
+			Expect(err).To(Succeed())
+
+			preemptible, _, _ := unstructured.NestedBool(machineSet.Object, "spec", "template", "spec", "providerSpec", "value",
+				"preemptible")
+			Expect(preemptible).To(BeTrue())
+		})
+	})
+
+	When("the overrides rename the machine set", func() {
+		BeforeEach(func() {
+			overrides.Patch = `{"metadata": {"name": "other"}}`
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the overrides remove the gateway node label", func() {
+		BeforeEach(func() {
+			overrides.Patch = `{"spec": {"template": {"spec": {"metadata": {"labels": {"submariner.io/gateway": null}}}}}}`
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the overrides make the selector miss the machines", func() {
+		BeforeEach(func() {
+			overrides.Patch = `{"spec": {"selector": {"matchLabels": {"role": "gateway"}}}}`
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the patch can't be parsed", func() {
+		It("should be rejected", func() {
+			Expect(msTemplate.SetMachineSetOverrides(ocp.MachineSetOverrides{Patch: "{{.InfraID"})).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,306 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ocp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultWaitTimeout is how long the machines are waited for when WaitConfig doesn't specify a timeout.
+	DefaultWaitTimeout = 10 * time.Minute
+
+	machinePollInterval = 10 * time.Second
+)
+
+// WaitConfig configures how long and how a MachineSetDeployer waits for the machines of the deployed machine sets to
+// become Ready gateway nodes, and for those of the deleted machine sets to be gone.
+type WaitConfig struct {
+	// KubeClient retrieves the nodes of the machines.
+	KubeClient kubernetes.Interface
+
+	// Timeout bounds the wait; Deploy fails if the gateway nodes aren't Ready by then. Zero uses DefaultWaitTimeout.
+	Timeout time.Duration
+
+	// DeleteTimeout bounds the wait on Delete for the machines and their nodes to be gone. Zero uses Timeout.
+	DeleteTimeout time.Duration
+
+	// SkipDeploy returns from Deploy as soon as the machine set is created or updated, without waiting for its machines,
+	// e.g. to only quiesce the cluster on cleanup.
+	SkipDeploy bool
+
+	// SkipDelete returns from Delete as soon as the machine set is deleted, without waiting for its machines to be gone.
+	SkipDelete bool
+
+	// Reporter reports the progress of the wait. Nil reports it on the standard output.
+	Reporter api.Reporter
+}
+
+func (c *WaitConfig) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultWaitTimeout
+	}
+
+	return c.Timeout
+}
+
+func (c *WaitConfig) deleteTimeout() time.Duration {
+	if c.DeleteTimeout == 0 {
+		return c.timeout()
+	}
+
+	return c.DeleteTimeout
+}
+
+func (c *WaitConfig) reporter() api.Reporter {
+	if c.Reporter == nil {
+		return api.NewLoggingReporter()
+	}
+
+	return c.Reporter
+}
+
+// waitForGatewayNodes waits until the given machine set's replicas are Running machines whose nodes are Ready and
+// labeled as gateways. It fails early if one of its machines fails.
+func (msd *k8sMachineSetDeployer) waitForGatewayNodes(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	replicas, found, _ := unstructured.NestedInt64(machineSet.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+
+	machineClient, err := msd.machineClientFor(machineSet)
+	if err != nil {
+		return err
+	}
+
+	reporter := msd.wait.reporter()
+	reporter.Started(fmt.Sprintf("Waiting for the machines of machine set %q to become Ready gateway nodes", machineSet.GetName()))
+
+	timeout := msd.wait.timeout()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	readyNodes := map[string]bool{}
+	pending := []string{}
+
+	err = wait.PollImmediateUntil(machinePollInterval, func() (bool, error) {
+		machines, err := listMachines(ctx, machineClient, machineSet)
+		if err != nil {
+			return false, err
+		}
+
+		pending = []string{}
+
+		for i := range machines.Items {
+			status, err := msd.gatewayMachineStatus(ctx, &machines.Items[i])
+			if err != nil {
+				return false, err
+			}
+
+			if status != "" {
+				pending = append(pending, status)
+				continue
+			}
+
+			if !readyNodes[machines.Items[i].GetName()] {
+				readyNodes[machines.Items[i].GetName()] = true
+				reporter.Succeeded("Machine %q is a Ready gateway node (%d of %d)", machines.Items[i].GetName(),
+					len(readyNodes), replicas)
+				reporter.Started(fmt.Sprintf("Waiting for the machines of machine set %q to become Ready gateway nodes",
+					machineSet.GetName()))
+			}
+		}
+
+		return int64(len(readyNodes)) >= replicas, nil
+	}, ctx.Done())
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		err = fmt.Errorf("timed out after %v waiting for the %d machines of machine set %q to become Ready gateway nodes: %s",
+			timeout, replicas, machineSet.GetName(), strings.Join(pending, "; "))
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("The machines of machine set %q are Ready gateway nodes", machineSet.GetName())
+
+	return nil
+}
+
+// waitForMachinesDeletion waits until the machines of the given machine set, and their nodes, are gone.
+func (msd *k8sMachineSetDeployer) waitForMachinesDeletion(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	machineClient, err := msd.machineClientFor(machineSet)
+	if err != nil {
+		return err
+	}
+
+	reporter := msd.wait.reporter()
+	reporter.Started(fmt.Sprintf("Waiting for the machines of machine set %q and their nodes to be deleted", machineSet.GetName()))
+
+	timeout := msd.wait.deleteTimeout()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	nodeNames := stringset.New()
+	remaining := []string{}
+
+	err = wait.PollImmediateUntil(machinePollInterval, func() (bool, error) {
+		machines, err := listMachines(ctx, machineClient, machineSet)
+		if err != nil {
+			return false, err
+		}
+
+		remaining = []string{}
+
+		for i := range machines.Items {
+			remaining = append(remaining, fmt.Sprintf("machine %q", machines.Items[i].GetName()))
+
+			nodeName, _, _ := unstructured.NestedString(machines.Items[i].Object, "status", "nodeRef", "name")
+			if nodeName != "" {
+				nodeNames.Add(nodeName)
+			}
+		}
+
+		for _, nodeName := range nodeNames.Elements() {
+			_, err := msd.wait.KubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				nodeNames.Remove(nodeName)
+				continue
+			}
+
+			if err != nil {
+				return false, errors.Wrapf(err, "error retrieving node %q", nodeName)
+			}
+
+			remaining = append(remaining, fmt.Sprintf("node %q", nodeName))
+		}
+
+		return len(remaining) == 0, nil
+	}, ctx.Done())
+
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		err = fmt.Errorf("timed out after %v waiting for the machines of machine set %q to be deleted, remaining: %s",
+			timeout, machineSet.GetName(), strings.Join(remaining, ", "))
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("The machines of machine set %q and their nodes are deleted", machineSet.GetName())
+
+	return nil
+}
+
+func (msd *k8sMachineSetDeployer) machineClientFor(machineSet *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	machine := &unstructured.Unstructured{}
+	machine.SetGroupVersionKind(machineSet.GroupVersionKind().GroupVersion().WithKind("Machine"))
+	machine.SetNamespace(machineSet.GetNamespace())
+
+	return msd.clientFor(machine)
+}
+
+// listMachines returns the machines selected by the given machine set.
+func listMachines(ctx context.Context, machineClient dynamic.ResourceInterface, machineSet *unstructured.Unstructured) (
+	*unstructured.UnstructuredList, error) {
+	selector, _, _ := unstructured.NestedStringMap(machineSet.Object, "spec", "selector", "matchLabels")
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("machine set %q doesn't select its machines by labels", machineSet.GetName())
+	}
+
+	machines, err := machineClient.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+
+	return machines, errors.Wrapf(err, "error listing the machines of machine set %q", machineSet.GetName())
+}
+
+// gatewayMachineStatus returns why the given machine isn't a Ready gateway node yet, or an empty string if it is. An
+// error is returned if the machine failed.
+func (msd *k8sMachineSetDeployer) gatewayMachineStatus(ctx context.Context, machine *unstructured.Unstructured) (string, error) {
+	phase, _, _ := unstructured.NestedString(machine.Object, "status", "phase")
+	if phase == "Failed" {
+		reason, _, _ := unstructured.NestedString(machine.Object, "status", "errorReason")
+		message, _, _ := unstructured.NestedString(machine.Object, "status", "errorMessage")
+
+		return "", fmt.Errorf("machine %q failed to provision: %s: %s", machine.GetName(), reason, message)
+	}
+
+	if phase != "Running" {
+		if phase == "" {
+			phase = "Pending"
+		}
+
+		return fmt.Sprintf("machine %q is %s", machine.GetName(), phase), nil
+	}
+
+	nodeName, _, _ := unstructured.NestedString(machine.Object, "status", "nodeRef", "name")
+	if nodeName == "" {
+		return fmt.Sprintf("machine %q has no node yet", machine.GetName()), nil
+	}
+
+	node, err := msd.wait.KubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("node %q of machine %q isn't registered yet", nodeName, machine.GetName()), nil
+	}
+
+	if err != nil {
+		return "", errors.Wrapf(err, "error retrieving node %q", nodeName)
+	}
+
+	if node.Labels[k8s.SubmarinerGatewayLabel] != "true" {
+		return fmt.Sprintf("node %q of machine %q isn't labeled as gateway yet", nodeName, machine.GetName()), nil
+	}
+
+	if !isNodeReady(node) {
+		return fmt.Sprintf("node %q of machine %q isn't Ready yet", nodeName, machine.GetName()), nil
+	}
+
+	return "", nil
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == v1.NodeReady {
+			return node.Status.Conditions[i].Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
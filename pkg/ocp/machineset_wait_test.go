@@ -0,0 +1,235 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocp_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	fakeClient "k8s.io/client-go/dynamic/fake"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("K8s MachineSetDeployer with wait", func() {
+	const (
+		machineSetName = "test-infraID-submariner-gw-zone1"
+		machineName    = machineSetName + "-abcde"
+		nodeName       = "gateway-node"
+	)
+
+	var (
+		restMapper    meta.RESTMapper
+		dynClient     dynamic.Interface
+		machineClient dynamic.ResourceInterface
+		kubeClient    *kubeFake.Clientset
+		waitConfig    ocp.WaitConfig
+		deployer      ocp.MachineSetDeployer
+		machineSet    *unstructured.Unstructured
+		machine       *unstructured.Unstructured
+		node          *corev1.Node
+	)
+
+	BeforeEach(func() {
+		machineSet = newMachineSet()
+		machineSet.SetName(machineSetName)
+		machineSet.Object["spec"] = map[string]interface{}{
+			"replicas": int64(1),
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"machine.openshift.io/cluster-api-machineset": machineSetName},
+			},
+		}
+
+		machine = &unstructured.Unstructured{}
+		machine.SetGroupVersionKind(schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "Machine"})
+		machine.SetNamespace(machineSet.GetNamespace())
+		machine.SetName(machineName)
+		machine.SetLabels(map[string]string{"machine.openshift.io/cluster-api-machineset": machineSetName})
+		machine.Object["status"] = map[string]interface{}{
+			"phase":   "Running",
+			"nodeRef": map[string]interface{}{"name": nodeName},
+		}
+
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   nodeName,
+				Labels: map[string]string{"submariner.io/gateway": "true"},
+			},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+
+		restMapper = test.GetRESTMapperFor(machineSet, machine)
+		dynClient = fakeClient.NewSimpleDynamicClient(scheme.Scheme)
+		machineClient = dynClient.Resource(*test.GetGroupVersionResourceFor(restMapper, machine)).Namespace(machine.GetNamespace())
+		kubeClient = kubeFake.NewSimpleClientset()
+
+		waitConfig = ocp.WaitConfig{
+			KubeClient: kubeClient,
+			Timeout:    100 * time.Millisecond,
+			Reporter:   api.NewLoggingReporter(),
+		}
+	})
+
+	JustBeforeEach(func() {
+		deployer = ocp.NewK8sMachinesetDeployerWithWait(restMapper, dynClient, waitConfig)
+
+		_, err := machineClient.Create(context.TODO(), machine, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+
+		_, err = kubeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+	})
+
+	Context("on Deploy", func() {
+		When("the machines are Ready gateway nodes", func() {
+			It("should succeed", func() {
+				Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+			})
+		})
+
+		When("the timeout and reporter aren't specified", func() {
+			BeforeEach(func() {
+				waitConfig = ocp.WaitConfig{KubeClient: kubeClient}
+			})
+
+			It("should wait with the defaults", func() {
+				Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+			})
+		})
+
+		When("a machine is still provisioning", func() {
+			BeforeEach(func() {
+				machine.Object["status"] = map[string]interface{}{"phase": "Provisioning"}
+			})
+
+			It("should time out, reporting the machine's phase", func() {
+				err := deployer.DeployWithContext(context.TODO(), machineSet)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("machine %q is Provisioning", machineName))
+			})
+		})
+
+		When("the deploy wait is skipped", func() {
+			BeforeEach(func() {
+				machine.Object["status"] = map[string]interface{}{"phase": "Provisioning"}
+				waitConfig.SkipDeploy = true
+			})
+
+			It("should not wait for the machines", func() {
+				Expect(deployer.DeployWithContext(context.TODO(), machineSet)).To(Succeed())
+			})
+		})
+
+		When("a node isn't Ready", func() {
+			BeforeEach(func() {
+				node.Status.Conditions[0].Status = corev1.ConditionFalse
+			})
+
+			It("should time out", func() {
+				err := deployer.DeployWithContext(context.TODO(), machineSet)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("isn't Ready yet"))
+			})
+		})
+
+		When("a machine failed", func() {
+			BeforeEach(func() {
+				machine.Object["status"] = map[string]interface{}{
+					"phase":        "Failed",
+					"errorReason":  "InvalidConfiguration",
+					"errorMessage": "unknown instance type",
+				}
+			})
+
+			It("should return an error with the failure reason", func() {
+				err := deployer.DeployWithContext(context.TODO(), machineSet)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("InvalidConfiguration: unknown instance type"))
+			})
+		})
+	})
+
+	Context("on Delete", func() {
+		When("the machines and their nodes are gone", func() {
+			BeforeEach(func() {
+				machine.SetLabels(map[string]string{"machine.openshift.io/cluster-api-machineset": "other"})
+				node.Name = "other-node"
+			})
+
+			It("should succeed", func() {
+				Expect(deployer.DeleteWithContext(context.TODO(), machineSet)).To(Succeed())
+			})
+
+			Context("and the timeout and reporter aren't specified", func() {
+				BeforeEach(func() {
+					waitConfig = ocp.WaitConfig{KubeClient: kubeClient}
+				})
+
+				It("should wait with the defaults", func() {
+					Expect(deployer.DeleteWithContext(context.TODO(), machineSet)).To(Succeed())
+				})
+			})
+		})
+
+		When("a machine remains", func() {
+			It("should time out, reporting the remaining machine and node", func() {
+				err := deployer.DeleteWithContext(context.TODO(), machineSet)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("machine %q", machineName))
+				Expect(err.Error()).To(ContainSubstring("node %q", nodeName))
+			})
+
+			Context("and a delete timeout is specified", func() {
+				BeforeEach(func() {
+					waitConfig.Timeout = time.Hour
+					waitConfig.DeleteTimeout = 200 * time.Millisecond
+				})
+
+				It("should time out after the delete timeout", func() {
+					err := deployer.DeleteWithContext(context.TODO(), machineSet)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("timed out after %v", waitConfig.DeleteTimeout))
+				})
+			})
+
+			Context("and the delete wait is skipped", func() {
+				BeforeEach(func() {
+					waitConfig.SkipDelete = true
+				})
+
+				It("should not wait for the machines", func() {
+					Expect(deployer.DeleteWithContext(context.TODO(), machineSet)).To(Succeed())
+				})
+			})
+		})
+	})
+})
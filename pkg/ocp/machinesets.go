@@ -21,6 +21,8 @@ package ocp
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/resource"
@@ -29,7 +31,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 )
 
@@ -40,16 +44,39 @@ type MachineSetDeployer interface {
 	// Deploy makes sure to deploy the given machine set (creating or updating it).
 	Deploy(machineSet *unstructured.Unstructured) error
 
+	// DeployWithContext is Deploy, bound to the given context.
+	DeployWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error
+
 	// GetWorkerNodeImage returns the image used by OCP worker nodes.
 	GetWorkerNodeImage(workerNodeList []string, machineSet *unstructured.Unstructured, infraID string) (string, error)
 
+	// GetWorkerNodeImageWithContext is GetWorkerNodeImage, bound to the given context.
+	GetWorkerNodeImageWithContext(ctx context.Context, workerNodeList []string, machineSet *unstructured.Unstructured,
+		infraID string) (string, error)
+
 	// Delete will remove the given machineset.
 	Delete(machineSet *unstructured.Unstructured) error
+
+	// DeleteWithContext is Delete, bound to the given context.
+	DeleteWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error
+
+	// List returns the machine sets of the given machine set's kind and namespace which carry all of its labels.
+	List(ctx context.Context, machineSet *unstructured.Unstructured) ([]unstructured.Unstructured, error)
+}
+
+// SpotMachineSetDeployer is implemented by the MachineSetDeployers which can deploy machine sets on spot instances,
+// falling back to on-demand instances.
+type SpotMachineSetDeployer interface {
+	// DeploySpot deploys the given spot machine set and waits up to the given timeout for its machines to be provisioned.
+	// If they aren't, the machine set is replaced by the given on-demand one, and its unprovisioned machines are deleted so
+	// that they're recreated on-demand. It returns whether the machines are spot instances.
+	DeploySpot(ctx context.Context, spot, onDemand *unstructured.Unstructured, timeout time.Duration) (bool, error)
 }
 
 type k8sMachineSetDeployer struct {
 	restMapper    meta.RESTMapper
 	dynamicClient dynamic.Interface
+	wait          *WaitConfig
 }
 
 // NewK8sMachinesetDeployer returns a MachineSetDeployer capable deploying directly to Kubernetes.
@@ -60,6 +87,18 @@ func NewK8sMachinesetDeployer(restMapper meta.RESTMapper, dynamicClient dynamic.
 	}
 }
 
+// NewK8sMachinesetDeployerWithWait returns a MachineSetDeployer capable deploying directly to Kubernetes, which then waits
+// for the machines of the deployed machine sets to become Ready gateway nodes, and for the machines of the deleted
+// machine sets and their nodes to be gone.
+func NewK8sMachinesetDeployerWithWait(restMapper meta.RESTMapper, dynamicClient dynamic.Interface,
+	waitConfig WaitConfig) MachineSetDeployer {
+	return &k8sMachineSetDeployer{
+		dynamicClient: dynamicClient,
+		restMapper:    restMapper,
+		wait:          &waitConfig,
+	}
+}
+
 func (msd *k8sMachineSetDeployer) clientFor(obj runtime.Object) (dynamic.ResourceInterface, error) {
 	machineSet, gvr, err := util.ToUnstructuredResource(obj, msd.restMapper)
 	if err != nil {
@@ -71,13 +110,18 @@ func (msd *k8sMachineSetDeployer) clientFor(obj runtime.Object) (dynamic.Resourc
 
 func (msd *k8sMachineSetDeployer) GetWorkerNodeImage(workerNodeList []string, machineSet *unstructured.Unstructured,
 	infraID string) (string, error) {
+	return msd.GetWorkerNodeImageWithContext(context.Background(), workerNodeList, machineSet, infraID)
+}
+
+func (msd *k8sMachineSetDeployer) GetWorkerNodeImageWithContext(ctx context.Context, workerNodeList []string,
+	machineSet *unstructured.Unstructured, infraID string) (string, error) {
 	machineSetClient, err := msd.clientFor(machineSet)
 	if err != nil {
 		return "", err
 	}
 
 	for _, nodeName := range workerNodeList {
-		existing, err := machineSetClient.Get(context.TODO(), nodeName, metav1.GetOptions{})
+		existing, err := machineSetClient.Get(ctx, nodeName, metav1.GetOptions{})
 		if apierrors.IsNotFound(err) {
 			continue
 		}
@@ -108,26 +152,109 @@ func (msd *k8sMachineSetDeployer) GetWorkerNodeImage(workerNodeList []string, ma
 }
 
 func (msd *k8sMachineSetDeployer) Deploy(machineSet *unstructured.Unstructured) error {
+	return msd.DeployWithContext(context.Background(), machineSet)
+}
+
+func (msd *k8sMachineSetDeployer) DeployWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error {
+	err := msd.createOrUpdate(ctx, machineSet)
+	if err != nil {
+		return err
+	}
+
+	if msd.wait != nil && !msd.wait.SkipDeploy {
+		return msd.waitForGatewayNodes(ctx, machineSet)
+	}
+
+	return nil
+}
+
+func (msd *k8sMachineSetDeployer) createOrUpdate(ctx context.Context, machineSet *unstructured.Unstructured) error {
 	machineSetClient, err := msd.clientFor(machineSet)
 	if err != nil {
 		return err
 	}
 
-	_, err = util.CreateOrUpdate(context.TODO(), resource.ForDynamic(machineSetClient), machineSet, util.Replace(machineSet))
+	_, err = util.CreateOrUpdate(ctx, resource.ForDynamic(machineSetClient), machineSet, util.Replace(machineSet))
 
 	return errors.Wrapf(err, "error creating machine set %#v", machineSet)
 }
 
 func (msd *k8sMachineSetDeployer) Delete(machineSet *unstructured.Unstructured) error {
+	return msd.DeleteWithContext(context.Background(), machineSet)
+}
+
+func (msd *k8sMachineSetDeployer) DeleteWithContext(ctx context.Context, machineSet *unstructured.Unstructured) error {
 	machineSetClient, err := msd.clientFor(machineSet)
 	if err != nil {
 		return err
 	}
 
-	err = machineSetClient.Delete(context.TODO(), machineSet.GetName(), metav1.DeleteOptions{})
-	if apierrors.IsNotFound(err) {
-		return nil
+	err = machineSetClient.Delete(ctx, machineSet.GetName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "error deleting machine set %q", machineSet.GetName())
+	}
+
+	if msd.wait != nil && !msd.wait.SkipDelete {
+		return msd.waitForMachinesDeletion(ctx, machineSet)
+	}
+
+	return nil
+}
+
+func (msd *k8sMachineSetDeployer) List(ctx context.Context, machineSet *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	machineSetClient, err := msd.clientFor(machineSet)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := machineSetClient.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(machineSet.GetLabels()).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing machine sets in %q", machineSet.GetNamespace())
+	}
+
+	return list.Items, nil
+}
+
+// ListGatewayMachineSets returns the names of the dedicated gateway machine sets deployed for the given infrastructure.
+func ListGatewayMachineSets(ctx context.Context, msDeployer MachineSetDeployer, infraID string) ([]string, error) {
+	machineSets, err := GatewayMachineSets(ctx, msDeployer, infraID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(machineSets))
+	for i := range machineSets {
+		names = append(names, machineSets[i].GetName())
+	}
+
+	return names, nil
+}
+
+// GatewayMachineSets returns the dedicated gateway machine sets deployed for the given infrastructure.
+func GatewayMachineSets(ctx context.Context, msDeployer MachineSetDeployer, infraID string) ([]unstructured.Unstructured, error) {
+	machineSet := &unstructured.Unstructured{}
+	machineSet.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "machine.openshift.io",
+		Version: "v1beta1",
+		Kind:    "MachineSet",
+	})
+	machineSet.SetNamespace("openshift-machine-api")
+	machineSet.SetLabels(map[string]string{"machine.openshift.io/cluster-api-cluster": infraID})
+
+	machineSets, err := msDeployer.List(ctx, machineSet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the machine sets of %q", infraID)
+	}
+
+	gatewayMachineSets := []unstructured.Unstructured{}
+
+	for i := range machineSets {
+		if strings.HasPrefix(machineSets[i].GetName(), infraID+"-submariner-gw-") {
+			gatewayMachineSets = append(gatewayMachineSets, machineSets[i])
+		}
 	}
 
-	return errors.Wrapf(err, "error deleting machine set %q", machineSet.GetName())
+	return gatewayMachineSets, nil
 }
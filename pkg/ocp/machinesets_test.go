@@ -167,6 +167,42 @@ var _ = Describe("K8s MachineSetDeployer", func() {
 			})
 		})
 	})
+
+	Context("on List", func() {
+		BeforeEach(func() {
+			machineSet.SetLabels(map[string]string{"cluster": infraID})
+
+			for name, labels := range map[string]map[string]string{
+				machineSetName: {"cluster": infraID, "role": "gateway"},
+				"other":        {"cluster": "other-infraID"},
+			} {
+				existing := newMachineSet()
+				existing.SetName(name)
+				existing.SetLabels(labels)
+
+				_, err := msClient.Create(context.TODO(), existing, metav1.CreateOptions{})
+				Expect(err).To(Succeed())
+			}
+		})
+
+		It("should return the machine sets carrying the labels", func() {
+			machineSets, err := deployer.List(context.TODO(), machineSet)
+			Expect(err).To(Succeed())
+			Expect(machineSets).To(HaveLen(1))
+			Expect(machineSets[0].GetName()).To(Equal(machineSetName))
+		})
+
+		Context("and listing fails", func() {
+			BeforeEach(func() {
+				fake.NewFailingReactor(&dynClient.Fake).SetFailOnList(errors.New("fake List error"))
+			})
+
+			It("should return an error", func() {
+				_, err := deployer.List(context.TODO(), machineSet)
+				Expect(err).ToNot(Succeed())
+			})
+		})
+	})
 })
 
 func newMachineSet() *unstructured.Unstructured {
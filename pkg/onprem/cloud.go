@@ -0,0 +1,123 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onprem
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+type onpremCloud struct {
+	firewall   Firewall
+	stateStore api.StateStore
+}
+
+// NewCloud creates a new api.Cloud instance which can prepare an on-premises cluster, on vSphere or bare metal, for
+// Submariner to be deployed on it. The ports are opened by the given firewall backend.
+func NewCloud(firewall Firewall) api.Cloud {
+	return &onpremCloud{firewall: firewall}
+}
+
+// SetStateStore sets the store recording the opened ports, which are then closed exactly on clean up.
+func (c *onpremCloud) SetStateStore(store api.StateStore) {
+	c.stateStore = store
+}
+
+func (c *onpremCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
+	return c.PrepareForSubmarinerWithContext(context.Background(), input, reporter)
+}
+
+func (c *onpremCloud) PrepareForSubmarinerWithContext(ctx context.Context, input api.PrepareForSubmarinerInput,
+	reporter api.Reporter) error {
+	reporter.Started("Opening internal ports %q for intra-cluster communications", formatPorts(input.InternalPorts))
+
+	err := openPorts(ctx, c.firewall, c.stateStore, FirewallRule{Scope: ScopeInternal, Ports: input.InternalPorts}, reporter)
+	if err != nil {
+		err = errors.Wrap(err, "error opening the internal ports")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	reporter.Succeeded("Opened internal ports %q for intra-cluster communications", formatPorts(input.InternalPorts))
+
+	return nil
+}
+
+func (c *onpremCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
+	return c.CleanupAfterSubmarinerWithContext(context.Background(), reporter)
+}
+
+func (c *onpremCloud) CleanupAfterSubmarinerWithContext(ctx context.Context, reporter api.Reporter) error {
+	reporter.Started("Closing the internal ports opened for intra-cluster communications")
+
+	err := closePorts(ctx, c.firewall, c.stateStore, FirewallRule{Scope: ScopeInternal}, reporter)
+	if err != nil {
+		err = errors.Wrap(err, "error closing the internal ports")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	reporter.Succeeded("Closed the internal ports opened for intra-cluster communications")
+
+	return nil
+}
+
+// CreateVpcPeering doesn't apply to on-premises clusters.
+func (c *onpremCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return c.CreateVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (c *onpremCloud) CreateVpcPeeringWithContext(_ context.Context, _ api.Cloud, reporter api.Reporter) error {
+	err := errors.New("VPC peering isn't supported on premises")
+	reporter.Failed(err)
+
+	return err
+}
+
+// CleanupVpcPeering doesn't apply to on-premises clusters.
+func (c *onpremCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) error {
+	return c.CleanupVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (c *onpremCloud) CleanupVpcPeeringWithContext(_ context.Context, _ api.Cloud, reporter api.Reporter) error {
+	err := errors.New("VPC peering isn't supported on premises")
+	reporter.Failed(err)
+
+	return err
+}
+
+// Plan returns the firewall rules PrepareForSubmariner would insert. The state of the firewall isn't known, so all the
+// internal ports are returned.
+func (c *onpremCloud) Plan(_ context.Context, input api.PrepareForSubmarinerInput) ([]api.Action, error) {
+	return planPorts([]api.Action{}, FirewallRule{Scope: ScopeInternal, Ports: input.InternalPorts}), nil
+}
+
+// Inspect returns the internal ports recorded as opened; without a state store, none are known.
+func (c *onpremCloud) Inspect(ctx context.Context) (*api.CloudStatus, error) {
+	ports, err := recordedPorts(ctx, c.stateStore, ScopeInternal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CloudStatus{InternalPorts: ports, Peerings: []api.PeeringStatus{}}, nil
+}
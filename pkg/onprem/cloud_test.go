@@ -0,0 +1,124 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package onprem_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/onprem"
+)
+
+var internalPorts = []api.PortSpec{{Port: 4800, Protocol: "udp"}, {Port: 8080, Protocol: "tcp"}}
+
+var _ = Describe("Cloud", func() {
+	var (
+		firewall *fakeFirewall
+		cloud    api.Cloud
+	)
+
+	BeforeEach(func() {
+		firewall = newFakeFirewall()
+		cloud = onprem.NewCloud(firewall.firewall)
+	})
+
+	Context("on PrepareForSubmariner", func() {
+		It("should open the internal ports on all the nodes", func() {
+			Expect(cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{InternalPorts: internalPorts},
+				api.NewLoggingReporter())).To(Succeed())
+			Expect(firewall.opened).To(Equal([]onprem.FirewallRule{{Scope: onprem.ScopeInternal, Ports: internalPorts}}))
+		})
+
+		When("the firewall fails", func() {
+			BeforeEach(func() {
+				firewall.openErr = errors.New("fake error")
+			})
+
+			It("should return an error", func() {
+				Expect(cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{InternalPorts: internalPorts},
+					api.NewLoggingReporter())).ToNot(Succeed())
+			})
+		})
+	})
+
+	Context("on CleanupAfterSubmariner", func() {
+		It("should close all the internal ports", func() {
+			Expect(cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+			Expect(firewall.closed).To(HaveLen(1))
+			Expect(firewall.closed[0].Scope).To(Equal(onprem.ScopeInternal))
+			Expect(firewall.closed[0].Ports).To(BeEmpty())
+		})
+
+		When("the opened ports were recorded", func() {
+			var store *memoryStateStore
+
+			BeforeEach(func() {
+				store = &memoryStateStore{}
+				cloud.(api.StateRecorder).SetStateStore(store)
+				Expect(cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{InternalPorts: internalPorts},
+					api.NewLoggingReporter())).To(Succeed())
+			})
+
+			It("should close the recorded ports and forget them", func() {
+				Expect(cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+				Expect(firewall.closed).To(Equal([]onprem.FirewallRule{{Scope: onprem.ScopeInternal, Ports: internalPorts}}))
+				Expect(store.state.Resources).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("on Plan", func() {
+		It("should return the firewall rules to insert", func() {
+			actions, err := cloud.Plan(context.TODO(), api.PrepareForSubmarinerInput{InternalPorts: internalPorts})
+			Expect(err).To(Succeed())
+			Expect(actions).To(Equal([]api.Action{
+				{
+					Kind: api.ActionInsertFirewallRule, Resource: "submariner-internal",
+					Parameters: map[string]string{"protocol": "udp", "port": "4800"},
+				},
+				{
+					Kind: api.ActionInsertFirewallRule, Resource: "submariner-internal",
+					Parameters: map[string]string{"protocol": "tcp", "port": "8080"},
+				},
+			}))
+			Expect(firewall.opened).To(BeEmpty())
+		})
+	})
+
+	Context("on Inspect", func() {
+		It("should return the recorded internal ports", func() {
+			cloud.(api.StateRecorder).SetStateStore(&memoryStateStore{})
+			Expect(cloud.PrepareForSubmariner(api.PrepareForSubmarinerInput{InternalPorts: internalPorts},
+				api.NewLoggingReporter())).To(Succeed())
+
+			status, err := cloud.Inspect(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(status.InternalPorts).To(Equal(internalPorts))
+			Expect(status.Peerings).To(BeEmpty())
+		})
+	})
+
+	Context("on CreateVpcPeering", func() {
+		It("should return an error", func() {
+			Expect(cloud.CreateVpcPeering(onprem.NewCloud(firewall.firewall), api.NewLoggingReporter())).ToNot(Succeed())
+		})
+	})
+})
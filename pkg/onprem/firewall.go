@@ -0,0 +1,170 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onprem
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+// Scope identifies which Submariner traffic a firewall rule allows.
+type Scope string
+
+const (
+	// ScopeInternal allows the traffic between Submariner components inside the cluster, on all the nodes.
+	ScopeInternal Scope = "internal"
+
+	// ScopeGateway allows the traffic between the Submariner gateways of the clusters, on the gateway nodes.
+	ScopeGateway Scope = "gateway"
+)
+
+// FirewallRule describes the ports a firewall must allow for Submariner.
+type FirewallRule struct {
+	Scope Scope
+
+	// Ports to allow. When closing, no ports means all the ports previously opened for the scope.
+	Ports []api.PortSpec
+
+	// Nodes the rule applies to; no nodes means all the nodes of the cluster.
+	Nodes []string
+}
+
+// Name returns a name identifying the rules of the scope, e.g. as a comment in generated rules.
+func (r FirewallRule) Name() string {
+	return "submariner-" + string(r.Scope)
+}
+
+// Firewall is a backend managing the firewall of an on-premises cluster, either in front of the nodes or on the nodes
+// themselves.
+type Firewall interface {
+	// Open allows the ports of the given rule. Problems requiring manual intervention are reported to the reporter.
+	Open(ctx context.Context, rule FirewallRule, reporter api.Reporter) error
+
+	// Close disallows the ports of the given rule, which were previously allowed by Open.
+	Close(ctx context.Context, rule FirewallRule, reporter api.Reporter) error
+}
+
+func formatPorts(ports []api.PortSpec) string {
+	portStrs := []string{}
+	for _, port := range ports {
+		portStrs = append(portStrs, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+	}
+
+	return strings.Join(portStrs, ", ")
+}
+
+func formatNodes(nodes []string) string {
+	if len(nodes) == 0 {
+		return "all the nodes"
+	}
+
+	return "nodes " + strings.Join(nodes, ", ")
+}
+
+func firewallRuleResource(scope Scope, port api.PortSpec) api.Resource {
+	return api.Resource{
+		Type: api.ResourceFirewallRule,
+		ID:   fmt.Sprintf("%s/%d/%s", scope, port.Port, port.Protocol),
+		Parameters: map[string]string{
+			"scope":    string(scope),
+			"port":     strconv.Itoa(int(port.Port)),
+			"protocol": port.Protocol,
+		},
+	}
+}
+
+// recordedPorts returns the ports recorded as opened for the given scope.
+func recordedPorts(ctx context.Context, store api.StateStore, scope Scope) ([]api.PortSpec, error) {
+	recorded, err := api.RecordedResources(ctx, store, api.ResourceFirewallRule)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	ports := []api.PortSpec{}
+
+	for i := range recorded {
+		if recorded[i].Parameters["scope"] != string(scope) {
+			continue
+		}
+
+		port, err := strconv.ParseUint(recorded[i].Parameters["port"], 10, 16)
+		if err != nil {
+			continue
+		}
+
+		ports = append(ports, api.PortSpec{Port: uint16(port), Protocol: recorded[i].Parameters["protocol"]})
+	}
+
+	return ports, nil
+}
+
+// openPorts opens the rule's ports with the firewall, and records them.
+func openPorts(ctx context.Context, firewall Firewall, store api.StateStore, rule FirewallRule, reporter api.Reporter) error {
+	if err := firewall.Open(ctx, rule, reporter); err != nil {
+		return err // nolint:wrapcheck // Let the caller wrap it.
+	}
+
+	resources := []api.Resource{}
+	for _, port := range rule.Ports {
+		resources = append(resources, firewallRuleResource(rule.Scope, port))
+	}
+
+	return api.RecordResources(ctx, store, resources...)
+}
+
+// closePorts closes the recorded ports of the scope with the firewall or, if none were recorded, all its ports.
+func closePorts(ctx context.Context, firewall Firewall, store api.StateStore, rule FirewallRule, reporter api.Reporter) error {
+	ports, err := recordedPorts(ctx, store, rule.Scope)
+	if err != nil {
+		return err
+	}
+
+	rule.Ports = ports
+
+	if err := firewall.Close(ctx, rule, reporter); err != nil {
+		return err // nolint:wrapcheck // Let the caller wrap it.
+	}
+
+	resources := []api.Resource{}
+	for _, port := range ports {
+		resources = append(resources, firewallRuleResource(rule.Scope, port))
+	}
+
+	return api.ForgetResources(ctx, store, resources...)
+}
+
+// planPorts returns the firewall rule insertions opening the ports would perform.
+func planPorts(actions []api.Action, rule FirewallRule) []api.Action {
+	for _, port := range rule.Ports {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionInsertFirewallRule,
+			Resource: rule.Name(),
+			Parameters: map[string]string{
+				"protocol": port.Protocol,
+				"port":     strconv.Itoa(int(port.Port)),
+			},
+		})
+	}
+
+	return actions
+}
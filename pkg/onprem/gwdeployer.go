@@ -0,0 +1,141 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onprem
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/generic"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+)
+
+type gatewayDeployer struct {
+	nodeDeployer api.GatewayDeployer
+	firewall     Firewall
+	k8sClient    k8s.Interface
+	stateStore   api.StateStore
+}
+
+// NewGatewayDeployer returns a GatewayDeployer which labels existing worker nodes as gateways, like the generic
+// deployer, and opens the public ports on the gateway nodes with the given firewall backend.
+func NewGatewayDeployer(firewall Firewall, k8sClient k8s.Interface) api.GatewayDeployer {
+	return &gatewayDeployer{
+		nodeDeployer: generic.NewGatewayDeployer(k8sClient),
+		firewall:     firewall,
+		k8sClient:    k8sClient,
+	}
+}
+
+// SetStateStore sets the store recording the labeled gateway nodes and the opened ports, which are then cleaned up
+// exactly.
+func (d *gatewayDeployer) SetStateStore(store api.StateStore) {
+	d.stateStore = store
+
+	if recorder, ok := d.nodeDeployer.(api.StateRecorder); ok {
+		recorder.SetStateStore(store)
+	}
+}
+
+func (d *gatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *gatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	if err := d.nodeDeployer.DeployWithContext(ctx, input, reporter); err != nil {
+		return err // nolint:wrapcheck // Already reported.
+	}
+
+	reporter.Started("Opening external ports %q on the gateway nodes", formatPorts(input.PublicPorts))
+
+	nodes, err := d.gatewayNodes(ctx)
+	if err == nil {
+		err = openPorts(ctx, d.firewall, d.stateStore, FirewallRule{Scope: ScopeGateway, Ports: input.PublicPorts, Nodes: nodes},
+			reporter)
+		err = errors.Wrap(err, "error opening the external ports")
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Opened external ports %q on %s", formatPorts(input.PublicPorts), formatNodes(nodes))
+
+	return nil
+}
+
+func (d *gatewayDeployer) gatewayNodes(ctx context.Context) ([]string, error) {
+	gwNodes, err := d.k8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	return k8s.NodeNames(gwNodes), nil
+}
+
+func (d *gatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *gatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
+	reporter.Started("Closing the external ports opened on the gateway nodes")
+
+	nodes, err := d.gatewayNodes(ctx)
+	if err == nil {
+		err = closePorts(ctx, d.firewall, d.stateStore, FirewallRule{Scope: ScopeGateway, Nodes: nodes}, reporter)
+		err = errors.Wrap(err, "error closing the external ports")
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Closed the external ports opened on %s", formatNodes(nodes))
+
+	return d.nodeDeployer.CleanupWithContext(ctx, reporter) // nolint:wrapcheck // Already reported.
+}
+
+// Plan returns the nodes Deploy would label or unlabel, and the firewall rules it would insert on the gateway nodes.
+func (d *gatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	actions, err := d.nodeDeployer.Plan(ctx, input)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	return planPorts(actions, FirewallRule{Scope: ScopeGateway, Ports: input.PublicPorts}), nil
+}
+
+// Inspect returns the gateway nodes and the external ports recorded as opened; without a state store, no ports are
+// known.
+func (d *gatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	status, err := d.nodeDeployer.Inspect(ctx)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	status.PublicPorts, err = recordedPorts(ctx, d.stateStore, ScopeGateway)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package onprem_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/onprem"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+)
+
+var publicPorts = []api.PortSpec{{Port: 4500, Protocol: "udp"}}
+
+var _ = Describe("GatewayDeployer", func() {
+	var (
+		firewall   *fakeFirewall
+		kubeClient *kubeFake.Clientset
+		gwDeployer api.GatewayDeployer
+	)
+
+	BeforeEach(func() {
+		firewall = newFakeFirewall()
+		kubeClient = kubeFake.NewSimpleClientset()
+
+		for _, name := range []string{"node-1", "node-2"} {
+			_, err := kubeClient.CoreV1().Nodes().Create(context.TODO(), &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+			}, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		}
+
+		gwDeployer = onprem.NewGatewayDeployer(firewall.firewall, k8s.NewInterface(kubeClient))
+	})
+
+	deploy := func() error {
+		return gwDeployer.Deploy(api.GatewayDeployInput{PublicPorts: publicPorts, Gateways: 1}, api.NewLoggingReporter())
+	}
+
+	Context("on Deploy", func() {
+		It("should label a gateway node and open the public ports on it", func() {
+			Expect(deploy()).To(Succeed())
+
+			status, err := gwDeployer.Inspect(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(status.GatewayNodes).To(HaveLen(1))
+			Expect(firewall.opened).To(Equal([]onprem.FirewallRule{
+				{Scope: onprem.ScopeGateway, Ports: publicPorts, Nodes: status.GatewayNodes},
+			}))
+		})
+	})
+
+	Context("on Plan", func() {
+		It("should return the node to label and the firewall rules to insert", func() {
+			actions, err := gwDeployer.Plan(context.TODO(), api.GatewayDeployInput{PublicPorts: publicPorts, Gateways: 1})
+			Expect(err).To(Succeed())
+			Expect(actions).To(HaveLen(2))
+			Expect(actions[0].Kind).To(Equal(api.ActionLabelNode))
+			Expect(actions[1]).To(Equal(api.Action{
+				Kind: api.ActionInsertFirewallRule, Resource: "submariner-gateway",
+				Parameters: map[string]string{"protocol": "udp", "port": "4500"},
+			}))
+			Expect(firewall.opened).To(BeEmpty())
+		})
+	})
+
+	Context("on Inspect", func() {
+		It("should return the recorded public ports", func() {
+			gwDeployer.(api.StateRecorder).SetStateStore(&memoryStateStore{})
+			Expect(deploy()).To(Succeed())
+
+			status, err := gwDeployer.Inspect(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(status.PublicPorts).To(Equal(publicPorts))
+		})
+	})
+
+	Context("on Cleanup", func() {
+		var store *memoryStateStore
+
+		BeforeEach(func() {
+			store = &memoryStateStore{}
+			gwDeployer.(api.StateRecorder).SetStateStore(store)
+			Expect(deploy()).To(Succeed())
+		})
+
+		It("should close the public ports and unlabel the gateway nodes", func() {
+			Expect(gwDeployer.Cleanup(api.NewLoggingReporter())).To(Succeed())
+			Expect(firewall.closed).To(HaveLen(1))
+			Expect(firewall.closed[0].Ports).To(Equal(publicPorts))
+			Expect(firewall.closed[0].Nodes).To(HaveLen(1))
+
+			status, err := gwDeployer.Inspect(context.TODO())
+			Expect(err).To(Succeed())
+			Expect(status.GatewayNodes).To(BeEmpty())
+			Expect(store.state.Resources).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,55 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onprem
+
+import (
+	"context"
+
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+// HookFunc is called with the firewall rule to open or close.
+type HookFunc func(ctx context.Context, rule FirewallRule) error
+
+type hookFirewall struct {
+	onOpen  HookFunc
+	onClose HookFunc
+}
+
+// NewHookFirewall returns a Firewall calling the given functions to open and close ports, e.g. to drive the API of an
+// external firewall. A nil function does nothing.
+func NewHookFirewall(onOpen, onClose HookFunc) Firewall {
+	return &hookFirewall{onOpen: onOpen, onClose: onClose}
+}
+
+func (f *hookFirewall) Open(ctx context.Context, rule FirewallRule, _ api.Reporter) error {
+	if f.onOpen == nil {
+		return nil
+	}
+
+	return f.onOpen(ctx, rule)
+}
+
+func (f *hookFirewall) Close(ctx context.Context, rule FirewallRule, _ api.Reporter) error {
+	if f.onClose == nil {
+		return nil
+	}
+
+	return f.onClose(ctx, rule)
+}
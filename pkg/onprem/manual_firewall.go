@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onprem
+
+import (
+	"context"
+
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+type manualFirewall struct{}
+
+// NewManualFirewall returns a Firewall which changes nothing, but reports as warnings the ports which must be opened or
+// closed manually.
+func NewManualFirewall() Firewall {
+	return &manualFirewall{}
+}
+
+func (f *manualFirewall) Open(_ context.Context, rule FirewallRule, reporter api.Reporter) error {
+	reporter.Warning("The firewall isn't managed: ports %q must be allowed manually on %s", formatPorts(rule.Ports),
+		formatNodes(rule.Nodes))
+
+	return nil
+}
+
+func (f *manualFirewall) Close(_ context.Context, rule FirewallRule, reporter api.Reporter) error {
+	if len(rule.Ports) == 0 {
+		reporter.Warning("The firewall isn't managed: the ports allowed for Submariner's %s traffic must be disallowed manually",
+			rule.Scope)
+
+		return nil
+	}
+
+	reporter.Warning("The firewall isn't managed: ports %q must be disallowed manually on %s", formatPorts(rule.Ports),
+		formatNodes(rule.Nodes))
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package onprem_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/onprem"
+)
+
+func TestOnprem(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "On-premises Suite")
+}
+
+// fakeFirewall records the rules opened and closed through a hook firewall.
+type fakeFirewall struct {
+	opened   []onprem.FirewallRule
+	closed   []onprem.FirewallRule
+	openErr  error
+	firewall onprem.Firewall
+}
+
+func newFakeFirewall() *fakeFirewall {
+	f := &fakeFirewall{}
+
+	f.firewall = onprem.NewHookFirewall(func(_ context.Context, rule onprem.FirewallRule) error {
+		if f.openErr != nil {
+			return f.openErr
+		}
+
+		f.opened = append(f.opened, rule)
+
+		return nil
+	}, func(_ context.Context, rule onprem.FirewallRule) error {
+		f.closed = append(f.closed, rule)
+		return nil
+	})
+
+	return f
+}
+
+type memoryStateStore struct {
+	state api.State
+}
+
+func (m *memoryStateStore) Load(_ context.Context) (*api.State, error) {
+	state := m.state
+	return &state, nil
+}
+
+func (m *memoryStateStore) Save(_ context.Context, state *api.State) error {
+	m.state = *state
+	return nil
+}
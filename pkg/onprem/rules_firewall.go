@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onprem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+)
+
+// RulesFormat is the node-level firewall a rules Firewall generates commands for.
+type RulesFormat string
+
+const (
+	RulesNftables RulesFormat = "nftables"
+	RulesIptables RulesFormat = "iptables"
+)
+
+type rulesFirewall struct {
+	format RulesFormat
+	out    io.Writer
+}
+
+// NewRulesFirewall returns a Firewall which generates the shell commands configuring a node-level firewall and writes
+// them to the given writer, to be run on the nodes. With nftables, the rules are added to the input chain of the
+// "inet filter" table; with iptables, to the INPUT chain. The rules are commented with the name of their scope, so
+// that they can be removed together.
+func NewRulesFirewall(format RulesFormat, out io.Writer) (Firewall, error) {
+	if format != RulesNftables && format != RulesIptables {
+		return nil, fmt.Errorf("unsupported firewall rules format %q", format)
+	}
+
+	return &rulesFirewall{format: format, out: out}, nil
+}
+
+func (f *rulesFirewall) Open(_ context.Context, rule FirewallRule, _ api.Reporter) error {
+	lines := []string{fmt.Sprintf("# Allow Submariner's %s traffic, to run on %s", rule.Scope, formatNodes(rule.Nodes))}
+
+	for _, port := range rule.Ports {
+		protocol := strings.ToLower(port.Protocol)
+
+		if f.format == RulesNftables {
+			match := "meta l4proto " + protocol
+			if port.Port != 0 {
+				match = fmt.Sprintf("%s dport %d", protocol, port.Port)
+			}
+
+			lines = append(lines, fmt.Sprintf("nft add rule inet filter input %s accept comment %q", match, rule.Name()))
+
+			continue
+		}
+
+		match := "-p " + protocol
+		if port.Port != 0 {
+			match += fmt.Sprintf(" --dport %d", port.Port)
+		}
+
+		lines = append(lines, fmt.Sprintf("iptables -I INPUT %s -m comment --comment %s -j ACCEPT", match, rule.Name()))
+	}
+
+	return f.write(lines)
+}
+
+func (f *rulesFirewall) Close(_ context.Context, rule FirewallRule, _ api.Reporter) error {
+	lines := []string{fmt.Sprintf("# Disallow Submariner's %s traffic, to run on %s", rule.Scope, formatNodes(rule.Nodes))}
+
+	if f.format == RulesNftables {
+		lines = append(lines, fmt.Sprintf("nft -a list chain inet filter input | sed -n 's/.*comment \"%s\".*# handle \\([0-9]*\\)$/\\1/p'"+
+			" | xargs -r -n1 nft delete rule inet filter input handle", rule.Name()))
+	} else {
+		lines = append(lines, fmt.Sprintf("iptables -S INPUT | grep -- '--comment %s ' | sed 's/^-A/-D/' | xargs -r -L1 iptables",
+			rule.Name()))
+	}
+
+	return f.write(lines)
+}
+
+func (f *rulesFirewall) write(lines []string) error {
+	_, err := io.WriteString(f.out, strings.Join(lines, "\n")+"\n")
+	return errors.Wrap(err, "error writing the firewall rules")
+}
@@ -0,0 +1,68 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package onprem_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/onprem"
+)
+
+var _ = Describe("RulesFirewall", func() {
+	rule := onprem.FirewallRule{
+		Scope: onprem.ScopeGateway, Ports: []api.PortSpec{{Port: 4500, Protocol: "udp"}}, Nodes: []string{"node-1"},
+	}
+
+	It("should generate nftables rules", func() {
+		out := &bytes.Buffer{}
+		firewall, err := onprem.NewRulesFirewall(onprem.RulesNftables, out)
+		Expect(err).To(Succeed())
+
+		Expect(firewall.Open(context.TODO(), rule, api.NewLoggingReporter())).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(`nft add rule inet filter input udp dport 4500 accept comment "submariner-gateway"`))
+		Expect(out.String()).To(ContainSubstring("nodes node-1"))
+
+		out.Reset()
+		Expect(firewall.Close(context.TODO(), rule, api.NewLoggingReporter())).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(`comment "submariner-gateway"`))
+		Expect(out.String()).To(ContainSubstring("nft delete rule inet filter input handle"))
+	})
+
+	It("should generate iptables rules", func() {
+		out := &bytes.Buffer{}
+		firewall, err := onprem.NewRulesFirewall(onprem.RulesIptables, out)
+		Expect(err).To(Succeed())
+
+		Expect(firewall.Open(context.TODO(), rule, api.NewLoggingReporter())).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(
+			"iptables -I INPUT -p udp --dport 4500 -m comment --comment submariner-gateway -j ACCEPT"))
+
+		out.Reset()
+		Expect(firewall.Close(context.TODO(), rule, api.NewLoggingReporter())).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("--comment submariner-gateway "))
+	})
+
+	It("should reject an unknown format", func() {
+		_, err := onprem.NewRulesFirewall("pf", &bytes.Buffer{})
+		Expect(err).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,239 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// nolint:wrapcheck // The functions are wrappers so let the caller wrap errors.
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/secgroups"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+)
+
+//go:generate mockgen -source=./client.go -destination=./fake/client.go -package=fake
+
+// Interface wraps the actual gophercloud compute and network clients to allow for easier testing.
+type Interface interface {
+	ListServers(ctx context.Context, opts servers.ListOpts) ([]servers.Server, error)
+
+	ListSecurityGroups(ctx context.Context) ([]secgroups.SecurityGroup, error)
+	CreateSecurityGroup(ctx context.Context, opts secgroups.CreateOpts) (*secgroups.SecurityGroup, error)
+	DeleteSecurityGroup(ctx context.Context, id string) error
+	AddServerToSecurityGroup(ctx context.Context, serverID, groupName string) error
+	RemoveServerFromSecurityGroup(ctx context.Context, serverID, groupName string) error
+
+	ListSecurityGroupRules(ctx context.Context, opts rules.ListOpts) ([]rules.SecGroupRule, error)
+	CreateSecurityGroupRule(ctx context.Context, opts rules.CreateOpts) (*rules.SecGroupRule, error)
+	DeleteSecurityGroupRule(ctx context.Context, id string) error
+
+	ListSubnets(ctx context.Context, opts subnets.ListOpts) ([]subnets.Subnet, error)
+
+	ListPorts(ctx context.Context, opts ports.ListOpts) ([]ports.Port, error)
+	CreatePort(ctx context.Context, opts ports.CreateOpts) (*ports.Port, error)
+	DeletePort(ctx context.Context, id string) error
+
+	ListRouters(ctx context.Context, opts routers.ListOpts) ([]routers.Router, error)
+	UpdateRouterRoutes(ctx context.Context, id string, routes []routers.Route) error
+	AddRouterInterface(ctx context.Context, id string, opts routers.AddInterfaceOpts) error
+	RemoveRouterInterface(ctx context.Context, id string, opts routers.RemoveInterfaceOpts) error
+}
+
+type rhosClient struct {
+	compute *gophercloud.ServiceClient
+	network *gophercloud.ServiceClient
+}
+
+// NewClient creates the compute and network clients for the given region.
+func NewClient(providerClient *gophercloud.ProviderClient, region string) (Interface, error) {
+	computeClient, err := openstack.NewComputeV2(providerClient, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return nil, err
+	}
+
+	networkClient, err := openstack.NewNetworkV2(providerClient, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rhosClient{
+		compute: computeClient,
+		network: networkClient,
+	}, nil
+}
+
+func (c *rhosClient) computeClient(ctx context.Context) *gophercloud.ServiceClient {
+	return withContext(ctx, c.compute)
+}
+
+func (c *rhosClient) networkClient(ctx context.Context) *gophercloud.ServiceClient {
+	return withContext(ctx, c.network)
+}
+
+// withContext returns a copy of the service client whose requests are bound to the given context. Gophercloud only
+// supports a context per provider client, so the provider client is cloned with its own token lock. The clone
+// reauthenticates through the shared provider client, so that a refreshed token is seen by both.
+func withContext(ctx context.Context, serviceClient *gophercloud.ServiceClient) *gophercloud.ServiceClient {
+	shared := serviceClient.ProviderClient
+
+	providerClient := &gophercloud.ProviderClient{
+		IdentityBase:      shared.IdentityBase,
+		IdentityEndpoint:  shared.IdentityEndpoint,
+		EndpointLocator:   shared.EndpointLocator,
+		HTTPClient:        shared.HTTPClient,
+		UserAgent:         shared.UserAgent,
+		Context:           ctx,
+		RetryBackoffFunc:  shared.RetryBackoffFunc,
+		MaxBackoffRetries: shared.MaxBackoffRetries,
+		RetryFunc:         shared.RetryFunc,
+	}
+	providerClient.UseTokenLock()
+	providerClient.CopyTokenFrom(shared)
+
+	if shared.ReauthFunc != nil {
+		providerClient.ReauthFunc = func() error {
+			err := shared.Reauthenticate(providerClient.Token())
+			if err != nil {
+				return err
+			}
+
+			providerClient.CopyTokenFrom(shared)
+
+			return nil
+		}
+	}
+
+	contextClient := *serviceClient
+	contextClient.ProviderClient = providerClient
+
+	return &contextClient
+}
+
+func IsNotFoundError(err error) bool {
+	var notFoundError gophercloud.ErrDefault404
+
+	return errors.As(err, &notFoundError)
+}
+
+func (c *rhosClient) ListServers(ctx context.Context, opts servers.ListOpts) ([]servers.Server, error) {
+	pages, err := servers.List(c.computeClient(ctx), opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	return servers.ExtractServers(pages)
+}
+
+func (c *rhosClient) ListSecurityGroups(ctx context.Context) ([]secgroups.SecurityGroup, error) {
+	pages, err := secgroups.List(c.computeClient(ctx)).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	return secgroups.ExtractSecurityGroups(pages)
+}
+
+func (c *rhosClient) CreateSecurityGroup(ctx context.Context, opts secgroups.CreateOpts) (*secgroups.SecurityGroup, error) {
+	return secgroups.Create(c.computeClient(ctx), opts).Extract()
+}
+
+func (c *rhosClient) DeleteSecurityGroup(ctx context.Context, id string) error {
+	return secgroups.Delete(c.computeClient(ctx), id).ExtractErr()
+}
+
+func (c *rhosClient) AddServerToSecurityGroup(ctx context.Context, serverID, groupName string) error {
+	return secgroups.AddServer(c.computeClient(ctx), serverID, groupName).ExtractErr()
+}
+
+func (c *rhosClient) RemoveServerFromSecurityGroup(ctx context.Context, serverID, groupName string) error {
+	return secgroups.RemoveServer(c.computeClient(ctx), serverID, groupName).ExtractErr()
+}
+
+func (c *rhosClient) ListSecurityGroupRules(ctx context.Context, opts rules.ListOpts) ([]rules.SecGroupRule, error) {
+	pages, err := rules.List(c.networkClient(ctx), opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	return rules.ExtractRules(pages)
+}
+
+func (c *rhosClient) CreateSecurityGroupRule(ctx context.Context, opts rules.CreateOpts) (*rules.SecGroupRule, error) {
+	return rules.Create(c.networkClient(ctx), opts).Extract()
+}
+
+func (c *rhosClient) DeleteSecurityGroupRule(ctx context.Context, id string) error {
+	return rules.Delete(c.networkClient(ctx), id).ExtractErr()
+}
+
+func (c *rhosClient) ListSubnets(ctx context.Context, opts subnets.ListOpts) ([]subnets.Subnet, error) {
+	pages, err := subnets.List(c.networkClient(ctx), opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	return subnets.ExtractSubnets(pages)
+}
+
+func (c *rhosClient) ListPorts(ctx context.Context, opts ports.ListOpts) ([]ports.Port, error) {
+	pages, err := ports.List(c.networkClient(ctx), opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	return ports.ExtractPorts(pages)
+}
+
+func (c *rhosClient) CreatePort(ctx context.Context, opts ports.CreateOpts) (*ports.Port, error) {
+	return ports.Create(c.networkClient(ctx), opts).Extract()
+}
+
+func (c *rhosClient) DeletePort(ctx context.Context, id string) error {
+	return ports.Delete(c.networkClient(ctx), id).ExtractErr()
+}
+
+func (c *rhosClient) ListRouters(ctx context.Context, opts routers.ListOpts) ([]routers.Router, error) {
+	pages, err := routers.List(c.networkClient(ctx), opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	return routers.ExtractRouters(pages)
+}
+
+func (c *rhosClient) UpdateRouterRoutes(ctx context.Context, id string, routes []routers.Route) error {
+	_, err := routers.Update(c.networkClient(ctx), id, routers.UpdateOpts{Routes: &routes}).Extract()
+	return err
+}
+
+func (c *rhosClient) AddRouterInterface(ctx context.Context, id string, opts routers.AddInterfaceOpts) error {
+	_, err := routers.AddInterface(c.networkClient(ctx), id, opts).Extract()
+	return err
+}
+
+func (c *rhosClient) RemoveRouterInterface(ctx context.Context, id string, opts routers.RemoveInterfaceOpts) error {
+	_, err := routers.RemoveInterface(c.networkClient(ctx), id, opts).Extract()
+	return err
+}
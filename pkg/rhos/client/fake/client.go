@@ -0,0 +1,305 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./client.go
+
+// Package fake is a generated GoMock package.
+package fake
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	secgroups "github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/secgroups"
+	servers "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	routers "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	rules "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	ports "github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	subnets "github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AddRouterInterface mocks base method.
+func (m *MockInterface) AddRouterInterface(ctx context.Context, id string, opts routers.AddInterfaceOpts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRouterInterface", ctx, id, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRouterInterface indicates an expected call of AddRouterInterface.
+func (mr *MockInterfaceMockRecorder) AddRouterInterface(ctx, id, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRouterInterface", reflect.TypeOf((*MockInterface)(nil).AddRouterInterface), ctx, id, opts)
+}
+
+// AddServerToSecurityGroup mocks base method.
+func (m *MockInterface) AddServerToSecurityGroup(ctx context.Context, serverID, groupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddServerToSecurityGroup", ctx, serverID, groupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddServerToSecurityGroup indicates an expected call of AddServerToSecurityGroup.
+func (mr *MockInterfaceMockRecorder) AddServerToSecurityGroup(ctx, serverID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddServerToSecurityGroup", reflect.TypeOf((*MockInterface)(nil).AddServerToSecurityGroup), ctx, serverID, groupName)
+}
+
+// CreatePort mocks base method.
+func (m *MockInterface) CreatePort(ctx context.Context, opts ports.CreateOpts) (*ports.Port, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePort", ctx, opts)
+	ret0, _ := ret[0].(*ports.Port)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePort indicates an expected call of CreatePort.
+func (mr *MockInterfaceMockRecorder) CreatePort(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePort", reflect.TypeOf((*MockInterface)(nil).CreatePort), ctx, opts)
+}
+
+// CreateSecurityGroup mocks base method.
+func (m *MockInterface) CreateSecurityGroup(ctx context.Context, opts secgroups.CreateOpts) (*secgroups.SecurityGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecurityGroup", ctx, opts)
+	ret0, _ := ret[0].(*secgroups.SecurityGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecurityGroup indicates an expected call of CreateSecurityGroup.
+func (mr *MockInterfaceMockRecorder) CreateSecurityGroup(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecurityGroup", reflect.TypeOf((*MockInterface)(nil).CreateSecurityGroup), ctx, opts)
+}
+
+// CreateSecurityGroupRule mocks base method.
+func (m *MockInterface) CreateSecurityGroupRule(ctx context.Context, opts rules.CreateOpts) (*rules.SecGroupRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecurityGroupRule", ctx, opts)
+	ret0, _ := ret[0].(*rules.SecGroupRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecurityGroupRule indicates an expected call of CreateSecurityGroupRule.
+func (mr *MockInterfaceMockRecorder) CreateSecurityGroupRule(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecurityGroupRule", reflect.TypeOf((*MockInterface)(nil).CreateSecurityGroupRule), ctx, opts)
+}
+
+// DeletePort mocks base method.
+func (m *MockInterface) DeletePort(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePort", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePort indicates an expected call of DeletePort.
+func (mr *MockInterfaceMockRecorder) DeletePort(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePort", reflect.TypeOf((*MockInterface)(nil).DeletePort), ctx, id)
+}
+
+// DeleteSecurityGroup mocks base method.
+func (m *MockInterface) DeleteSecurityGroup(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecurityGroup", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecurityGroup indicates an expected call of DeleteSecurityGroup.
+func (mr *MockInterfaceMockRecorder) DeleteSecurityGroup(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecurityGroup", reflect.TypeOf((*MockInterface)(nil).DeleteSecurityGroup), ctx, id)
+}
+
+// DeleteSecurityGroupRule mocks base method.
+func (m *MockInterface) DeleteSecurityGroupRule(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecurityGroupRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecurityGroupRule indicates an expected call of DeleteSecurityGroupRule.
+func (mr *MockInterfaceMockRecorder) DeleteSecurityGroupRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecurityGroupRule", reflect.TypeOf((*MockInterface)(nil).DeleteSecurityGroupRule), ctx, id)
+}
+
+// ListPorts mocks base method.
+func (m *MockInterface) ListPorts(ctx context.Context, opts ports.ListOpts) ([]ports.Port, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPorts", ctx, opts)
+	ret0, _ := ret[0].([]ports.Port)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPorts indicates an expected call of ListPorts.
+func (mr *MockInterfaceMockRecorder) ListPorts(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPorts", reflect.TypeOf((*MockInterface)(nil).ListPorts), ctx, opts)
+}
+
+// ListRouters mocks base method.
+func (m *MockInterface) ListRouters(ctx context.Context, opts routers.ListOpts) ([]routers.Router, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRouters", ctx, opts)
+	ret0, _ := ret[0].([]routers.Router)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRouters indicates an expected call of ListRouters.
+func (mr *MockInterfaceMockRecorder) ListRouters(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRouters", reflect.TypeOf((*MockInterface)(nil).ListRouters), ctx, opts)
+}
+
+// ListSecurityGroupRules mocks base method.
+func (m *MockInterface) ListSecurityGroupRules(ctx context.Context, opts rules.ListOpts) ([]rules.SecGroupRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecurityGroupRules", ctx, opts)
+	ret0, _ := ret[0].([]rules.SecGroupRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecurityGroupRules indicates an expected call of ListSecurityGroupRules.
+func (mr *MockInterfaceMockRecorder) ListSecurityGroupRules(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecurityGroupRules", reflect.TypeOf((*MockInterface)(nil).ListSecurityGroupRules), ctx, opts)
+}
+
+// ListSecurityGroups mocks base method.
+func (m *MockInterface) ListSecurityGroups(ctx context.Context) ([]secgroups.SecurityGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecurityGroups", ctx)
+	ret0, _ := ret[0].([]secgroups.SecurityGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecurityGroups indicates an expected call of ListSecurityGroups.
+func (mr *MockInterfaceMockRecorder) ListSecurityGroups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecurityGroups", reflect.TypeOf((*MockInterface)(nil).ListSecurityGroups), ctx)
+}
+
+// ListServers mocks base method.
+func (m *MockInterface) ListServers(ctx context.Context, opts servers.ListOpts) ([]servers.Server, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServers", ctx, opts)
+	ret0, _ := ret[0].([]servers.Server)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServers indicates an expected call of ListServers.
+func (mr *MockInterfaceMockRecorder) ListServers(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServers", reflect.TypeOf((*MockInterface)(nil).ListServers), ctx, opts)
+}
+
+// ListSubnets mocks base method.
+func (m *MockInterface) ListSubnets(ctx context.Context, opts subnets.ListOpts) ([]subnets.Subnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubnets", ctx, opts)
+	ret0, _ := ret[0].([]subnets.Subnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubnets indicates an expected call of ListSubnets.
+func (mr *MockInterfaceMockRecorder) ListSubnets(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubnets", reflect.TypeOf((*MockInterface)(nil).ListSubnets), ctx, opts)
+}
+
+// RemoveRouterInterface mocks base method.
+func (m *MockInterface) RemoveRouterInterface(ctx context.Context, id string, opts routers.RemoveInterfaceOpts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRouterInterface", ctx, id, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRouterInterface indicates an expected call of RemoveRouterInterface.
+func (mr *MockInterfaceMockRecorder) RemoveRouterInterface(ctx, id, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRouterInterface", reflect.TypeOf((*MockInterface)(nil).RemoveRouterInterface), ctx, id, opts)
+}
+
+// RemoveServerFromSecurityGroup mocks base method.
+func (m *MockInterface) RemoveServerFromSecurityGroup(ctx context.Context, serverID, groupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveServerFromSecurityGroup", ctx, serverID, groupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveServerFromSecurityGroup indicates an expected call of RemoveServerFromSecurityGroup.
+func (mr *MockInterfaceMockRecorder) RemoveServerFromSecurityGroup(ctx, serverID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveServerFromSecurityGroup", reflect.TypeOf((*MockInterface)(nil).RemoveServerFromSecurityGroup), ctx, serverID, groupName)
+}
+
+// UpdateRouterRoutes mocks base method.
+func (m *MockInterface) UpdateRouterRoutes(ctx context.Context, id string, routes []routers.Route) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRouterRoutes", ctx, id, routes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRouterRoutes indicates an expected call of UpdateRouterRoutes.
+func (mr *MockInterfaceMockRecorder) UpdateRouterRoutes(ctx, id, routes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRouterRoutes", reflect.TypeOf((*MockInterface)(nil).UpdateRouterRoutes), ctx, id, routes)
+}
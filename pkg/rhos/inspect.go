@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rhos
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	"github.com/submariner-io/cloud-prepare/pkg/ocp"
+)
+
+// Inspect returns the ports opened by the internal security group and the peering ports plugged into the cluster's router.
+func (rc *rhosCloud) Inspect(ctx context.Context) (*api.CloudStatus, error) {
+	if rc.clientErr != nil {
+		return nil, rc.clientErr
+	}
+
+	internalPorts, err := rc.inspectSecurityGroup(ctx, rc.InfraID+internalSecurityGroupSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := rc.getRouter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	portList, err := rc.RHOSClient.ListPorts(ctx, ports.ListOpts{DeviceID: router.ID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the ports of router %q", router.Name)
+	}
+
+	status := &api.CloudStatus{InternalPorts: internalPorts, Peerings: []api.PeeringStatus{}}
+
+	for i := range portList {
+		peer := strings.TrimPrefix(portList[i].Name, rc.InfraID+"-")
+		if peer == portList[i].Name || !strings.HasSuffix(peer, "-peering") {
+			continue
+		}
+
+		status.Peerings = append(status.Peerings, api.PeeringStatus{
+			ID:    portList[i].ID,
+			Peer:  strings.TrimSuffix(peer, "-peering"),
+			State: portList[i].Status,
+		})
+	}
+
+	return status, nil
+}
+
+// Inspect returns the ports opened by the gateway security group, the gateway nodes and their machine sets on RHOS.
+func (d *ocpGatewayDeployer) Inspect(ctx context.Context) (*api.GatewayStatus, error) {
+	if d.clientErr != nil {
+		return nil, d.clientErr
+	}
+
+	publicPorts, err := d.inspectSecurityGroup(ctx, d.InfraID+gwSecurityGroupSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	gwNodes, err := d.K8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway nodes")
+	}
+
+	machineSets, err := ocp.ListGatewayMachineSets(ctx, d.msDeployer, d.InfraID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the gateway machine sets")
+	}
+
+	return &api.GatewayStatus{
+		PublicPorts:        publicPorts,
+		GatewaySubnets:     []string{},
+		GatewayNodes:       k8s.NodeNames(gwNodes),
+		GatewayMachineSets: machineSets,
+	}, nil
+}
+
+// inspectSecurityGroup returns the ports opened by the ingress rules of the named security group, if it exists.
+func (c *CloudInfo) inspectSecurityGroup(ctx context.Context, groupName string) ([]api.PortSpec, error) {
+	specs := []api.PortSpec{}
+
+	groupID, err := c.getSecurityGroupID(ctx, groupName)
+	if err != nil || groupID == "" {
+		return specs, err
+	}
+
+	groupRules, err := c.RHOSClient.ListSecurityGroupRules(ctx, rules.ListOpts{SecGroupID: groupID, Direction: string(rules.DirIngress)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the rules of security group %q", groupName)
+	}
+
+	for i := range groupRules {
+		specs = append(specs, api.PortSpec{Port: uint16(groupRules[i].PortRangeMin), Protocol: groupRules[i].Protocol})
+	}
+
+	return specs, nil
+}
@@ -18,23 +18,21 @@ limitations under the License.
 package rhos
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
-	"text/template"
 
-	"github.com/gophercloud/gophercloud"
-	"github.com/gophercloud/gophercloud/openstack"
 	"github.com/pkg/errors"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
 	"github.com/submariner-io/cloud-prepare/pkg/ocp"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 )
 
 type ocpGatewayDeployer struct {
+	ocp.MachineSetTemplate
 	CloudInfo
 	projectID       string
 	instanceType    string
@@ -42,19 +40,21 @@ type ocpGatewayDeployer struct {
 	cloudName       string
 	dedicatedGWNode bool
 	msDeployer      ocp.MachineSetDeployer
+	spot            *ocp.SpotConfig
 }
 
 // NewOcpGatewayDeployer returns a GatewayDeployer capable of deploying gateways using OCP.
 func NewOcpGatewayDeployer(info CloudInfo, msDeployer ocp.MachineSetDeployer, projectID, instanceType, image, cloudName string,
 	dedicatedGWNode bool) api.GatewayDeployer {
 	return &ocpGatewayDeployer{
-		CloudInfo:       info,
-		projectID:       projectID,
-		instanceType:    instanceType,
-		image:           image,
-		cloudName:       cloudName,
-		dedicatedGWNode: dedicatedGWNode,
-		msDeployer:      msDeployer,
+		MachineSetTemplate: ocp.NewMachineSetTemplate(machineSetYAML),
+		CloudInfo:          info.withRHOSClient(),
+		projectID:          projectID,
+		instanceType:       instanceType,
+		image:              image,
+		cloudName:          cloudName,
+		dedicatedGWNode:    dedicatedGWNode,
+		msDeployer:         msDeployer,
 	}
 }
 
@@ -69,16 +69,7 @@ type machineSetConfig struct {
 	CloudName           string
 }
 
-func (d *ocpGatewayDeployer) loadGatewayYAML(index, image string) ([]byte, error) {
-	var buf bytes.Buffer
-
-	// TODO: Not working properly, but we should revisit this as it makes more sense
-	// tpl, err := template.ParseFiles("pkg/aws/gw-machineset.yaml.template")
-	tpl, err := template.New("").Parse(machineSetYAML)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create machine set template")
-	}
-
+func (d *ocpGatewayDeployer) initMachineSet(index string) (*unstructured.Unstructured, error) {
 	tplVars := machineSetConfig{
 		Index:               index,
 		InfraID:             d.InfraID,
@@ -86,32 +77,14 @@ func (d *ocpGatewayDeployer) loadGatewayYAML(index, image string) ([]byte, error
 		InstanceType:        d.instanceType,
 		Region:              d.Region,
 		CloudName:           d.cloudName,
-		Image:               image,
+		Image:               d.image,
 		SubmarinerGWNodeTag: submarinerGatewayNodeTag,
 	}
 
-	err = tpl.Execute(&buf, tplVars)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to execute machine set template")
-	}
-
-	return buf.Bytes(), nil
+	return d.RenderMachineSet(tplVars)
 }
 
-func (d *ocpGatewayDeployer) initMachineSet(index string) (*unstructured.Unstructured, error) {
-	gatewayYAML, err := d.loadGatewayYAML(index, d.image)
-	if err != nil {
-		return nil, err
-	}
-
-	unstructDecoder := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-	machineSet := &unstructured.Unstructured{}
-	_, _, err = unstructDecoder.Decode(gatewayYAML, nil, machineSet)
-
-	return machineSet, errors.Wrap(err, "error decoding message gateway yaml")
-}
-
-func (d *ocpGatewayDeployer) deployGateway(index string) error {
+func (d *ocpGatewayDeployer) deployGateway(ctx context.Context, index string) error {
 	machineSet, err := d.initMachineSet(index)
 	if err != nil {
 		return err
@@ -121,7 +94,7 @@ func (d *ocpGatewayDeployer) deployGateway(index string) error {
 		// TODO: use machineSetClient.List() instead of hard coding.
 		workerNodeList := []string{d.InfraID + "-worker-0", d.InfraID + "-worker-1", d.InfraID + "-worker-2"}
 
-		d.image, err = d.msDeployer.GetWorkerNodeImage(workerNodeList, machineSet, d.InfraID)
+		d.image, err = d.msDeployer.GetWorkerNodeImageWithContext(ctx, workerNodeList, machineSet, d.InfraID)
 		if err != nil {
 			return errors.Wrap(err, "error getting the worker image")
 		}
@@ -132,42 +105,81 @@ func (d *ocpGatewayDeployer) deployGateway(index string) error {
 		}
 	}
 
-	return errors.Wrap(d.msDeployer.Deploy(machineSet), "failed to deploy submariner gateway node")
+	err = d.msDeployer.DeployWithContext(ctx, machineSet)
+	if err != nil {
+		return errors.Wrap(err, "failed to deploy submariner gateway node")
+	}
+
+	return api.RecordResources(ctx, d.StateStore, d.machineSetResource(index))
 }
 
-func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
-	reporter.Started("Configuring the required firewall rules for inter-cluster traffic")
+// SetSpotInstances requests dedicated gateway nodes on spot instances. OpenStack has no spot instances, so the gateways
+// always fall back to on-demand instances.
+func (d *ocpGatewayDeployer) SetSpotInstances(config *ocp.SpotConfig) {
+	d.spot = config
+}
 
-	computeClient, err := openstack.NewComputeV2(d.Client, gophercloud.EndpointOpts{Region: d.Region})
-	if err != nil {
-		return errors.Wrap(err, "error creating the compute client")
+// UpdatePublicPorts updates the source CIDRs of the public ports in the gateway security group, without redeploying the
+// gateways.
+func (d *ocpGatewayDeployer) UpdatePublicPorts(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	if d.clientErr != nil {
+		reporter.Failed(d.clientErr)
+		return d.clientErr
 	}
 
-	networkClient, err := openstack.NewNetworkV2(d.Client, gophercloud.EndpointOpts{Region: d.Region})
-	if err != nil {
-		return errors.Wrap(err, "error creating the network client")
+	return d.updatePublicPorts(ctx, d.InfraID+gwSecurityGroupSuffix, &input, reporter)
+}
+
+func (d *ocpGatewayDeployer) Deploy(input api.GatewayDeployInput, reporter api.Reporter) error {
+	return d.DeployWithContext(context.Background(), input, reporter)
+}
+
+func (d *ocpGatewayDeployer) DeployWithContext(ctx context.Context, input api.GatewayDeployInput, reporter api.Reporter) error {
+	if d.clientErr != nil {
+		reporter.Failed(d.clientErr)
+		return d.clientErr
 	}
 
 	groupName := d.InfraID + gwSecurityGroupSuffix
-	if err := d.createGWSecurityGroup(input.PublicPorts, groupName, computeClient, networkClient); err != nil {
-		return errors.Wrap(err, "creating gateway security group failed")
+	groupStep := api.ResourceStep(providerName, api.ResourceSecurityGroup, groupName)
+
+	api.StepStarted(reporter, groupStep, "Configuring the required firewall rules for inter-cluster traffic")
+
+	if err := d.createGWSecurityGroup(ctx, &input, groupName); err != nil {
+		err = errors.Wrap(err, "creating gateway security group failed")
+		api.StepFailed(reporter, groupStep, err)
+
+		return err
 	}
 
-	reporter.Succeeded("Opened External ports %q in security group %q on RHOS",
+	api.StepSucceeded(reporter, groupStep, "Opened External ports %q in security group %q on RHOS",
 		formatPorts(input.PublicPorts), groupName)
 
 	reporter.Started("Configuring the required number of Submariner gateway pods")
 
-	gwNodes, err := d.K8sClient.ListGatewayNodes()
+	gwNodes, err := d.K8sClient.ListGatewayNodesWithContext(ctx)
 	if err != nil {
-		return errors.Wrap(err, "listing the existing gatway nodes failed")
+		err = errors.Wrap(err, "listing the existing gatway nodes failed")
+		reporter.Failed(err)
+
+		return err
 	}
 
-	return d.deployGWNode(gwNodes, input.Gateways, groupName, computeClient, reporter)
+	// Reattach the gateway security group to existing gateways it may have been detached from.
+	for i := range gwNodes.Items {
+		if err := d.openGatewayPort(ctx, groupName, gwNodes.Items[i].Name); err != nil {
+			err = errors.Wrap(err, "failed to open the Submariner gateway port")
+			reporter.Failed(err)
+
+			return err
+		}
+	}
+
+	return d.deployGWNode(ctx, gwNodes, input.Gateways, groupName, reporter)
 }
 
-func (d *ocpGatewayDeployer) deployGWNode(gwNodes *v1.NodeList, gatewayCount int, groupName string,
-	computeClient *gophercloud.ServiceClient, reporter api.Reporter) error {
+func (d *ocpGatewayDeployer) deployGWNode(ctx context.Context, gwNodes *v1.NodeList, gatewayCount int, groupName string,
+	reporter api.Reporter) error {
 	numGatewayNodes := len(gwNodes.Items)
 
 	if numGatewayNodes == gatewayCount {
@@ -175,78 +187,210 @@ func (d *ocpGatewayDeployer) deployGWNode(gwNodes *v1.NodeList, gatewayCount int
 		return nil
 	}
 
-	// Currently, we only support increasing the number of Gateway nodes which could be a valid use-case
-	// to convert a non-HA deployment to an HA deployment. We are not supporting decreasing the Gateway
-	// nodes (for now) as it might impact the datapath if we accidentally delete the active GW node.
-	if numGatewayNodes < gatewayCount {
-		gatewayNodesToDeploy := gatewayCount - numGatewayNodes
-
-		workerNodes, err := d.K8sClient.ListNodesWithLabel("node-role.kubernetes.io/worker")
-		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("failed to list k8s nodes in project %q", d.projectID))
+	if numGatewayNodes > gatewayCount {
+		// The default policy keeps whatever gateways already exist.
+		if gatewayCount == 0 {
+			reporter.Succeeded("Current Submariner gateways match the required number of Submariner gateways")
+			return nil
 		}
 
-		nodes := workerNodes.Items
+		return d.removeSurplusGateways(ctx, gwNodes, gatewayCount, groupName, reporter)
+	}
 
-		for i := range nodes {
-			if d.dedicatedGWNode {
-				reporter.Started(fmt.Sprintf("Deploying dedicated gateway node %s",
-					d.InfraID+"-submariner-gw"+strconv.Itoa(i)))
+	gatewayNodesToDeploy := gatewayCount - numGatewayNodes
 
-				err = d.deployGateway(strconv.Itoa(i))
+	workerNodes, err := d.K8sClient.ListNodesWithLabelWithContext(ctx, "node-role.kubernetes.io/worker")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to list k8s nodes in project %q", d.projectID)
+		reporter.Failed(err)
 
-				if err != nil {
-					reporter.Failed(err)
-					return err
-				}
-			} else {
-				alreadyTagged := nodes[i].GetLabels()[submarinerGatewayNodeTag]
-				if alreadyTagged == "true" {
-					continue
-				}
+		return err
+	}
+
+	nodes := workerNodes.Items
 
-				reporter.Started(fmt.Sprintf("Configuring worker node %q as Submariner gateway node", nodes[i].Name))
+	for i := range nodes {
+		if d.dedicatedGWNode {
+			gatewayName := d.InfraID + "-submariner-gw" + strconv.Itoa(i)
+			machineSetStep := d.machineSetStep(strconv.Itoa(i))
 
-				err := d.K8sClient.AddGWLabelOnNode(nodes[i].Name)
-				if err != nil {
-					return errors.Wrapf(err, "failed to label the node %q as Submariner gateway node", nodes[i].Name)
-				}
+			api.StepStarted(reporter, machineSetStep, "Deploying dedicated gateway node %s", gatewayName)
+
+			err = d.deployGateway(ctx, strconv.Itoa(i))
+			if err != nil {
+				api.StepFailed(reporter, machineSetStep, err)
+				return err
 			}
 
-			if err := d.openGatewayPort(groupName, nodes[i].Name, computeClient); err != nil {
-				return errors.Wrap(err, "failed to open the Submariner gateway port")
+			if d.spot != nil {
+				reporter.Warning("OpenStack has no spot instances, falling back to on-demand instances")
 			}
 
-			gatewayNodesToDeploy--
-			if gatewayNodesToDeploy <= 0 {
-				reporter.Succeeded("Successfully deployed Submariner gateway node")
-				return nil
+			api.StepSucceeded(reporter, machineSetStep, "Deployed dedicated gateway node %s on on-demand instances", gatewayName)
+		} else {
+			alreadyTagged := nodes[i].GetLabels()[submarinerGatewayNodeTag]
+			if alreadyTagged == "true" {
+				continue
 			}
 
-			if gatewayNodesToDeploy > 0 {
-				reporter.Failed(errors.Wrap(err, "there are insufficient nodes to deploy the required number of gateways"))
-				return nil
+			reporter.Started(fmt.Sprintf("Configuring worker node %q as Submariner gateway node", nodes[i].Name))
+
+			err := d.K8sClient.AddGWLabelOnNodeWithContext(ctx, nodes[i].Name)
+			if err != nil {
+				err = errors.Wrapf(err, "failed to label the node %q as Submariner gateway node", nodes[i].Name)
+				reporter.Failed(err)
+
+				return err
 			}
+
+			err = api.RecordResources(ctx, d.StateStore, nodeLabelResource(nodes[i].Name))
+			if err != nil {
+				reporter.Failed(err)
+				return err
+			}
+		}
+
+		if err := d.openGatewayPort(ctx, groupName, nodes[i].Name); err != nil {
+			err = errors.Wrap(err, "failed to open the Submariner gateway port")
+			reporter.Failed(err)
+
+			return err
+		}
+
+		gatewayNodesToDeploy--
+		if gatewayNodesToDeploy <= 0 {
+			reporter.Succeeded("Successfully deployed Submariner gateway node")
+			return nil
+		}
+	}
+
+	reporter.Warning("There are insufficient nodes to deploy the required number of gateways; %d gateways are missing",
+		gatewayNodesToDeploy)
+
+	return nil
+}
+
+// removeSurplusGateways removes gateways until only the desired number remain, keeping the oldest gateways spread
+// across zones. Dedicated gateway nodes are deleted, other nodes are reverted to plain worker nodes.
+func (d *ocpGatewayDeployer) removeSurplusGateways(ctx context.Context, gwNodes *v1.NodeList, desired int, groupName string,
+	reporter api.Reporter) error {
+	for _, node := range k8s.SurplusGatewayNodes(gwNodes, desired) {
+		reporter.Started(fmt.Sprintf("Removing the Submariner gateway configuration from node %q", node.Name))
+
+		err := d.removeGateway(ctx, node, groupName)
+		if err != nil {
+			reporter.Failed(err)
+			return err
 		}
+
+		reporter.Succeeded("Removed the Submariner gateway configuration from node %q", node.Name)
 	}
 
 	return nil
 }
 
+func (d *ocpGatewayDeployer) removeGateway(ctx context.Context, node *v1.Node, groupName string) error {
+	if index, dedicated := d.gatewayIndex(node); dedicated {
+		err := d.deleteGateway(ctx, index)
+		if err != nil {
+			return errors.Wrapf(err, "error deleting the Submariner gateway node %q", node.Name)
+		}
+
+		return api.ForgetResources(ctx, d.StateStore, d.machineSetResource(index))
+	}
+
+	err := d.removeGWFirewallRules(ctx, groupName, node.Name)
+	if err != nil {
+		return errors.Wrapf(err, "error deleting the Submariner gateway security group rules from node: %q", node.Name)
+	}
+
+	err = d.K8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, node)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove labels from node %q", node.Name)
+	}
+
+	return api.ForgetResources(ctx, d.StateStore, nodeLabelResource(node.Name))
+}
+
+// gatewayIndex returns the index of the machine set which deployed the given node, if it is a dedicated gateway node.
+// Machines, and so their nodes, are named after their machine set with a random suffix.
+func (d *ocpGatewayDeployer) gatewayIndex(node *v1.Node) (string, bool) {
+	machineName := node.Name
+
+	machineInfo := strings.Split(node.GetAnnotations()["machine.openshift.io/machine"], "/")
+	if len(machineInfo) > 1 {
+		machineName = machineInfo[1]
+	}
+
+	prefix := d.InfraID + "-submariner-gw-"
+	if !strings.HasPrefix(machineName, prefix) {
+		return "", false
+	}
+
+	index := strings.TrimPrefix(machineName, prefix)
+	if i := strings.LastIndex(index, "-"); i >= 0 {
+		index = index[:i]
+	}
+
+	return index, true
+}
+
 func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
+	return d.CleanupWithContext(context.Background(), reporter)
+}
+
+func (d *ocpGatewayDeployer) CleanupWithContext(ctx context.Context, reporter api.Reporter) error {
+	if d.clientErr != nil {
+		reporter.Failed(d.clientErr)
+		return d.clientErr
+	}
+
 	reporter.Started("Removing the Submariner gateway configuration from nodes ")
 
-	computeClient, err := openstack.NewComputeV2(d.Client, gophercloud.EndpointOpts{Region: d.Region})
+	groupName := d.InfraID + gwSecurityGroupSuffix
+
+	cleanedUp, err := d.cleanupRecordedGateways(ctx, groupName, reporter)
 	if err != nil {
-		return errors.Wrapf(err, "error creating the compute client for the region: %q", d.Region)
+		reporter.Failed(err)
+		return err
 	}
 
-	gwNodesList, err := d.K8sClient.ListGatewayNodes()
+	if !cleanedUp {
+		err = d.cleanupDiscoveredGateways(ctx, groupName, reporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	reporter.Succeeded("Successfully removed the Submariner gateway configuration from the nodes")
+
+	groupStep := api.ResourceStep(providerName, api.ResourceSecurityGroup, groupName)
+
+	api.StepStarted(reporter, groupStep, "Deleting the Submariner gateway security group")
+
+	err = d.deleteSG(ctx, groupName)
 	if err != nil {
-		return errors.Wrap(err, "error listing the Submariner gateway nodes")
+		err = errors.Wrap(err, "error deleting the Submariner gateway security group")
+		api.StepFailed(reporter, groupStep, err)
+
+		return err
+	}
+
+	api.StepSucceeded(reporter, groupStep, "Successfully deleted the Submariner Submariner gateway firewall rules")
+
+	return nil
+}
+
+// cleanupDiscoveredGateways removes the gateway configuration from the nodes currently labeled as gateways.
+func (d *ocpGatewayDeployer) cleanupDiscoveredGateways(ctx context.Context, groupName string, reporter api.Reporter) error {
+	gwNodesList, err := d.K8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "error listing the Submariner gateway nodes")
+		reporter.Failed(err)
+
+		return err
 	}
 
-	groupName := d.InfraID + gwSecurityGroupSuffix
 	gwNodes := gwNodesList.Items
 
 	for i := range gwNodes {
@@ -257,45 +401,44 @@ func (d *ocpGatewayDeployer) Cleanup(reporter api.Reporter) error {
 
 		// If the instance name matches with d.InfraID + "-submariner-gw-", it implies that
 		// the gateway node was deployed using the OCPMachineSet API otherwise it's an existing worker node.
-		prefix := d.InfraID + "-submariner-gw-"
-		if strings.HasPrefix(gwNodes[i].Name, prefix) {
-			reporter.Started(fmt.Sprintf("Deleting the gateway instance %q", gwNodes[i].Name))
+		if index, dedicated := d.gatewayIndex(&gwNodes[i]); dedicated {
+			machineSetStep := d.machineSetStep(index)
+
+			api.StepStarted(reporter, machineSetStep, "Deleting the gateway instance %q", gwNodes[i].Name)
 
-			err = d.deleteGateway(strconv.Itoa(i))
+			err = d.deleteGateway(ctx, index)
 			if err != nil {
-				return errors.Wrapf(err, "error deleting the Submariner gateway security group rules from node: %q",
+				err = errors.Wrapf(err, "error deleting the Submariner gateway security group rules from node: %q",
 					gwNodes[i].Name)
+				api.StepFailed(reporter, machineSetStep, err)
+
+				return err
 			}
 
-			reporter.Succeeded("Successfully deleted the instance")
+			api.StepSucceeded(reporter, machineSetStep, "Successfully deleted the instance")
 		} else {
-			err = d.removeGWFirewallRules(groupName, gwNodes[i].Name, computeClient)
+			err = d.removeGWFirewallRules(ctx, groupName, gwNodes[i].Name)
 			if err != nil {
-				return errors.Wrapf(err, "error deleting the Submariner gateway security group rules from node: %q",
+				err = errors.Wrapf(err, "error deleting the Submariner gateway security group rules from node: %q",
 					gwNodes[i].Name)
+				reporter.Failed(err)
+
+				return err
 			}
 
 			reporter.Started(fmt.Sprintf("Removing the gateway configuration from instance %q", gwNodes[i].Name))
-			err = d.K8sClient.RemoveGWLabelFromWorkerNode(&gwNodes[i])
+			err = d.K8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, &gwNodes[i])
 			if err != nil {
-				return errors.Wrap(err, "failed to remove labels from worker node")
+				err = errors.Wrap(err, "failed to remove labels from worker node")
+				reporter.Failed(err)
+
+				return err
 			}
 
 			reporter.Succeeded("Successfully reconfigured the instance")
 		}
 	}
 
-	reporter.Succeeded("Successfully removed the Submariner gateway configuration from the nodes")
-
-	reporter.Started("Deleting the Submariner gateway security group")
-
-	err = d.deleteSG(groupName, computeClient)
-	if err != nil {
-		return errors.Wrap(err, "error deleting the Submariner gateway security group")
-	}
-
-	reporter.Succeeded("Successfully deleted the Submariner Submariner gateway firewall rules")
-
 	return nil
 }
 
@@ -308,11 +451,16 @@ func formatPorts(ports []api.PortSpec) string {
 	return strings.Join(portStrs, ", ")
 }
 
-func (d *ocpGatewayDeployer) deleteGateway(index string) error {
+// machineSetStep returns the step operating on the gateway machine set with the given index.
+func (d *ocpGatewayDeployer) machineSetStep(index string) api.Step {
+	return api.ResourceStep(providerName, api.ResourceMachineSet, d.machineSetResource(index).ID)
+}
+
+func (d *ocpGatewayDeployer) deleteGateway(ctx context.Context, index string) error {
 	machineSet, err := d.initMachineSet(index)
 	if err != nil {
 		return err
 	}
 
-	return errors.Wrap(d.msDeployer.Delete(machineSet), "error deleting the submariner gateway node")
+	return errors.Wrap(d.msDeployer.DeleteWithContext(ctx, machineSet), "error deleting the submariner gateway node")
 }
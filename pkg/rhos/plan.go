@@ -0,0 +1,249 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rhos
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Plan returns the security group changes PrepareForSubmariner would make on RHOS, including the rules and server
+// attachments missing from an existing internal security group.
+func (rc *rhosCloud) Plan(ctx context.Context, input api.PrepareForSubmarinerInput) ([]api.Action, error) {
+	if rc.clientErr != nil {
+		return nil, rc.clientErr
+	}
+
+	groupName := rc.InfraID + internalSecurityGroupSuffix
+
+	actions, err := rc.planSecurityGroup(ctx, groupName, input.InternalPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	serverList, err := rc.serversWithoutGroup(ctx, rc.InfraID, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range serverList {
+		actions = append(actions, planAttachSecurityGroup(serverList[i].Name, groupName))
+	}
+
+	return actions, nil
+}
+
+// Plan returns the security group and gateway node changes, including the removal of surplus gateways, Deploy would
+// make on RHOS.
+func (d *ocpGatewayDeployer) Plan(ctx context.Context, input api.GatewayDeployInput) ([]api.Action, error) {
+	if d.clientErr != nil {
+		return nil, d.clientErr
+	}
+
+	groupName := d.InfraID + gwSecurityGroupSuffix
+
+	actions, err := d.planGatewaySecurityGroup(ctx, groupName, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	gwNodes, err := d.K8sClient.ListGatewayNodesWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the existing gatway nodes failed")
+	}
+
+	for i := range gwNodes.Items {
+		serverList, err := d.serversWithoutGroup(ctx, gwNodes.Items[i].Name, groupName)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range serverList {
+			actions = append(actions, planAttachSecurityGroup(serverList[j].Name, groupName))
+		}
+	}
+
+	gatewayNodesToDeploy := input.Gateways - len(gwNodes.Items)
+	if gatewayNodesToDeploy < 0 && input.Gateways > 0 {
+		for _, node := range k8s.SurplusGatewayNodes(gwNodes, input.Gateways) {
+			actions = append(actions, d.planGatewayRemoval(node, groupName)...)
+		}
+	}
+
+	if gatewayNodesToDeploy <= 0 {
+		return actions, nil
+	}
+
+	workerNodes, err := d.K8sClient.ListNodesWithLabelWithContext(ctx, "node-role.kubernetes.io/worker")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list k8s nodes in project %q", d.projectID)
+	}
+
+	nodes := workerNodes.Items
+
+	for i := range nodes {
+		if d.dedicatedGWNode {
+			machineSet, err := d.initMachineSet(strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+
+			actions = append(actions, api.Action{
+				Kind:     api.ActionDeployMachineSet,
+				Resource: machineSet.GetName(),
+				Parameters: map[string]string{
+					"namespace":     machineSet.GetNamespace(),
+					"instance-type": d.instanceType,
+					"image":         d.image,
+				},
+			})
+		} else {
+			if nodes[i].GetLabels()[submarinerGatewayNodeTag] == "true" {
+				continue
+			}
+
+			actions = append(actions, api.Action{
+				Kind:     api.ActionLabelNode,
+				Resource: nodes[i].Name,
+			})
+		}
+
+		actions = append(actions, planAttachSecurityGroup(nodes[i].Name, groupName))
+
+		gatewayNodesToDeploy--
+		if gatewayNodesToDeploy <= 0 {
+			break
+		}
+	}
+
+	return actions, nil
+}
+
+func (d *ocpGatewayDeployer) planGatewayRemoval(node *v1.Node, groupName string) []api.Action {
+	if index, dedicated := d.gatewayIndex(node); dedicated {
+		return []api.Action{{
+			Kind:     api.ActionDeleteMachineSet,
+			Resource: d.InfraID + "-submariner-gw-" + index,
+		}}
+	}
+
+	return []api.Action{
+		{
+			Kind:       api.ActionDetachSecurityGroup,
+			Resource:   node.Name,
+			Parameters: map[string]string{"security-group": groupName},
+		},
+		{
+			Kind:     api.ActionUnlabelNode,
+			Resource: node.Name,
+		},
+	}
+}
+
+// planSecurityGroup returns the actions needed to create the named security group if it's missing, and to add the
+// rules opening the given ports to the group itself which it lacks.
+func (c *CloudInfo) planSecurityGroup(ctx context.Context, groupName string, ports []api.PortSpec) ([]api.Action, error) {
+	groupID, err := c.getSecurityGroupID(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []api.Action{}
+
+	if groupID == "" {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionCreateSecurityGroup,
+			Resource: groupName,
+		})
+	} else {
+		ports, err = c.missingSGRules(ctx, groupID, groupID, ports)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, port := range ports {
+		actions = append(actions, planSGRule(api.ActionAuthorizeIngress, groupName, port, "remote-group", groupName))
+	}
+
+	return actions, nil
+}
+
+// planGatewaySecurityGroup returns the actions needed to create the gateway security group if it's missing, to open the
+// public ports to their source CIDRs, and to delete the rules opening them to the CIDRs which are no longer allowed.
+func (c *CloudInfo) planGatewaySecurityGroup(ctx context.Context, groupName string, input *api.GatewayDeployInput) ([]api.Action, error) {
+	groupID, err := c.getSecurityGroupID(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []api.Action{}
+	existingRules := []rules.SecGroupRule{}
+
+	if groupID == "" {
+		actions = append(actions, api.Action{
+			Kind:     api.ActionCreateSecurityGroup,
+			Resource: groupName,
+		})
+	} else {
+		existingRules, err = c.listIngressRules(ctx, groupID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, port := range input.PublicPorts {
+		added, removed := api.DiffSourceCIDRs(prefixes(publicSGRules(existingRules, port)), input.SourceCIDRs(port))
+
+		for _, cidr := range added {
+			actions = append(actions, planSGRule(api.ActionAuthorizeIngress, groupName, port, "remote-ip-prefix", cidr))
+		}
+
+		for _, cidr := range removed {
+			actions = append(actions, planSGRule(api.ActionRevokeIngress, groupName, port, "remote-ip-prefix", cidr))
+		}
+	}
+
+	return actions, nil
+}
+
+func planSGRule(kind api.ActionKind, groupName string, port api.PortSpec, sourceKey, source string) api.Action {
+	return api.Action{
+		Kind:     kind,
+		Resource: groupName,
+		Parameters: map[string]string{
+			"protocol": port.Protocol,
+			"port":     strconv.Itoa(int(port.Port)),
+			sourceKey:  source,
+		},
+	}
+}
+
+func planAttachSecurityGroup(serverName, groupName string) api.Action {
+	return api.Action{
+		Kind:       api.ActionAttachSecurityGroup,
+		Resource:   serverName,
+		Parameters: map[string]string{"security-group": groupName},
+	}
+}
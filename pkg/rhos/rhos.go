@@ -18,17 +18,17 @@ limitations under the License.
 package rhos
 
 import (
-	"github.com/gophercloud/gophercloud"
-	"github.com/gophercloud/gophercloud/openstack"
+	"context"
+
 	"github.com/pkg/errors"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 )
 
 const (
+	providerName                = "rhos"
 	gwSecurityGroupSuffix       = "-submariner-gw-sg"
 	internalSecurityGroupSuffix = "-submariner-internal-sg"
 	submarinerGatewayNodeTag    = "submariner-io-gateway-node"
-	allNetworkCIDR              = "0.0.0.0/0"
 )
 
 type rhosCloud struct {
@@ -38,63 +38,120 @@ type rhosCloud struct {
 // NewCloud creates a new api.Cloud instance which can prepare RHOS for Submariner to be deployed on it.
 func NewCloud(info CloudInfo) api.Cloud {
 	return &rhosCloud{
-		CloudInfo: info,
+		CloudInfo: info.withRHOSClient(),
 	}
 }
 
 func (rc *rhosCloud) PrepareForSubmariner(input api.PrepareForSubmarinerInput, reporter api.Reporter) error {
-	reporter.Started("Opening internal ports for intra-cluster communications on RHOS")
+	return rc.PrepareForSubmarinerWithContext(context.Background(), input, reporter)
+}
 
-	computeClient, err := openstack.NewComputeV2(rc.Client, gophercloud.EndpointOpts{Region: rc.Region})
-	if err != nil {
-		return errors.WithMessage(err, "Error creating the compute client")
+func (rc *rhosCloud) PrepareForSubmarinerWithContext(ctx context.Context, input api.PrepareForSubmarinerInput,
+	reporter api.Reporter) error {
+	if rc.clientErr != nil {
+		reporter.Failed(rc.clientErr)
+		return rc.clientErr
 	}
 
-	networkClient, err := openstack.NewNetworkV2(rc.Client, gophercloud.EndpointOpts{Region: rc.Region})
-	if err != nil {
-		return errors.WithMessage(err, "Error creating the network client")
-	}
+	step := rc.internalSGStep()
 
-	if err := rc.openInternalPorts(rc.InfraID, input.InternalPorts, computeClient, networkClient); err != nil {
-		reporter.Failed(err)
+	api.StepStarted(reporter, step, "Opening internal ports for intra-cluster communications on RHOS")
+
+	if err := rc.openInternalPorts(ctx, rc.InfraID, input.InternalPorts); err != nil {
+		api.StepFailed(reporter, step, err)
 		return err
 	}
 
-	reporter.Succeeded("Opened internal ports %q for intra-cluster communications on RHOS",
+	api.StepSucceeded(reporter, step, "Opened internal ports %q for intra-cluster communications on RHOS",
 		formatPorts(input.InternalPorts))
 
 	return nil
 }
 
 func (rc *rhosCloud) CleanupAfterSubmariner(reporter api.Reporter) error {
-	reporter.Started("Revoking intra-cluster communication permissions")
+	return rc.CleanupAfterSubmarinerWithContext(context.Background(), reporter)
+}
 
-	computeClient, err := openstack.NewComputeV2(rc.Client, gophercloud.EndpointOpts{Region: rc.Region})
-	if err != nil {
-		return errors.WithMessagef(err, "creating compute client failed for region %q", rc.Region)
+func (rc *rhosCloud) CleanupAfterSubmarinerWithContext(ctx context.Context, reporter api.Reporter) error {
+	if rc.clientErr != nil {
+		reporter.Failed(rc.clientErr)
+		return rc.clientErr
 	}
 
-	if err := rc.removeInternalFirewallRules(rc.InfraID, computeClient); err != nil {
-		reporter.Failed(err)
+	step := rc.internalSGStep()
+
+	api.StepStarted(reporter, step, "Revoking intra-cluster communication permissions")
+
+	if err := rc.removeInternalFirewallRules(ctx, rc.InfraID); err != nil {
+		api.StepFailed(reporter, step, err)
 		return err
 	}
 
-	if err := rc.deleteSG(rc.InfraID+internalSecurityGroupSuffix, computeClient); err != nil {
+	if err := rc.deleteSG(ctx, rc.InfraID+internalSecurityGroupSuffix); err != nil {
+		api.StepFailed(reporter, step, err)
 		return err
 	}
 
-	reporter.Succeeded("Revoked intra-cluster communication permissions")
+	api.StepSucceeded(reporter, step, "Revoked intra-cluster communication permissions")
 
 	return nil
 }
 
+// internalSGStep returns the step operating on the internal security group.
+func (rc *rhosCloud) internalSGStep() api.Step {
+	return api.ResourceStep(providerName, api.ResourceSecurityGroup, rc.InfraID+internalSecurityGroupSuffix)
+}
+
 // CreateVpcPeering Creates a VPC Peering to the target cloud. Only the same
 // Cloud Provider is supported.
 func (rc *rhosCloud) CreateVpcPeering(target api.Cloud, reporter api.Reporter) error {
-	return errors.New("RHOS CreateVpcPeering not implemented")
+	return rc.CreateVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (rc *rhosCloud) CreateVpcPeeringWithContext(ctx context.Context, target api.Cloud, reporter api.Reporter) error {
+	rhosTarget, ok := target.(*rhosCloud)
+	if !ok {
+		err := errors.New("only RHOS clients are supported")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	if err := rc.peeringClientError(rhosTarget); err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	return rc.createVpcPeering(ctx, rhosTarget, reporter)
 }
 
 // CleanupVpcPeering Removes the VPC Peering with the target cloud and the related Routes.
 func (rc *rhosCloud) CleanupVpcPeering(target api.Cloud, reporter api.Reporter) error {
-	return errors.New("RHOS CleanupVpcPeering not implemented")
+	return rc.CleanupVpcPeeringWithContext(context.Background(), target, reporter)
+}
+
+func (rc *rhosCloud) CleanupVpcPeeringWithContext(ctx context.Context, target api.Cloud, reporter api.Reporter) error {
+	rhosTarget, ok := target.(*rhosCloud)
+	if !ok {
+		err := errors.New("only RHOS clients are supported")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	if err := rc.peeringClientError(rhosTarget); err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	return rc.cleanupVpcPeering(ctx, rhosTarget, reporter)
+}
+
+// peeringClientError returns the error creating the RHOS client of either peer, if any.
+func (rc *rhosCloud) peeringClientError(target *rhosCloud) error {
+	if rc.clientErr != nil {
+		return rc.clientErr
+	}
+
+	return target.clientErr
 }
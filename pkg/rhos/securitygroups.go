@@ -18,230 +18,419 @@ limitations under the License.
 package rhos
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/secgroups"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
-	"github.com/gophercloud/gophercloud/pagination"
 	"github.com/pkg/errors"
 	"github.com/submariner-io/cloud-prepare/pkg/api"
 	"github.com/submariner-io/cloud-prepare/pkg/k8s"
+	rhosclient "github.com/submariner-io/cloud-prepare/pkg/rhos/client"
 )
 
 type CloudInfo struct {
-	Client    *gophercloud.ProviderClient
-	InfraID   string
-	Region    string
-	K8sClient k8s.Interface
+	// Client is the provider client which the RHOS compute and network clients are created from, if RHOSClient isn't set.
+	//
+	// Deprecated: Set RHOSClient, created with rhosclient.NewClient, instead.
+	Client *gophercloud.ProviderClient
+
+	// RHOSClient wraps the RHOS compute and network clients.
+	RHOSClient rhosclient.Interface
+	InfraID    string
+	Region     string
+	K8sClient  k8s.Interface
+
+	// StateStore optionally records the provisioned resources, so that they can be cleaned up exactly.
+	StateStore api.StateStore
+
+	// clientErr is the error creating RHOSClient from Client, which is returned by every operation.
+	clientErr error
+}
+
+// withRHOSClient returns the cloud information with RHOSClient created from the deprecated Client, if it isn't set.
+func (c CloudInfo) withRHOSClient() CloudInfo {
+	if c.RHOSClient == nil && c.Client != nil {
+		var err error
+
+		c.RHOSClient, err = rhosclient.NewClient(c.Client, c.Region)
+		c.clientErr = errors.Wrapf(err, "error creating the RHOS clients for region %q", c.Region)
+	}
+
+	return c
 }
 
-func (c *CloudInfo) openInternalPorts(infraID string, ports []api.PortSpec,
-	computeClient, networkClient *gophercloud.ServiceClient) error {
+func (c *CloudInfo) openInternalPorts(ctx context.Context, infraID string, ports []api.PortSpec) error {
 	groupName := infraID + internalSecurityGroupSuffix
-	opts := secgroups.CreateOpts{
-		Name:        groupName,
-		Description: "Submariner Internal",
+
+	groupID, err := c.getSecurityGroupID(ctx, groupName)
+	if err != nil {
+		return err
 	}
 
-	isFound, err := checkIfSecurityGroupPresent(groupName, computeClient)
-	if isFound {
-		return nil
+	if groupID == "" {
+		group, err := c.RHOSClient.CreateSecurityGroup(ctx, secgroups.CreateOpts{
+			Name:        groupName,
+			Description: "Submariner Internal",
+		})
+		if err != nil {
+			return errors.WithMessagef(err, "creating security group failed")
+		}
+
+		groupID = group.ID
 	}
 
+	err = api.RecordResources(ctx, c.StateStore, securityGroupResource(groupID, groupName))
 	if err != nil {
-		return err
+		return err // nolint:wrapcheck // Already wrapped.
 	}
 
-	group, err := secgroups.Create(computeClient, opts).Extract()
+	err = c.ensureSGRules(ctx, groupID, groupID, ports)
 	if err != nil {
-		return errors.WithMessagef(err, "creating security group failed")
+		return errors.WithMessage(err, "creating security group rule failed")
 	}
 
-	for _, port := range ports {
-		err = c.createSGRule(group.ID, group.ID, "", port.Port, port.Protocol, networkClient)
-		if err != nil {
-			return errors.WithMessage(err, "creating security group rule failed")
-		}
+	serverList, err := c.serversWithoutGroup(ctx, c.InfraID, groupName)
+	if err != nil {
+		return err
 	}
 
-	pager := servers.List(computeClient, servers.ListOpts{Name: c.InfraID})
-	err = pager.EachPage(func(page pagination.Page) (bool, error) {
-		serverList, err := servers.ExtractServers(page)
+	for i := range serverList {
+		err := c.RHOSClient.AddServerToSecurityGroup(ctx, serverList[i].ID, groupName)
 		if err != nil {
-			return false, errors.WithMessage(err, "getting the server List failed")
-		}
-		for i := range serverList {
-			err := secgroups.AddServer(computeClient, serverList[i].ID, groupName).ExtractErr()
-			if err != nil {
-				return false, errors.WithMessage(err, "failed to add the security group to the server")
-			}
+			return errors.WithMessage(err, "failed to add the security group to the server")
 		}
 
-		return true, nil
-	})
+		err = api.RecordResources(ctx, c.StateStore, attachmentResource(&serverList[i], groupName))
+		if err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
 
-	return errors.WithMessagef(err, "failed to open ports")
+	return nil
 }
 
-func (c *CloudInfo) removeInternalFirewallRules(infraID string,
-	computeClient *gophercloud.ServiceClient) error {
+func (c *CloudInfo) removeInternalFirewallRules(ctx context.Context, infraID string) error {
 	groupName := infraID + internalSecurityGroupSuffix
 
-	pager := servers.List(computeClient, servers.ListOpts{Name: c.InfraID})
+	attachments, err := c.recordedAttachments(ctx, groupName, "")
+	if err != nil {
+		return err
+	}
 
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
-		serverList, err := servers.ExtractServers(page)
+	if len(attachments) == 0 {
+		attachments, err = c.serverAttachments(ctx, c.InfraID, groupName)
 		if err != nil {
-			return false, errors.WithMessage(err, "getting the server List failed")
+			return err
 		}
-		for i := range serverList {
-			err = secgroups.RemoveServer(computeClient, serverList[i].ID, groupName).ExtractErr()
+	}
+
+	return errors.WithMessage(c.detachSecurityGroup(ctx, attachments), "failed to remove the internal firewall")
+}
+
+func (c *CloudInfo) createGWSecurityGroup(ctx context.Context, input *api.GatewayDeployInput, groupName string) error {
+	groupID, err := c.getSecurityGroupID(ctx, groupName)
+	if err != nil {
+		return err
+	}
+
+	if groupID == "" {
+		group, err := c.RHOSClient.CreateSecurityGroup(ctx, secgroups.CreateOpts{
+			Name:        groupName,
+			Description: "Submariner Gateway",
+		})
+		if err != nil {
+			return errors.WithMessage(err, "failed to create g/w security group")
+		}
+
+		groupID = group.ID
+	}
+
+	err = api.RecordResources(ctx, c.StateStore, securityGroupResource(groupID, groupName))
+	if err != nil {
+		return err // nolint:wrapcheck // Already wrapped.
+	}
+
+	return errors.WithMessage(c.updatePublicSGRules(ctx, groupID, input), "updating the security group rules failed")
+}
+
+// updatePublicSGRules opens the public ports of the gateway security group to their source CIDRs, then deletes the rules
+// opening them to the CIDRs which are no longer allowed.
+func (c *CloudInfo) updatePublicSGRules(ctx context.Context, groupID string, input *api.GatewayDeployInput) error {
+	existingRules, err := c.listIngressRules(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range input.PublicPorts {
+		prefixRules := publicSGRules(existingRules, port)
+
+		added, removed := api.DiffSourceCIDRs(prefixes(prefixRules), input.SourceCIDRs(port))
+
+		for _, cidr := range added {
+			err = c.createSGRule(ctx, groupID, "", cidr, port.Port, port.Protocol)
 			if err != nil {
-				notFoundError := &gophercloud.ErrDefault404{}
-				if errors.As(err, notFoundError) {
-					continue
+				return err
+			}
+		}
+
+		for _, cidr := range removed {
+			for _, ruleID := range prefixRules[cidr] {
+				err = c.RHOSClient.DeleteSecurityGroupRule(ctx, ruleID)
+				if err != nil && !rhosclient.IsNotFoundError(err) {
+					return errors.WithMessagef(err, "failed deleting the rule opening port %d/%s to %q in security group %q",
+						port.Port, port.Protocol, cidr, groupID)
 				}
 
-				return false, errors.WithMessagef(err, "failed to remove the internal firewall for "+
-					"the server: %q ", serverList[i].Name)
+				err = api.ForgetResources(ctx, c.StateStore, api.Resource{Type: api.ResourceIngressRule, ID: ruleID})
+				if err != nil {
+					return err // nolint:wrapcheck // Already wrapped.
+				}
 			}
 		}
+	}
 
-		return true, nil
-	})
-
-	return errors.WithMessage(err, "failed to remove security group from servers")
+	return nil
 }
 
-func (c *CloudInfo) createGWSecurityGroup(ports []api.PortSpec, groupName string, computeClient *gophercloud.ServiceClient,
-	networkClient *gophercloud.ServiceClient) error {
-	isFound, err := checkIfSecurityGroupPresent(groupName, computeClient)
+// updatePublicPorts updates the source CIDRs of the public ports of the existing gateway security group.
+func (c *CloudInfo) updatePublicPorts(ctx context.Context, groupName string, input *api.GatewayDeployInput, reporter api.Reporter) error {
+	step := api.ResourceStep(providerName, api.ResourceSecurityGroup, groupName)
+
+	api.StepStarted(reporter, step, "Updating the source CIDRs of the public ports in security group %q", groupName)
+
+	groupID, err := c.getSecurityGroupID(ctx, groupName)
+	if err == nil && groupID == "" {
+		err = fmt.Errorf("security group %q not found", groupName)
+	}
+
+	if err == nil {
+		err = c.updatePublicSGRules(ctx, groupID, input)
+	}
+
 	if err != nil {
+		api.StepFailed(reporter, step, err)
 		return err
 	}
 
-	if isFound {
-		return nil
+	api.StepSucceeded(reporter, step, "Updated the source CIDRs of the public ports in security group %q", groupName)
+
+	return nil
+}
+
+// publicSGRules returns the IDs of the rules opening the given port to IP prefixes, by prefix.
+func publicSGRules(existingRules []rules.SecGroupRule, port api.PortSpec) map[string][]string {
+	prefixRules := map[string][]string{}
+
+	for i := range existingRules {
+		if existingRules[i].PortRangeMin == int(port.Port) && existingRules[i].PortRangeMax == int(port.Port) &&
+			existingRules[i].Protocol == port.Protocol && existingRules[i].RemoteGroupID == "" && existingRules[i].RemoteIPPrefix != "" {
+			prefixRules[existingRules[i].RemoteIPPrefix] = append(prefixRules[existingRules[i].RemoteIPPrefix], existingRules[i].ID)
+		}
 	}
 
-	opts := secgroups.CreateOpts{
-		Name:        groupName,
-		Description: "Submariner Gateway",
+	return prefixRules
+}
+
+func prefixes(prefixRules map[string][]string) []string {
+	keys := make([]string, 0, len(prefixRules))
+	for prefix := range prefixRules {
+		keys = append(keys, prefix)
 	}
 
-	group, err := secgroups.Create(computeClient, opts).Extract()
+	return keys
+}
+
+// ensureSGRules creates the ingress rules for the given ports which are missing from the security group, so that
+// rules removed by hand are restored.
+func (c *CloudInfo) ensureSGRules(ctx context.Context, groupID, remoteGroupID string, ports []api.PortSpec) error {
+	missingPorts, err := c.missingSGRules(ctx, groupID, remoteGroupID, ports)
 	if err != nil {
-		return errors.WithMessage(err, "failed to create g/w security group")
+		return err
 	}
 
-	for _, port := range ports {
-		err = c.createSGRule(group.ID, "", allNetworkCIDR, port.Port, port.Protocol, networkClient)
+	for _, port := range missingPorts {
+		err = c.createSGRule(ctx, groupID, remoteGroupID, "", port.Port, port.Protocol)
 		if err != nil {
-			return errors.WithMessagef(err, "creating security group rule failed")
+			return err
 		}
 	}
 
 	return nil
 }
 
-func checkIfSecurityGroupPresent(groupName string, computeClient *gophercloud.ServiceClient) (bool, error) {
-	pager := secgroups.List(computeClient)
-	var isFound bool
+// missingSGRules returns the ports which no ingress rule of the security group opens to the given remote group.
+func (c *CloudInfo) missingSGRules(ctx context.Context, groupID, remoteGroupID string, ports []api.PortSpec) ([]api.PortSpec, error) {
+	existingRules, err := c.listIngressRules(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
 
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
-		serverList, err := secgroups.ExtractSecurityGroups(page)
+	missingPorts := []api.PortSpec{}
 
-		for _, s := range serverList {
-			if s.Name == groupName {
-				isFound = true
-			}
+	for _, port := range ports {
+		if !hasSGRule(existingRules, remoteGroupID, port) {
+			missingPorts = append(missingPorts, port)
 		}
-		return true, errors.WithMessagef(err, "failed to extract the security group %q from results", groupName)
-	})
+	}
 
-	return isFound, errors.WithMessagef(err, "error getting the security group : %q", groupName)
+	return missingPorts, nil
 }
 
-func (c *CloudInfo) openGatewayPort(groupName, nodeName string, computeClient *gophercloud.ServiceClient) error {
-	opts := servers.ListOpts{Name: nodeName}
-	pager := servers.List(computeClient, opts)
+func (c *CloudInfo) listIngressRules(ctx context.Context, groupID string) ([]rules.SecGroupRule, error) {
+	existingRules, err := c.RHOSClient.ListSecurityGroupRules(ctx, rules.ListOpts{SecGroupID: groupID, Direction: string(rules.DirIngress)})
 
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
-		serverList, err := servers.ExtractServers(page)
-		if err != nil {
-			return false, errors.WithMessagef(err, "getting the server List failed for node %q", nodeName)
+	return existingRules, errors.WithMessagef(err, "error listing the rules of security group %q", groupID)
+}
+
+func hasSGRule(existingRules []rules.SecGroupRule, remoteGroupID string, port api.PortSpec) bool {
+	for i := range existingRules {
+		if existingRules[i].PortRangeMin == int(port.Port) && existingRules[i].Protocol == port.Protocol &&
+			existingRules[i].RemoteGroupID == remoteGroupID && existingRules[i].RemoteIPPrefix == "" {
+			return true
 		}
-		for i := range serverList {
-			err = secgroups.AddServer(computeClient, serverList[i].ID, groupName).ExtractErr()
-			if err != nil {
-				return false, errors.WithMessagef(err, "adding security group %q to the server %q failed",
-					groupName, serverList[i].Name)
-			}
+	}
+
+	return false
+}
+
+// serversWithoutGroup returns the servers matching the given name which aren't attached to the named security group.
+func (c *CloudInfo) serversWithoutGroup(ctx context.Context, serverName, groupName string) ([]servers.Server, error) {
+	serverList, err := c.RHOSClient.ListServers(ctx, servers.ListOpts{Name: serverName})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "getting the server list failed for %q", serverName)
+	}
+
+	detached := []servers.Server{}
+
+	for i := range serverList {
+		if !hasSecurityGroup(&serverList[i], groupName) {
+			detached = append(detached, serverList[i])
 		}
+	}
 
-		return true, nil
-	})
+	return detached, nil
+}
 
-	return errors.WithMessagef(err, "open gateway ports failed")
+func hasSecurityGroup(server *servers.Server, groupName string) bool {
+	for _, group := range server.SecurityGroups {
+		if group["name"] == groupName {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (c *CloudInfo) removeGWFirewallRules(groupName, nodeName string, computeClient *gophercloud.ServiceClient) error {
-	opts := servers.ListOpts{Name: nodeName}
-	pager := servers.List(computeClient, opts)
+// getSecurityGroupID returns the ID of the named security group, or an empty string if it doesn't exist.
+func (c *CloudInfo) getSecurityGroupID(ctx context.Context, groupName string) (string, error) {
+	groups, err := c.RHOSClient.ListSecurityGroups(ctx)
+	if err != nil {
+		return "", errors.WithMessagef(err, "error getting the security group : %q", groupName)
+	}
 
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
-		serverList, err := servers.ExtractServers(page)
-		if err != nil {
-			return false, errors.WithMessagef(err, "getting the server list failed")
+	for i := range groups {
+		if groups[i].Name == groupName {
+			return groups[i].ID, nil
 		}
-		for i := range serverList {
-			err = secgroups.RemoveServer(computeClient, serverList[i].ID, groupName).ExtractErr()
-			if err != nil {
-				notFoundError := &gophercloud.ErrDefault404{}
-				if errors.As(err, notFoundError) {
-					continue
-				}
+	}
 
-				return false, errors.WithMessagef(err, "failed to remove the firewall for"+
-					" the server: %q", serverList[i].Name)
-			}
+	return "", nil
+}
+
+func (c *CloudInfo) openGatewayPort(ctx context.Context, groupName, nodeName string) error {
+	serverList, err := c.serversWithoutGroup(ctx, nodeName, groupName)
+	if err != nil {
+		return err
+	}
+
+	for i := range serverList {
+		err = c.RHOSClient.AddServerToSecurityGroup(ctx, serverList[i].ID, groupName)
+		if err != nil {
+			return errors.WithMessagef(err, "adding security group %q to the server %q failed",
+				groupName, serverList[i].Name)
 		}
 
-		return true, nil
-	})
+		err = api.RecordResources(ctx, c.StateStore, attachmentResource(&serverList[i], groupName))
+		if err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
 
-	return errors.WithMessagef(err, "removing firewall rules failed for security group %q", groupName)
+	return nil
 }
 
-func (c *CloudInfo) deleteSG(groupName string, computeClient *gophercloud.ServiceClient) error {
-	pager := secgroups.List(computeClient)
-	var isFound bool
-	var securityGroupID string
+func (c *CloudInfo) removeGWFirewallRules(ctx context.Context, groupName, nodeName string) error {
+	attachments, err := c.recordedAttachments(ctx, groupName, nodeName)
+	if err != nil {
+		return err
+	}
 
-	err := pager.EachPage(func(page pagination.Page) (bool, error) {
-		serverList, err := secgroups.ExtractSecurityGroups(page)
+	if len(attachments) == 0 {
+		attachments, err = c.serverAttachments(ctx, nodeName, groupName)
 		if err != nil {
-			return false, errors.WithMessagef(err, "failed to list the security group %q", groupName)
+			return err
 		}
-		for _, s := range serverList {
-			if s.Name == groupName {
-				isFound = true
-				securityGroupID = s.ID
-				return true, nil
-			}
+	}
+
+	return errors.WithMessage(c.detachSecurityGroup(ctx, attachments), "failed to remove the firewall")
+}
+
+// serverAttachments returns the attachments of the security group to the servers matching the given name.
+func (c *CloudInfo) serverAttachments(ctx context.Context, serverName, groupName string) ([]api.Resource, error) {
+	serverList, err := c.RHOSClient.ListServers(ctx, servers.ListOpts{Name: serverName})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "getting the server list failed for %q", serverName)
+	}
+
+	attachments := []api.Resource{}
+	for i := range serverList {
+		attachments = append(attachments, attachmentResource(&serverList[i], groupName))
+	}
+
+	return attachments, nil
+}
+
+// detachSecurityGroup removes the given security group attachments from their servers and forgets them.
+func (c *CloudInfo) detachSecurityGroup(ctx context.Context, attachments []api.Resource) error {
+	for i := range attachments {
+		err := c.RHOSClient.RemoveServerFromSecurityGroup(ctx, attachments[i].Parameters["server"], attachments[i].Parameters["group"])
+		if err != nil && !rhosclient.IsNotFoundError(err) {
+			return errors.WithMessagef(err, "failed to detach security group %q from the server %q",
+				attachments[i].Parameters["group"], attachments[i].Parameters["server-name"])
 		}
-		return true, errors.WithMessagef(err, "error finding the uuid for the security group: %q", groupName)
-	})
 
-	if err == nil && isFound {
-		err = secgroups.Delete(computeClient, securityGroupID).ExtractErr()
+		err = api.ForgetResources(ctx, c.StateStore, attachments[i])
+		if err != nil {
+			return err // nolint:wrapcheck // Already wrapped.
+		}
+	}
+
+	return nil
+}
+
+// deleteSG deletes the recorded security group with the given name or, if there's no record, the one found by name.
+func (c *CloudInfo) deleteSG(ctx context.Context, groupName string) error {
+	groupID, err := c.recordedSecurityGroupID(ctx, groupName)
+	if err == nil && groupID == "" {
+		groupID, err = c.getSecurityGroupID(ctx, groupName)
+	}
+
+	if err != nil || groupID == "" {
+		return errors.WithMessagef(err, "error deleting the security group %q", groupName)
 	}
 
-	return errors.WithMessagef(err, "error deleting the security group %q", groupName)
+	err = c.RHOSClient.DeleteSecurityGroup(ctx, groupID)
+	if err != nil && !rhosclient.IsNotFoundError(err) {
+		return errors.WithMessagef(err, "error deleting the security group %q", groupName)
+	}
+
+	return c.forgetSecurityGroup(ctx, groupID, groupName)
 }
 
-func (c *CloudInfo) createSGRule(group, remoteGroupID, remoteIPPrefix string, port uint16,
-	protocol string, networkClient *gophercloud.ServiceClient) error {
+func (c *CloudInfo) createSGRule(ctx context.Context, group, remoteGroupID, remoteIPPrefix string, port uint16, protocol string) error {
 	opts := rules.CreateOpts{
 		Direction:      "ingress",
 		EtherType:      rules.EtherType4,
@@ -253,8 +442,11 @@ func (c *CloudInfo) createSGRule(group, remoteGroupID, remoteIPPrefix string, po
 		RemoteIPPrefix: remoteIPPrefix,
 	}
 
-	_, err := rules.Create(networkClient, opts).Extract()
+	rule, err := c.RHOSClient.CreateSecurityGroupRule(ctx, opts)
+	if err != nil {
+		return errors.WithMessagef(err, "failed creating security group rule with port %d , protocol %q,"+
+			"remotegroupID %q, remoteIPprefix %q , in security group %q", port, protocol, remoteGroupID, remoteIPPrefix, group)
+	}
 
-	return errors.WithMessagef(err, "failed creating security group rule with port %d , protocol %q,"+
-		"remotegroupID %q, remoteIPprefix %q , in security group %q", port, protocol, remoteGroupID, remoteIPPrefix, group)
+	return api.RecordResources(ctx, c.StateStore, ingressRuleResource(rule))
 }
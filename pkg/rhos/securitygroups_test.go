@@ -0,0 +1,349 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rhos_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/secgroups"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/rhos"
+	"github.com/submariner-io/cloud-prepare/pkg/rhos/client/fake"
+)
+
+const internalGroupName = infraID + "-submariner-internal-sg"
+
+var _ = Describe("RHOS internal security group", func() {
+	var (
+		mockCtrl *gomock.Controller
+		client   *fake.MockInterface
+		cloud    api.Cloud
+		groups   []secgroups.SecurityGroup
+		sgRules  []rules.SecGroupRule
+		attached map[string]bool
+		input    api.PrepareForSubmarinerInput
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		client = fake.NewMockInterface(mockCtrl)
+		cloud = rhos.NewCloud(rhos.CloudInfo{RHOSClient: client, InfraID: infraID, Region: "test-region"})
+		groups = nil
+		sgRules = nil
+		attached = map[string]bool{}
+		input = api.PrepareForSubmarinerInput{
+			InternalPorts: []api.PortSpec{{Port: 4800, Protocol: "udp"}, {Port: 8080, Protocol: "tcp"}},
+		}
+
+		client.EXPECT().ListSecurityGroups(gomock.Any()).DoAndReturn(func(_ context.Context) ([]secgroups.SecurityGroup, error) {
+			return groups, nil
+		}).AnyTimes()
+
+		client.EXPECT().CreateSecurityGroup(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, opts secgroups.CreateOpts) (*secgroups.SecurityGroup, error) {
+				groups = append(groups, secgroups.SecurityGroup{ID: opts.Name, Name: opts.Name})
+				return &groups[len(groups)-1], nil
+			}).AnyTimes()
+
+		client.EXPECT().ListSecurityGroupRules(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, opts rules.ListOpts) ([]rules.SecGroupRule, error) {
+				var found []rules.SecGroupRule
+
+				for i := range sgRules {
+					if sgRules[i].SecGroupID == opts.SecGroupID {
+						found = append(found, sgRules[i])
+					}
+				}
+
+				return found, nil
+			}).AnyTimes()
+
+		client.EXPECT().CreateSecurityGroupRule(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, opts rules.CreateOpts) (*rules.SecGroupRule, error) {
+				sgRules = append(sgRules, rules.SecGroupRule{
+					ID:            fmt.Sprintf("rule-%d", len(sgRules)),
+					SecGroupID:    opts.SecGroupID,
+					Protocol:      string(opts.Protocol),
+					PortRangeMin:  opts.PortRangeMin,
+					PortRangeMax:  opts.PortRangeMax,
+					RemoteGroupID: opts.RemoteGroupID,
+				})
+
+				return &sgRules[len(sgRules)-1], nil
+			}).AnyTimes()
+
+		client.EXPECT().ListServers(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ servers.ListOpts) ([]servers.Server, error) {
+				serverList := []servers.Server{}
+
+				for _, name := range []string{infraID + "-worker-0", infraID + "-worker-1"} {
+					server := servers.Server{ID: name, Name: name}
+					if attached[name] {
+						server.SecurityGroups = []map[string]interface{}{{"name": internalGroupName}}
+					}
+
+					serverList = append(serverList, server)
+				}
+
+				return serverList, nil
+			}).AnyTimes()
+
+		client.EXPECT().AddServerToSecurityGroup(gomock.Any(), gomock.Any(), internalGroupName).DoAndReturn(
+			func(_ context.Context, serverID, _ string) error {
+				Expect(attached[serverID]).To(BeFalse())
+				attached[serverID] = true
+
+				return nil
+			}).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	When("the cloud is prepared", func() {
+		It("should create the security group with its rules and attach it to the servers", func() {
+			Expect(cloud.PrepareForSubmariner(input, api.NewLoggingReporter())).To(Succeed())
+			Expect(groups).To(HaveLen(1))
+			Expect(sgRules).To(HaveLen(2))
+			Expect(attached).To(HaveLen(2))
+
+			actions, err := cloud.Plan(context.TODO(), input)
+			Expect(err).To(Succeed())
+			Expect(actions).To(BeEmpty())
+		})
+	})
+
+	When("the cloud records its resources in a state store", func() {
+		var (
+			dir      string
+			store    api.StateStore
+			detached []string
+			deleted  []string
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			dir, err = ioutil.TempDir("", "state")
+			Expect(err).To(Succeed())
+
+			store = api.NewFileStateStore(filepath.Join(dir, "state.json"))
+			cloud.(api.StateRecorder).SetStateStore(store)
+
+			detached = nil
+			deleted = nil
+
+			client.EXPECT().RemoveServerFromSecurityGroup(gomock.Any(), gomock.Any(), internalGroupName).DoAndReturn(
+				func(_ context.Context, serverID, _ string) error {
+					detached = append(detached, serverID)
+					return nil
+				}).AnyTimes()
+
+			client.EXPECT().DeleteSecurityGroup(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, groupID string) error {
+				deleted = append(deleted, groupID)
+				return nil
+			}).AnyTimes()
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should record the security group, its rules and attachments, and clean up exactly those", func() {
+			Expect(cloud.PrepareForSubmariner(input, api.NewLoggingReporter())).To(Succeed())
+
+			for resourceType, count := range map[api.ResourceType]int{
+				api.ResourceSecurityGroup: 1, api.ResourceIngressRule: 2, api.ResourceSecurityGroupAttachment: 2,
+			} {
+				recorded, err := api.RecordedResources(context.TODO(), store, resourceType)
+				Expect(err).To(Succeed())
+				Expect(recorded).To(HaveLen(count), "Unexpected records of type %q", resourceType)
+			}
+
+			// The group can't be found by its name anymore, only the record knows it.
+			groups = nil
+
+			Expect(cloud.CleanupAfterSubmariner(api.NewLoggingReporter())).To(Succeed())
+			Expect(detached).To(ConsistOf(infraID+"-worker-0", infraID+"-worker-1"))
+			Expect(deleted).To(Equal([]string{internalGroupName}))
+
+			for _, resourceType := range []api.ResourceType{
+				api.ResourceSecurityGroup, api.ResourceIngressRule, api.ResourceSecurityGroupAttachment,
+			} {
+				recorded, err := api.RecordedResources(context.TODO(), store, resourceType)
+				Expect(err).To(Succeed())
+				Expect(recorded).To(BeEmpty(), "Unexpected records of type %q", resourceType)
+			}
+		})
+	})
+
+	When("the cloud is created from a provider client lacking the compute service", func() {
+		BeforeEach(func() {
+			cloud = rhos.NewCloud(rhos.CloudInfo{
+				Client: &gophercloud.ProviderClient{EndpointLocator: func(gophercloud.EndpointOpts) (string, error) {
+					return "", errors.New("no compute endpoint")
+				}},
+				InfraID: infraID,
+				Region:  "test-region",
+			})
+		})
+
+		It("should return an error", func() {
+			Expect(cloud.PrepareForSubmariner(input, api.NewLoggingReporter())).ToNot(Succeed())
+
+			_, err := cloud.Plan(context.TODO(), input)
+			Expect(err).ToNot(Succeed())
+		})
+	})
+
+	When("a rule was removed and a server detached after the cloud was prepared", func() {
+		BeforeEach(func() {
+			Expect(cloud.PrepareForSubmariner(input, api.NewLoggingReporter())).To(Succeed())
+
+			sgRules = sgRules[1:]
+			attached[infraID+"-worker-1"] = false
+		})
+
+		It("should report the drift", func() {
+			drifts, err := api.VerifyCloud(context.TODO(), cloud, input)
+			Expect(err).To(Succeed())
+			Expect(drifts).To(HaveLen(2))
+			Expect(drifts[0].Repair).To(Equal(api.Action{
+				Kind:     api.ActionAuthorizeIngress,
+				Resource: internalGroupName,
+				Parameters: map[string]string{
+					"protocol":     "udp",
+					"port":         "4800",
+					"remote-group": internalGroupName,
+				},
+			}))
+			Expect(drifts[1].Repair.Kind).To(Equal(api.ActionAttachSecurityGroup))
+			Expect(drifts[1].Resource).To(Equal(infraID + "-worker-1"))
+		})
+
+		It("should repair the drift", func() {
+			drifts, err := api.ReconcileCloud(context.TODO(), cloud, input, api.NewLoggingReporter())
+			Expect(err).To(Succeed())
+			Expect(drifts).To(HaveLen(2))
+			Expect(groups).To(HaveLen(1))
+			Expect(sgRules).To(HaveLen(2))
+			Expect(attached[infraID+"-worker-1"]).To(BeTrue())
+
+			drifts, err = api.VerifyCloud(context.TODO(), cloud, input)
+			Expect(err).To(Succeed())
+			Expect(drifts).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("RHOS gateway security group", func() {
+	const gatewayGroupName = infraID + "-submariner-gw-sg"
+
+	var (
+		mockCtrl   *gomock.Controller
+		client     *fake.MockInterface
+		gwDeployer api.GatewayDeployer
+		sgRules    []rules.SecGroupRule
+		input      api.GatewayDeployInput
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		client = fake.NewMockInterface(mockCtrl)
+		gwDeployer = rhos.NewOcpGatewayDeployer(rhos.CloudInfo{RHOSClient: client, InfraID: infraID, Region: "test-region"}, nil,
+			"test-project", "test-instance", "test-image", "test-cloud", false)
+		sgRules = []rules.SecGroupRule{
+			{
+				ID: "rule-any", SecGroupID: gatewayGroupName, Protocol: "udp", PortRangeMin: 4500, PortRangeMax: 4500,
+				RemoteIPPrefix: api.AnyIPv4CIDR,
+			},
+			{
+				ID: "rule-kept", SecGroupID: gatewayGroupName, Protocol: "udp", PortRangeMin: 4500, PortRangeMax: 4500,
+				RemoteIPPrefix: "10.1.0.0/16",
+			},
+		}
+		input = api.GatewayDeployInput{
+			PublicPorts:       []api.PortSpec{{Port: 4500, Protocol: "udp"}},
+			PublicSourceCIDRs: []string{"10.1.0.0/16", "10.2.0.0/16"},
+		}
+
+		client.EXPECT().ListSecurityGroups(gomock.Any()).Return([]secgroups.SecurityGroup{
+			{ID: gatewayGroupName, Name: gatewayGroupName},
+		}, nil).AnyTimes()
+
+		client.EXPECT().ListSecurityGroupRules(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ rules.ListOpts) ([]rules.SecGroupRule, error) {
+				return sgRules, nil
+			}).AnyTimes()
+
+		client.EXPECT().CreateSecurityGroupRule(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, opts rules.CreateOpts) (*rules.SecGroupRule, error) {
+				sgRules = append(sgRules, rules.SecGroupRule{
+					ID:             "rule-" + opts.RemoteIPPrefix,
+					SecGroupID:     opts.SecGroupID,
+					Protocol:       string(opts.Protocol),
+					PortRangeMin:   opts.PortRangeMin,
+					PortRangeMax:   opts.PortRangeMax,
+					RemoteIPPrefix: opts.RemoteIPPrefix,
+				})
+
+				return &sgRules[len(sgRules)-1], nil
+			}).AnyTimes()
+
+		client.EXPECT().DeleteSecurityGroupRule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id string) error {
+			for i := range sgRules {
+				if sgRules[i].ID == id {
+					sgRules = append(sgRules[:i], sgRules[i+1:]...)
+					return nil
+				}
+			}
+
+			return fmt.Errorf("rule %q not found", id)
+		}).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	When("the public ports are restricted to source CIDRs", func() {
+		It("should update the rules in place", func() {
+			Expect(gwDeployer.(api.PublicPortsUpdater).UpdatePublicPorts(context.TODO(), input, api.NewLoggingReporter())).To(Succeed())
+
+			prefixes := []string{}
+			for i := range sgRules {
+				prefixes = append(prefixes, sgRules[i].RemoteIPPrefix)
+			}
+
+			Expect(prefixes).To(ConsistOf("10.1.0.0/16", "10.2.0.0/16"))
+			Expect(sgRules[0].ID).To(Equal("rule-kept"))
+		})
+	})
+})
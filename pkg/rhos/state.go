@@ -0,0 +1,198 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rhos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetStateStore sets the store recording the resources provisioned on RHOS, which are then cleaned up exactly.
+func (c *CloudInfo) SetStateStore(store api.StateStore) {
+	c.StateStore = store
+}
+
+func (d *ocpGatewayDeployer) machineSetResource(index string) api.Resource {
+	return api.Resource{
+		Type:       api.ResourceMachineSet,
+		ID:         d.InfraID + "-submariner-gw-" + index,
+		Parameters: map[string]string{"index": index},
+	}
+}
+
+func securityGroupResource(groupID, groupName string) api.Resource {
+	return api.Resource{
+		Type:       api.ResourceSecurityGroup,
+		ID:         groupID,
+		Parameters: map[string]string{"name": groupName},
+	}
+}
+
+func ingressRuleResource(rule *rules.SecGroupRule) api.Resource {
+	return api.Resource{
+		Type: api.ResourceIngressRule,
+		ID:   rule.ID,
+		Parameters: map[string]string{
+			"group-id":         rule.SecGroupID,
+			"port":             strconv.Itoa(rule.PortRangeMin),
+			"protocol":         rule.Protocol,
+			"remote-group":     rule.RemoteGroupID,
+			"remote-ip-prefix": rule.RemoteIPPrefix,
+		},
+	}
+}
+
+func attachmentResource(server *servers.Server, groupName string) api.Resource {
+	return api.Resource{
+		Type: api.ResourceSecurityGroupAttachment,
+		ID:   server.ID + "/" + groupName,
+		Parameters: map[string]string{
+			"server":      server.ID,
+			"server-name": server.Name,
+			"group":       groupName,
+		},
+	}
+}
+
+// recordedSecurityGroupID returns the ID of the security group recorded with the given name, or an empty string if
+// there's none.
+func (c *CloudInfo) recordedSecurityGroupID(ctx context.Context, groupName string) (string, error) {
+	recorded, err := api.RecordedResources(ctx, c.StateStore, api.ResourceSecurityGroup)
+	if err != nil {
+		return "", err // nolint:wrapcheck // Already wrapped.
+	}
+
+	for i := range recorded {
+		if recorded[i].Parameters["name"] == groupName {
+			return recorded[i].ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// forgetSecurityGroup forgets the recorded security group along with the rules recorded in it.
+func (c *CloudInfo) forgetSecurityGroup(ctx context.Context, groupID, groupName string) error {
+	recorded, err := api.RecordedResources(ctx, c.StateStore, api.ResourceIngressRule)
+	if err != nil {
+		return err // nolint:wrapcheck // Already wrapped.
+	}
+
+	forgotten := []api.Resource{securityGroupResource(groupID, groupName)}
+
+	for i := range recorded {
+		if recorded[i].Parameters["group-id"] == groupID {
+			forgotten = append(forgotten, recorded[i])
+		}
+	}
+
+	return api.ForgetResources(ctx, c.StateStore, forgotten...)
+}
+
+// recordedAttachments returns the recorded attachments of the security group to the servers, restricted to the named
+// server unless the name is empty.
+func (c *CloudInfo) recordedAttachments(ctx context.Context, groupName, serverName string) ([]api.Resource, error) {
+	recorded, err := api.RecordedResources(ctx, c.StateStore, api.ResourceSecurityGroupAttachment)
+	if err != nil {
+		return nil, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	attachments := []api.Resource{}
+
+	for i := range recorded {
+		if recorded[i].Parameters["group"] == groupName && (serverName == "" || recorded[i].Parameters["server-name"] == serverName) {
+			attachments = append(attachments, recorded[i])
+		}
+	}
+
+	return attachments, nil
+}
+
+func nodeLabelResource(nodeName string) api.Resource {
+	return api.Resource{Type: api.ResourceNodeLabel, ID: nodeName}
+}
+
+// cleanupRecordedGateways removes the recorded gateway machine sets and node labels, returning false if there are no
+// records to clean up from.
+func (d *ocpGatewayDeployer) cleanupRecordedGateways(ctx context.Context, groupName string, reporter api.Reporter) (bool, error) {
+	machineSets, err := api.RecordedResources(ctx, d.StateStore, api.ResourceMachineSet)
+	if err != nil {
+		return false, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	labels, err := api.RecordedResources(ctx, d.StateStore, api.ResourceNodeLabel)
+	if err != nil {
+		return false, err // nolint:wrapcheck // Already wrapped.
+	}
+
+	if len(machineSets) == 0 && len(labels) == 0 {
+		return false, nil
+	}
+
+	for i := range machineSets {
+		machineSetStep := d.machineSetStep(machineSets[i].Parameters["index"])
+
+		api.StepStarted(reporter, machineSetStep, "Deleting the gateway machine set %q", machineSets[i].ID)
+
+		err = d.deleteGateway(ctx, machineSets[i].Parameters["index"])
+		if err != nil {
+			return true, errors.WithMessagef(err, "error deleting the gateway machine set %q", machineSets[i].ID)
+		}
+
+		err = api.ForgetResources(ctx, d.StateStore, machineSets[i])
+		if err != nil {
+			return true, err // nolint:wrapcheck // Already wrapped.
+		}
+
+		api.StepSucceeded(reporter, machineSetStep, "Successfully deleted the machine set")
+	}
+
+	for i := range labels {
+		reporter.Started(fmt.Sprintf("Removing the gateway configuration from instance %q", labels[i].ID))
+
+		err = d.removeGWFirewallRules(ctx, groupName, labels[i].ID)
+		if err != nil {
+			return true, errors.Wrapf(err, "error deleting the Submariner gateway security group rules from node: %q", labels[i].ID)
+		}
+
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: labels[i].ID}}
+
+		err = d.K8sClient.RemoveGWLabelFromWorkerNodeWithContext(ctx, node)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return true, errors.Wrapf(err, "failed to remove labels from node %q", labels[i].ID)
+		}
+
+		err = api.ForgetResources(ctx, d.StateStore, labels[i])
+		if err != nil {
+			return true, err // nolint:wrapcheck // Already wrapped.
+		}
+
+		reporter.Succeeded("Successfully reconfigured the instance")
+	}
+
+	return true, nil
+}
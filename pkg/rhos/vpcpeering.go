@@ -0,0 +1,434 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rhos
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	rhosclient "github.com/submariner-io/cloud-prepare/pkg/rhos/client"
+)
+
+const (
+	routerSuffix              = "-external-router"
+	nodesSubnetSuffix         = "-nodes"
+	workerSecurityGroupSuffix = "-worker"
+	peeringTraffic            = "Submariner peering traffic"
+)
+
+// peeringPorts are opened between peered networks so that the gateways can reach each other over their private IPs.
+var peeringPorts = []api.PortSpec{
+	{Port: 500, Protocol: "udp"},
+	{Port: 4490, Protocol: "udp"},
+	{Port: 4500, Protocol: "udp"},
+	// ESP & AH protocols are used for private-ip to private-ip gateway communications
+	{Port: 0, Protocol: "50"},
+	{Port: 0, Protocol: "51"},
+}
+
+// The peering is done by plugging the source cluster's router into the target cluster's nodes subnet through a
+// dedicated port. The source nodes then reach the target subnet directly through their router, while the target
+// router gets a static route sending the traffic for the source subnet to that port.
+func (rc *rhosCloud) createVpcPeering(ctx context.Context, target *rhosCloud, reporter api.Reporter) error {
+	reporter.Started("Retrieving the subnets to peer")
+
+	sourceSubnet, err := rc.getNodesSubnet(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	targetSubnet, err := target.getNodesSubnet(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Retrieved subnets %s and %s", sourceSubnet.Name, targetSubnet.Name)
+
+	reporter.Started("Validating pre-requisites")
+
+	overlap, err := cidrsOverlap(sourceSubnet.CIDR, targetSubnet.CIDR)
+	if err == nil && overlap {
+		err = fmt.Errorf("the CIDR %s of subnet %s overlaps with the CIDR %s of subnet %s",
+			sourceSubnet.CIDR, sourceSubnet.Name, targetSubnet.CIDR, targetSubnet.Name)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Validated pre-requisites")
+
+	sourceRouter, err := rc.getRouter(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	targetRouter, err := target.getRouter(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Started("Attaching subnet %s to router %s", targetSubnet.Name, sourceRouter.Name)
+
+	peeringIP, err := rc.attachPeeringPort(ctx, target, sourceRouter, targetSubnet)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Attached subnet %s to router %s with address %s", targetSubnet.Name, sourceRouter.Name, peeringIP)
+
+	reporter.Started("Adding a route to %s on router %s", sourceSubnet.CIDR, targetRouter.Name)
+
+	err = target.addPeeringRoute(ctx, targetRouter, sourceSubnet.CIDR, peeringIP)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Added a route to %s on router %s", sourceSubnet.CIDR, targetRouter.Name)
+
+	reporter.Started("Opening Submariner ports between %s and %s", sourceSubnet.CIDR, targetSubnet.CIDR)
+
+	err = rc.allowPeeringTraffic(ctx, targetSubnet.CIDR)
+	if err == nil {
+		err = target.allowPeeringTraffic(ctx, sourceSubnet.CIDR)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Opened Submariner ports between %s and %s", sourceSubnet.CIDR, targetSubnet.CIDR)
+
+	return nil
+}
+
+func (rc *rhosCloud) cleanupVpcPeering(ctx context.Context, target *rhosCloud, reporter api.Reporter) error {
+	reporter.Started("Retrieving the peered subnets")
+
+	sourceSubnet, err := rc.getNodesSubnet(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	targetSubnet, err := target.getNodesSubnet(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Retrieved subnets %s and %s", sourceSubnet.Name, targetSubnet.Name)
+
+	reporter.Started("Revoking Submariner ports between %s and %s", sourceSubnet.CIDR, targetSubnet.CIDR)
+
+	err = rc.revokePeeringTraffic(ctx, targetSubnet.CIDR)
+	if err == nil {
+		err = target.revokePeeringTraffic(ctx, sourceSubnet.CIDR)
+	}
+
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	reporter.Succeeded("Revoked Submariner ports between %s and %s", sourceSubnet.CIDR, targetSubnet.CIDR)
+
+	reporter.Started("Detaching subnet %s from the router of %s", targetSubnet.Name, rc.InfraID)
+
+	portList, err := rc.RHOSClient.ListPorts(ctx, ports.ListOpts{Name: rc.peeringPortName(target)})
+	if err != nil {
+		err = errors.Wrap(err, "error listing the peering ports")
+		reporter.Failed(err)
+
+		return err
+	}
+
+	targetRouter, err := target.getRouter(ctx)
+	if err != nil {
+		reporter.Failed(err)
+		return err
+	}
+
+	for i := range portList {
+		err = target.removePeeringRoute(ctx, targetRouter, sourceSubnet.CIDR, portList[i].FixedIPs)
+		if err == nil {
+			err = rc.deletePeeringPort(ctx, &portList[i])
+		}
+
+		if err != nil {
+			reporter.Failed(err)
+			return err
+		}
+	}
+
+	reporter.Succeeded("Detached subnet %s from the router of %s", targetSubnet.Name, rc.InfraID)
+
+	return nil
+}
+
+func cidrsOverlap(cidr1, cidr2 string) (bool, error) {
+	_, net1, err := net.ParseCIDR(cidr1)
+	if err != nil {
+		return false, errors.Wrapf(err, "error parsing CIDR %q", cidr1)
+	}
+
+	_, net2, err := net.ParseCIDR(cidr2)
+	if err != nil {
+		return false, errors.Wrapf(err, "error parsing CIDR %q", cidr2)
+	}
+
+	return net1.Contains(net2.IP) || net2.Contains(net1.IP), nil
+}
+
+func (rc *rhosCloud) getNodesSubnet(ctx context.Context) (*subnets.Subnet, error) {
+	name := rc.InfraID + nodesSubnetSuffix
+
+	subnetList, err := rc.RHOSClient.ListSubnets(ctx, subnets.ListOpts{Name: name})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the subnet %q", name)
+	}
+
+	if len(subnetList) == 0 {
+		return nil, fmt.Errorf("subnet %q not found", name)
+	}
+
+	return &subnetList[0], nil
+}
+
+func (rc *rhosCloud) getRouter(ctx context.Context) (*routers.Router, error) {
+	name := rc.InfraID + routerSuffix
+
+	routerList, err := rc.RHOSClient.ListRouters(ctx, routers.ListOpts{Name: name})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing the router %q", name)
+	}
+
+	if len(routerList) == 0 {
+		return nil, fmt.Errorf("router %q not found", name)
+	}
+
+	return &routerList[0], nil
+}
+
+func (rc *rhosCloud) peeringPortName(target *rhosCloud) string {
+	return fmt.Sprintf("%s-%s-peering", rc.InfraID, target.InfraID)
+}
+
+// attachPeeringPort plugs the router into the target subnet, returning the address of the router on that subnet.
+func (rc *rhosCloud) attachPeeringPort(ctx context.Context, target *rhosCloud, router *routers.Router,
+	targetSubnet *subnets.Subnet) (string, error) {
+	portName := rc.peeringPortName(target)
+
+	portList, err := rc.RHOSClient.ListPorts(ctx, ports.ListOpts{Name: portName})
+	if err != nil {
+		return "", errors.Wrapf(err, "error listing the port %q", portName)
+	}
+
+	var port *ports.Port
+
+	if len(portList) > 0 {
+		port = &portList[0]
+	} else {
+		port, err = rc.RHOSClient.CreatePort(ctx, ports.CreateOpts{
+			Name:      portName,
+			NetworkID: targetSubnet.NetworkID,
+			FixedIPs:  []ports.IP{{SubnetID: targetSubnet.ID}},
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "error creating the port %q", portName)
+		}
+	}
+
+	if len(port.FixedIPs) == 0 {
+		return "", fmt.Errorf("port %q has no address on subnet %q", portName, targetSubnet.Name)
+	}
+
+	if port.DeviceID != router.ID {
+		err = rc.RHOSClient.AddRouterInterface(ctx, router.ID, routers.AddInterfaceOpts{PortID: port.ID})
+		if err != nil {
+			return "", errors.Wrapf(err, "error attaching the port %q to the router %q", portName, router.Name)
+		}
+	}
+
+	return port.FixedIPs[0].IPAddress, nil
+}
+
+func (rc *rhosCloud) deletePeeringPort(ctx context.Context, port *ports.Port) error {
+	var err error
+
+	// Removing a router interface by port also deletes the port.
+	if port.DeviceID != "" {
+		err = rc.RHOSClient.RemoveRouterInterface(ctx, port.DeviceID, routers.RemoveInterfaceOpts{PortID: port.ID})
+	} else {
+		err = rc.RHOSClient.DeletePort(ctx, port.ID)
+	}
+
+	if rhosclient.IsNotFoundError(err) {
+		return nil
+	}
+
+	return errors.Wrapf(err, "error deleting the port %q", port.Name)
+}
+
+func (rc *rhosCloud) addPeeringRoute(ctx context.Context, router *routers.Router, cidr, nextHop string) error {
+	for _, route := range router.Routes {
+		if route.DestinationCIDR == cidr && route.NextHop == nextHop {
+			return nil
+		}
+	}
+
+	routes := append([]routers.Route{}, router.Routes...)
+	routes = append(routes, routers.Route{DestinationCIDR: cidr, NextHop: nextHop})
+
+	err := rc.RHOSClient.UpdateRouterRoutes(ctx, router.ID, routes)
+
+	return errors.Wrapf(err, "error adding the route to %q on router %q", cidr, router.Name)
+}
+
+func (rc *rhosCloud) removePeeringRoute(ctx context.Context, router *routers.Router, cidr string, nextHops []ports.IP) error {
+	routes := []routers.Route{}
+
+	for _, route := range router.Routes {
+		if route.DestinationCIDR != cidr || !hasAddress(nextHops, route.NextHop) {
+			routes = append(routes, route)
+		}
+	}
+
+	if len(routes) == len(router.Routes) {
+		return nil
+	}
+
+	err := rc.RHOSClient.UpdateRouterRoutes(ctx, router.ID, routes)
+
+	return errors.Wrapf(err, "error removing the route to %q from router %q", cidr, router.Name)
+}
+
+func hasAddress(ips []ports.IP, address string) bool {
+	for _, ip := range ips {
+		if ip.IPAddress == address {
+			return true
+		}
+	}
+
+	return false
+}
+
+func peeringTrafficDescription(peerCIDR string) string {
+	return fmt.Sprintf("%s from %s", peeringTraffic, peerCIDR)
+}
+
+func (rc *rhosCloud) getWorkerSecurityGroupID(ctx context.Context) (string, error) {
+	groupName := rc.InfraID + workerSecurityGroupSuffix
+
+	groupID, err := rc.getSecurityGroupID(ctx, groupName)
+	if err == nil && groupID == "" {
+		err = fmt.Errorf("security group %q not found", groupName)
+	}
+
+	return groupID, err
+}
+
+func (rc *rhosCloud) listPeeringRules(ctx context.Context, groupID, peerCIDR string) ([]rules.SecGroupRule, error) {
+	ruleList, err := rc.RHOSClient.ListSecurityGroupRules(ctx, rules.ListOpts{
+		SecGroupID:     groupID,
+		RemoteIPPrefix: peerCIDR,
+		Description:    peeringTrafficDescription(peerCIDR),
+	})
+
+	return ruleList, errors.Wrapf(err, "error listing the rules of security group %q", groupID)
+}
+
+func (rc *rhosCloud) allowPeeringTraffic(ctx context.Context, peerCIDR string) error {
+	groupID, err := rc.getWorkerSecurityGroupID(ctx)
+	if err != nil {
+		return err
+	}
+
+	existingRules, err := rc.listPeeringRules(ctx, groupID, peerCIDR)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range peeringPorts {
+		if hasPeeringRule(existingRules, port) {
+			continue
+		}
+
+		_, err = rc.RHOSClient.CreateSecurityGroupRule(ctx, rules.CreateOpts{
+			Direction:      rules.DirIngress,
+			EtherType:      rules.EtherType4,
+			SecGroupID:     groupID,
+			PortRangeMax:   int(port.Port),
+			PortRangeMin:   int(port.Port),
+			Protocol:       rules.RuleProtocol(port.Protocol),
+			RemoteIPPrefix: peerCIDR,
+			Description:    peeringTrafficDescription(peerCIDR),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error allowing %d/%s from %q", port.Port, port.Protocol, peerCIDR)
+		}
+	}
+
+	return nil
+}
+
+func hasPeeringRule(existingRules []rules.SecGroupRule, port api.PortSpec) bool {
+	for i := range existingRules {
+		if existingRules[i].Protocol == port.Protocol && existingRules[i].PortRangeMin == int(port.Port) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rc *rhosCloud) revokePeeringTraffic(ctx context.Context, peerCIDR string) error {
+	groupID, err := rc.getWorkerSecurityGroupID(ctx)
+	if err != nil {
+		return err
+	}
+
+	existingRules, err := rc.listPeeringRules(ctx, groupID, peerCIDR)
+	if err != nil {
+		return err
+	}
+
+	for i := range existingRules {
+		err = rc.RHOSClient.DeleteSecurityGroupRule(ctx, existingRules[i].ID)
+		if err != nil && !rhosclient.IsNotFoundError(err) {
+			return errors.Wrapf(err, "error deleting the security group rule %q", existingRules[i].ID)
+		}
+	}
+
+	return nil
+}
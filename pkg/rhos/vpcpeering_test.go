@@ -0,0 +1,383 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rhos_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/secgroups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/cloud-prepare/pkg/api"
+	"github.com/submariner-io/cloud-prepare/pkg/rhos"
+	"github.com/submariner-io/cloud-prepare/pkg/rhos/client/fake"
+)
+
+const (
+	infraID       = "test-infraID"
+	targetInfraID = "test-target-infraID"
+	sourceCIDR    = "10.0.0.0/16"
+	targetCIDR    = "10.1.0.0/16"
+	peeringIP     = "10.1.0.100"
+	peeringPortID = "test-peering-port"
+)
+
+var _ = Describe("RHOS Peering", func() {
+	Context("CreateVpcPeering", testCreateVpcPeering)
+	Context("CleanupVpcPeering", testCleanupVpcPeering)
+})
+
+func testCreateVpcPeering() {
+	t := newPeeringTestDriver()
+
+	var retError error
+
+	JustBeforeEach(func() {
+		retError = t.source.CreateVpcPeering(t.target, api.NewLoggingReporter())
+	})
+
+	When("the networks are not peered", func() {
+		It("should attach the target subnet to the source router, add the route and open the ports", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.ports).To(HaveLen(1))
+			Expect(t.ports[0].Name).To(Equal(infraID + "-" + targetInfraID + "-peering"))
+			Expect(t.ports[0].NetworkID).To(Equal(targetInfraID + "-network"))
+			Expect(t.ports[0].DeviceID).To(Equal(infraID + "-external-router"))
+			Expect(t.routers[targetInfraID+"-external-router"].Routes).To(Equal([]routers.Route{
+				{DestinationCIDR: sourceCIDR, NextHop: peeringIP},
+			}))
+			t.assertPeeringRules(infraID+"-worker", targetCIDR)
+			t.assertPeeringRules(targetInfraID+"-worker", sourceCIDR)
+		})
+	})
+
+	When("the networks are already peered", func() {
+		BeforeEach(func() {
+			Expect(t.source.CreateVpcPeering(t.target, api.NewLoggingReporter())).To(Succeed())
+		})
+
+		It("should not change anything", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.ports).To(HaveLen(1))
+			Expect(t.routers[targetInfraID+"-external-router"].Routes).To(HaveLen(1))
+			Expect(t.rules).To(HaveLen(2 * 5))
+		})
+	})
+
+	When("the subnet CIDRs overlap", func() {
+		BeforeEach(func() {
+			t.subnets[targetInfraID+"-nodes"].CIDR = "10.0.1.0/24"
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+			Expect(t.ports).To(BeEmpty())
+		})
+	})
+
+	When("the port creation fails", func() {
+		BeforeEach(func() {
+			t.createPortErr = errors.New("fake create error")
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+
+	When("called with a non-RHOS Cloud", func() {
+		BeforeEach(func() {
+			t.target = &invalidCloud{}
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+func testCleanupVpcPeering() {
+	t := newPeeringTestDriver()
+
+	var retError error
+
+	JustBeforeEach(func() {
+		retError = t.source.CleanupVpcPeering(t.target, api.NewLoggingReporter())
+	})
+
+	When("the networks are peered", func() {
+		BeforeEach(func() {
+			Expect(t.source.CreateVpcPeering(t.target, api.NewLoggingReporter())).To(Succeed())
+		})
+
+		It("should remove the port, the route and the rules", func() {
+			Expect(retError).To(Succeed())
+			Expect(t.ports).To(BeEmpty())
+			Expect(t.routers[targetInfraID+"-external-router"].Routes).To(BeEmpty())
+			Expect(t.rules).To(BeEmpty())
+		})
+	})
+
+	When("the networks are not peered", func() {
+		It("should succeed without changes", func() {
+			Expect(retError).To(Succeed())
+		})
+	})
+
+	When("called with a non-RHOS Cloud", func() {
+		BeforeEach(func() {
+			t.target = &invalidCloud{}
+		})
+
+		It("should return an error", func() {
+			Expect(retError).ToNot(Succeed())
+		})
+	})
+}
+
+// peeringTestDriver simulates the Neutron resources of two clusters deployed in the same OpenStack cloud.
+type peeringTestDriver struct {
+	mockCtrl      *gomock.Controller
+	client        *fake.MockInterface
+	source        api.Cloud
+	target        api.Cloud
+	subnets       map[string]*subnets.Subnet
+	routers       map[string]*routers.Router
+	ports         []ports.Port
+	rules         []rules.SecGroupRule
+	createPortErr error
+}
+
+func newPeeringTestDriver() *peeringTestDriver {
+	t := &peeringTestDriver{}
+
+	BeforeEach(func() {
+		t.mockCtrl = gomock.NewController(GinkgoT())
+		t.client = fake.NewMockInterface(t.mockCtrl)
+		t.ports = nil
+		t.rules = nil
+		t.createPortErr = nil
+		t.subnets = map[string]*subnets.Subnet{
+			infraID + "-nodes":       newSubnet(infraID, sourceCIDR),
+			targetInfraID + "-nodes": newSubnet(targetInfraID, targetCIDR),
+		}
+		t.routers = map[string]*routers.Router{
+			infraID + "-external-router":       newRouter(infraID),
+			targetInfraID + "-external-router": newRouter(targetInfraID),
+		}
+
+		t.source = rhos.NewCloud(rhos.CloudInfo{RHOSClient: t.client, InfraID: infraID, Region: "test-region"})
+		t.target = rhos.NewCloud(rhos.CloudInfo{RHOSClient: t.client, InfraID: targetInfraID, Region: "test-region"})
+
+		t.expectSubnetsAndRouters()
+		t.expectPorts()
+		t.expectSecurityGroups()
+	})
+
+	AfterEach(func() {
+		t.mockCtrl.Finish()
+	})
+
+	return t
+}
+
+func (t *peeringTestDriver) expectSubnetsAndRouters() {
+	t.client.EXPECT().ListSubnets(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, opts subnets.ListOpts) ([]subnets.Subnet, error) {
+			if subnet, ok := t.subnets[opts.Name]; ok {
+				return []subnets.Subnet{*subnet}, nil
+			}
+
+			return nil, nil
+		}).AnyTimes()
+
+	t.client.EXPECT().ListRouters(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, opts routers.ListOpts) ([]routers.Router, error) {
+			if router, ok := t.routers[opts.Name]; ok {
+				return []routers.Router{*router}, nil
+			}
+
+			return nil, nil
+		}).AnyTimes()
+
+	t.client.EXPECT().UpdateRouterRoutes(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string, routes []routers.Route) error {
+			t.routers[id].Routes = routes
+			return nil
+		}).AnyTimes()
+}
+
+func (t *peeringTestDriver) expectPorts() {
+	t.client.EXPECT().ListPorts(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, opts ports.ListOpts) ([]ports.Port, error) {
+		var found []ports.Port
+
+		for i := range t.ports {
+			if t.ports[i].Name == opts.Name {
+				found = append(found, t.ports[i])
+			}
+		}
+
+		return found, nil
+	}).AnyTimes()
+
+	t.client.EXPECT().CreatePort(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, opts ports.CreateOpts) (*ports.Port, error) {
+		if t.createPortErr != nil {
+			return nil, t.createPortErr
+		}
+
+		Expect(opts.FixedIPs).To(Equal([]ports.IP{{SubnetID: t.subnets[targetInfraID+"-nodes"].ID}}))
+
+		t.ports = append(t.ports, ports.Port{
+			ID:        peeringPortID,
+			Name:      opts.Name,
+			NetworkID: opts.NetworkID,
+			FixedIPs:  []ports.IP{{SubnetID: t.subnets[targetInfraID+"-nodes"].ID, IPAddress: peeringIP}},
+		})
+
+		return &t.ports[len(t.ports)-1], nil
+	}).AnyTimes()
+
+	t.client.EXPECT().AddRouterInterface(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string, opts routers.AddInterfaceOpts) error {
+			t.port(opts.PortID).DeviceID = id
+			return nil
+		}).AnyTimes()
+
+	t.client.EXPECT().RemoveRouterInterface(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string, opts routers.RemoveInterfaceOpts) error {
+			Expect(t.port(opts.PortID).DeviceID).To(Equal(id))
+			return t.deletePort(opts.PortID)
+		}).AnyTimes()
+
+	t.client.EXPECT().DeletePort(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id string) error {
+		return t.deletePort(id)
+	}).AnyTimes()
+}
+
+func (t *peeringTestDriver) expectSecurityGroups() {
+	t.client.EXPECT().ListSecurityGroups(gomock.Any()).Return([]secgroups.SecurityGroup{
+		{ID: infraID + "-worker", Name: infraID + "-worker"},
+		{ID: targetInfraID + "-worker", Name: targetInfraID + "-worker"},
+	}, nil).AnyTimes()
+
+	t.client.EXPECT().ListSecurityGroupRules(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, opts rules.ListOpts) ([]rules.SecGroupRule, error) {
+			var found []rules.SecGroupRule
+
+			for i := range t.rules {
+				if t.rules[i].SecGroupID == opts.SecGroupID && t.rules[i].RemoteIPPrefix == opts.RemoteIPPrefix &&
+					t.rules[i].Description == opts.Description {
+					found = append(found, t.rules[i])
+				}
+			}
+
+			return found, nil
+		}).AnyTimes()
+
+	t.client.EXPECT().CreateSecurityGroupRule(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, opts rules.CreateOpts) (*rules.SecGroupRule, error) {
+			t.rules = append(t.rules, rules.SecGroupRule{
+				ID:             fmt.Sprintf("rule-%d", len(t.rules)),
+				SecGroupID:     opts.SecGroupID,
+				Protocol:       string(opts.Protocol),
+				PortRangeMin:   opts.PortRangeMin,
+				PortRangeMax:   opts.PortRangeMax,
+				RemoteIPPrefix: opts.RemoteIPPrefix,
+				Description:    opts.Description,
+			})
+
+			return &t.rules[len(t.rules)-1], nil
+		}).AnyTimes()
+
+	t.client.EXPECT().DeleteSecurityGroupRule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id string) error {
+		for i := range t.rules {
+			if t.rules[i].ID == id {
+				t.rules = append(t.rules[:i], t.rules[i+1:]...)
+				return nil
+			}
+		}
+
+		return gophercloud.ErrDefault404{}
+	}).AnyTimes()
+}
+
+func (t *peeringTestDriver) port(id string) *ports.Port {
+	for i := range t.ports {
+		if t.ports[i].ID == id {
+			return &t.ports[i]
+		}
+	}
+
+	Fail("port " + id + " not found")
+
+	return nil
+}
+
+func (t *peeringTestDriver) deletePort(id string) error {
+	for i := range t.ports {
+		if t.ports[i].ID == id {
+			t.ports = append(t.ports[:i], t.ports[i+1:]...)
+			return nil
+		}
+	}
+
+	return gophercloud.ErrDefault404{}
+}
+
+func (t *peeringTestDriver) assertPeeringRules(groupID, cidr string) {
+	count := 0
+
+	for i := range t.rules {
+		if t.rules[i].SecGroupID == groupID {
+			Expect(t.rules[i].RemoteIPPrefix).To(Equal(cidr))
+			Expect(t.rules[i].Description).To(Equal("Submariner peering traffic from " + cidr))
+
+			count++
+		}
+	}
+
+	Expect(count).To(Equal(5))
+}
+
+func newSubnet(infraID, cidr string) *subnets.Subnet {
+	return &subnets.Subnet{
+		ID:        infraID + "-nodes",
+		Name:      infraID + "-nodes",
+		NetworkID: infraID + "-network",
+		CIDR:      cidr,
+	}
+}
+
+func newRouter(infraID string) *routers.Router {
+	return &routers.Router{
+		ID:   infraID + "-external-router",
+		Name: infraID + "-external-router",
+	}
+}
+
+type invalidCloud struct {
+	api.Cloud
+}